@@ -16,6 +16,7 @@ import (
 	yaml "gopkg.in/yaml.v3"
 
 	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/health"
 	httpdocs "github.com/benthosdev/benthos/v4/internal/http/docs"
 	"github.com/benthosdev/benthos/v4/internal/log"
 )
@@ -32,6 +33,7 @@ type Config struct {
 	CertFile       string              `json:"cert_file" yaml:"cert_file"`
 	KeyFile        string              `json:"key_file" yaml:"key_file"`
 	CORS           httpdocs.ServerCORS `json:"cors" yaml:"cors"`
+	Health         health.Config       `json:"health" yaml:"health"`
 }
 
 // NewConfig creates a new API config with default values.
@@ -45,6 +47,7 @@ func NewConfig() Config {
 		CertFile:       "",
 		KeyFile:        "",
 		CORS:           httpdocs.NewServerCORS(),
+		Health:         health.NewConfig(),
 	}
 }
 
@@ -2,6 +2,7 @@ package api
 
 import (
 	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/health"
 	httpdocs "github.com/benthosdev/benthos/v4/internal/http/docs"
 )
 
@@ -19,6 +20,7 @@ func Spec() docs.FieldSpecs {
 		docs.FieldString("cert_file", "An optional certificate file for enabling TLS.").Advanced().HasDefault(""),
 		docs.FieldString("key_file", "An optional key file for enabling TLS.").Advanced().HasDefault(""),
 		httpdocs.ServerCORSFieldSpec(),
+		health.Spec(),
 		docs.FieldDeprecated("read_timeout"),
 	}
 }
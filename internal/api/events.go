@@ -0,0 +1,236 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event describes a change in the state of a service, such as a stream being
+// created or a component losing its connection, that is pushed to clients of an
+// event stream.
+type Event struct {
+	ID      uint64            `json:"id"`
+	Type    string            `json:"type"`
+	Stream  string            `json:"stream,omitempty"`
+	Time    time.Time         `json:"time"`
+	Message string            `json:"message,omitempty"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+// Events is a broadcaster of service events to any number of subscribers. A
+// bounded history of recent events is kept so that clients that reconnect can
+// catch up on the events they missed.
+//
+// Publishing an event never blocks, subscribers that are unable to keep up
+// with the rate of events have events dropped.
+type Events struct {
+	historySize int
+	history     []Event
+	nextID      uint64
+	subs        map[*eventSub]struct{}
+	closed      bool
+	mut         sync.Mutex
+
+	keepAlive time.Duration
+}
+
+type eventSub struct {
+	c chan Event
+}
+
+// NewEvents creates a new event broadcaster that retains up to historySize of
+// the most recent events.
+func NewEvents(historySize int) *Events {
+	return &Events{
+		historySize: historySize,
+		nextID:      1,
+		subs:        map[*eventSub]struct{}{},
+		keepAlive:   time.Second * 15,
+	}
+}
+
+// Publish an event to all subscribers. The ID and time of the event are set by
+// the broadcaster.
+func (e *Events) Publish(ev Event) {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+
+	if e.closed {
+		return
+	}
+
+	ev.ID = e.nextID
+	e.nextID++
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+
+	if e.historySize > 0 {
+		if len(e.history) >= e.historySize {
+			e.history = append(e.history[:0], e.history[1:]...)
+		}
+		e.history = append(e.history, ev)
+	}
+
+	for s := range e.subs {
+		select {
+		case s.c <- ev:
+		default:
+		}
+	}
+}
+
+// Subscribe to events, returning the events from history with an ID greater
+// than afterID, followed by a channel of all subsequent events and a func for
+// ending the subscription. The channel is closed when either the subscription
+// ends or the broadcaster is closed.
+func (e *Events) Subscribe(afterID uint64) (history []Event, events <-chan Event, done func()) {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+
+	for _, ev := range e.history {
+		if ev.ID > afterID {
+			history = append(history, ev)
+		}
+	}
+
+	s := &eventSub{c: make(chan Event, 100)}
+	if e.closed {
+		close(s.c)
+		return history, s.c, func() {}
+	}
+	e.subs[s] = struct{}{}
+
+	var once sync.Once
+	return history, s.c, func() {
+		once.Do(func() {
+			e.mut.Lock()
+			if _, exists := e.subs[s]; exists {
+				delete(e.subs, s)
+				close(s.c)
+			}
+			e.mut.Unlock()
+		})
+	}
+}
+
+// Close the broadcaster, ending all subscriptions.
+func (e *Events) Close() {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+
+	if e.closed {
+		return
+	}
+	e.closed = true
+	for s := range e.subs {
+		close(s.c)
+	}
+	e.subs = map[*eventSub]struct{}{}
+}
+
+//------------------------------------------------------------------------------
+
+func csvSet(v string) map[string]struct{} {
+	if v == "" {
+		return nil
+	}
+	set := map[string]struct{}{}
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			set[s] = struct{}{}
+		}
+	}
+	return set
+}
+
+// HandleSSE is an http.HandlerFunc that streams events to the client as
+// server-sent events. Events can be filtered with the URL params `stream` and
+// `type`, each a comma separated list, and when the client reconnects with a
+// `Last-Event-ID` header the events it missed are sent from history.
+func (e *Events) HandleSSE(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	var afterID uint64
+	if lastID := r.Header.Get("Last-Event-ID"); lastID != "" {
+		var err error
+		if afterID, err = strconv.ParseUint(lastID, 10, 64); err != nil {
+			http.Error(w, fmt.Sprintf("invalid Last-Event-ID: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	streams := csvSet(r.URL.Query().Get("stream"))
+	types := csvSet(r.URL.Query().Get("type"))
+	matches := func(ev Event) bool {
+		if streams != nil {
+			if _, exists := streams[ev.Stream]; !exists {
+				return false
+			}
+		}
+		if types != nil {
+			if _, exists := types[ev.Type]; !exists {
+				return false
+			}
+		}
+		return true
+	}
+
+	history, events, done := e.Subscribe(afterID)
+	defer done()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	write := func(ev Event) error {
+		if !matches(ev) {
+			return nil
+		}
+		data, err := json.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "id: %v\nevent: %v\ndata: %s\n\n", ev.ID, ev.Type, data)
+		return err
+	}
+
+	for _, ev := range history {
+		if err := write(ev); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(e.keepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case ev, open := <-events:
+			if !open {
+				return
+			}
+			if err := write(ev); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
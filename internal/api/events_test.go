@@ -0,0 +1,118 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventsHistory(t *testing.T) {
+	e := NewEvents(2)
+
+	e.Publish(Event{Type: "foo"})
+	e.Publish(Event{Type: "bar"})
+	e.Publish(Event{Type: "baz"})
+
+	history, events, done := e.Subscribe(0)
+	require.Len(t, history, 2)
+	assert.Equal(t, uint64(2), history[0].ID)
+	assert.Equal(t, "bar", history[0].Type)
+	assert.Equal(t, uint64(3), history[1].ID)
+	assert.Equal(t, "baz", history[1].Type)
+
+	history, _, done2 := e.Subscribe(2)
+	require.Len(t, history, 1)
+	assert.Equal(t, "baz", history[0].Type)
+	done2()
+
+	e.Publish(Event{Type: "buz"})
+	ev := <-events
+	assert.Equal(t, uint64(4), ev.ID)
+	assert.Equal(t, "buz", ev.Type)
+	assert.False(t, ev.Time.IsZero())
+
+	done()
+	_, open := <-events
+	assert.False(t, open)
+
+	_, events, _ = e.Subscribe(0)
+	e.Close()
+	_, open = <-events
+	assert.False(t, open)
+}
+
+func TestEventsSSE(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	e := NewEvents(10)
+	e.Publish(Event{Type: "created", Stream: "foo"})
+	e.Publish(Event{Type: "created", Stream: "bar"})
+
+	server := httptest.NewServer(http.HandlerFunc(e.HandleSSE))
+	defer server.Close()
+
+	req, err := http.NewRequestWithContext(tCtx, "GET", server.URL+"?stream=foo", http.NoBody)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "0")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	scanner := bufio.NewScanner(res.Body)
+	readEvent := func() (lines []string) {
+		t.Helper()
+		for scanner.Scan() {
+			if scanner.Text() == "" {
+				return
+			}
+			lines = append(lines, scanner.Text())
+		}
+		t.Fatal(scanner.Err())
+		return
+	}
+
+	lines := readEvent()
+	require.Len(t, lines, 3)
+	assert.Equal(t, "id: 1", lines[0])
+	assert.Equal(t, "event: created", lines[1])
+
+	var ev Event
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(lines[2], "data: ")), &ev))
+	assert.Equal(t, "foo", ev.Stream)
+
+	e.Publish(Event{Type: "deleted", Stream: "bar"})
+	e.Publish(Event{Type: "deleted", Stream: "foo", Message: "meow"})
+
+	lines = readEvent()
+	require.Len(t, lines, 3)
+	assert.Equal(t, "id: 4", lines[0])
+	assert.Equal(t, "event: deleted", lines[1])
+	assert.Contains(t, lines[2], `"message":"meow"`)
+
+	e.Close()
+	assert.False(t, scanner.Scan())
+}
+
+func TestEventsSSEBadLastID(t *testing.T) {
+	e := NewEvents(10)
+
+	req := httptest.NewRequest("GET", "/", http.NoBody)
+	req.Header.Set("Last-Event-ID", "nope")
+
+	res := httptest.NewRecorder()
+	e.HandleSSE(res, req)
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+}
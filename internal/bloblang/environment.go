@@ -168,6 +168,16 @@ func (e *Environment) WithoutFunctions(names ...string) *Environment {
 	return &env
 }
 
+// WithTracing returns a copy of the environment where parsed mappings record
+// the sections of the mapping that their functions, methods and match cases
+// were parsed from, allowing the steps of their executions to be traced in
+// detail.
+func (e *Environment) WithTracing() *Environment {
+	env := *e
+	env.pCtx = env.pCtx.WithTracing()
+	return &env
+}
+
 // WithMaxMapRecursion returns a copy of the environment where the maximum
 // recursion allowed for maps is set to a given value. If the execution of a
 // mapping from this environment matches this number of recursive map calls the
@@ -130,6 +130,10 @@ func (m *MetaAssignment) Target() TargetPath {
 // value.
 type JSONAssignment struct {
 	path []string
+
+	// noClone is set when the assigned values are known to be newly allocated
+	// and can therefore be assigned without being copied.
+	noClone bool
 }
 
 // NewJSONAssignment creates a new JSON assignment.
@@ -162,7 +166,7 @@ func findTheNonObject(gObj *gabs.Container, allowArray bool, paths ...string) (c
 // Apply a value to the target JSON path.
 func (j *JSONAssignment) Apply(value interface{}, ctx AssignmentContext) error {
 	_, deleted := value.(query.Delete)
-	if !deleted {
+	if !deleted && !j.noClone {
 		value = query.IClone(value)
 	}
 	if len(j.path) == 0 {
@@ -38,25 +38,6 @@ func LineAndColOf(input, clip []rune) (line, col int) {
 
 //------------------------------------------------------------------------------
 
-// Statement describes an isolated mapping statement, where the result of a
-// query function is to be mapped according to an Assignment.
-type Statement struct {
-	input      []rune
-	assignment Assignment
-	query      query.Function
-}
-
-// NewStatement initialises a new mapping statement from an Assignment and
-// query.Function. The input parameter is an optional slice pointing to the
-// parsed expression that created the statement.
-func NewStatement(input []rune, assignment Assignment, query query.Function) Statement {
-	return Statement{
-		input, assignment, query,
-	}
-}
-
-//------------------------------------------------------------------------------
-
 // Executor is a parsed bloblang mapping that can be executed on a Benthos
 // message.
 type Executor struct {
@@ -64,8 +45,11 @@ type Executor struct {
 	input      []rune
 	maps       map[string]query.Function
 	statements []Statement
+	params     []string
 
 	maxMapStacks int
+	fieldCache   *query.FieldCachePlan
+	sources      *SourceMap
 }
 
 const defaultMaxMapStacks = 5000
@@ -75,14 +59,84 @@ const defaultMaxMapStacks = 5000
 // is an optional slice pointing to the parsed expression that created the
 // executor.
 func NewExecutor(annotation string, input []rune, maps map[string]query.Function, statements ...Statement) *Executor {
-	return &Executor{annotation, input, maps, statements, defaultMaxMapStacks}
+	return &Executor{
+		annotation:   annotation,
+		input:        input,
+		maps:         maps,
+		statements:   statements,
+		maxMapStacks: defaultMaxMapStacks,
+	}
 }
 
 // SetMaxMapRecursion configures the maximum recursion allowed for maps, if the
 // execution of this mapping matches this number of recursive map calls the
-// mapping will error out.
+// mapping will error out. The limit is also applied to maps declared within the
+// mapping.
 func (e *Executor) SetMaxMapRecursion(m int) {
 	e.maxMapStacks = m
+	for _, v := range e.maps {
+		if mExec, ok := v.(*Executor); ok {
+			mExec.maxMapStacks = m
+		}
+	}
+}
+
+// SetParams configures the names of parameters accepted by the mapping when it
+// is executed as a map, the arguments of which are bound to variables.
+func (e *Executor) SetParams(params ...string) {
+	e.params = params
+}
+
+// Params returns the names of parameters accepted by the mapping when it is
+// executed as a map.
+func (e *Executor) Params() []string {
+	return e.params
+}
+
+// Optimise prepares the mapping, and any maps declared within it, for faster
+// execution. Field paths of the input document that share a common prefix are
+// configured to resolve the prefix once per execution, and assignments of
+// values that are newly allocated by their query are configured to skip
+// copying them. This should be called once the mapping is fully parsed and
+// before it is executed.
+func (e *Executor) Optimise() {
+	e.optimise(map[*Executor]struct{}{})
+}
+
+func (e *Executor) optimise(visited map[*Executor]struct{}) {
+	if _, exists := visited[e]; exists {
+		return
+	}
+	visited[e] = struct{}{}
+
+	var queries []query.Function
+	optimiseStatements(e.statements, &queries)
+	e.fieldCache = query.OptimiseFieldPaths(queries...)
+
+	for _, v := range e.maps {
+		if mExec, ok := v.(*Executor); ok {
+			mExec.optimise(visited)
+		}
+	}
+}
+
+func optimiseStatements(stmts []Statement, queries *[]query.Function) {
+	for _, stmt := range stmts {
+		switch t := stmt.(type) {
+		case *AssignmentStatement:
+			*queries = append(*queries, t.query)
+			if j, ok := t.assignment.(*JSONAssignment); ok {
+				j.noClone = query.IsFreshResult(t.query)
+			}
+		case *IfStatement:
+			for _, c := range t.cases {
+				if c.query != nil {
+					*queries = append(*queries, c.query)
+				}
+				optimiseStatements(c.statements, queries)
+			}
+		}
+	}
 }
 
 // Annotation returns a string annotation that describes the mapping executor.
@@ -166,40 +220,41 @@ func (e *Executor) mapPart(appendTo *message.Part, index int, reference Message)
 
 	vars := map[string]interface{}{}
 
+	fnCtx := query.FunctionContext{
+		Maps:     e.maps,
+		Vars:     vars,
+		Index:    index,
+		MsgBatch: reference,
+		NewMeta:  newPart,
+		NewValue: &newValue,
+	}.WithValueFunc(lazyValue).WithFieldCache(e.fieldCache)
+	asCtx := AssignmentContext{
+		Vars:  vars,
+		Meta:  newPart,
+		Value: &newValue,
+	}
+
 	for _, stmt := range e.statements {
-		res, err := stmt.query.Exec(query.FunctionContext{
-			Maps:     e.maps,
-			Vars:     vars,
-			Index:    index,
-			MsgBatch: reference,
-			NewMeta:  newPart,
-			NewValue: &newValue,
-		}.WithValueFunc(lazyValue))
-		if err != nil {
-			var line int
-			if len(e.input) > 0 && len(stmt.input) > 0 {
-				line, _ = LineAndColOf(e.input, stmt.input)
-			}
-			if parseErr != nil && errors.Is(err, query.ErrNoContext) {
-				err = fmt.Errorf("unable to reference message as structured (with 'this'): %w", parseErr)
-			}
-			return nil, fmt.Errorf("failed assignment (line %v): %w", line, err)
-		}
-		if _, isNothing := res.(query.Nothing); isNothing {
-			// Skip assignment entirely
+		err := stmt.Execute(fnCtx, asCtx)
+		if err == nil {
 			continue
 		}
-		if err = stmt.assignment.Apply(res, AssignmentContext{
-			Vars:  vars,
-			Meta:  newPart,
-			Value: &newValue,
-		}); err != nil {
-			var line int
-			if len(e.input) > 0 && len(stmt.input) > 0 {
-				line, _ = LineAndColOf(e.input, stmt.input)
-			}
-			return nil, fmt.Errorf("failed to assign result (line %v): %w", line, err)
+		sErr, ok := err.(*statementErr)
+		if !ok {
+			return nil, err
 		}
+		var line int
+		if len(e.input) > 0 && len(sErr.input) > 0 {
+			line, _ = LineAndColOf(e.input, sErr.input)
+		}
+		if !sErr.onExec {
+			return nil, fmt.Errorf("failed to assign result (line %v): %w", line, sErr.err)
+		}
+		err = sErr.err
+		if parseErr != nil && errors.Is(err, query.ErrNoContext) {
+			err = fmt.Errorf("unable to reference message as structured (with 'this'): %w", parseErr)
+		}
+		return nil, fmt.Errorf("failed assignment (line %v): %w", line, err)
 	}
 
 	switch newValue.(type) {
@@ -230,7 +285,7 @@ func (e *Executor) QueryTargets(ctx query.TargetsContext) (query.TargetsContext,
 
 	var paths []query.TargetPath
 	for _, stmt := range e.statements {
-		_, tmpPaths := stmt.query.QueryTargets(childCtx)
+		_, tmpPaths := stmt.QueryTargets(childCtx)
 		paths = append(paths, tmpPaths...)
 	}
 
@@ -242,7 +297,7 @@ func (e *Executor) QueryTargets(ctx query.TargetsContext) (query.TargetsContext,
 func (e *Executor) AssignmentTargets() []TargetPath {
 	var paths []TargetPath
 	for _, stmt := range e.statements {
-		paths = append(paths, stmt.assignment.Target())
+		paths = append(paths, stmt.AssignmentTargets()...)
 	}
 	return paths
 }
@@ -256,22 +311,16 @@ func (e *Executor) Exec(ctx query.FunctionContext) (interface{}, error) {
 
 	var newObj interface{} = query.Nothing(nil)
 	ctx.NewValue = &newObj
+	ctx = ctx.WithFieldCache(e.fieldCache)
 
+	asCtx := AssignmentContext{
+		Vars: ctx.Vars,
+		// Meta: meta, Prevented for now due to .from(int)
+		Value: &newObj,
+	}
 	for _, stmt := range e.statements {
-		res, err := stmt.query.Exec(ctx)
-		if err != nil {
-			return nil, formatExecErr(err, true, e.input, stmt.input)
-		}
-		if _, isNothing := res.(query.Nothing); isNothing {
-			// Skip assignment entirely
-			continue
-		}
-		if err = stmt.assignment.Apply(res, AssignmentContext{
-			Vars: ctx.Vars,
-			// Meta: meta, Prevented for now due to .from(int)
-			Value: &newObj,
-		}); err != nil {
-			return nil, formatExecErr(err, false, e.input, stmt.input)
+		if err := stmt.Execute(ctx, asCtx); err != nil {
+			return nil, formatExecErr(err, e.input)
 		}
 	}
 
@@ -280,17 +329,10 @@ func (e *Executor) Exec(ctx query.FunctionContext) (interface{}, error) {
 
 // ExecOnto a provided assignment context.
 func (e *Executor) ExecOnto(ctx query.FunctionContext, onto AssignmentContext) error {
+	ctx = ctx.WithFieldCache(e.fieldCache)
 	for _, stmt := range e.statements {
-		res, err := stmt.query.Exec(ctx)
-		if err != nil {
-			return formatExecErr(err, true, e.input, stmt.input)
-		}
-		if _, isNothing := res.(query.Nothing); isNothing {
-			// Skip assignment entirely
-			continue
-		}
-		if err = stmt.assignment.Apply(res, onto); err != nil {
-			return formatExecErr(err, false, e.input, stmt.input)
+		if err := stmt.Execute(ctx, onto); err != nil {
+			return formatExecErr(err, e.input)
 		}
 	}
 	return nil
@@ -350,15 +392,21 @@ func (e *errStacks) Error() string {
 	return fmt.Sprintf("entering %v exceeded maximum allowed stacks of %v, this could be due to unbounded recursion", e.annotation, e.maxStacks)
 }
 
-func formatExecErr(err error, onExec bool, input, stmtInput []rune) error {
+func formatExecErr(err error, input []rune) error {
+	sErr, ok := err.(*statementErr)
+	if !ok {
+		return err
+	}
+	err = sErr.err
+
 	var u *failedAssignmentErr
 	if errors.As(err, &u) {
 		return u
 	}
 
 	var line int
-	if len(input) > 0 && len(stmtInput) > 0 {
-		line, _ = LineAndColOf(input, stmtInput)
+	if len(input) > 0 && len(sErr.input) > 0 {
+		line, _ = LineAndColOf(input, sErr.input)
 	}
 
 	var e *errStacks
@@ -368,7 +416,7 @@ func formatExecErr(err error, onExec bool, input, stmtInput []rune) error {
 
 	return &failedAssignmentErr{
 		line:   line,
-		onExec: onExec,
+		onExec: sErr.onExec,
 		err:    err,
 	}
 }
@@ -213,6 +213,64 @@ func TestAssignments(t *testing.T) {
 				},
 			},
 		},
+		"if statement": {
+			mapping: NewExecutor("", nil, nil,
+				NewStatement(nil, NewJSONAssignment("foo"), query.NewLiteralFunction("", "first")),
+				NewIfStatement().
+					Add(nil, query.NewFieldFunction("nope"),
+						NewStatement(nil, NewJSONAssignment("foo"), query.NewLiteralFunction("", "second")),
+					).
+					Add(nil, query.NewFieldFunction("yep"),
+						NewStatement(nil, NewVarAssignment("bar"), query.NewLiteralFunction("", "third")),
+						NewStatement(nil, NewMetaAssignment(metaKey("foo")), initFunc("var", "bar")),
+					).
+					Add(nil, nil,
+						NewStatement(nil, NewJSONAssignment("foo"), query.NewLiteralFunction("", "fourth")),
+					),
+			),
+			input: []part{{Content: `{"nope":false,"yep":true}`}},
+			output: &part{
+				Content: `{"foo":"first"}`,
+				Meta: map[string]string{
+					"foo": "third",
+				},
+			},
+		},
+		"if statement else": {
+			mapping: NewExecutor("", nil, nil,
+				NewIfStatement().
+					Add(nil, query.NewFieldFunction("nope"),
+						NewStatement(nil, NewJSONAssignment("foo"), query.NewLiteralFunction("", "second")),
+					).
+					Add(nil, nil,
+						NewStatement(nil, NewJSONAssignment("foo"), query.NewLiteralFunction("", "fourth")),
+					),
+			),
+			input:  []part{{Content: `{"nope":false}`}},
+			output: &part{Content: `{"foo":"fourth"}`},
+		},
+		"if statement non bool condition": {
+			mapping: NewExecutor("", nil, nil,
+				NewIfStatement().
+					Add(nil, query.NewFieldFunction("nope"),
+						NewStatement(nil, NewJSONAssignment("foo"), query.NewLiteralFunction("", "second")),
+					),
+			),
+			input: []part{{Content: `{"nope":"true"}`}},
+			err:   errors.New("failed assignment (line 0): expected bool value, got string from field `this.nope` (\"true\")"),
+		},
+		"if statement nested error": {
+			mapping: NewExecutor("", []rune("foo\nbar"), nil,
+				NewIfStatement().
+					Add([]rune("foo\nbar"), query.NewFieldFunction("yep"),
+						NewStatement([]rune("bar"), NewJSONAssignment("foo"), query.ClosureFunction("", func(ctx query.FunctionContext) (interface{}, error) {
+							return nil, errors.New("nope")
+						}, nil)),
+					),
+			),
+			input: []part{{Content: `{"yep":true}`}},
+			err:   errors.New("failed assignment (line 2): nope"),
+		},
 		"invalid json message": {
 			mapping: NewExecutor("", nil, nil,
 				NewStatement(nil, NewJSONAssignment("bar"), query.NewLiteralFunction("", "test2")),
@@ -327,6 +385,26 @@ func TestTargets(t *testing.T) {
 				NewTargetPath(TargetVariable, "baz"),
 			},
 		},
+		{
+			mapping: NewExecutor("", nil, nil,
+				NewIfStatement().
+					Add(nil, query.NewFieldFunction("first"),
+						NewStatement(nil, NewJSONAssignment("foo"), query.NewFieldFunction("second")),
+					).
+					Add(nil, nil,
+						NewStatement(nil, NewVarAssignment("baz"), function("meta", "third")),
+					),
+			),
+			queryTargets: []query.TargetPath{
+				query.NewTargetPath(query.TargetValue, "first"),
+				query.NewTargetPath(query.TargetValue, "second"),
+				query.NewTargetPath(query.TargetMetadata, "third"),
+			},
+			assignmentTargets: []TargetPath{
+				NewTargetPath(TargetValue, "foo"),
+				NewTargetPath(TargetVariable, "baz"),
+			},
+		},
 	}
 
 	for i, test := range tests {
@@ -0,0 +1,215 @@
+package mapping
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+)
+
+// Lint describes a potential problem within a mapping that was found by static
+// analysis, but doesn't prevent the mapping from being executed.
+type Lint struct {
+	Line   int
+	Column int
+	What   string
+}
+
+// Lint performs a static analysis of the mapping and returns any potential
+// problems found, ordered by their position within the mapping. The input
+// schema describes the documents that the mapping will be executed upon and
+// can be nil when nothing is known about them.
+//
+// Lints are reported for queries that would definitely fail due to the types
+// of their values, unreachable branches and match cases, variables that are
+// unused or referenced without being declared, and deprecated functions and
+// methods.
+func (e *Executor) Lint(input *query.ValueSchema) []Lint {
+	var lints []Lint
+
+	ctx := query.NewAnalysisContext(e.maps, input)
+	ctx.Vars = map[string]*query.AnalysisVar{}
+	newLinter(e.input, &lints).lintExecutor(ctx, e)
+
+	mapNames := make([]string, 0, len(e.maps))
+	for k := range e.maps {
+		mapNames = append(mapNames, k)
+	}
+	sort.Strings(mapNames)
+
+	for _, k := range mapNames {
+		mExec, ok := e.maps[k].(*Executor)
+		if !ok || !isSuffixOf(e.input, mExec.input) {
+			// Maps imported from other files are linted separately.
+			continue
+		}
+
+		mCtx := query.NewAnalysisContext(e.maps, nil)
+		mCtx.Vars = map[string]*query.AnalysisVar{}
+		for _, p := range mExec.params {
+			mCtx.Vars[p] = &query.AnalysisVar{Used: true}
+		}
+		newLinter(e.input, &lints).lintExecutor(mCtx, mExec)
+	}
+
+	sort.SliceStable(lints, func(i, j int) bool {
+		if lints[i].Line == lints[j].Line {
+			return lints[i].Column < lints[j].Column
+		}
+		return lints[i].Line < lints[j].Line
+	})
+	return lints
+}
+
+// isSuffixOf returns true if the clip is a tailing slice of the same input.
+func isSuffixOf(input, clip []rune) bool {
+	if len(clip) == 0 || len(clip) > len(input) {
+		return false
+	}
+	return &input[len(input)-len(clip)] == &clip[0]
+}
+
+//------------------------------------------------------------------------------
+
+type linter struct {
+	input    []rune
+	lints    *[]Lint
+	declared map[string][]rune
+}
+
+func newLinter(input []rune, lints *[]Lint) *linter {
+	return &linter{
+		input:    input,
+		lints:    lints,
+		declared: map[string][]rune{},
+	}
+}
+
+func (l *linter) add(clip []rune, warnings ...string) {
+	if len(warnings) == 0 || !isSuffixOf(l.input, clip) {
+		return
+	}
+	line, col := LineAndColOf(l.input, clip)
+	for _, w := range warnings {
+		*l.lints = append(*l.lints, Lint{Line: line, Column: col, What: w})
+	}
+}
+
+func (l *linter) lintExecutor(ctx query.AnalysisContext, e *Executor) {
+	l.lintStatements(ctx, e.statements)
+	for _, name := range ctx.UnusedVars() {
+		l.add(l.declared[name], fmt.Sprintf("variable %v is declared but never used", name))
+	}
+}
+
+func (l *linter) lintStatements(ctx query.AnalysisContext, statements []Statement) {
+	for _, stmt := range statements {
+		switch t := stmt.(type) {
+		case *AssignmentStatement:
+			l.lintAssignment(ctx, t)
+		case *IfStatement:
+			l.lintIf(ctx, t)
+		}
+	}
+}
+
+func (l *linter) lintAssignment(ctx query.AnalysisContext, stmt *AssignmentStatement) {
+	sCtx := ctx.WithNewWarnings()
+	schema := query.Analyse(sCtx, stmt.query)
+	l.add(stmt.input, sCtx.Warnings()...)
+
+	varAssignment, isVar := stmt.assignment.(*VarAssignment)
+	if !isVar {
+		return
+	}
+
+	switch schema.ValueType() {
+	case query.ValueDelete:
+		delete(ctx.Vars, varAssignment.name)
+		return
+	case query.ValueNothing:
+		return
+	}
+
+	var used bool
+	if existing, exists := ctx.Vars[varAssignment.name]; exists {
+		used = existing.Used
+	}
+	ctx.Vars[varAssignment.name] = &query.AnalysisVar{
+		Schema: schema,
+		Used:   used,
+	}
+	if _, exists := l.declared[varAssignment.name]; !exists {
+		l.declared[varAssignment.name] = stmt.input
+	}
+}
+
+func (l *linter) lintIf(ctx query.AnalysisContext, stmt *IfStatement) {
+	branchVars := make([]map[string]*query.AnalysisVar, 0, len(stmt.cases)+1)
+
+	exhausted := false
+	for _, c := range stmt.cases {
+		if exhausted {
+			l.add(c.input, "if statement: branch is unreachable as a previous condition is always true")
+		} else if c.query != nil {
+			sCtx := ctx.WithNewWarnings()
+			switch query.AnalyseCondition(sCtx, "if statement", c.query) {
+			case query.ConditionAlwaysTrue:
+				exhausted = true
+			case query.ConditionNeverTrue:
+				sCtx.Warnf("if statement: branch is unreachable as its condition is never true")
+			}
+			l.add(c.input, sCtx.Warnings()...)
+		} else {
+			exhausted = true
+		}
+
+		bCtx := ctx
+		bCtx.Vars = make(map[string]*query.AnalysisVar, len(ctx.Vars))
+		for k, v := range ctx.Vars {
+			bCtx.Vars[k] = v
+		}
+		l.lintStatements(bCtx, c.statements)
+		branchVars = append(branchVars, bCtx.Vars)
+	}
+	if !exhausted {
+		// None of the branches might be executed.
+		branchVars = append(branchVars, ctx.Vars)
+	}
+
+	changed := map[string]struct{}{}
+	for _, vars := range branchVars {
+		for k, v := range vars {
+			if ctx.Vars[k] != v {
+				changed[k] = struct{}{}
+			}
+		}
+		for k := range ctx.Vars {
+			if _, exists := vars[k]; !exists {
+				changed[k] = struct{}{}
+			}
+		}
+	}
+
+	for k := range changed {
+		var merged *query.AnalysisVar
+		for _, vars := range branchVars {
+			v, exists := vars[k]
+			if !exists {
+				if merged == nil {
+					merged = &query.AnalysisVar{}
+				}
+				merged.Partial = true
+				continue
+			}
+			if merged == nil {
+				merged = &query.AnalysisVar{Schema: v.Schema}
+			} else if merged.Schema != v.Schema {
+				merged.Schema = query.MergeValueSchemas(merged.Schema, v.Schema)
+			}
+			merged.Partial = merged.Partial || v.Partial
+			merged.Used = merged.Used || v.Used
+		}
+		ctx.Vars[k] = merged
+	}
+}
@@ -0,0 +1,193 @@
+package mapping
+
+import (
+	"fmt"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+)
+
+// Statement describes an isolated mapping statement, which is executed with a
+// function context and applies its results to an assignment context.
+type Statement interface {
+	QueryTargets(ctx query.TargetsContext) (query.TargetsContext, []query.TargetPath)
+	AssignmentTargets() []TargetPath
+	Execute(fnCtx query.FunctionContext, asCtx AssignmentContext) error
+}
+
+// statementErr is returned by statements in order to annotate errors with the
+// input of the statement that produced them, allowing executors to report the
+// line of the failing statement even when it is nested.
+type statementErr struct {
+	input  []rune
+	onExec bool
+	err    error
+}
+
+func (s *statementErr) Unwrap() error {
+	return s.err
+}
+
+func (s *statementErr) Error() string {
+	return s.err.Error()
+}
+
+//------------------------------------------------------------------------------
+
+// AssignmentStatement describes a mapping statement where the result of a
+// query function is to be mapped according to an Assignment.
+type AssignmentStatement struct {
+	input      []rune
+	assignment Assignment
+	query      query.Function
+}
+
+// NewStatement initialises a new mapping statement from an Assignment and
+// query.Function. The input parameter is an optional slice pointing to the
+// parsed expression that created the statement.
+func NewStatement(input []rune, assignment Assignment, query query.Function) *AssignmentStatement {
+	return &AssignmentStatement{
+		input, assignment, query,
+	}
+}
+
+// QueryTargets returns a slice of all targets referenced by the query of the
+// statement.
+func (s *AssignmentStatement) QueryTargets(ctx query.TargetsContext) (query.TargetsContext, []query.TargetPath) {
+	return s.query.QueryTargets(ctx)
+}
+
+// AssignmentTargets returns the target assigned to by the statement.
+func (s *AssignmentStatement) AssignmentTargets() []TargetPath {
+	return []TargetPath{s.assignment.Target()}
+}
+
+// Execute the query of the statement and apply the result to an assignment
+// context.
+func (s *AssignmentStatement) Execute(fnCtx query.FunctionContext, asCtx AssignmentContext) error {
+	res, err := s.query.Exec(fnCtx)
+	if err != nil {
+		s.trace(fnCtx, nil, err)
+		return &statementErr{input: s.input, onExec: true, err: err}
+	}
+	if _, isNothing := res.(query.Nothing); isNothing {
+		// Skip assignment entirely
+		s.trace(fnCtx, res, nil)
+		return nil
+	}
+	if err = s.assignment.Apply(res, asCtx); err != nil {
+		s.trace(fnCtx, nil, err)
+		return &statementErr{input: s.input, onExec: false, err: err}
+	}
+	s.trace(fnCtx, res, nil)
+	return nil
+}
+
+func (s *AssignmentStatement) trace(fnCtx query.FunctionContext, value interface{}, err error) {
+	if !fnCtx.Tracing() {
+		return
+	}
+	fnCtx.Trace(query.TraceEvent{
+		Kind:   query.TraceAssignment,
+		Input:  s.input,
+		Target: s.assignment.Target().String(),
+		Value:  value,
+		Err:    err,
+	})
+}
+
+//------------------------------------------------------------------------------
+
+type ifStatementCase struct {
+	input      []rune
+	query      query.Function
+	statements []Statement
+}
+
+// IfStatement describes a mapping statement that executes the statements of
+// the first case where the query resolves to true, where a case without a
+// query acts as an else block.
+type IfStatement struct {
+	cases []ifStatementCase
+}
+
+// NewIfStatement initialises a new if statement without any cases.
+func NewIfStatement() *IfStatement {
+	return &IfStatement{}
+}
+
+// Add a case to the if statement, where the statements are executed when the
+// query resolves to true. The query should be nil for an else case, which must
+// be added last. The input parameter is an optional slice pointing to the
+// parsed expression that created the case.
+func (i *IfStatement) Add(input []rune, query query.Function, statements ...Statement) *IfStatement {
+	i.cases = append(i.cases, ifStatementCase{
+		input:      input,
+		query:      query,
+		statements: statements,
+	})
+	return i
+}
+
+// QueryTargets returns a slice of all targets referenced by the queries of the
+// statement and the statements nested within it.
+func (i *IfStatement) QueryTargets(ctx query.TargetsContext) (query.TargetsContext, []query.TargetPath) {
+	var paths []query.TargetPath
+	for _, c := range i.cases {
+		if c.query != nil {
+			_, tmpPaths := c.query.QueryTargets(ctx)
+			paths = append(paths, tmpPaths...)
+		}
+		for _, stmt := range c.statements {
+			_, tmpPaths := stmt.QueryTargets(ctx)
+			paths = append(paths, tmpPaths...)
+		}
+	}
+	return ctx, paths
+}
+
+// AssignmentTargets returns a slice of all targets that could be assigned to
+// by the statements nested within the statement.
+func (i *IfStatement) AssignmentTargets() []TargetPath {
+	var paths []TargetPath
+	for _, c := range i.cases {
+		for _, stmt := range c.statements {
+			paths = append(paths, stmt.AssignmentTargets()...)
+		}
+	}
+	return paths
+}
+
+// Execute the statements of the first case where the query resolves to true.
+func (i *IfStatement) Execute(fnCtx query.FunctionContext, asCtx AssignmentContext) error {
+	for n, c := range i.cases {
+		if c.query != nil {
+			queryVal, err := c.query.Exec(fnCtx)
+			if err != nil {
+				return &statementErr{
+					input:  c.input,
+					onExec: true,
+					err:    fmt.Errorf("failed to check if condition: %w", err),
+				}
+			}
+			queryRes, isBool := queryVal.(bool)
+			if !isBool {
+				return &statementErr{
+					input:  c.input,
+					onExec: true,
+					err:    query.NewTypeErrorFrom(c.query.Annotation(), queryVal, query.ValueBool),
+				}
+			}
+			if !queryRes {
+				continue
+			}
+		}
+		fnCtx.Trace(query.TraceEvent{Kind: query.TraceIfCase, Input: c.input, Case: n, Value: true})
+		for _, stmt := range c.statements {
+			if err := stmt.Execute(fnCtx, asCtx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return nil
+}
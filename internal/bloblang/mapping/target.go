@@ -1,5 +1,11 @@
 package mapping
 
+import (
+	"strings"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+)
+
 // TargetType represents a mapping target type, which is a destination for a
 // query result to be mapped into a message.
 type TargetType int
@@ -26,3 +32,21 @@ func NewTargetPath(t TargetType, path ...string) TargetPath {
 		Path: path,
 	}
 }
+
+// String returns a human readable representation of the target path in the
+// form it would be written within a mapping.
+func (t TargetPath) String() string {
+	switch t.Type {
+	case TargetMetadata:
+		if len(t.Path) == 0 {
+			return "meta"
+		}
+		return "meta " + strings.Join(t.Path, ".")
+	case TargetVariable:
+		return "$" + query.SliceToDotPath(t.Path...)
+	}
+	if len(t.Path) == 0 {
+		return "root"
+	}
+	return "root." + query.SliceToDotPath(t.Path...)
+}
@@ -0,0 +1,153 @@
+package mapping
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+)
+
+// SourceMap records the sections of a mapping that its functions, methods and
+// match expressions were parsed from, allowing the steps of a traced execution
+// to be located within the mapping.
+type SourceMap struct {
+	functions map[query.Function]sourceSpan
+	cases     map[query.Function][]sourceSpan
+}
+
+// sourceSpan is a section of a mapping, where a negative length indicates that
+// only the beginning of the section is known.
+type sourceSpan struct {
+	input  []rune
+	length int
+}
+
+func (s sourceSpan) String() string {
+	end := s.length
+	if end < 0 {
+		end = len(s.input)
+	}
+	for i, r := range s.input[:end] {
+		if r == '\n' {
+			str := strings.TrimSpace(string(s.input[:i]))
+			if s.length >= 0 {
+				str += " ..."
+			}
+			return str
+		}
+	}
+	return strings.TrimSpace(string(s.input[:end]))
+}
+
+// NewSourceMap creates an empty source map.
+func NewSourceMap() *SourceMap {
+	return &SourceMap{
+		functions: map[query.Function]sourceSpan{},
+		cases:     map[query.Function][]sourceSpan{},
+	}
+}
+
+// AddFunction records that a function was parsed from the first length runes
+// of an input.
+func (s *SourceMap) AddFunction(fn query.Function, input []rune, length int) {
+	s.functions[fn] = sourceSpan{input: input, length: length}
+}
+
+// AddMatchCase records that the next case of a match expression was parsed
+// from the first length runes of an input. Cases must be added in the order
+// that they appear within the match expression.
+func (s *SourceMap) AddMatchCase(fn query.Function, input []rune, length int) {
+	s.cases[fn] = append(s.cases[fn], sourceSpan{input: input, length: length})
+}
+
+func (s *SourceMap) function(fn query.Function) (sourceSpan, bool) {
+	if s == nil {
+		return sourceSpan{}, false
+	}
+	span, exists := s.functions[fn]
+	return span, exists
+}
+
+func (s *SourceMap) matchCase(fn query.Function, index int) (sourceSpan, bool) {
+	if s == nil {
+		return sourceSpan{}, false
+	}
+	cases := s.cases[fn]
+	if index < 0 || index >= len(cases) {
+		return sourceSpan{}, false
+	}
+	return cases[index], true
+}
+
+// SetSourceMap provides the executor with a map of the sections of the mapping
+// that its functions were parsed from, which is used in order to describe the
+// steps of traced executions.
+func (e *Executor) SetSourceMap(s *SourceMap) {
+	e.sources = s
+}
+
+//------------------------------------------------------------------------------
+
+// TraceStep describes a step of a traced mapping execution.
+type TraceStep struct {
+	// Line and Column of the step within the mapping, which are zero when the
+	// step originates from a file imported by the mapping.
+	Line   int
+	Column int
+
+	// Source is the section of the mapping that the step was parsed from,
+	// limited to the first line.
+	Source string
+
+	// What describes the step.
+	What string
+
+	// Value is the result of the step, if it succeeded.
+	Value interface{}
+
+	// Err is the error returned by the step, if it failed.
+	Err error
+}
+
+// NewTracer returns a tracer that describes the steps of executions of the
+// mapping and passes them to a closure. Statements are always described with
+// their source, but functions, methods and match cases are only described with
+// their source when the executor has a source map.
+func (e *Executor) NewTracer(fn func(step TraceStep)) query.Tracer {
+	return func(ev query.TraceEvent) {
+		step := TraceStep{
+			Value: ev.Value,
+			Err:   ev.Err,
+		}
+
+		var span sourceSpan
+		var hasSpan bool
+
+		switch ev.Kind {
+		case query.TraceFunction:
+			step.What = ev.Function.Annotation()
+			span, hasSpan = e.sources.function(ev.Function)
+		case query.TraceMatchCase:
+			step.What = fmt.Sprintf("match case %v", ev.Case)
+			if span, hasSpan = e.sources.matchCase(ev.Function, ev.Case); !hasSpan {
+				span, hasSpan = e.sources.function(ev.Function)
+			}
+		case query.TraceAssignment:
+			step.What = "assignment to " + ev.Target
+			span, hasSpan = sourceSpan{input: ev.Input, length: -1}, len(ev.Input) > 0
+		case query.TraceIfCase:
+			step.What = fmt.Sprintf("if case %v", ev.Case)
+			span, hasSpan = sourceSpan{input: ev.Input, length: -1}, len(ev.Input) > 0
+		}
+
+		if hasSpan {
+			step.Source = span.String()
+			if isSuffixOf(e.input, span.input) {
+				step.Line, step.Column = LineAndColOf(e.input, span.input)
+			}
+		} else if ev.Function != nil {
+			step.Source = ev.Function.Annotation()
+		}
+		fn(step)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
 	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
 )
 
@@ -16,6 +17,15 @@ type Context struct {
 	Methods      *query.MethodSet
 	namedContext *namedContext
 	importer     Importer
+
+	// Maps declared within the mapping being parsed and their parameters,
+	// allowing them to be called as functions.
+	declaredMaps map[string][]string
+
+	// Whether parsed mappings should record the source of their functions,
+	// and the source map of the mapping being parsed.
+	tracing bool
+	sources *mapping.SourceMap
 }
 
 // EmptyContext returns a parser context with no functions, methods or import
@@ -62,6 +72,61 @@ func (pCtx Context) HasNamedContext(name string) bool {
 	return false
 }
 
+// withMapDeclarations returns a Context where maps declared within a mapping
+// can be called as functions.
+func (pCtx Context) withMapDeclarations() Context {
+	pCtx.declaredMaps = map[string][]string{}
+	return pCtx
+}
+
+func (pCtx Context) declareMap(name string, params []string) {
+	if pCtx.declaredMaps != nil {
+		pCtx.declaredMaps[name] = params
+	}
+}
+
+// mapParams returns the parameters of a declared map.
+func (pCtx Context) mapParams(name string) (query.Params, bool) {
+	mapParams, exists := pCtx.declaredMaps[name]
+	if !exists {
+		return query.Params{}, false
+	}
+	params := query.NewParams()
+	for _, p := range mapParams {
+		params = params.Add(query.ParamAny(p, ""))
+	}
+	return params, true
+}
+
+// WithTracing returns a Context where parsed mappings record the sections of
+// the mapping that their functions, methods and match cases were parsed from,
+// allowing the steps of their executions to be traced in detail.
+func (pCtx Context) WithTracing() Context {
+	pCtx.tracing = true
+	return pCtx
+}
+
+// withSourceMap returns a Context where the sources of parsed functions are
+// recorded, if tracing is enabled.
+func (pCtx Context) withSourceMap() Context {
+	if pCtx.tracing {
+		pCtx.sources = mapping.NewSourceMap()
+	}
+	return pCtx
+}
+
+func (pCtx Context) recordFunction(fn query.Function, input, remaining []rune) {
+	if pCtx.sources != nil {
+		pCtx.sources.AddFunction(fn, input, len(input)-len(remaining))
+	}
+}
+
+func (pCtx Context) recordMatchCase(fn query.Function, input, remaining []rune) {
+	if pCtx.sources != nil {
+		pCtx.sources.AddMatchCase(fn, input, len(input)-len(remaining))
+	}
+}
+
 // InitFunction attempts to initialise a function from the available
 // constructors of the parser context.
 func (pCtx Context) InitFunction(name string, args *query.ParsedParams) (query.Function, error) {
@@ -18,13 +18,17 @@ import (
 // messages.
 func ParseMapping(pCtx Context, expr string) (*mapping.Executor, *Error) {
 	in := []rune(expr)
+	pCtx = pCtx.withSourceMap()
 
 	resDirectImport := singleRootImport(pCtx)(in)
 	if resDirectImport.Err != nil && resDirectImport.Err.IsFatal() {
 		return nil, resDirectImport.Err
 	}
 	if resDirectImport.Err == nil && len(resDirectImport.Remaining) == 0 {
-		return resDirectImport.Payload.(*mapping.Executor), nil
+		exec := resDirectImport.Payload.(*mapping.Executor)
+		exec.SetSourceMap(pCtx.sources)
+		exec.Optimise()
+		return exec, nil
 	}
 
 	resExe := parseExecutor(pCtx)(in)
@@ -37,7 +41,10 @@ func ParseMapping(pCtx Context, expr string) (*mapping.Executor, *Error) {
 	if res.Err != nil {
 		return nil, res.Err
 	}
-	return res.Payload.(*mapping.Executor), nil
+	exec := res.Payload.(*mapping.Executor)
+	exec.SetSourceMap(pCtx.sources)
+	exec.Optimise()
+	return exec, nil
 }
 
 //------------------------------------------------------------------------------'
@@ -48,12 +55,14 @@ func parseExecutor(pCtx Context) Func {
 	allWhitespace := DiscardAll(OneOf(whitespace, newline))
 
 	return func(input []rune) Result {
+		pCtx := pCtx.withMapDeclarations()
 		maps := map[string]query.Function{}
 		statements := []mapping.Statement{}
 
 		statement := OneOf(
 			importParser(maps, pCtx),
 			mapParser(maps, pCtx),
+			ifStatementParser(false, pCtx),
 			letStatementParser(pCtx),
 			metaStatementParser(false, pCtx),
 			plainMappingStatementParser(pCtx),
@@ -225,6 +234,11 @@ func importParser(maps map[string]query.Function, pCtx Context) Func {
 				collisions = append(collisions, k)
 			} else {
 				maps[k] = v
+				var params []string
+				if pm, ok := v.(query.ParameterizedMap); ok {
+					params = pm.Params()
+				}
+				pCtx.declareMap(k, params)
 			}
 		}
 		if len(collisions) > 0 {
@@ -241,7 +255,7 @@ func mapParser(maps map[string]query.Function, pCtx Context) Func {
 	whitespace := SpacesAndTabs()
 	allWhitespace := DiscardAll(OneOf(whitespace, newline))
 
-	p := Sequence(
+	header := Sequence(
 		Term("map"),
 		whitespace,
 		// Prevents a missing path from being captured by the next parser
@@ -254,50 +268,94 @@ func mapParser(maps map[string]query.Function, pCtx Context) Func {
 				"map name",
 			),
 		),
-		SpacesAndTabs(),
-		DelimitedPattern(
+		Optional(DelimitedPattern(
 			Sequence(
-				Char('{'),
+				Char('('),
 				allWhitespace,
 			),
-			OneOf(
-				letStatementParser(pCtx),
-				metaStatementParser(true, pCtx), // Prevented for now due to .from(int)
-				plainMappingStatementParser(pCtx),
-			),
+			Expect(varNameParser(), "parameter name"),
 			Sequence(
 				Discard(whitespace),
-				newline,
+				Char(','),
 				allWhitespace,
 			),
 			Sequence(
 				allWhitespace,
-				Char('}'),
+				Char(')'),
 			),
-			true,
+			false,
+		)),
+		SpacesAndTabs(),
+	)
+
+	body := DelimitedPattern(
+		Sequence(
+			Char('{'),
+			allWhitespace,
+		),
+		OneOf(
+			ifStatementParser(true, pCtx),
+			letStatementParser(pCtx),
+			metaStatementParser(true, pCtx), // Prevented for now due to .from(int)
+			plainMappingStatementParser(pCtx),
+		),
+		Sequence(
+			Discard(whitespace),
+			newline,
+			allWhitespace,
 		),
+		Sequence(
+			allWhitespace,
+			Char('}'),
+		),
+		true,
 	)
 
 	return func(input []rune) Result {
-		res := p(input)
+		res := header(input)
 		if res.Err != nil {
 			return res
 		}
 
 		seqSlice := res.Payload.([]interface{})
 		ident := seqSlice[2].(string)
-		stmtSlice := seqSlice[4].([]interface{})
 
 		if _, exists := maps[ident]; exists {
 			return Fail(NewFatalError(input, fmt.Errorf("map name collision: %v", ident)), input)
 		}
 
+		var params []string
+		if paramSlice, hasParams := seqSlice[3].([]interface{}); hasParams {
+			if _, err := pCtx.Functions.Params(ident); err == nil {
+				return Fail(NewFatalError(input, fmt.Errorf("map %v has parameters but its name collides with a function", ident)), input)
+			}
+			seen := map[string]struct{}{}
+			for _, v := range paramSlice {
+				param := v.(string)
+				if _, exists := seen[param]; exists {
+					return Fail(NewFatalError(input, fmt.Errorf("map %v has duplicate parameter: %v", ident, param)), input)
+				}
+				seen[param] = struct{}{}
+				params = append(params, param)
+			}
+		}
+
+		// Declared before parsing the body so that maps can call themselves.
+		pCtx.declareMap(ident, params)
+
+		if res = body(res.Remaining); res.Err != nil {
+			return Fail(res.Err, input)
+		}
+
+		stmtSlice := res.Payload.([]interface{})
 		statements := make([]mapping.Statement, len(stmtSlice))
 		for i, v := range stmtSlice {
 			statements[i] = v.(mapping.Statement)
 		}
 
-		maps[ident] = mapping.NewExecutor("map "+ident, input, maps, statements...)
+		exec := mapping.NewExecutor("map "+ident, input, maps, statements...)
+		exec.SetParams(params...)
+		maps[ident] = exec
 
 		return Success(ident, res.Remaining)
 	}
@@ -340,6 +398,118 @@ func letStatementParser(pCtx Context) Func {
 	}
 }
 
+// ifStatementParser parses an if statement, where meta assignments within its
+// blocks can be disabled in the same way as metaStatementParser.
+func ifStatementParser(metaDisabled bool, pCtx Context) Func {
+	newline := NewlineAllowComment()
+	whitespace := SpacesAndTabs()
+	allWhitespace := DiscardAll(OneOf(whitespace, newline))
+
+	return func(input []rune) Result {
+		statementsBlock := DelimitedPattern(
+			Sequence(
+				Char('{'),
+				allWhitespace,
+			),
+			OneOf(
+				ifStatementParser(metaDisabled, pCtx),
+				letStatementParser(pCtx),
+				metaStatementParser(metaDisabled, pCtx),
+				plainMappingStatementParser(pCtx),
+			),
+			Sequence(
+				Discard(whitespace),
+				newline,
+				allWhitespace,
+			),
+			Sequence(
+				allWhitespace,
+				Char('}'),
+			),
+			true,
+		)
+
+		ifParser := Sequence(
+			Expect(Term("if"), "assignment"),
+			whitespace,
+			queryParser(pCtx),
+			allWhitespace,
+			statementsBlock,
+		)
+
+		elseIfParser := Optional(Sequence(
+			Term("else if"),
+			whitespace,
+			MustBe(queryParser(pCtx)),
+			allWhitespace,
+			MustBe(statementsBlock),
+		))
+
+		// The else keyword must not match the start of a statement on the
+		// line following the block, such as `elsewhere = true`.
+		elseTerm := func(input []rune) Result {
+			res := Term("else")(input)
+			if res.Err == nil && len(res.Remaining) > 0 {
+				if c := res.Remaining[0]; c != ' ' && c != '\t' && c != '\n' && c != '\r' && c != '{' {
+					return Fail(NewError(input, "else"), input)
+				}
+			}
+			return res
+		}
+
+		elseParser := Optional(Sequence(
+			elseTerm,
+			allWhitespace,
+			MustBe(statementsBlock),
+		))
+
+		toStatements := func(v interface{}) []mapping.Statement {
+			stmtSlice := v.([]interface{})
+			statements := make([]mapping.Statement, len(stmtSlice))
+			for i, s := range stmtSlice {
+				statements[i] = s.(mapping.Statement)
+			}
+			return statements
+		}
+
+		res := ifParser(input)
+		if res.Err != nil {
+			return res
+		}
+
+		seqSlice := res.Payload.([]interface{})
+		stmt := mapping.NewIfStatement().Add(input, seqSlice[2].(query.Function), toStatements(seqSlice[4])...)
+
+		// Subsequent cases may begin on the line following the closing brace
+		// of the previous block, in which case the newline is only consumed
+		// when a case follows.
+		remaining := res.Remaining
+		for {
+			caseInput := allWhitespace(remaining).Remaining
+			if res = elseIfParser(caseInput); res.Err != nil {
+				return Fail(res.Err, input)
+			}
+			if res.Payload == nil {
+				break
+			}
+			seqSlice = res.Payload.([]interface{})
+			stmt.Add(caseInput, seqSlice[2].(query.Function), toStatements(seqSlice[4])...)
+			remaining = res.Remaining
+		}
+
+		caseInput := allWhitespace(remaining).Remaining
+		if res = elseParser(caseInput); res.Err != nil {
+			return Fail(res.Err, input)
+		}
+		if res.Payload != nil {
+			stmt.Add(caseInput, nil, toStatements(res.Payload.([]interface{})[2])...)
+			remaining = res.Remaining
+		}
+
+		return Success(stmt, remaining)
+	}
+}
+
 func nameLiteralParser() Func {
 	return JoinStringPayloads(
 		UntilFail(
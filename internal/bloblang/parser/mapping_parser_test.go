@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,6 +10,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
 	"github.com/benthosdev/benthos/v4/internal/message"
 )
 
@@ -154,6 +157,82 @@ foo = bar.apply("foo")`, goodMapFile),
 "root.something" = 5 + 2`,
 			errContains: "line 2 char 1: expected import, map, or assignment",
 		},
+		"if statement bad assignment": {
+			mapping: `if this.foo {
+  root.bar = 
+}`,
+			errContains: "line 2 char 14: expected query",
+		},
+		"if statement unclosed": {
+			mapping: `if this.foo {
+  root.bar = "baz"
+`,
+			errContains: "line 3 char 1: expected",
+		},
+		"else statement bad assignment": {
+			mapping: `if this.foo {
+  root.bar = "baz"
+} else {
+  root.bar wat
+}`,
+			errContains: "line 4 char 12: required: expected =",
+		},
+		"if statement within map sets meta": {
+			mapping: `map foo {
+  if this.bar {
+    meta foo = "bar"
+  }
+}`,
+			errContains: "line 3 char 5: setting meta fields from within a map is not allowed",
+		},
+		"else keyword without block": {
+			mapping: `if this.foo {
+  root.bar = "baz"
+}
+else root.bar = "buz"`,
+			errContains: "line 4 char 6: required: expected {",
+		},
+		"if statement contains map": {
+			mapping: `if this.foo {
+  map foo {
+    root = "bar"
+  }
+}`,
+			errContains: "line 2 char 7: expected =",
+		},
+		"map duplicate parameters": {
+			mapping: `map foo(a, a) {
+  root = $a
+}`,
+			errContains: "line 1 char 1: map foo has duplicate parameter: a",
+		},
+		"map parameters collide with function": {
+			mapping: `map uuid_v4(a) {
+  root = $a
+}`,
+			errContains: "line 1 char 1: map uuid_v4 has parameters but its name collides with a function",
+		},
+		"map call wrong arguments": {
+			mapping: `map foo(a, b) {
+  root = $a + $b
+}
+root = foo(1, 2, 3)`,
+			errContains: "line 4 char 8: map foo: ",
+		},
+		"map call before declaration": {
+			mapping: `root = foo("bar")
+map foo(a) {
+  root = $a
+}`,
+			errContains: "line 1 char 8: unrecognised function 'foo'",
+		},
+		"map call unknown named argument": {
+			mapping: `map foo(a, b) {
+  root = $a + $b
+}
+root = foo(a: 1, c: 2)`,
+			errContains: "line 4 char 8: map foo: ",
+		},
 	}
 
 	for name, test := range tests {
@@ -179,6 +258,12 @@ func TestMappings(t *testing.T) {
 	directMapFile := filepath.Join(dir, "direct_map.blobl")
 	require.NoError(t, os.WriteFile(directMapFile, []byte(`root.nested = this`), 0o777))
 
+	paramMapFile := filepath.Join(dir, "param_map.blobl")
+	require.NoError(t, os.WriteFile(paramMapFile, []byte(`map wrap(key) {
+  root = {}
+  root = root.merge({ $key: this })
+}`), 0o777))
+
 	type part struct {
 		Content string
 		Meta    map[string]string
@@ -426,6 +511,61 @@ root = this.apply("foo")`,
 				Content: `{"applied":["foo"],"bar":{"outter":{"inner":"hello world"}},"foo":"static foo"}`,
 			},
 		},
+		"test map with parameters": {
+			mapping: `map greet(greeting, punctuation) {
+  root = $greeting + " " + this.name + $punctuation
+}
+root.a = greet("hello", "!")
+root.b = greet(punctuation: "?", greeting: "hey")
+root.c = this.apply("greet", "hi", ".")`,
+			input: []part{
+				{Content: `{"name":"sam"}`},
+			},
+			output: part{
+				Content: `{"a":"hello sam!","b":"hey sam?","c":"hi sam."}`,
+			},
+		},
+		"test map without parameters called as function": {
+			mapping: `map foo {
+  root = this.value.uppercase()
+}
+root = foo()`,
+			input: []part{
+				{Content: `{"value":"hello"}`},
+			},
+			output: part{
+				Content: `HELLO`,
+			},
+		},
+		"test recursive map with parameters": {
+			mapping: `map factorial(n) {
+  root = if $n <= 1 { 1 } else { $n * factorial($n - 1) }
+}
+root = factorial(this.n)`,
+			input: []part{
+				{Content: `{"n":5}`},
+			},
+			output: part{
+				Content: `120`,
+			},
+		},
+		"test map parameters are isolated": {
+			mapping: `map bar(b) {
+  root = $b
+}
+map foo(a) {
+  root = [ $a, bar($a + 1) ]
+}
+let a = "outer"
+root.inner = foo(1)
+root.outer = $a`,
+			input: []part{
+				{Content: `{}`},
+			},
+			output: part{
+				Content: `{"inner":[1,2],"outer":"outer"}`,
+			},
+		},
 		"test nested maps": {
 			mapping: `map foo {
   let tmp = this.apply("bar")
@@ -457,6 +597,18 @@ root = this.apply("foo")`, goodMapFile),
 				Content: `{"foo":"this is valid","nested":{"outter":{"inner":"hello world"}}}`,
 			},
 		},
+		"test imported map with parameters": {
+			mapping: fmt.Sprintf(`import "%v"
+
+root.a = wrap("foo")
+root.b = this.inner.apply("wrap", "bar")`, paramMapFile),
+			input: []part{
+				{Content: `{"inner":"hello world"}`},
+			},
+			output: part{
+				Content: `{"a":{"foo":{"inner":"hello world"}},"b":{"bar":"hello world"}}`,
+			},
+		},
 		"test directly imported map": {
 			mapping: fmt.Sprintf(`from "%v"`, directMapFile),
 			input: []part{
@@ -466,6 +618,153 @@ root = this.apply("foo")`, goodMapFile),
 				Content: `{"nested":{"inner":"hello world"}}`,
 			},
 		},
+		"if statement": {
+			mapping: `root = this
+if this.type == "foo" {
+  let id = this.id.uppercase()
+  meta kind = "foo"
+  root.id = $id
+  root.checked = true
+}`,
+			input: []part{
+				{Content: `{"type":"foo","id":"abc"}`},
+			},
+			output: part{
+				Content: `{"checked":true,"id":"ABC","type":"foo"}`,
+				Meta:    map[string]string{"kind": "foo"},
+			},
+		},
+		"if statement not matched": {
+			mapping: `root = this
+if this.type == "foo" {
+  root.checked = true
+}`,
+			input: []part{
+				{Content: `{"type":"bar"}`},
+			},
+			output: part{
+				Content: `{"type":"bar"}`,
+			},
+		},
+		"if else if else statements": {
+			mapping: `
+if this.n > 10 {
+  root.size = "big"
+} else if this.n > 5 {
+  root.size = "medium"
+  root.medium = true
+} else {
+  root.size = "small"
+}
+root.n = this.n`,
+			input: []part{
+				{Content: `{"n":7}`},
+			},
+			output: part{
+				Content: `{"medium":true,"n":7,"size":"medium"}`,
+			},
+		},
+		"else statement": {
+			mapping: `if this.n > 10 {
+  root.size = "big"
+} else if this.n > 5 {
+  root.size = "medium"
+} else {
+  root.size = "small"
+}`,
+			input: []part{
+				{Content: `{"n":1}`},
+			},
+			output: part{
+				Content: `{"size":"small"}`,
+			},
+		},
+		"nested if statements": {
+			mapping: `if this.a {
+  # comments are allowed
+  if this.b {
+    root.result = "a and b"
+  } else {
+    root.result = "only a"
+  }
+
+} else {}`,
+			input: []part{
+				{Content: `{"a":true,"b":false}`},
+			},
+			output: part{
+				Content: `{"result":"only a"}`,
+			},
+		},
+		"if expression at root": {
+			mapping: `if this.a { "yes" } else { "no" }`,
+			input: []part{
+				{Content: `{"a":false}`},
+			},
+			output: part{
+				Content: `no`,
+			},
+		},
+		"field named if": {
+			mapping: `iffy = "foo"
+if = "bar"`,
+			input: []part{
+				{Content: `{}`},
+			},
+			output: part{
+				Content: `{"if":"bar","iffy":"foo"}`,
+			},
+		},
+		"else statements on new lines": {
+			mapping: `if this.n > 10 {
+  root.size = "big"
+}
+else if this.n > 5 {
+  root.size = "medium"
+}
+# the else block is not required to follow directly
+else {
+  root.size = "small"
+}
+elsewhere = "foo"`,
+			input: []part{
+				{Content: `{"n":7}`},
+			},
+			output: part{
+				Content: `{"elsewhere":"foo","size":"medium"}`,
+			},
+		},
+		"if statement followed by field named else": {
+			mapping: `if this.n > 10 {
+  root.size = "big"
+}
+else_thing = "foo"`,
+			input: []part{
+				{Content: `{"n":1}`},
+			},
+			output: part{
+				Content: `{"else_thing":"foo"}`,
+			},
+		},
+		"if statement within map": {
+			mapping: `map sized {
+  root.n = this.n
+  if this.n > 5 {
+    root.size = "big"
+  }
+  else {
+    root.size = "small"
+  }
+}
+root.a = this.a.apply("sized")
+root.b = this.b.apply("sized")`,
+			input: []part{
+				{Content: `{"a":{"n":7},"b":{"n":1}}`},
+			},
+			output: part{
+				Content: `{"a":{"n":7,"size":"big"},"b":{"n":1,"size":"small"}}`,
+			},
+		},
 	}
 
 	for name, test := range tests {
@@ -502,3 +801,403 @@ root = this.apply("foo")`, goodMapFile),
 		})
 	}
 }
+
+func TestMappingMapRecursionLimit(t *testing.T) {
+	exec, perr := ParseMapping(GlobalContext(), `map countdown(n) {
+  root = if $n <= 0 { "done" } else { countdown($n - 1) }
+}
+root = countdown(this.n)`)
+	require.Nil(t, perr)
+
+	exec.SetMaxMapRecursion(10)
+
+	resPart, err := exec.MapPart(0, message.QuickBatch([][]byte{[]byte(`{"n":5}`)}))
+	require.NoError(t, err)
+	assert.Equal(t, `done`, string(resPart.Get()))
+
+	_, err = exec.MapPart(0, message.QuickBatch([][]byte{[]byte(`{"n":20}`)}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeded maximum allowed stacks of")
+}
+
+func TestMappingLints(t *testing.T) {
+	tests := map[string]struct {
+		mapping string
+		lints   []mapping.Lint
+	}{
+		"no lints": {
+			mapping: `let foo = this.foo.uppercase()
+root.foo = $foo
+root.bar = this.bar.number().floor()`,
+		},
+		"method applied to wrong type": {
+			mapping: `root.foo = this.foo.number().uppercase()`,
+			lints: []mapping.Lint{
+				{Line: 1, Column: 1, What: "method uppercase: expected string or bytes value, got number from method number"},
+			},
+		},
+		"bad arithmetic": {
+			mapping: `root.foo = this.foo.string() + 5
+root.bar = this.bar - "bar"
+root.baz = this.baz.number() - "baz"`,
+			lints: []mapping.Lint{
+				{Line: 1, Column: 1, What: "cannot add types string (from method string) and number (from number literal)"},
+				{Line: 2, Column: 1, What: "cannot subtract types unknown (from field `this.bar`) and string (from string literal)"},
+				{Line: 3, Column: 1, What: "cannot subtract types number (from method number) and string (from string literal)"},
+			},
+		},
+		"undeclared variable": {
+			mapping: `root.foo = $foo`,
+			lints: []mapping.Lint{
+				{Line: 1, Column: 1, What: "variable foo is referenced but never declared"},
+			},
+		},
+		"unused variable": {
+			mapping: `root = this
+let foo = "unused"
+let bar = "used"
+root.bar = $bar`,
+			lints: []mapping.Lint{
+				{Line: 2, Column: 1, What: "variable foo is declared but never used"},
+			},
+		},
+		"variable declared in some branches": {
+			mapping: `if this.foo {
+  let foo = "yep"
+} else if this.bar {
+  let foo = "yep"
+}
+root.foo = $foo`,
+			lints: []mapping.Lint{
+				{Line: 6, Column: 1, What: "variable foo is referenced but only declared within some branches"},
+			},
+		},
+		"variable declared in all branches": {
+			mapping: `if this.foo {
+  let foo = "yep"
+} else {
+  let foo = "nope"
+}
+root.foo = $foo.uppercase()`,
+		},
+		"unreachable match cases": {
+			mapping: `root.foo = match this.foo {
+  "a" => 1
+  "a" => 2
+  _ => 3
+  "b" => 4
+}
+root.bar = match this.bar.number() {
+  "c" => 5
+}`,
+			lints: []mapping.Lint{
+				{Line: 1, Column: 1, What: "match case 1 is unreachable as a previous case matches the same value"},
+				{Line: 1, Column: 1, What: "match case 3 is unreachable as a previous case matches all values"},
+				{Line: 7, Column: 1, What: "match case 0 is unreachable as a number value never equals a string"},
+			},
+		},
+		"unreachable if branches": {
+			mapping: `if true {
+  root.foo = "a"
+} else {
+  root.foo = "b"
+}`,
+			lints: []mapping.Lint{
+				{Line: 3, Column: 3, What: "if statement: branch is unreachable as a previous condition is always true"},
+			},
+		},
+		"non boolean condition": {
+			mapping: `root.foo = if this.foo.length() { "a" }`,
+			lints: []mapping.Lint{
+				{Line: 1, Column: 1, What: "if expression: condition: expected bool value, got number from method length"},
+			},
+		},
+		"lints within maps": {
+			mapping: `map foo(bar) {
+  let unused = "baz"
+  root = $bar
+}
+root = this.apply("foo")`,
+			lints: []mapping.Lint{
+				{Line: 2, Column: 3, What: "variable unused is declared but never used"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			exec, perr := ParseMapping(GlobalContext(), test.mapping)
+			require.Nil(t, perr)
+			assert.Equal(t, test.lints, exec.Lint(nil))
+		})
+	}
+}
+
+func TestMappingOptimisations(t *testing.T) {
+	tests := map[string]struct {
+		mapping string
+		input   []string
+	}{
+		"shared field prefixes": {
+			mapping: `root.a = this.a.b.c
+root.b = this.a.b.d
+root.c = this.a.b.c.uppercase()
+root.d = this.a.e`,
+			input: []string{
+				`{"a":{"b":{"c":"foo","d":"bar"},"e":"baz"}}`,
+				`{"a":{"b":"not an object","e":"baz"}}`,
+				`{"a":{"b":{"d":"bar"}}}`,
+				`{}`,
+			},
+		},
+		"shared field prefixes of arrays": {
+			mapping: `root.a = this.a.0.b
+root.b = this.a.0.c
+root.c = this.a.1.b | "default"`,
+			input: []string{
+				`{"a":[{"b":"foo","c":"bar"}]}`,
+				`{"a":[{"b":"foo"},{"b":"bar"}]}`,
+				`{"a":{"0":{"b":"foo","c":"bar"}}}`,
+			},
+		},
+		"shared field prefixes within lambdas": {
+			mapping: `root.a = this.a.b.map_each(ele -> ele.a.b + this.a.b.0.a.b)
+root.b = this.a.b.map_each(this.a.b)
+root.c = match this.a.b.0 {
+  this.a.b == 1 => this.a.c
+  _ => this.a.b
+}`,
+			input: []string{
+				`{"a":{"b":[{"a":{"b":1,"c":"one"}},{"a":{"b":2,"c":"two"}}]}}`,
+			},
+		},
+		"shared field prefixes within maps": {
+			mapping: `map foo {
+  root.a = this.a.b.c
+  root.b = this.a.b.d
+}
+map bar {
+  root.c = this.a.b.c
+  root.d = this.a.b.d.apply("foo")
+}
+root.a = this.a.b.c
+root.b = this.a.apply("foo")
+root.c = this.apply("foo")
+root.d = this.a.b.apply("bar")
+root.e = this.a.b.d.apply("bar")`,
+			input: []string{
+				`{"a":{"b":{"c":"foo","d":{"a":{"b":{"c":"bar"}}}}}}`,
+			},
+		},
+		"shared field prefixes within if statements": {
+			mapping: `if this.a.b.c == "foo" {
+  root.a = this.a.b.d
+} else if this.a.b.c == "bar" {
+  root.b = this.a.b.e
+} else {
+  root.c = this.a.b
+}`,
+			input: []string{
+				`{"a":{"b":{"c":"foo","d":"first"}}}`,
+				`{"a":{"b":{"c":"bar","e":"second"}}}`,
+				`{"a":{"b":{"c":"baz"}}}`,
+			},
+		},
+		"folded literals": {
+			mapping: `root.a = "foo".uppercase()
+root.b = [ "a", "b" ].join(",").length()
+root.c = "2020-08-14T11:45:26Z".parse_timestamp("2006-01-02T15:04:05Z07:00").format_timestamp_unix()
+root.d = "nope".number() | "failed to fold"`,
+			input: []string{`{}`},
+		},
+		"fresh values mutated after assignment": {
+			mapping: `root.a = { "b": this.a, "c": [ this.b, "c" ] }
+root.a.b.c = "mutated"
+root.a.c.0 = "mutated"
+root.d = this.a
+root.e = this.b`,
+			input: []string{
+				`{"a":{"c":"original"},"b":"b"}`,
+				`{"a":"not an object","b":["b"]}`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			optimised, perr := ParseMapping(GlobalContext(), test.mapping)
+			require.Nil(t, perr)
+
+			res := parseExecutor(GlobalContext())([]rune(test.mapping))
+			require.Nil(t, res.Err)
+			unoptimised := res.Payload.(*mapping.Executor)
+
+			for i, input := range test.input {
+				msg := message.QuickBatch([][]byte{[]byte(input)})
+
+				expPart, expErr := unoptimised.MapPart(0, msg)
+				actPart, actErr := optimised.MapPart(0, msg)
+				if expErr != nil {
+					require.Error(t, actErr, i)
+					assert.Equal(t, expErr.Error(), actErr.Error(), i)
+					continue
+				}
+				require.NoError(t, actErr, i)
+				assert.Equal(t, string(expPart.Get()), string(actPart.Get()), i)
+				assert.Equal(t, input, string(msg.Get(0).Get()), i)
+			}
+		})
+	}
+}
+
+func BenchmarkMappings(b *testing.B) {
+	input := []byte(`{"a":{"b":{"c":"foo","d":"bar","e":[1,2,3]}},"f":"baz"}`)
+
+	tests := map[string]string{
+		"shared field prefixes": `root.a = this.a.b.c
+root.b = this.a.b.d
+root.c = this.a.b.e.sum()
+root.d = this.f`,
+		"structured literals": `root.a = { "b": this.a.b.c, "c": [ this.a.b.d, this.f ] }
+root.b = { "c": this.a.b.c.uppercase(), "d": this.f.length() }`,
+		"constant expressions": `root.a = "foo".uppercase()
+root.b = [ "a", "b", "c" ].join(",")
+root.c = this.f + "bar".capitalize()`,
+	}
+
+	for name, mapStr := range tests {
+		mapStr := mapStr
+		b.Run(name, func(b *testing.B) {
+			exec, perr := ParseMapping(GlobalContext(), mapStr)
+			require.Nil(b, perr)
+
+			msg := message.QuickBatch([][]byte{input})
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, err := exec.MapPart(0, msg)
+				require.NoError(b, err)
+			}
+		})
+	}
+}
+
+func TestMappingTrace(t *testing.T) {
+	type step struct {
+		Pos    string
+		Source string
+		What   string
+		Value  interface{}
+		Err    string
+	}
+
+	tests := map[string]struct {
+		mapping string
+		input   string
+		steps   []step
+	}{
+		"method chains and variables": {
+			mapping: `let name = this.name.uppercase()
+root.greeting = "hello " + $name.trim()`,
+			input: `{"name":" bob "}`,
+			steps: []step{
+				{Pos: "1:12", Source: `this.name.uppercase()`, What: "method uppercase", Value: " BOB "},
+				{Pos: "1:1", Source: `let name = this.name.uppercase()`, What: "assignment to $name", Value: " BOB "},
+				{Pos: "2:28", Source: `$name.trim()`, What: "method trim", Value: "BOB"},
+				{Pos: "2:1", Source: `root.greeting = "hello " + $name.trim()`, What: "assignment to root.greeting", Value: "hello BOB"},
+			},
+		},
+		"match cases": {
+			mapping: `root = match this.kind {
+  "a" => "first"
+  _ => "other"
+}`,
+			input: `{"kind":"b"}`,
+			steps: []step{
+				{Pos: "3:3", Source: `_ => "other"`, What: "match case 1", Value: "other"},
+				{Pos: "1:1", Source: `root = match this.kind {`, What: "assignment to root", Value: "other"},
+			},
+		},
+		"if statements": {
+			mapping: `if this.n > 10 {
+  meta big = "yes"
+} else {
+  meta big = "no"
+}`,
+			input: `{"n":5}`,
+			steps: []step{
+				{Pos: "3:3", Source: `else {`, What: "if case 1", Value: true},
+				{Pos: "4:3", Source: `meta big = "no"`, What: "assignment to meta big", Value: "no"},
+			},
+		},
+		"failing method": {
+			mapping: `root.a = this.a.number().floor()`,
+			input:   `{"a":"nope"}`,
+			steps: []step{
+				{Pos: "1:10", Source: `this.a.number()`, What: "method number", Err: "strconv.ParseFloat"},
+				{Pos: "1:10", Source: `this.a.number().floor()`, What: "method floor", Err: "strconv.ParseFloat"},
+				{Pos: "1:1", Source: `root.a = this.a.number().floor()`, What: "assignment to root.a", Err: "strconv.ParseFloat"},
+			},
+		},
+		"maps": {
+			mapping: `map upper {
+  root = this.uppercase()
+}
+root.a = this.a.apply("upper")`,
+			input: `{"a":"foo"}`,
+			steps: []step{
+				{Pos: "2:10", Source: `this.uppercase()`, What: "method uppercase", Value: "FOO"},
+				{Pos: "2:3", Source: `root = this.uppercase()`, What: "assignment to root", Value: "FOO"},
+				{Pos: "4:10", Source: `this.a.apply("upper")`, What: "map upper", Value: "FOO"},
+				{Pos: "4:1", Source: `root.a = this.a.apply("upper")`, What: "assignment to root.a", Value: "FOO"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			exec, perr := ParseMapping(GlobalContext().WithTracing(), test.mapping)
+			require.Nil(t, perr)
+
+			var steps []step
+			tracer := exec.NewTracer(func(s mapping.TraceStep) {
+				st := step{
+					Pos:    fmt.Sprintf("%v:%v", s.Line, s.Column),
+					Source: s.Source,
+					What:   s.What,
+					Value:  s.Value,
+				}
+				if s.Err != nil {
+					st.Value = nil
+					st.Err = "strconv.ParseFloat"
+					assert.Contains(t, s.Err.Error(), st.Err)
+				}
+				steps = append(steps, st)
+			})
+
+			var value interface{}
+			require.NoError(t, json.Unmarshal([]byte(test.input), &value))
+
+			var result interface{} = query.Nothing(nil)
+			vars := map[string]interface{}{}
+			meta := message.NewPart(nil)
+			_ = exec.ExecOnto(query.FunctionContext{
+				Maps:     exec.Maps(),
+				Vars:     vars,
+				MsgBatch: message.QuickBatch(nil),
+				NewMeta:  meta,
+				NewValue: &result,
+			}.WithValue(value).WithTracer(tracer), mapping.AssignmentContext{
+				Vars:  vars,
+				Meta:  meta,
+				Value: &result,
+			})
+
+			assert.Equal(t, test.steps, steps)
+		})
+	}
+}
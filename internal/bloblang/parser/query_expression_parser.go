@@ -3,11 +3,16 @@ package parser
 import (
 	"fmt"
 
-	"github.com/google/go-cmp/cmp"
-
 	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
 )
 
+// parsedMatchCase is a match case along with the input it was parsed from.
+type parsedMatchCase struct {
+	matchCase query.MatchCase
+	input     []rune
+	remaining []rune
+}
+
 func matchCaseParser(pCtx Context) Func {
 	whitespace := SpacesAndTabs()
 
@@ -42,28 +47,25 @@ func matchCaseParser(pCtx Context) Func {
 
 		seqSlice := res.Payload.([]interface{})
 
-		var caseFn query.Function
+		queryFn := seqSlice[2].(query.Function)
+
+		var matchCase query.MatchCase
 		switch t := seqSlice[0].([]interface{})[0].(type) {
 		case query.Function:
 			if lit, isLiteral := t.(*query.Literal); isLiteral {
-				caseFn = query.ClosureFunction("case statement", func(ctx query.FunctionContext) (interface{}, error) {
-					v := ctx.Value()
-					if v == nil {
-						return false, nil
-					}
-					return cmp.Equal(*v, lit.Value), nil
-				}, nil)
+				matchCase = query.NewLiteralMatchCase(lit.Value, queryFn)
 			} else {
-				caseFn = t
+				matchCase = query.NewMatchCase(t, queryFn)
 			}
 		case string:
-			caseFn = query.NewLiteralFunction("", true)
+			matchCase = query.NewMatchCase(query.NewLiteralFunction("", true), queryFn)
 		}
 
-		return Success(
-			query.NewMatchCase(caseFn, seqSlice[2].(query.Function)),
-			res.Remaining,
-		)
+		return Success(parsedMatchCase{
+			matchCase: matchCase,
+			input:     input,
+			remaining: res.Remaining,
+		}, res.Remaining)
 	}
 }
 
@@ -111,12 +113,20 @@ func matchExpressionParser(pCtx Context) Func {
 		seqSlice := res.Payload.([]interface{})
 		contextFn, _ := seqSlice[2].(query.Function)
 
-		cases := []query.MatchCase{}
-		for _, caseVal := range seqSlice[4].([]interface{}) {
-			cases = append(cases, caseVal.(query.MatchCase))
+		parsedCases := seqSlice[4].([]interface{})
+		cases := make([]query.MatchCase, 0, len(parsedCases))
+		for _, caseVal := range parsedCases {
+			cases = append(cases, caseVal.(parsedMatchCase).matchCase)
+		}
+
+		fn := query.NewMatchFunction(contextFn, cases...)
+		pCtx.recordFunction(fn, input, res.Remaining)
+		for _, caseVal := range parsedCases {
+			c := caseVal.(parsedMatchCase)
+			pCtx.recordMatchCase(fn, c.input, c.remaining)
 		}
 
-		res.Payload = query.NewMatchFunction(contextFn, cases...)
+		res.Payload = fn
 		return res
 	}
 }
@@ -108,23 +108,22 @@ func parseWithTails(fnParser Func, pCtx Context) Func {
 		),
 	)
 
-	mightNot := Sequence(
-		Optional(Sequence(
-			Char('!'),
-			Discard(SpacesAndTabs()),
-		)),
-		fnParser,
-	)
+	not := Optional(Sequence(
+		Char('!'),
+		Discard(SpacesAndTabs()),
+	))
 
 	return func(input []rune) Result {
-		res := mightNot(input)
-		if res.Err != nil {
-			return res
+		res := not(input)
+		isNot := res.Payload != nil
+
+		fnInput := res.Remaining
+		if res = fnParser(fnInput); res.Err != nil {
+			return Fail(res.Err, input)
 		}
 
-		seq := res.Payload.([]interface{})
-		isNot := seq[0] != nil
-		fn := seq[1].(query.Function)
+		fn := res.Payload.(query.Function)
+		pCtx.recordFunction(fn, fnInput, res.Remaining)
 		for {
 			if res = delim(res.Remaining); res.Err != nil {
 				if isNot {
@@ -136,6 +135,7 @@ func parseWithTails(fnParser Func, pCtx Context) Func {
 				return Fail(res.Err, input)
 			}
 			fn = res.Payload.(query.Function)
+			pCtx.recordFunction(fn, fnInput, res.Remaining)
 		}
 	}
 }
@@ -356,7 +356,15 @@ func functionParser(pCtx Context) Func {
 		targetFunc := seqSlice[0].(string)
 		params, err := pCtx.Functions.Params(targetFunc)
 		if err != nil {
-			return Fail(NewFatalError(input, err), input)
+			mapParams, isMap := pCtx.mapParams(targetFunc)
+			if !isMap {
+				return Fail(NewFatalError(input, err), input)
+			}
+			parsedParams, err := extractArgsParserResult(mapParams, seqSlice[1].([]interface{}))
+			if err != nil {
+				return Fail(NewFatalError(input, fmt.Errorf("map %v: %w", targetFunc, err)), input)
+			}
+			return Success(query.NewMapCallFunction(targetFunc, parsedParams), res.Remaining)
 		}
 
 		parsedParams, err := extractArgsParserResult(params, seqSlice[1].([]interface{}))
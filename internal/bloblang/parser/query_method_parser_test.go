@@ -629,3 +629,45 @@ func TestMethodMaps(t *testing.T) {
 		})
 	}
 }
+
+func TestMethodParserFolding(t *testing.T) {
+	tests := map[string]struct {
+		query  string
+		folded bool
+	}{
+		"string method": {
+			query:  `"foo".uppercase()`,
+			folded: true,
+		},
+		"chained methods": {
+			query:  `"foo,bar".split(",").join(" ")`,
+			folded: true,
+		},
+		"method with dynamic args": {
+			query:  `"foo".replace_all("o", this.bar)`,
+			folded: false,
+		},
+		"method with query args": {
+			query:  `[1,2,3].map_each(ele -> ele + 1)`,
+			folded: false,
+		},
+		"method that fails": {
+			query:  `"foo".number()`,
+			folded: false,
+		},
+		"method of a function": {
+			query:  `this.foo.uppercase()`,
+			folded: false,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			fn, err := tryParseQuery(test.query, false)
+			require.Nil(t, err)
+			_, isLit := fn.(*query.Literal)
+			assert.Equal(t, test.folded, isLit)
+		})
+	}
+}
@@ -0,0 +1,355 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// ValueSchema describes what is known about a value prior to the execution of
+// a query, such as its type and the structure of its children. A nil schema
+// indicates that nothing is known about the value.
+type ValueSchema struct {
+	Type       ValueType
+	Properties map[string]*ValueSchema
+	Items      *ValueSchema
+}
+
+// NewValueSchema creates a schema of a given type without any knowledge of its
+// children.
+func NewValueSchema(t ValueType) *ValueSchema {
+	if t == "" || t == ValueUnknown {
+		return nil
+	}
+	return &ValueSchema{Type: t}
+}
+
+// ValueType returns the type of the value described by the schema, or
+// ValueUnknown if the schema is nil.
+func (s *ValueSchema) ValueType() ValueType {
+	if s == nil || s.Type == "" {
+		return ValueUnknown
+	}
+	return s.Type
+}
+
+// Get attempts to obtain the schema of a child value at a given path, returns
+// nil if the child is unknown.
+func (s *ValueSchema) Get(path ...string) *ValueSchema {
+	for _, p := range path {
+		if s == nil {
+			return nil
+		}
+		switch s.Type {
+		case ValueObject:
+			s = s.Properties[p]
+		case ValueArray:
+			if _, err := strconv.Atoi(p); err != nil {
+				return nil
+			}
+			s = s.Items
+		default:
+			return nil
+		}
+	}
+	return s
+}
+
+// schemaOfLiteral returns a schema describing a literal value, including the
+// structure of any objects or arrays.
+func schemaOfLiteral(v interface{}) *ValueSchema {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		s := &ValueSchema{
+			Type:       ValueObject,
+			Properties: make(map[string]*ValueSchema, len(t)),
+		}
+		for k, v := range t {
+			s.Properties[k] = schemaOfLiteral(v)
+		}
+		return s
+	case []interface{}:
+		s := &ValueSchema{Type: ValueArray}
+		for i, v := range t {
+			if i == 0 {
+				s.Items = schemaOfLiteral(v)
+			} else {
+				s.Items = MergeValueSchemas(s.Items, schemaOfLiteral(v))
+			}
+		}
+		return s
+	}
+	return NewValueSchema(ITypeOf(v))
+}
+
+// MergeValueSchemas returns a schema that describes a value that could be
+// described by either of two schemas.
+func MergeValueSchemas(a, b *ValueSchema) *ValueSchema {
+	if a == nil || b == nil || a.Type != b.Type {
+		return nil
+	}
+	return &ValueSchema{Type: a.Type}
+}
+
+//------------------------------------------------------------------------------
+
+// AnalysisVar describes a variable that has been declared within a mapping at
+// the point where a query is being analysed.
+type AnalysisVar struct {
+	// Schema describes the value of the variable.
+	Schema *ValueSchema
+
+	// Partial indicates that the variable is only declared within some
+	// branches of the mapping.
+	Partial bool
+
+	// Used is set when the variable is referenced by a query.
+	Used bool
+}
+
+type analysisValue struct {
+	schema *ValueSchema
+	next   *analysisValue
+}
+
+type namedAnalysisValue struct {
+	name   string
+	schema *ValueSchema
+	next   *namedAnalysisValue
+}
+
+// AnalysisContext provides access to information known about a query prior to
+// execution, and collects any warnings found during the analysis of it.
+type AnalysisContext struct {
+	// Maps declared within the mapping.
+	Maps map[string]Function
+
+	// Vars declared at the point where the query is executed, where a nil map
+	// indicates that variables are not known and therefore not checked.
+	Vars map[string]*AnalysisVar
+
+	value      *analysisValue
+	namedValue *namedAnalysisValue
+	warnings   *[]string
+}
+
+// NewAnalysisContext creates a new context for analysing queries executed on a
+// value described by a schema, which can be nil.
+func NewAnalysisContext(maps map[string]Function, value *ValueSchema) AnalysisContext {
+	return AnalysisContext{
+		Maps:     maps,
+		value:    &analysisValue{schema: value},
+		warnings: &[]string{},
+	}
+}
+
+// WithNewWarnings returns a copy of the context where warnings are collected
+// separately from the original context.
+func (ctx AnalysisContext) WithNewWarnings() AnalysisContext {
+	ctx.warnings = &[]string{}
+	return ctx
+}
+
+// Warnf adds a warning to the context.
+func (ctx AnalysisContext) Warnf(format string, args ...interface{}) {
+	if ctx.warnings == nil {
+		return
+	}
+	*ctx.warnings = append(*ctx.warnings, fmt.Sprintf(format, args...))
+}
+
+// Warnings returns all warnings collected by the context.
+func (ctx AnalysisContext) Warnings() []string {
+	if ctx.warnings == nil {
+		return nil
+	}
+	return *ctx.warnings
+}
+
+// Value returns the schema of the current context value.
+func (ctx AnalysisContext) Value() *ValueSchema {
+	if ctx.value == nil {
+		return nil
+	}
+	return ctx.value.schema
+}
+
+// WithValue returns a copy of the context where the current context value is
+// described by a new schema.
+func (ctx AnalysisContext) WithValue(s *ValueSchema) AnalysisContext {
+	ctx.value = &analysisValue{schema: s, next: ctx.value}
+	return ctx
+}
+
+// PopValue returns the schema of the current context value and a copy of the
+// context where the previous context value is restored.
+func (ctx AnalysisContext) PopValue() (*ValueSchema, AnalysisContext) {
+	if ctx.value == nil {
+		return nil, ctx
+	}
+	s := ctx.value.schema
+	ctx.value = ctx.value.next
+	return s, ctx
+}
+
+// NamedValue returns the schema of a named context value.
+func (ctx AnalysisContext) NamedValue(name string) *ValueSchema {
+	for n := ctx.namedValue; n != nil; n = n.next {
+		if n.name == name {
+			return n.schema
+		}
+	}
+	return nil
+}
+
+// WithNamedValue returns a copy of the context with a named context value
+// described by a schema.
+func (ctx AnalysisContext) WithNamedValue(name string, s *ValueSchema) AnalysisContext {
+	ctx.namedValue = &namedAnalysisValue{name: name, schema: s, next: ctx.namedValue}
+	return ctx
+}
+
+// UnusedVars returns the names of any variables of the context that have not
+// been used, sorted alphabetically.
+func (ctx AnalysisContext) UnusedVars() []string {
+	var names []string
+	for k, v := range ctx.Vars {
+		if !v.Used {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+//------------------------------------------------------------------------------
+
+// Analysable is implemented by query functions that support static analysis,
+// where any problems found within the function are reported to the context and
+// a schema describing the returned value is inferred.
+type Analysable interface {
+	Analyse(ctx AnalysisContext) *ValueSchema
+}
+
+// Analyse performs a static analysis of a query function, returning a schema
+// of the value it returns, or nil if the value is unknown.
+func Analyse(ctx AnalysisContext, fn Function) *ValueSchema {
+	if fn == nil {
+		return nil
+	}
+	if a, ok := fn.(Analysable); ok {
+		return a.Analyse(ctx)
+	}
+	return nil
+}
+
+type analysedFunction struct {
+	Function
+	analyse func(ctx AnalysisContext) *ValueSchema
+}
+
+func (a *analysedFunction) Analyse(ctx AnalysisContext) *ValueSchema {
+	return a.analyse(ctx)
+}
+
+// withAnalysis wraps a function with a static analysis implementation.
+func withAnalysis(fn Function, analyse func(ctx AnalysisContext) *ValueSchema) Function {
+	return &analysedFunction{Function: fn, analyse: analyse}
+}
+
+// isConcreteType returns true if the type is one that a value can have during
+// execution, and is therefore able to be checked.
+func isConcreteType(t ValueType) bool {
+	switch t {
+	case ValueString, ValueBytes, ValueNumber, ValueBool, ValueTimestamp,
+		ValueArray, ValueObject, ValueNull:
+		return true
+	}
+	return false
+}
+
+// acceptsType returns true if a parameter of a given type accepts a value of
+// another, following the rules of argument parsing.
+func acceptsType(param, t ValueType) bool {
+	if !isConcreteType(t) {
+		return true
+	}
+	switch param {
+	case ValueInt, ValueFloat, ValueNumber:
+		return t == ValueNumber
+	case ValueString:
+		return t == ValueString || t == ValueBytes
+	case ValueBool:
+		return t == ValueBool || t == ValueNumber
+	case ValueTimestamp:
+		return t == ValueTimestamp || t == ValueNumber || t == ValueString || t == ValueBytes
+	case ValueArray, ValueObject:
+		return t == param
+	}
+	return true
+}
+
+// analyseArgs performs a static analysis of the dynamic arguments of a function
+// or method, reporting arguments of a type that cannot be accepted.
+func analyseArgs(ctx AnalysisContext, from string, args *ParsedParams) {
+	if args == nil {
+		return
+	}
+	for i, v := range args.values {
+		fn, isFn := v.(Function)
+		if !isFn {
+			continue
+		}
+		var def ParamDefinition
+		if i < len(args.source.Definitions) {
+			def = args.source.Definitions[i]
+		}
+		if def.ValueType == ValueQuery {
+			// Query arguments are executed upon a different context value,
+			// which we know nothing about.
+			_ = Analyse(ctx.WithValue(nil), fn)
+			continue
+		}
+		t := Analyse(ctx, fn).ValueType()
+		if def.Name != "" && !acceptsType(def.ValueType, t) {
+			ctx.Warnf("%v: argument %v: %v", from, def.Name, &TypeError{
+				From:     fn.Annotation(),
+				Expected: []ValueType{def.ValueType},
+				Actual:   t,
+			})
+		}
+	}
+}
+
+// ConditionResult describes what is known about the result of a query used as
+// a condition prior to execution.
+type ConditionResult int
+
+// Condition results.
+const (
+	ConditionUnknown ConditionResult = iota
+	ConditionAlwaysTrue
+	ConditionNeverTrue
+)
+
+// AnalyseCondition performs a static analysis of a query used as a condition,
+// reporting a condition that doesn't return a boolean value, and returns what
+// is known about the result.
+func AnalyseCondition(ctx AnalysisContext, from string, fn Function) ConditionResult {
+	if lit, isLit := fn.(*Literal); isLit {
+		if b, isBool := lit.Value.(bool); isBool {
+			if b {
+				return ConditionAlwaysTrue
+			}
+			return ConditionNeverTrue
+		}
+	}
+	if t := Analyse(ctx, fn).ValueType(); t != ValueBool && isConcreteType(t) {
+		ctx.Warnf("%v: condition: %v", from, &TypeError{
+			From:     fn.Annotation(),
+			Expected: []ValueType{ValueBool},
+			Actual:   t,
+		})
+	}
+	return ConditionUnknown
+}
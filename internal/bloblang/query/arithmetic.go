@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -58,7 +59,7 @@ func (o ArithmeticOperator) String() string {
 
 type arithmeticOpFunc func(lhs Function, rhs Function, l, r interface{}) (interface{}, error)
 
-func arithmeticFunc(lhs, rhs Function, op arithmeticOpFunc) (Function, error) {
+func arithmeticFunc(lhs, rhs Function, operator ArithmeticOperator, op arithmeticOpFunc) (Function, error) {
 	annotation := rhs.Annotation()
 
 	var litL, litR *Literal
@@ -73,7 +74,7 @@ func arithmeticFunc(lhs, rhs Function, op arithmeticOpFunc) (Function, error) {
 		}
 	}
 
-	return ClosureFunction(annotation, func(ctx FunctionContext) (interface{}, error) {
+	fn := ClosureFunction(annotation, func(ctx FunctionContext) (interface{}, error) {
 		var err error
 		var leftV, rightV interface{}
 		if leftV, err = lhs.Exec(ctx); err == nil {
@@ -83,7 +84,89 @@ func arithmeticFunc(lhs, rhs Function, op arithmeticOpFunc) (Function, error) {
 			return nil, err
 		}
 		return op(lhs, rhs, leftV, rightV)
-	}, aggregateTargetPaths(lhs, rhs)), nil
+	}, aggregateTargetPaths(lhs, rhs))
+	return withAnalysis(fn, analyseArithmetic(operator, lhs, rhs)), nil
+}
+
+// analyseArithmetic returns a static analysis of an arithmetic operator, where
+// operand types that would definitely result in an error are reported.
+func analyseArithmetic(op ArithmeticOperator, lhs, rhs Function) func(ctx AnalysisContext) *ValueSchema {
+	return func(ctx AnalysisContext) *ValueSchema {
+		lSchema, rSchema := Analyse(ctx, lhs), Analyse(ctx, rhs)
+		l, r := lSchema.ValueType(), rSchema.ValueType()
+
+		// Each operand is checked individually as the other might not be
+		// known.
+		isMismatch := func(accepted ...ValueType) bool {
+			for _, t := range []ValueType{l, r} {
+				if !isConcreteType(t) {
+					continue
+				}
+				matched := false
+				for _, a := range accepted {
+					if t == a {
+						matched = true
+					}
+				}
+				if !matched {
+					return true
+				}
+			}
+			return false
+		}
+
+		var mismatch bool
+		var res *ValueSchema
+		switch op {
+		case ArithmeticAdd:
+			switch l {
+			case ValueNumber:
+				mismatch = isMismatch(ValueNumber)
+				res = NewValueSchema(ValueNumber)
+			case ValueString, ValueBytes:
+				mismatch = isMismatch(ValueString, ValueBytes)
+				res = NewValueSchema(ValueString)
+			default:
+				mismatch = isMismatch(ValueNumber, ValueString, ValueBytes)
+			}
+		case ArithmeticSub, ArithmeticMul, ArithmeticDiv, ArithmeticMod:
+			mismatch = isMismatch(ValueNumber)
+			res = NewValueSchema(ValueNumber)
+		case ArithmeticGt, ArithmeticGte, ArithmeticLt, ArithmeticLte:
+			switch l {
+			case ValueNumber:
+				mismatch = isMismatch(ValueNumber)
+			case ValueString, ValueBytes:
+				mismatch = isMismatch(ValueString, ValueBytes)
+			case ValueTimestamp:
+				mismatch = isMismatch(ValueTimestamp, ValueNumber, ValueString, ValueBytes)
+			default:
+				mismatch = isMismatch(ValueNumber, ValueString, ValueBytes, ValueTimestamp)
+			}
+			res = NewValueSchema(ValueBool)
+		case ArithmeticEq, ArithmeticNeq:
+			res = NewValueSchema(ValueBool)
+		case ArithmeticAnd, ArithmeticOr:
+			mismatch = isMismatch(ValueBool, ValueNumber)
+			res = NewValueSchema(ValueBool)
+		case ArithmeticPipe:
+			if l == ValueNull {
+				res = rSchema
+			} else {
+				res = MergeValueSchemas(lSchema, rSchema)
+			}
+		}
+		if mismatch {
+			ctx.Warnf("%v", &TypeMismatch{
+				Lfn:       lhs,
+				Rfn:       rhs,
+				Left:      l,
+				Right:     r,
+				Operation: op.String(),
+			})
+		}
+		return res
+	}
 }
 
 //------------------------------------------------------------------------------
@@ -281,6 +364,36 @@ func compareStrFn(op ArithmeticOperator) func(lhs, rhs string) bool {
 	return nil
 }
 
+func compareTimeFn(op ArithmeticOperator) func(lhs, rhs time.Time) bool {
+	switch op {
+	case ArithmeticEq:
+		return func(lhs, rhs time.Time) bool {
+			return lhs.Equal(rhs)
+		}
+	case ArithmeticNeq:
+		return func(lhs, rhs time.Time) bool {
+			return !lhs.Equal(rhs)
+		}
+	case ArithmeticGt:
+		return func(lhs, rhs time.Time) bool {
+			return lhs.After(rhs)
+		}
+	case ArithmeticGte:
+		return func(lhs, rhs time.Time) bool {
+			return !lhs.Before(rhs)
+		}
+	case ArithmeticLt:
+		return func(lhs, rhs time.Time) bool {
+			return lhs.Before(rhs)
+		}
+	case ArithmeticLte:
+		return func(lhs, rhs time.Time) bool {
+			return !lhs.After(rhs)
+		}
+	}
+	return nil
+}
+
 func compareBoolFn(op ArithmeticOperator) func(lhs, rhs bool) bool {
 	switch op {
 	case ArithmeticEq:
@@ -310,6 +423,9 @@ func compareGenericFn(op ArithmeticOperator) func(lhs, rhs interface{}) bool {
 }
 
 func restrictForComparison(v interface{}) interface{} {
+	if _, isTime := v.(time.Time); isTime {
+		return v
+	}
 	v = ISanitize(v)
 	switch t := v.(type) {
 	case int64:
@@ -337,6 +453,7 @@ func compareOp(op ArithmeticOperator) (arithmeticOpFunc, bool) {
 		strOpFn := compareStrFn(op)
 		numOpFn := compareNumFn(op)
 		boolOpFn := compareBoolFn(op)
+		timeOpFn := compareTimeFn(op)
 		genericOpFn := compareGenericFn(op)
 		return func(lFn, rFn Function, left, right interface{}) (interface{}, error) {
 			switch lhs := restrictForComparison(left).(type) {
@@ -376,6 +493,15 @@ func compareOp(op ArithmeticOperator) (arithmeticOpFunc, bool) {
 					return genericOpFn(lhs, restrictForComparison(right)), nil
 				}
 				return boolOpFn(lhs, rhs), nil
+			case time.Time:
+				rhs, err := IGetTimestamp(right)
+				if err != nil {
+					if genericOpFn == nil {
+						return nil, NewTypeMismatch(op.String(), lFn, rFn, left, right)
+					}
+					return genericOpFn(lhs, restrictForComparison(right)), nil
+				}
+				return timeOpFn(lhs, rhs), nil
 			default:
 				if genericOpFn == nil {
 					return nil, NewTypeMismatch(op.String(), lFn, rFn, left, right)
@@ -388,7 +514,7 @@ func compareOp(op ArithmeticOperator) (arithmeticOpFunc, bool) {
 }
 
 func boolOr(lhs, rhs Function) Function {
-	return ClosureFunction(rhs.Annotation(), func(ctx FunctionContext) (interface{}, error) {
+	fn := ClosureFunction(rhs.Annotation(), func(ctx FunctionContext) (interface{}, error) {
 		lhsV, err := lhs.Exec(ctx)
 		if err != nil {
 			return nil, err
@@ -409,10 +535,11 @@ func boolOr(lhs, rhs Function) Function {
 		}
 		return b, nil
 	}, aggregateTargetPaths(lhs, rhs))
+	return withAnalysis(fn, analyseArithmetic(ArithmeticOr, lhs, rhs))
 }
 
 func boolAnd(lhs, rhs Function) Function {
-	return ClosureFunction(rhs.Annotation(), func(ctx FunctionContext) (interface{}, error) {
+	fn := ClosureFunction(rhs.Annotation(), func(ctx FunctionContext) (interface{}, error) {
 		lhsV, err := lhs.Exec(ctx)
 		if err != nil {
 			return nil, err
@@ -433,16 +560,18 @@ func boolAnd(lhs, rhs Function) Function {
 		}
 		return b, nil
 	}, aggregateTargetPaths(lhs, rhs))
+	return withAnalysis(fn, analyseArithmetic(ArithmeticAnd, lhs, rhs))
 }
 
 func coalesce(lhs, rhs Function) Function {
-	return ClosureFunction(rhs.Annotation(), func(ctx FunctionContext) (interface{}, error) {
+	fn := ClosureFunction(rhs.Annotation(), func(ctx FunctionContext) (interface{}, error) {
 		lhsV, err := lhs.Exec(ctx)
 		if err == nil && !IIsNull(lhsV) {
 			return lhsV, nil
 		}
 		return rhs.Exec(ctx)
 	}, aggregateTargetPaths(lhs, rhs))
+	return withAnalysis(fn, analyseArithmetic(ArithmeticPipe, lhs, rhs))
 }
 
 // NewArithmeticExpression creates a single query function from a list of child
@@ -463,7 +592,7 @@ func NewArithmeticExpression(fns []Function, ops []ArithmeticOperator) (Function
 	for i, op := range ops {
 		leftFn, rightFn := fnsNew[len(fnsNew)-1], fns[i+1]
 		if opFunc, isProd := prodOp(op); isProd {
-			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(leftFn, rightFn, opFunc); err != nil {
+			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(leftFn, rightFn, op, opFunc); err != nil {
 				return nil, err
 			}
 		} else if op == ArithmeticPipe {
@@ -483,7 +612,7 @@ func NewArithmeticExpression(fns []Function, ops []ArithmeticOperator) (Function
 	for i, op := range ops {
 		leftFn, rightFn := fnsNew[len(fnsNew)-1], fns[i+1]
 		if opFunc, isSum := sumOp(op); isSum {
-			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(leftFn, rightFn, opFunc); err != nil {
+			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(leftFn, rightFn, op, opFunc); err != nil {
 				return nil, err
 			}
 		} else {
@@ -501,7 +630,7 @@ func NewArithmeticExpression(fns []Function, ops []ArithmeticOperator) (Function
 	for i, op := range ops {
 		leftFn, rightFn := fnsNew[len(fnsNew)-1], fns[i+1]
 		if opFunc, isCompare := compareOp(op); isCompare {
-			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(leftFn, rightFn, opFunc); err != nil {
+			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(leftFn, rightFn, op, opFunc); err != nil {
 				return nil, err
 			}
 		} else {
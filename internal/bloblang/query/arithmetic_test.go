@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -244,6 +245,27 @@ func TestArithmeticComparisons(t *testing.T) {
 			op:     ArithmeticNeq,
 			result: false,
 		},
+		{
+			name:   "timestamp equal to timestamp in another zone",
+			left:   time.Date(2020, 8, 14, 11, 50, 26, 0, time.UTC),
+			right:  time.Date(2020, 8, 14, 13, 50, 26, 0, time.FixedZone("", 7200)),
+			op:     ArithmeticEq,
+			result: true,
+		},
+		{
+			name:   "timestamp greater than string",
+			left:   time.Date(2020, 8, 14, 11, 50, 26, 0, time.UTC),
+			right:  "2020-08-14T13:00:00+02:00",
+			op:     ArithmeticGt,
+			result: true,
+		},
+		{
+			name:   "timestamp less than unix",
+			left:   time.Unix(1597405826, 0),
+			right:  int64(1597405827),
+			op:     ArithmeticLt,
+			result: true,
+		},
 	}
 
 	for _, test := range testCases {
@@ -73,6 +73,10 @@ type FunctionSpec struct {
 	// Impure indicates that a function accesses or interacts with the outter
 	// environment, and is therefore unsafe to execute in shared environments.
 	Impure bool `json:"impure"`
+
+	// ReturnType optionally describes the type of value returned by the
+	// function, which is used for static analysis of mappings.
+	ReturnType ValueType `json:"return_type,omitempty"`
 }
 
 // NewFunctionSpec creates a new function spec.
@@ -106,6 +110,12 @@ func (s FunctionSpec) Param(def ParamDefinition) FunctionSpec {
 	return s
 }
 
+// Returns sets the type of value returned by the function.
+func (s FunctionSpec) Returns(t ValueType) FunctionSpec {
+	s.ReturnType = t
+	return s
+}
+
 // NewDeprecatedFunctionSpec creates a new function spec that is deprecated.
 func NewDeprecatedFunctionSpec(name, description string, examples ...ExampleSpec) FunctionSpec {
 	return FunctionSpec{
@@ -178,6 +188,18 @@ type MethodSpec struct {
 	// Impure indicates that a method accesses or interacts with the outter
 	// environment, and is therefore unsafe to execute in shared environments.
 	Impure bool `json:"impure"`
+
+	// InputTypes optionally lists the types of value that the method can be
+	// applied to, which is used for static analysis of mappings.
+	InputTypes []ValueType `json:"input_types,omitempty"`
+
+	// ReturnType optionally describes the type of value returned by the
+	// method, which is used for static analysis of mappings.
+	ReturnType ValueType `json:"return_type,omitempty"`
+
+	// foldable indicates that the method is a pure builtin, and can therefore
+	// be executed during parsing when its target and arguments are static.
+	foldable bool
 }
 
 // NewMethodSpec creates a new method spec.
@@ -231,9 +253,22 @@ func (m MethodSpec) Param(def ParamDefinition) MethodSpec {
 	return m
 }
 
-// VariadicParams configures the method spec to allow variadic parameters.
+// Accepts sets the types of value that the method can be applied to.
+func (m MethodSpec) Accepts(types ...ValueType) MethodSpec {
+	m.InputTypes = types
+	return m
+}
+
+// Returns sets the type of value returned by the method.
+func (m MethodSpec) Returns(t ValueType) MethodSpec {
+	m.ReturnType = t
+	return m
+}
+
+// VariadicParams configures the method spec to allow variadic parameters,
+// which follow any parameters that have already been added.
 func (m MethodSpec) VariadicParams() MethodSpec {
-	m.Params = VariadicParams()
+	m.Params.Variadic = true
 	return m
 }
 
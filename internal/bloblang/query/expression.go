@@ -2,6 +2,8 @@ package query
 
 import (
 	"fmt"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 // MatchCase represents a single match case of a match expression, where a case
@@ -9,20 +11,53 @@ import (
 type MatchCase struct {
 	caseFn  Function
 	queryFn Function
+
+	isLiteral bool
+	value     interface{}
 }
 
 // NewMatchCase creates a single match case of a match expression, where a case
 // query is checked and, if true, the underlying query is executed and returned.
 func NewMatchCase(caseFn, queryFn Function) MatchCase {
 	return MatchCase{
-		caseFn, queryFn,
+		caseFn:  caseFn,
+		queryFn: queryFn,
+	}
+}
+
+// NewLiteralMatchCase creates a single match case of a match expression, where
+// the context is compared with a literal value and, if equal, the underlying
+// query is executed and returned.
+func NewLiteralMatchCase(value interface{}, queryFn Function) MatchCase {
+	return MatchCase{
+		caseFn: ClosureFunction("case statement", func(ctx FunctionContext) (interface{}, error) {
+			v := ctx.Value()
+			if v == nil {
+				return false, nil
+			}
+			return cmp.Equal(*v, value), nil
+		}, nil),
+		queryFn:   queryFn,
+		isLiteral: true,
+		value:     value,
+	}
+}
+
+// isCatchAll returns true if the case matches any value.
+func (c MatchCase) isCatchAll() bool {
+	lit, isLit := c.caseFn.(*Literal)
+	if !isLit {
+		return false
 	}
+	b, _ := lit.Value.(bool)
+	return b
 }
 
 // NewMatchFunction takes a contextual mapping and a list of MatchCases, when
 // the function is executed
 func NewMatchFunction(contextFn Function, cases ...MatchCase) Function {
-	if contextFn == nil {
+	hasContextFn := contextFn != nil
+	if !hasContextFn {
 		contextFn = ClosureFunction("this", func(ctx FunctionContext) (interface{}, error) {
 			var value interface{}
 			if v := ctx.Value(); v != nil {
@@ -31,7 +66,8 @@ func NewMatchFunction(contextFn Function, cases ...MatchCase) Function {
 			return value, nil
 		}, nil)
 	}
-	return ClosureFunction("match expression", func(ctx FunctionContext) (interface{}, error) {
+	var fn Function
+	fn = ClosureFunction("match expression", func(ctx FunctionContext) (interface{}, error) {
 		ctxVal, err := contextFn.Exec(ctx)
 		if err != nil {
 			return nil, err
@@ -43,7 +79,9 @@ func NewMatchFunction(contextFn Function, cases ...MatchCase) Function {
 				return nil, fmt.Errorf("failed to check match case %v: %w", i, err)
 			}
 			if matched, _ := caseVal.(bool); matched {
-				return c.queryFn.Exec(caseCtx)
+				res, err := c.queryFn.Exec(caseCtx)
+				ctx.Trace(TraceEvent{Kind: TraceMatchCase, Function: fn, Case: i, Value: res, Err: err})
+				return res, err
 			}
 		}
 		return Nothing(nil), nil
@@ -64,6 +102,51 @@ func NewMatchFunction(contextFn Function, cases ...MatchCase) Function {
 		targets = append(targets, contextTargets...)
 		return ctx, targets
 	})
+	fn = withAnalysis(fn, func(ctx AnalysisContext) *ValueSchema {
+		ctxSchema := ctx.Value()
+		if hasContextFn {
+			ctxSchema = Analyse(ctx, contextFn)
+		}
+		caseCtx := ctx.WithValue(ctxSchema)
+
+		var res *ValueSchema
+		var seenLiterals []interface{}
+		exhausted := false
+		for i, c := range cases {
+			if exhausted {
+				ctx.Warnf("match case %v is unreachable as a previous case matches all values", i)
+			} else if c.isLiteral {
+				litType := ITypeOf(c.value)
+				ctxType := ctxSchema.ValueType()
+				for _, seen := range seenLiterals {
+					if cmp.Equal(seen, c.value) {
+						ctx.Warnf("match case %v is unreachable as a previous case matches the same value", i)
+						break
+					}
+				}
+				if isConcreteType(ctxType) && ctxType != litType {
+					ctx.Warnf("match case %v is unreachable as a %v value never equals a %v", i, ctxType, litType)
+				}
+				seenLiterals = append(seenLiterals, c.value)
+			} else if c.isCatchAll() {
+				exhausted = true
+			} else {
+				_ = Analyse(caseCtx, c.caseFn)
+			}
+
+			qSchema := Analyse(caseCtx, c.queryFn)
+			if i == 0 {
+				res = qSchema
+			} else {
+				res = MergeValueSchemas(res, qSchema)
+			}
+		}
+		if !exhausted {
+			return nil
+		}
+		return res
+	})
+	return fn
 }
 
 // ElseIf represents an else-if block in an if expression.
@@ -83,7 +166,7 @@ func NewIfFunction(queryFn, ifFn Function, elseIfs []ElseIf, elseFn Function) Fu
 		allFns = append(allFns, eIf.QueryFn, eIf.MapFn)
 	}
 
-	return ClosureFunction("if expression", func(ctx FunctionContext) (interface{}, error) {
+	fn := ClosureFunction("if expression", func(ctx FunctionContext) (interface{}, error) {
 		queryVal, err := queryFn.Exec(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to check if condition: %w", err)
@@ -107,6 +190,37 @@ func NewIfFunction(queryFn, ifFn Function, elseIfs []ElseIf, elseFn Function) Fu
 		}
 		return Nothing(nil), nil
 	}, aggregateTargetPaths(allFns...))
+	return withAnalysis(fn, func(ctx AnalysisContext) *ValueSchema {
+		branches := append([]ElseIf{{QueryFn: queryFn, MapFn: ifFn}}, elseIfs...)
+
+		var res *ValueSchema
+		exhausted := false
+		for i, b := range branches {
+			if exhausted {
+				ctx.Warnf("if expression: branch %v is unreachable as a previous condition is always true", i)
+			} else {
+				switch AnalyseCondition(ctx, "if expression", b.QueryFn) {
+				case ConditionAlwaysTrue:
+					exhausted = true
+				case ConditionNeverTrue:
+					ctx.Warnf("if expression: branch %v is unreachable as its condition is never true", i)
+				}
+			}
+			mSchema := Analyse(ctx, b.MapFn)
+			if i == 0 {
+				res = mSchema
+			} else {
+				res = MergeValueSchemas(res, mSchema)
+			}
+		}
+		if elseFn == nil {
+			return nil
+		}
+		if exhausted {
+			ctx.Warnf("if expression: else branch is unreachable as a previous condition is always true")
+		}
+		return MergeValueSchemas(res, Analyse(ctx, elseFn))
+	})
 }
 
 // NewNamedContextFunction wraps a function and ensures that when the function
@@ -156,3 +270,13 @@ func (n *NamedContextFunction) QueryTargets(ctx TargetsContext) (TargetsContext,
 	}
 	return n.fn.QueryTargets(ctx)
 }
+
+// Analyse performs a static analysis of the wrapped query function with the
+// context captured under an alias.
+func (n *NamedContextFunction) Analyse(ctx AnalysisContext) *ValueSchema {
+	v, nextCtx := ctx.PopValue()
+	if n.name != "_" {
+		nextCtx = nextCtx.WithNamedValue(n.name, v)
+	}
+	return Analyse(nextCtx, n.fn)
+}
@@ -69,10 +69,19 @@ func (f *FunctionSet) Init(name string, args *ParsedParams) (Function, error) {
 	if !exists {
 		return nil, badFunctionErr(name)
 	}
+	var fn Function
 	if f.disableCtors {
-		return disabledFunction(name), nil
+		fn = disabledFunction(name)
+	} else {
+		var err error
+		if fn, err = wrapCtorWithDynamicArgs(name, args, ctor); err != nil {
+			return nil, err
+		}
+	}
+	if _, isLit := fn.(*Literal); isLit {
+		return fn, nil
 	}
-	return wrapCtorWithDynamicArgs(name, args, ctor)
+	return &specFunction{Function: fn, spec: f.specs[name], args: args}, nil
 }
 
 // Without creates a clone of the function set that can be mutated in isolation,
@@ -181,6 +190,25 @@ func FunctionDocs() []FunctionSpec {
 
 //------------------------------------------------------------------------------
 
+// specFunction wraps an initialized function with its spec, allowing it to be
+// statically analysed.
+type specFunction struct {
+	Function
+	spec FunctionSpec
+	args *ParsedParams
+}
+
+func (s *specFunction) Analyse(ctx AnalysisContext) *ValueSchema {
+	if s.spec.Status == StatusDeprecated {
+		ctx.Warnf("function %v is deprecated", s.spec.Name)
+	}
+	if a, ok := s.Function.(Analysable); ok {
+		return a.Analyse(ctx)
+	}
+	analyseArgs(ctx, "function "+s.spec.Name, s.args)
+	return NewValueSchema(s.spec.ReturnType)
+}
+
 func disabledFunction(name string) Function {
 	return ClosureFunction("function "+name, func(ctx FunctionContext) (interface{}, error) {
 		return nil, errors.New("this function has been disabled")
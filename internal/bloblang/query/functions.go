@@ -21,6 +21,12 @@ type fieldFunction struct {
 	namedContext string
 	fromRoot     bool
 	path         []string
+
+	// Set when a prefix of the path is shared with other fields of a mapping
+	// and is cached during execution.
+	cachePlan   *FieldCachePlan
+	cacheSlot   int
+	cachePrefix int
 }
 
 func (f *fieldFunction) expand(path ...string) *fieldFunction {
@@ -60,6 +66,10 @@ func (f *fieldFunction) Exec(ctx FunctionContext) (interface{}, error) {
 				Err:       ErrNoContext,
 			}
 		}
+		if f.cachePlan != nil && ctx.fieldCache != nil && ctx.fieldCache.plan == f.cachePlan {
+			target = ctx.fieldCache.cachedPrefix(f.cacheSlot, v, f.path[:f.cachePrefix])
+			return getPath(target, f.path[f.cachePrefix:]), nil
+		}
 		target = *v
 	} else {
 		var ok bool
@@ -70,7 +80,7 @@ func (f *fieldFunction) Exec(ctx FunctionContext) (interface{}, error) {
 	if len(f.path) == 0 {
 		return target, nil
 	}
-	return gabs.Wrap(target).S(f.path...).Data(), nil
+	return getPath(target, f.path), nil
 }
 
 func (f *fieldFunction) QueryTargets(ctx TargetsContext) (TargetsContext, []TargetPath) {
@@ -80,6 +90,9 @@ func (f *fieldFunction) QueryTargets(ctx TargetsContext) (TargetsContext, []Targ
 	} else if f.namedContext == "" {
 		if basePaths = ctx.MainContext(); len(basePaths) == 0 {
 			basePaths = []TargetPath{NewTargetPath(TargetValue)}
+			if ctx.fieldVisitor != nil {
+				ctx.fieldVisitor(f)
+			}
 		}
 	} else {
 		basePaths = ctx.NamedContext(f.namedContext)
@@ -97,6 +110,16 @@ func (f *fieldFunction) Close(ctx context.Context) error {
 	return nil
 }
 
+func (f *fieldFunction) Analyse(ctx AnalysisContext) *ValueSchema {
+	if f.fromRoot {
+		return nil
+	}
+	if f.namedContext == "" {
+		return ctx.Value().Get(f.path...)
+	}
+	return ctx.NamedValue(f.namedContext).Get(f.path...)
+}
+
 // NewNamedContextFieldFunction creates a query function that attempts to
 // return a field from a named context.
 func NewNamedContextFieldFunction(namedContext, pathStr string) Function {
@@ -104,7 +127,10 @@ func NewNamedContextFieldFunction(namedContext, pathStr string) Function {
 	if len(pathStr) > 0 {
 		path = gabs.DotPathToSlice(pathStr)
 	}
-	return &fieldFunction{namedContext, false, path}
+	return &fieldFunction{
+		namedContext: namedContext,
+		path:         path,
+	}
 }
 
 // NewFieldFunction creates a query function that returns a field from the
@@ -164,6 +190,11 @@ func (l *Literal) Close(ctx context.Context) error {
 	return nil
 }
 
+// Analyse returns a schema describing the literal value.
+func (l *Literal) Analyse(ctx AnalysisContext) *ValueSchema {
+	return schemaOfLiteral(l.Value)
+}
+
 // String returns a string representation of the literal function.
 func (l *Literal) String() string {
 	return fmt.Sprintf("%v", l.Value)
@@ -184,7 +215,7 @@ var _ = registerSimpleFunction(
 		NewExampleSpec("",
 			`root = if batch_index() > 0 { deleted() }`,
 		),
-	),
+	).Returns(ValueNumber),
 	func(ctx FunctionContext) (interface{}, error) {
 		return int64(ctx.Index), nil
 	},
@@ -199,7 +230,7 @@ var _ = registerSimpleFunction(
 		NewExampleSpec("",
 			`root.foo = batch_size()`,
 		),
-	),
+	).Returns(ValueNumber),
 	func(ctx FunctionContext) (interface{}, error) {
 		return int64(ctx.MsgBatch.Len()), nil
 	},
@@ -236,7 +267,7 @@ root.id = count("bloblang_function_example")`,
 			`{"message":"bar"}`,
 			`{"id":2,"message":"bar"}`,
 		),
-	).Param(ParamString("name", "An identifier for the counter.")).MarkImpure(),
+	).Param(ParamString("name", "An identifier for the counter.")).MarkImpure().Returns(ValueNumber),
 	countFunction,
 )
 
@@ -440,7 +471,7 @@ var _ = registerSimpleFunction(
 		NewExampleSpec("",
 			`root.thing.host = hostname()`,
 		),
-	).MarkImpure(),
+	).MarkImpure().Returns(ValueString),
 	func(_ FunctionContext) (interface{}, error) {
 		hn, err := os.Hostname()
 		if err != nil {
@@ -646,7 +677,8 @@ root.second = random_int(1)`,
 			"seed",
 			"A seed to use, if a query is provided it will only be resolved once during the lifetime of the mapping.",
 			true,
-		).Default(NewLiteralFunction("", 0))),
+		).Default(NewLiteralFunction("", 0))).
+		Returns(ValueNumber),
 	randomIntFunction,
 )
 
@@ -694,7 +726,7 @@ var _ = registerFunction(
 		NewExampleSpec("",
 			`root.received_at = now().format_timestamp("Mon Jan 2 15:04:05 -0700 MST 2006", "UTC")`,
 		),
-	),
+	).Returns(ValueString),
 	func(args *ParsedParams) (Function, error) {
 		return ClosureFunction("function now", func(_ FunctionContext) (interface{}, error) {
 			return time.Now().Format(time.RFC3339Nano), nil
@@ -709,7 +741,7 @@ var _ = registerSimpleFunction(
 		NewExampleSpec("",
 			`root.received_at = timestamp_unix()`,
 		),
-	),
+	).Returns(ValueNumber),
 	func(_ FunctionContext) (interface{}, error) {
 		return time.Now().Unix(), nil
 	},
@@ -765,7 +797,7 @@ var _ = registerSimpleFunction(
 		FunctionCategoryGeneral, "uuid_v4",
 		"Generates a new RFC-4122 UUID each time it is invoked and prints a string representation.",
 		NewExampleSpec("", `root.id = uuid_v4()`),
-	),
+	).Returns(ValueString),
 	func(_ FunctionContext) (interface{}, error) {
 		u4, err := uuid.NewV4()
 		if err != nil {
@@ -786,7 +818,8 @@ var _ = registerFunction(
 		NewExampleSpec("It is also possible to specify an optional custom alphabet after the length parameter.", `root.id = nanoid(54, "abcde")`),
 	).
 		Param(ParamInt64("length", "An optional length.").Optional()).
-		Param(ParamString("alphabet", "An optional custom alphabet to use for generating IDs. When specified the field `length` must also be present.").Optional()),
+		Param(ParamString("alphabet", "An optional custom alphabet to use for generating IDs. When specified the field `length` must also be present.").Optional()).
+		Returns(ValueString),
 	nanoidFunction,
 )
 
@@ -820,7 +853,7 @@ var _ = registerSimpleFunction(
 		FunctionCategoryGeneral, "ksuid",
 		"Generates a new ksuid each time it is invoked and prints a string representation.",
 		NewExampleSpec("", `root.id = ksuid()`),
-	),
+	).Returns(ValueString),
 	func(_ FunctionContext) (interface{}, error) {
 		return ksuid.New().String(), nil
 	},
@@ -841,19 +874,47 @@ var _ = registerFunction(
 
 // NewVarFunction creates a new variable function.
 func NewVarFunction(name string) Function {
-	return ClosureFunction("variable "+name, func(ctx FunctionContext) (interface{}, error) {
-		if ctx.Vars == nil {
-			return nil, errors.New("variables were undefined")
-		}
-		if res, ok := ctx.Vars[name]; ok {
-			return res, nil
-		}
-		return nil, fmt.Errorf("variable '%v' undefined", name)
-	}, func(ctx TargetsContext) (TargetsContext, []TargetPath) {
-		paths := []TargetPath{
-			NewTargetPath(TargetVariable, name),
-		}
-		ctx = ctx.WithValues(paths)
-		return ctx, paths
-	})
+	return &varFunction{name: name}
+}
+
+type varFunction struct {
+	name string
+}
+
+func (v *varFunction) Annotation() string {
+	return "variable " + v.name
+}
+
+func (v *varFunction) Exec(ctx FunctionContext) (interface{}, error) {
+	if ctx.Vars == nil {
+		return nil, errors.New("variables were undefined")
+	}
+	if res, ok := ctx.Vars[v.name]; ok {
+		return res, nil
+	}
+	return nil, fmt.Errorf("variable '%v' undefined", v.name)
+}
+
+func (v *varFunction) QueryTargets(ctx TargetsContext) (TargetsContext, []TargetPath) {
+	paths := []TargetPath{
+		NewTargetPath(TargetVariable, v.name),
+	}
+	ctx = ctx.WithValues(paths)
+	return ctx, paths
+}
+
+func (v *varFunction) Analyse(ctx AnalysisContext) *ValueSchema {
+	if ctx.Vars == nil {
+		return nil
+	}
+	aVar, exists := ctx.Vars[v.name]
+	if !exists {
+		ctx.Warnf("variable %v is referenced but never declared", v.name)
+		return nil
+	}
+	aVar.Used = true
+	if aVar.Partial {
+		ctx.Warnf("variable %v is referenced but only declared within some branches", v.name)
+	}
+	return aVar.Schema
 }
@@ -124,6 +124,37 @@ func (m *mapLiteral) QueryTargets(ctx TargetsContext) (TargetsContext, []TargetP
 	return ctx, targetPaths
 }
 
+func (m *mapLiteral) Analyse(ctx AnalysisContext) *ValueSchema {
+	schema := &ValueSchema{
+		Type:       ValueObject,
+		Properties: map[string]*ValueSchema{},
+	}
+	for _, kv := range m.keyValues {
+		key, isStatic := kv[0].(string)
+		if !isStatic {
+			keyType := Analyse(ctx, kv[0].(Function)).ValueType()
+			if keyType != ValueString && keyType != ValueBytes && isConcreteType(keyType) {
+				ctx.Warnf("object literal: key %v", &TypeError{
+					From:     kv[0].(Function).Annotation(),
+					Expected: []ValueType{ValueString},
+					Actual:   keyType,
+				})
+			}
+		}
+
+		var valueSchema *ValueSchema
+		if fn, isFunction := kv[1].(Function); isFunction {
+			valueSchema = Analyse(ctx, fn)
+		} else {
+			valueSchema = schemaOfLiteral(kv[1])
+		}
+		if isStatic && isConcreteType(valueSchema.ValueType()) {
+			schema.Properties[key] = valueSchema
+		}
+	}
+	return schema
+}
+
 //------------------------------------------------------------------------------
 
 var _ Function = &arrayLiteral{}
@@ -193,3 +224,21 @@ func (a *arrayLiteral) QueryTargets(ctx TargetsContext) (TargetsContext, []Targe
 	// TODO: Mark next context with aliases?
 	return ctx, targetPaths
 }
+
+func (a *arrayLiteral) Analyse(ctx AnalysisContext) *ValueSchema {
+	schema := &ValueSchema{Type: ValueArray}
+	for i, v := range a.values {
+		var itemSchema *ValueSchema
+		if fn, isFunction := v.(Function); isFunction {
+			itemSchema = Analyse(ctx, fn)
+		} else {
+			itemSchema = schemaOfLiteral(v)
+		}
+		if i == 0 {
+			schema.Items = itemSchema
+		} else {
+			schema.Items = MergeValueSchemas(schema.Items, itemSchema)
+		}
+	}
+	return schema
+}
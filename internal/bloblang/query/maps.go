@@ -0,0 +1,67 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ParameterizedMap is implemented by declared maps that accept arguments, which
+// are bound to variables of the given parameter names when the map is executed.
+type ParameterizedMap interface {
+	Function
+	Params() []string
+}
+
+// execMap executes a declared map with a given context, where the arguments
+// are bound to the parameters of the map.
+func execMap(ctx FunctionContext, name string, args []interface{}) (interface{}, error) {
+	if ctx.Maps == nil {
+		return nil, errors.New("no maps were found")
+	}
+	m, ok := ctx.Maps[name]
+	if !ok {
+		return nil, fmt.Errorf("map %v was not found", name)
+	}
+
+	var params []string
+	if pm, ok := m.(ParameterizedMap); ok {
+		params = pm.Params()
+	}
+	if len(args) != len(params) {
+		return nil, fmt.Errorf("map %v expects %v arguments, got %v", name, len(params), len(args))
+	}
+
+	// ISOLATED VARIABLES
+	ctx.Vars = make(map[string]interface{}, len(params))
+	for i, p := range params {
+		ctx.Vars[p] = args[i]
+	}
+	return m.Exec(ctx)
+}
+
+// NewMapCallFunction creates a query function that executes a declared map
+// with the current context, where the provided arguments are bound to the
+// parameters of the map.
+func NewMapCallFunction(name string, args *ParsedParams) Function {
+	fn := ClosureFunction("map "+name, func(ctx FunctionContext) (interface{}, error) {
+		resolved, err := args.ResolveDynamic(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return execMap(ctx, name, resolved.Raw())
+	}, func(ctx TargetsContext) (TargetsContext, []TargetPath) {
+		_, targets := aggregateTargetPaths(args.dynamic()...)(ctx)
+
+		mapFn, ok := ctx.Maps[name]
+		if !ok || ctx.hasVisitedMap(name) {
+			return ctx, targets
+		}
+
+		_, mapTargets := mapFn.QueryTargets(ctx.withVisitedMap(name))
+		return ctx, append(targets, mapTargets...)
+	})
+	return withAnalysis(fn, func(ctx AnalysisContext) *ValueSchema {
+		analyseArgs(ctx, "map "+name, args)
+		return nil
+	})
+}
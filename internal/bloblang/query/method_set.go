@@ -65,10 +65,24 @@ func (m *MethodSet) Init(name string, target Function, args *ParsedParams) (Func
 	if !exists {
 		return nil, badMethodErr(name)
 	}
+	var fn Function
 	if m.disableCtors {
-		return disabledMethod(name), nil
+		fn = disabledMethod(name)
+	} else {
+		var err error
+		if fn, err = wrapMethodCtorWithDynamicArgs(name, target, args, ctor); err != nil {
+			return nil, err
+		}
+	}
+	if _, isLit := fn.(*Literal); isLit {
+		return fn, nil
+	}
+	if _, isLit := target.(*Literal); isLit && !m.disableCtors && args.isStatic() && m.specs[name].foldable {
+		if lit, ok := foldMethod(fn); ok {
+			return lit, nil
+		}
 	}
-	return wrapMethodCtorWithDynamicArgs(name, target, args, ctor)
+	return &specMethod{Function: fn, spec: m.specs[name], target: target, args: args}, nil
 }
 
 // Without creates a clone of the method set that can be mutated in isolation,
@@ -127,6 +141,8 @@ func (m *MethodSet) Deactivated() *MethodSet {
 var AllMethods = NewMethodSet()
 
 func registerMethod(spec MethodSpec, ctor MethodCtor) struct{} {
+	// Only builtin methods are folded, as the purity of plugins is unknown.
+	spec.foldable = !spec.Impure
 	if err := AllMethods.Add(spec, func(target Function, args *ParsedParams) (Function, error) {
 		return ctor(target, args)
 	}); err != nil {
@@ -156,6 +172,45 @@ func MethodDocs() []MethodSpec {
 
 //------------------------------------------------------------------------------
 
+// specMethod wraps an initialized method with its spec, allowing it to be
+// statically analysed.
+type specMethod struct {
+	Function
+	spec   MethodSpec
+	target Function
+	args   *ParsedParams
+}
+
+func (s *specMethod) Analyse(ctx AnalysisContext) *ValueSchema {
+	if s.spec.Status == StatusDeprecated {
+		ctx.Warnf("method %v is deprecated", s.spec.Name)
+	}
+	if a, ok := s.Function.(Analysable); ok {
+		return a.Analyse(ctx)
+	}
+
+	targetType := Analyse(ctx, s.target).ValueType()
+	if len(s.spec.InputTypes) > 0 && isConcreteType(targetType) {
+		accepted := false
+		for _, t := range s.spec.InputTypes {
+			if t == targetType {
+				accepted = true
+				break
+			}
+		}
+		if !accepted {
+			ctx.Warnf("method %v: %v", s.spec.Name, &TypeError{
+				From:     s.target.Annotation(),
+				Expected: s.spec.InputTypes,
+				Actual:   targetType,
+			})
+		}
+	}
+
+	analyseArgs(ctx, "method "+s.spec.Name, s.args)
+	return NewValueSchema(s.spec.ReturnType)
+}
+
 func disabledMethod(name string) Function {
 	return ClosureFunction("method "+name, func(ctx FunctionContext) (interface{}, error) {
 		return nil, errors.New("this method has been disabled")
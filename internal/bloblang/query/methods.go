@@ -2,7 +2,6 @@ package query
 
 import (
 	"errors"
-	"fmt"
 	"strconv"
 
 	"github.com/Jeffail/gabs/v2"
@@ -11,7 +10,7 @@ import (
 var _ = registerMethod(
 	NewMethodSpec(
 		"apply",
-		"Apply a declared mapping to a target value.",
+		"Apply a declared mapping to a target value. Any arguments following the name of the mapping are passed to the parameters of the mapping.",
 		NewExampleSpec("",
 			`map thing {
   root.inner = this.first
@@ -32,7 +31,16 @@ root.foo = null.apply("create_foo")`,
 			`{"id":"1234"}`,
 			`{"foo":{"name":"a foo","purpose":"to be a foo"},"id":"1234"}`,
 		),
-	).Param(ParamString("mapping", "The mapping to apply.")),
+		NewExampleSpec("",
+			`map greet(greeting) {
+  root = $greeting + " " + this.name
+}
+
+root.message = this.user.apply("greet", "hello")`,
+			`{"user":{"name":"sam"}}`,
+			`{"message":"hello sam"}`,
+		),
+	).Param(ParamString("mapping", "The mapping to apply.")).VariadicParams(),
 	applyMethod,
 )
 
@@ -42,32 +50,22 @@ func applyMethod(target Function, args *ParsedParams) (Function, error) {
 		return nil, err
 	}
 
+	mapArgs := args.Raw()[1:]
+
 	return ClosureFunction("map "+targetMap, func(ctx FunctionContext) (interface{}, error) {
 		res, err := target.Exec(ctx)
 		if err != nil {
 			return nil, err
 		}
-		ctx = ctx.WithValue(res)
-
-		if ctx.Maps == nil {
-			return nil, errors.New("no maps were found")
-		}
-		m, ok := ctx.Maps[targetMap]
-		if !ok {
-			return nil, fmt.Errorf("map %v was not found", targetMap)
-		}
-
-		// ISOLATED VARIABLES
-		ctx.Vars = map[string]interface{}{}
-		return m.Exec(ctx)
+		return execMap(ctx.WithValue(res), targetMap, mapArgs)
 	}, func(ctx TargetsContext) (TargetsContext, []TargetPath) {
 		mapFn, ok := ctx.Maps[targetMap]
-		if !ok {
+		if !ok || ctx.hasVisitedMap(targetMap) {
 			return target.QueryTargets(ctx)
 		}
 
 		mapCtx, targets := target.QueryTargets(ctx)
-		mapCtx = mapCtx.WithValues(targets).WithValuesAsContext()
+		mapCtx = mapCtx.WithValues(targets).WithValuesAsContext().withVisitedMap(targetMap)
 
 		returnCtx, mapTargets := mapFn.QueryTargets(mapCtx)
 		return returnCtx, append(targets, mapTargets...)
@@ -84,7 +82,7 @@ var _ = registerMethod(
 			`root.foo = this.thing.bool()
 root.bar = this.thing.bool(true)`,
 		),
-	).Param(ParamBool("default", "An optional value to yield if the target cannot be parsed as a boolean.").Optional()),
+	).Param(ParamBool("default", "An optional value to yield if the target cannot be parsed as a boolean.").Optional()).Returns(ValueBool),
 	boolMethod,
 )
 
@@ -262,7 +260,7 @@ func (g *getMethod) Exec(ctx FunctionContext) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	return gabs.Wrap(v).S(g.path...).Data(), nil
+	return getPath(v, g.path), nil
 }
 
 func (g *getMethod) QueryTargets(ctx TargetsContext) (TargetsContext, []TargetPath) {
@@ -279,9 +277,18 @@ func (g *getMethod) QueryTargets(ctx TargetsContext) (TargetsContext, []TargetPa
 	return ctx, append(fnPaths, paths...)
 }
 
+func (g *getMethod) Analyse(ctx AnalysisContext) *ValueSchema {
+	return Analyse(ctx, g.fn).Get(g.path...)
+}
+
 // NewGetMethod creates a new get method.
 func NewGetMethod(target Function, pathStr string) (Function, error) {
 	path := gabs.DotPathToSlice(pathStr)
+	if sm, isSpec := target.(*specMethod); isSpec {
+		if g, isGet := sm.Function.(*getMethod); isGet {
+			target = g
+		}
+	}
 	switch t := target.(type) {
 	case *getMethod:
 		newPath := append([]string{}, t.path...)
@@ -317,7 +324,7 @@ var _ = registerMethod(
 
 // NewMapMethod attempts to create a map method.
 func NewMapMethod(target, mapFn Function) (Function, error) {
-	return ClosureFunction(mapFn.Annotation(), func(ctx FunctionContext) (interface{}, error) {
+	fn := ClosureFunction(mapFn.Annotation(), func(ctx FunctionContext) (interface{}, error) {
 		res, err := target.Exec(ctx)
 		if err != nil {
 			return nil, err
@@ -329,6 +336,9 @@ func NewMapMethod(target, mapFn Function) (Function, error) {
 
 		returnCtx, mapTargets := mapFn.QueryTargets(mapCtx)
 		return returnCtx, append(targets, mapTargets...)
+	})
+	return withAnalysis(fn, func(ctx AnalysisContext) *ValueSchema {
+		return Analyse(ctx.WithValue(Analyse(ctx, target)), mapFn)
 	}), nil
 }
 
@@ -375,6 +385,17 @@ func (n *notMethod) QueryTargets(ctx TargetsContext) (TargetsContext, []TargetPa
 	return n.fn.QueryTargets(ctx)
 }
 
+func (n *notMethod) Analyse(ctx AnalysisContext) *ValueSchema {
+	if t := Analyse(ctx, n.fn).ValueType(); t != ValueBool && isConcreteType(t) {
+		ctx.Warnf("not: %v", &TypeError{
+			From:     n.fn.Annotation(),
+			Expected: []ValueType{ValueBool},
+			Actual:   t,
+		})
+	}
+	return NewValueSchema(ValueBool)
+}
+
 func notMethodCtor(target Function, _ *ParsedParams) (Function, error) {
 	return &notMethod{fn: target}, nil
 }
@@ -417,7 +438,7 @@ var _ = registerMethod(
 			`root.foo = this.thing.number() + 10
 root.bar = this.thing.number(5) * 10`,
 		),
-	).Param(ParamFloat("default", "An optional value to yield if the target cannot be parsed as a number.").Optional()),
+	).Param(ParamFloat("default", "An optional value to yield if the target cannot be parsed as a number.").Optional()).Returns(ValueNumber),
 	numberCoerceMethod,
 )
 
@@ -476,7 +497,7 @@ var _ = registerSimpleMethod(
 		"type", "",
 	).InCategory(
 		MethodCategoryCoercion,
-		"Returns the type of a value as a string, providing one of the following values: `string`, `bytes`, `number`, `bool`, `array`, `object` or `null`.",
+		"Returns the type of a value as a string, providing one of the following values: `string`, `bytes`, `number`, `bool`, `array`, `object`, `timestamp` or `null`.",
 		NewExampleSpec("",
 			`root.bar_type = this.bar.type()
 root.foo_type = this.foo.type()`,
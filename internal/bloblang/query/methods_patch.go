@@ -0,0 +1,531 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// valuesEqual returns true if two values are structurally equal, where numbers
+// are compared by value regardless of their type, byte arrays are compared as
+// strings and timestamps are compared by the instant they represent.
+func valuesEqual(lhs, rhs interface{}) bool {
+	lhs, rhs = ISanitize(lhs), ISanitize(rhs)
+	switch l := lhs.(type) {
+	case map[string]interface{}:
+		r, ok := rhs.(map[string]interface{})
+		if !ok || len(l) != len(r) {
+			return false
+		}
+		for k, lv := range l {
+			rv, exists := r[k]
+			if !exists || !valuesEqual(lv, rv) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		r, ok := rhs.([]interface{})
+		if !ok || len(l) != len(r) {
+			return false
+		}
+		for i, lv := range l {
+			if !valuesEqual(lv, r[i]) {
+				return false
+			}
+		}
+		return true
+	case int64, uint64, float64:
+		switch rhs.(type) {
+		case int64, uint64, float64:
+		default:
+			return false
+		}
+		lf, _ := IGetNumber(l)
+		rf, _ := IGetNumber(rhs)
+		return lf == rf
+	case string, []byte:
+		switch rhs.(type) {
+		case string, []byte:
+		default:
+			return false
+		}
+		return IToString(l) == IToString(rhs)
+	case time.Time:
+		r, ok := rhs.(time.Time)
+		return ok && l.Equal(r)
+	case bool:
+		r, ok := rhs.(bool)
+		return ok && l == r
+	case nil:
+		return rhs == nil
+	}
+	return false
+}
+
+//------------------------------------------------------------------------------
+
+func escapeJSONPointerToken(s string) string {
+	s = strings.ReplaceAll(s, "~", "~0")
+	return strings.ReplaceAll(s, "/", "~1")
+}
+
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("pointer '%v' must be empty or begin with '/'", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		t = strings.ReplaceAll(t, "~1", "/")
+		tokens[i] = strings.ReplaceAll(t, "~0", "~")
+	}
+	return tokens, nil
+}
+
+// jsonDiff appends to ops the JSON Patch operations that transform the value
+// from into the value to, where path is the pointer of both values.
+func jsonDiff(path string, from, to interface{}, ops []interface{}) []interface{} {
+	if valuesEqual(from, to) {
+		return ops
+	}
+	switch f := ISanitize(from).(type) {
+	case map[string]interface{}:
+		t, ok := ISanitize(to).(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(f))
+		for k := range f {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			subPath := path + "/" + escapeJSONPointerToken(k)
+			if tv, exists := t[k]; exists {
+				ops = jsonDiff(subPath, f[k], tv, ops)
+			} else {
+				ops = append(ops, map[string]interface{}{
+					"op":   "remove",
+					"path": subPath,
+				})
+			}
+		}
+		keys = keys[:0]
+		for k := range t {
+			if _, exists := f[k]; !exists {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			ops = append(ops, map[string]interface{}{
+				"op":    "add",
+				"path":  path + "/" + escapeJSONPointerToken(k),
+				"value": IClone(t[k]),
+			})
+		}
+		return ops
+	case []interface{}:
+		t, ok := ISanitize(to).([]interface{})
+		if !ok {
+			break
+		}
+		common := len(f)
+		if len(t) < common {
+			common = len(t)
+		}
+		for i := 0; i < common; i++ {
+			ops = jsonDiff(path+"/"+strconv.Itoa(i), f[i], t[i], ops)
+		}
+		// Elements are removed from the end so that the indexes of earlier
+		// removals remain valid.
+		for i := len(f) - 1; i >= common; i-- {
+			ops = append(ops, map[string]interface{}{
+				"op":   "remove",
+				"path": path + "/" + strconv.Itoa(i),
+			})
+		}
+		for i := common; i < len(t); i++ {
+			ops = append(ops, map[string]interface{}{
+				"op":    "add",
+				"path":  path + "/" + strconv.Itoa(i),
+				"value": IClone(t[i]),
+			})
+		}
+		return ops
+	}
+	return append(ops, map[string]interface{}{
+		"op":    "replace",
+		"path":  path,
+		"value": IClone(to),
+	})
+}
+
+//------------------------------------------------------------------------------
+
+func jsonPointerIndex(token string, length int, allowEnd bool) (int, error) {
+	if allowEnd && token == "-" {
+		return length, nil
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || (len(token) > 1 && token[0] == '0') || i < 0 {
+		return 0, fmt.Errorf("invalid array index '%v'", token)
+	}
+	if i > length || (i == length && !allowEnd) {
+		return 0, fmt.Errorf("array index %v is out of bounds", i)
+	}
+	return i, nil
+}
+
+// patchAt walks a document to the parent of the last token of a pointer and
+// calls a function with it, where the function returns the new value of the
+// parent.
+func patchAt(node interface{}, tokens []string, fn func(parent interface{}, key string) (interface{}, error)) (interface{}, error) {
+	if len(tokens) == 1 {
+		return fn(node, tokens[0])
+	}
+	switch t := node.(type) {
+	case map[string]interface{}:
+		child, exists := t[tokens[0]]
+		if !exists {
+			return nil, fmt.Errorf("field '%v' does not exist", tokens[0])
+		}
+		newChild, err := patchAt(child, tokens[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		t[tokens[0]] = newChild
+		return t, nil
+	case []interface{}:
+		i, err := jsonPointerIndex(tokens[0], len(t), false)
+		if err != nil {
+			return nil, err
+		}
+		newChild, err := patchAt(t[i], tokens[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		t[i] = newChild
+		return t, nil
+	}
+	return nil, fmt.Errorf("cannot walk into %v value with '%v'", ITypeOf(node), tokens[0])
+}
+
+func patchGet(node interface{}, tokens []string) (interface{}, error) {
+	for _, token := range tokens {
+		switch t := node.(type) {
+		case map[string]interface{}:
+			child, exists := t[token]
+			if !exists {
+				return nil, fmt.Errorf("field '%v' does not exist", token)
+			}
+			node = child
+		case []interface{}:
+			i, err := jsonPointerIndex(token, len(t), false)
+			if err != nil {
+				return nil, err
+			}
+			node = t[i]
+		default:
+			return nil, fmt.Errorf("cannot walk into %v value with '%v'", ITypeOf(node), token)
+		}
+	}
+	return node, nil
+}
+
+func patchAdd(doc interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return patchAt(doc, tokens, func(parent interface{}, key string) (interface{}, error) {
+		switch t := parent.(type) {
+		case map[string]interface{}:
+			t[key] = value
+			return t, nil
+		case []interface{}:
+			i, err := jsonPointerIndex(key, len(t), true)
+			if err != nil {
+				return nil, err
+			}
+			t = append(t, nil)
+			copy(t[i+1:], t[i:])
+			t[i] = value
+			return t, nil
+		}
+		return nil, fmt.Errorf("cannot add '%v' to %v value", key, ITypeOf(parent))
+	})
+}
+
+func patchRemove(doc interface{}, tokens []string) (interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, errors.New("cannot remove the root of the document")
+	}
+	return patchAt(doc, tokens, func(parent interface{}, key string) (interface{}, error) {
+		switch t := parent.(type) {
+		case map[string]interface{}:
+			if _, exists := t[key]; !exists {
+				return nil, fmt.Errorf("field '%v' does not exist", key)
+			}
+			delete(t, key)
+			return t, nil
+		case []interface{}:
+			i, err := jsonPointerIndex(key, len(t), false)
+			if err != nil {
+				return nil, err
+			}
+			return append(t[:i], t[i+1:]...), nil
+		}
+		return nil, fmt.Errorf("cannot remove '%v' from %v value", key, ITypeOf(parent))
+	})
+}
+
+func patchReplace(doc interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return patchAt(doc, tokens, func(parent interface{}, key string) (interface{}, error) {
+		switch t := parent.(type) {
+		case map[string]interface{}:
+			if _, exists := t[key]; !exists {
+				return nil, fmt.Errorf("field '%v' does not exist", key)
+			}
+			t[key] = value
+			return t, nil
+		case []interface{}:
+			i, err := jsonPointerIndex(key, len(t), false)
+			if err != nil {
+				return nil, err
+			}
+			t[i] = value
+			return t, nil
+		}
+		return nil, fmt.Errorf("cannot replace '%v' of %v value", key, ITypeOf(parent))
+	})
+}
+
+// applyJSONPatchOp applies a single JSON Patch operation to a document,
+// returning the new document.
+func applyJSONPatchOp(doc interface{}, opV interface{}) (interface{}, error) {
+	op, ok := opV.(map[string]interface{})
+	if !ok {
+		return nil, NewTypeError(opV, ValueObject)
+	}
+
+	getPointer := func(field string) ([]string, error) {
+		v, exists := op[field]
+		if !exists {
+			return nil, fmt.Errorf("missing field '%v'", field)
+		}
+		s, err := IGetString(v)
+		if err != nil {
+			return nil, fmt.Errorf("field '%v': %w", field, err)
+		}
+		return parseJSONPointer(s)
+	}
+	getValue := func() (interface{}, error) {
+		v, exists := op["value"]
+		if !exists {
+			return nil, errors.New("missing field 'value'")
+		}
+		return IClone(v), nil
+	}
+
+	opName, err := IGetString(op["op"])
+	if err != nil {
+		return nil, fmt.Errorf("field 'op': %w", err)
+	}
+	path, err := getPointer("path")
+	if err != nil {
+		return nil, err
+	}
+
+	switch opName {
+	case "add":
+		value, err := getValue()
+		if err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, path, value)
+	case "remove":
+		return patchRemove(doc, path)
+	case "replace":
+		value, err := getValue()
+		if err != nil {
+			return nil, err
+		}
+		return patchReplace(doc, path, value)
+	case "move", "copy":
+		from, err := getPointer("from")
+		if err != nil {
+			return nil, err
+		}
+		value, err := patchGet(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if opName == "copy" {
+			return patchAdd(doc, path, IClone(value))
+		}
+		if len(path) > len(from) && SliceToDotPath(path[:len(from)]...) == SliceToDotPath(from...) {
+			return nil, errors.New("cannot move a value into one of its children")
+		}
+		if doc, err = patchRemove(doc, from); err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, path, value)
+	case "test":
+		expected, err := getValue()
+		if err != nil {
+			return nil, err
+		}
+		value, err := patchGet(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !valuesEqual(value, expected) {
+			return nil, errors.New("test failed, value does not match")
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("unrecognised operation '%v'", opName)
+}
+
+// applyMergePatch applies a JSON Merge Patch to a document, returning the new
+// document.
+func applyMergePatch(doc, patch interface{}) interface{} {
+	patchObj, isObj := patch.(map[string]interface{})
+	if !isObj {
+		return IClone(patch)
+	}
+	docObj, isObj := doc.(map[string]interface{})
+	if !isObj {
+		docObj = map[string]interface{}{}
+	}
+	for k, v := range patchObj {
+		if v == nil {
+			delete(docObj, k)
+			continue
+		}
+		docObj[k] = applyMergePatch(docObj[k], v)
+	}
+	return docObj
+}
+
+//------------------------------------------------------------------------------
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"equals", "",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"Checks whether a value is structurally equal to another, returning a boolean. Unlike the `==` operator, objects and arrays are compared element by element following the same rules as scalar values, where numbers are equal when they have the same value regardless of whether they are integers or floats.",
+		NewExampleSpec("",
+			`root.changed = !this.previous.equals(this.current)`,
+			`{"previous":{"id":1,"tags":["a","b"]},"current":{"id":1.0,"tags":["a","b"]}}`,
+			`{"changed":false}`,
+			`{"previous":{"id":1,"tags":["a","b"]},"current":{"id":1,"tags":["b","a"]}}`,
+			`{"changed":true}`,
+		),
+	).Param(ParamAny("other", "The value to compare against.")).Returns(ValueBool),
+	func(args *ParsedParams) (simpleMethod, error) {
+		other, err := args.Field("other")
+		if err != nil {
+			return nil, err
+		}
+		return func(v interface{}, ctx FunctionContext) (interface{}, error) {
+			return valuesEqual(v, other), nil
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"diff", "",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"Computes the structural differences between a value and another as an array of [RFC 6902 JSON Patch](https://datatracker.ietf.org/doc/html/rfc6902) operations, which transform the value into the other when applied with the [`patch`](#patch) method. Fields of objects are compared in key order, and elements of arrays are compared by index. When both values are equal the result is an empty array.",
+		NewExampleSpec("",
+			`root = this.previous.diff(this.current)`,
+			`{"previous":{"name":"foo","tags":["a","b"],"age":10},"current":{"name":"bar","tags":["a"],"email":"foo@example.com"}}`,
+			`[{"op":"remove","path":"/age"},{"op":"replace","path":"/name","value":"bar"},{"op":"remove","path":"/tags/1"},{"op":"add","path":"/email","value":"foo@example.com"}]`,
+		),
+	).Param(ParamAny("other", "The value to compare against.")).Returns(ValueArray),
+	func(args *ParsedParams) (simpleMethod, error) {
+		other, err := args.Field("other")
+		if err != nil {
+			return nil, err
+		}
+		return func(v interface{}, ctx FunctionContext) (interface{}, error) {
+			return jsonDiff("", v, other, []interface{}{}), nil
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"patch", "",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"Applies an array of [RFC 6902 JSON Patch](https://datatracker.ietf.org/doc/html/rfc6902) operations to a value, supporting the operations `add`, `remove`, `replace`, `move`, `copy` and `test`. Operations are applied in order, and if any operation fails, including a `test` operation that does not match, then an error is returned and the value is left unchanged.",
+		NewExampleSpec("",
+			`root = this.doc.patch(this.ops)`,
+			`{"doc":{"name":"foo","tags":["a"]},"ops":[{"op":"replace","path":"/name","value":"bar"},{"op":"add","path":"/tags/-","value":"b"},{"op":"copy","from":"/name","path":"/alias"}]}`,
+			`{"alias":"bar","name":"bar","tags":["a","b"]}`,
+		),
+		NewExampleSpec("The result of the [`diff`](#diff) method can be used in order to repeat a change on another document.",
+			`root = this.other.patch(this.before.diff(this.after))`,
+			`{"before":{"a":1},"after":{"a":2},"other":{"a":1,"b":3}}`,
+			`{"a":2,"b":3}`,
+		),
+	).Param(ParamArray("operations", "An array of JSON Patch operations.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		ops, err := args.FieldArray("operations")
+		if err != nil {
+			return nil, err
+		}
+		return func(v interface{}, ctx FunctionContext) (interface{}, error) {
+			doc := IClone(v)
+			for i, op := range ops {
+				if doc, err = applyJSONPatchOp(doc, op); err != nil {
+					return nil, fmt.Errorf("operation %v: %w", i, err)
+				}
+			}
+			return doc, nil
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"merge_patch", "",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"Applies an [RFC 7396 JSON Merge Patch](https://datatracker.ietf.org/doc/html/rfc7396) to a value. Fields of the patch object are assigned to the value recursively, where fields set to `null` are deleted and values that are not objects, including arrays, replace the existing value entirely.",
+		NewExampleSpec("",
+			`root = this.doc.merge_patch(this.patch)`,
+			`{"doc":{"name":"foo","address":{"city":"London","zip":"E1"},"tags":["a","b"]},"patch":{"address":{"zip":null},"tags":["c"],"age":10}}`,
+			`{"address":{"city":"London"},"age":10,"name":"foo","tags":["c"]}`,
+		),
+	).Param(ParamAny("patch", "The merge patch to apply.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		patch, err := args.Field("patch")
+		if err != nil {
+			return nil, err
+		}
+		return func(v interface{}, ctx FunctionContext) (interface{}, error) {
+			return applyMergePatch(IClone(v), patch), nil
+		}, nil
+	},
+)
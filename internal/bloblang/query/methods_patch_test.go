@@ -0,0 +1,259 @@
+package query
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValuesEqual(t *testing.T) {
+	testCases := []struct {
+		name  string
+		lhs   interface{}
+		rhs   interface{}
+		equal bool
+	}{
+		{name: "int and float", lhs: int64(1), rhs: 1.0, equal: true},
+		{name: "json number and uint", lhs: json.Number("5"), rhs: uint64(5), equal: true},
+		{name: "different numbers", lhs: int64(1), rhs: 1.5, equal: false},
+		{name: "string and bytes", lhs: "foo", rhs: []byte("foo"), equal: true},
+		{name: "string and number", lhs: "1", rhs: int64(1), equal: false},
+		{name: "null and null", lhs: nil, rhs: nil, equal: true},
+		{name: "null and string", lhs: nil, rhs: "", equal: false},
+		{
+			name:  "nested structures",
+			lhs:   map[string]interface{}{"a": []interface{}{int64(1), "b"}},
+			rhs:   map[string]interface{}{"a": []interface{}{1.0, []byte("b")}},
+			equal: true,
+		},
+		{
+			name:  "missing field",
+			lhs:   map[string]interface{}{"a": nil},
+			rhs:   map[string]interface{}{"b": nil},
+			equal: false,
+		},
+		{
+			name:  "array order",
+			lhs:   []interface{}{"a", "b"},
+			rhs:   []interface{}{"b", "a"},
+			equal: false,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.equal, valuesEqual(test.lhs, test.rhs))
+			assert.Equal(t, test.equal, valuesEqual(test.rhs, test.lhs))
+		})
+	}
+}
+
+func TestDiffPatchRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name string
+		from string
+		to   string
+		ops  string
+	}{
+		{
+			name: "equal",
+			from: `{"a":[1,{"b":"c"}]}`,
+			to:   `{"a":[1,{"b":"c"}]}`,
+			ops:  `[]`,
+		},
+		{
+			name: "root type change",
+			from: `{"a":1}`,
+			to:   `[1]`,
+			ops:  `[{"op":"replace","path":"","value":[1]}]`,
+		},
+		{
+			name: "escaped keys",
+			from: `{"a/b":1,"c~d":2}`,
+			to:   `{"a/b":2}`,
+			ops:  `[{"op":"replace","path":"/a~1b","value":2},{"op":"remove","path":"/c~0d"}]`,
+		},
+		{
+			name: "shrink array",
+			from: `[1,2,3,4]`,
+			to:   `[1,5]`,
+			ops:  `[{"op":"replace","path":"/1","value":5},{"op":"remove","path":"/3"},{"op":"remove","path":"/2"}]`,
+		},
+		{
+			name: "grow nested array",
+			from: `{"a":{"b":[]}}`,
+			to:   `{"a":{"b":[{"c":1},2]}}`,
+			ops:  `[{"op":"add","path":"/a/b/0","value":{"c":1}},{"op":"add","path":"/a/b/1","value":2}]`,
+		},
+	}
+
+	parse := func(t *testing.T, s string) interface{} {
+		t.Helper()
+		var v interface{}
+		require.NoError(t, json.Unmarshal([]byte(s), &v))
+		return v
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			from, to := parse(t, test.from), parse(t, test.to)
+
+			diff, err := InitMethodHelper("diff", NewLiteralFunction("", from), to)
+			require.NoError(t, err)
+
+			ops, err := diff.Exec(FunctionContext{})
+			require.NoError(t, err)
+			assert.Equal(t, parse(t, test.ops), ops)
+
+			patch, err := InitMethodHelper("patch", NewLiteralFunction("", from), ops)
+			require.NoError(t, err)
+
+			res, err := patch.Exec(FunctionContext{})
+			require.NoError(t, err)
+			assert.Equal(t, to, res)
+
+			// The target of the patch must not be modified.
+			assert.Equal(t, parse(t, test.from), from)
+		})
+	}
+}
+
+func TestPatch(t *testing.T) {
+	testCases := []struct {
+		name string
+		doc  string
+		ops  string
+		exp  string
+		err  string
+	}{
+		{
+			name: "move field",
+			doc:  `{"a":{"b":1},"c":[]}`,
+			ops:  `[{"op":"move","from":"/a/b","path":"/c/0"}]`,
+			exp:  `{"a":{},"c":[1]}`,
+		},
+		{
+			name: "test passes",
+			doc:  `{"a":1}`,
+			ops:  `[{"op":"test","path":"/a","value":1.0},{"op":"remove","path":"/a"}]`,
+			exp:  `{}`,
+		},
+		{
+			name: "test fails",
+			doc:  `{"a":1}`,
+			ops:  `[{"op":"remove","path":"/a"},{"op":"test","path":"/a","value":1}]`,
+			err:  "operation 1: field 'a' does not exist",
+		},
+		{
+			name: "test value mismatch",
+			doc:  `{"a":1}`,
+			ops:  `[{"op":"test","path":"/a","value":2}]`,
+			err:  "operation 0: test failed, value does not match",
+		},
+		{
+			name: "add missing parent",
+			doc:  `{"a":1}`,
+			ops:  `[{"op":"add","path":"/b/c","value":2}]`,
+			err:  "operation 0: field 'b' does not exist",
+		},
+		{
+			name: "array index out of bounds",
+			doc:  `[1,2]`,
+			ops:  `[{"op":"add","path":"/3","value":3}]`,
+			err:  "operation 0: array index 3 is out of bounds",
+		},
+		{
+			name: "array index leading zero",
+			doc:  `[1,2]`,
+			ops:  `[{"op":"replace","path":"/01","value":3}]`,
+			err:  "operation 0: invalid array index '01'",
+		},
+		{
+			name: "move into child",
+			doc:  `{"a":{"b":1}}`,
+			ops:  `[{"op":"move","from":"/a","path":"/a/c"}]`,
+			err:  "operation 0: cannot move a value into one of its children",
+		},
+		{
+			name: "unknown operation",
+			doc:  `{}`,
+			ops:  `[{"op":"nope","path":""}]`,
+			err:  "operation 0: unrecognised operation 'nope'",
+		},
+		{
+			name: "bad pointer",
+			doc:  `{}`,
+			ops:  `[{"op":"add","path":"a","value":1}]`,
+			err:  "operation 0: pointer 'a' must be empty or begin with '/'",
+		},
+		{
+			name: "missing value",
+			doc:  `{}`,
+			ops:  `[{"op":"add","path":"/a"}]`,
+			err:  "operation 0: missing field 'value'",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			var doc, ops interface{}
+			require.NoError(t, json.Unmarshal([]byte(test.doc), &doc))
+			require.NoError(t, json.Unmarshal([]byte(test.ops), &ops))
+
+			fn, err := InitMethodHelper("patch", NewLiteralFunction("", doc), ops)
+			require.NoError(t, err)
+
+			res, err := fn.Exec(FunctionContext{})
+			if test.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.err)
+				return
+			}
+			require.NoError(t, err)
+
+			var exp interface{}
+			require.NoError(t, json.Unmarshal([]byte(test.exp), &exp))
+			assert.Equal(t, exp, res)
+		})
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	// Test cases from RFC 7396 Appendix A.
+	testCases := [][3]string{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, test := range testCases {
+		var doc, patch, exp interface{}
+		require.NoError(t, json.Unmarshal([]byte(test[0]), &doc))
+		require.NoError(t, json.Unmarshal([]byte(test[1]), &patch))
+		require.NoError(t, json.Unmarshal([]byte(test[2]), &exp))
+
+		fn, err := InitMethodHelper("merge_patch", NewLiteralFunction("", doc), patch)
+		require.NoError(t, err, test[1])
+
+		res, err := fn.Exec(FunctionContext{})
+		require.NoError(t, err, test[1])
+		assert.Equal(t, exp, res, test[1])
+	}
+}
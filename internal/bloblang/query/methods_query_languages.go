@@ -0,0 +1,184 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/itchyny/gojq"
+	"github.com/jmespath/go-jmespath"
+)
+
+// normaliseForQuery returns a copy of a value containing only the types of a
+// parsed JSON document, where integers are either kept as ints or converted
+// into floats, as required by the jq and JMESPath libraries respectively.
+func normaliseForQuery(v interface{}, intsAsFloats bool) interface{} {
+	switch t := ISanitize(v).(type) {
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			obj[k] = normaliseForQuery(e, intsAsFloats)
+		}
+		return obj
+	case []interface{}:
+		arr := make([]interface{}, len(t))
+		for i, e := range t {
+			arr[i] = normaliseForQuery(e, intsAsFloats)
+		}
+		return arr
+	case int64:
+		if intsAsFloats || int64(int(t)) != t {
+			return float64(t)
+		}
+		return int(t)
+	case uint64:
+		if intsAsFloats || t > uint64(int(^uint(0)>>1)) {
+			return float64(t)
+		}
+		return int(t)
+	case []byte:
+		return string(t)
+	case time.Time:
+		return t.Format(time.RFC3339Nano)
+	case Delete, Nothing:
+		return nil
+	default:
+		return t
+	}
+}
+
+// denormaliseJQResult converts the numerical types returned by jq queries into
+// those used by Bloblang.
+func denormaliseJQResult(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = denormaliseJQResult(e)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = denormaliseJQResult(e)
+		}
+	case int:
+		return int64(t)
+	case *big.Int:
+		if t.IsInt64() {
+			return t.Int64()
+		}
+		f, _ := new(big.Float).SetInt(t).Float64()
+		return f
+	}
+	return v
+}
+
+//------------------------------------------------------------------------------
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"jmespath", "",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"Executes a [JMESPath query](https://jmespath.org/) on a value and returns the result. The query is compiled once when the mapping is parsed, which allows queries written for the [`jmespath` processor](/docs/components/processors/jmespath) to be used within a mapping.",
+		NewExampleSpec("",
+			`root.names = this.jmespath("locations[?state == 'WA'].name | sort(@)")`,
+			`{"locations":[{"name":"Seattle","state":"WA"},{"name":"New York","state":"NY"},{"name":"Bellevue","state":"WA"},{"name":"Olympia","state":"WA"}]}`,
+			`{"names":["Bellevue","Olympia","Seattle"]}`,
+		),
+	).Param(ParamString("query", "The JMESPath query to execute.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		queryStr, err := args.FieldString("query")
+		if err != nil {
+			return nil, err
+		}
+		query, err := jmespath.Compile(queryStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile JMESPath query: %w", err)
+		}
+		return func(v interface{}, ctx FunctionContext) (res interface{}, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("jmespath panic: %v", r)
+				}
+			}()
+			return query.Search(normaliseForQuery(v, true))
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"jq", "",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"Executes a [jq query](https://stedolan.github.io/jq/manual/) on a value and returns the result. When the query emits multiple values they are returned as an array, and when it emits none the result is `null`. The query is compiled once when the mapping is parsed, and the metadata of the message being mapped is available as the variable `$metadata`, which allows queries written for the [`jq` processor](/docs/components/processors/jq) to be used within a mapping.",
+		NewExampleSpec("",
+			`root.total = this.jq("[.items[] | .price * .quantity] | add")`,
+			`{"items":[{"price":2.5,"quantity":4},{"price":1,"quantity":3}]}`,
+			`{"total":13}`,
+		),
+		NewExampleSpec("",
+			`root.tags = this.jq(".posts[] | select(.published) | .tags[]")`,
+			`{"posts":[{"published":true,"tags":["foo","bar"]},{"published":false,"tags":["baz"]}]}`,
+			`{"tags":["foo","bar"]}`,
+		),
+	).Param(ParamString("query", "The jq query to execute.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		queryStr, err := args.FieldString("query")
+		if err != nil {
+			return nil, err
+		}
+		query, err := gojq.Parse(queryStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse jq query: %w", err)
+		}
+		// Queries are only given metadata when they reference it, as otherwise
+		// queries of constant values can be evaluated ahead of time.
+		usesMetadata := false
+		code, err := gojq.Compile(query)
+		if err != nil {
+			usesMetadata = true
+			if code, err = gojq.Compile(query, gojq.WithVariables([]string{"$metadata"})); err != nil {
+				return nil, fmt.Errorf("failed to compile jq query: %w", err)
+			}
+		}
+		return func(v interface{}, ctx FunctionContext) (interface{}, error) {
+			var iter gojq.Iter
+			if usesMetadata {
+				if ctx.MsgBatch == nil || ctx.Index >= ctx.MsgBatch.Len() {
+					return nil, errors.New("message metadata is not available")
+				}
+				metadata := map[string]interface{}{}
+				_ = ctx.MsgBatch.Get(ctx.Index).MetaIter(func(k, v string) error {
+					metadata[k] = v
+					return nil
+				})
+				iter = code.Run(normaliseForQuery(v, false), metadata)
+			} else {
+				iter = code.Run(normaliseForQuery(v, false))
+			}
+
+			var emitted []interface{}
+			for {
+				out, ok := iter.Next()
+				if !ok {
+					break
+				}
+				if err, ok := out.(error); ok {
+					return nil, err
+				}
+				emitted = append(emitted, denormaliseJQResult(out))
+			}
+
+			switch len(emitted) {
+			case 0:
+				return nil, nil
+			case 1:
+				return emitted[0], nil
+			}
+			return emitted, nil
+		}, nil
+	},
+)
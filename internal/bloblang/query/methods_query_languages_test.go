@@ -0,0 +1,151 @@
+package query
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+func TestQueryLanguageMethods(t *testing.T) {
+	doc := map[string]interface{}{
+		"id":      int64(5),
+		"big":     uint64(1 << 63),
+		"num":     json.Number("1.5"),
+		"raw":     []byte("foo"),
+		"created": time.Date(2020, 8, 14, 11, 50, 26, 0, time.UTC),
+		"items": []interface{}{
+			map[string]interface{}{"name": "a", "count": int64(2)},
+			map[string]interface{}{"name": "b", "count": 3.0},
+		},
+	}
+
+	testCases := []struct {
+		name   string
+		method string
+		query  string
+		exp    interface{}
+		err    string
+	}{
+		{
+			name:   "jmespath integer comparison",
+			method: "jmespath",
+			query:  "items[?count > `2`].name",
+			exp:    []interface{}{"b"},
+		},
+		{
+			name:   "jmespath normalised types",
+			method: "jmespath",
+			query:  "[id, big, num, raw, created]",
+			exp:    []interface{}{5.0, float64(1 << 63), 1.5, "foo", "2020-08-14T11:50:26Z"},
+		},
+		{
+			name:   "jmespath no match",
+			method: "jmespath",
+			query:  "nope.nah",
+			exp:    nil,
+		},
+		{
+			name:   "jmespath bad query",
+			method: "jmespath",
+			query:  "items[?",
+			err:    "failed to compile JMESPath query",
+		},
+		{
+			name:   "jq integers",
+			method: "jq",
+			query:  "([.items[].count] | add) + .id",
+			exp:    10.0,
+		},
+		{
+			name:   "jq normalised types",
+			method: "jq",
+			query:  "[.id, .num, .raw, .created]",
+			exp:    []interface{}{int64(5), 1.5, "foo", "2020-08-14T11:50:26Z"},
+		},
+		{
+			name:   "jq big integer",
+			method: "jq",
+			query:  ".id * 4611686018427387904",
+			exp:    float64(5 * (1 << 62)),
+		},
+		{
+			name:   "jq multiple results",
+			method: "jq",
+			query:  ".items[].name",
+			exp:    []interface{}{"a", "b"},
+		},
+		{
+			name:   "jq no results",
+			method: "jq",
+			query:  "empty",
+			exp:    nil,
+		},
+		{
+			name:   "jq runtime error",
+			method: "jq",
+			query:  ".id | keys",
+			err:    "keys cannot be applied to: number (5)",
+		},
+		{
+			name:   "jq bad query",
+			method: "jq",
+			query:  ".[",
+			err:    "failed to parse jq query",
+		},
+		{
+			name:   "jq unknown variable",
+			method: "jq",
+			query:  "$nope",
+			err:    "failed to compile jq query: variable not defined: $nope",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			docClone := IClone(doc)
+
+			fn, err := InitMethodHelper(test.method, NewLiteralFunction("", docClone), test.query)
+			if err == nil {
+				var res interface{}
+				if res, err = fn.Exec(FunctionContext{}); err == nil {
+					require.Empty(t, test.err)
+					assert.Equal(t, test.exp, res)
+					assert.Equal(t, doc, docClone)
+					return
+				}
+			}
+			require.NotEmpty(t, test.err, err.Error())
+			assert.Contains(t, err.Error(), test.err)
+		})
+	}
+}
+
+func TestJQMetadata(t *testing.T) {
+	part := message.NewPart(nil)
+	part.MetaSet("topic", "foo")
+	batch := message.QuickBatch(nil)
+	batch.Append(part)
+
+	fn, err := InitMethodHelper("jq", NewFieldFunction(""), `{topic: $metadata.topic, value: .}`)
+	require.NoError(t, err)
+
+	res, err := fn.Exec(FunctionContext{MsgBatch: batch}.WithValue("bar"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"topic": "foo", "value": "bar"}, res)
+
+	// Queries of metadata must not be evaluated ahead of time.
+	fn, err = InitMethodHelper("jq", NewLiteralFunction("", "bar"), `$metadata.topic`)
+	require.NoError(t, err)
+	_, isLit := fn.(*Literal)
+	assert.False(t, isLit)
+
+	res, err = fn.Exec(FunctionContext{MsgBatch: batch})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", res)
+}
@@ -645,7 +645,7 @@ var _ = registerSimpleMethod(
 			`{"foo":{"bar":1,"baz":2}}`,
 			`{"foo_keys":["bar","baz"]}`,
 		),
-	),
+	).Accepts(ValueObject).Returns(ValueArray),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v interface{}, ctx FunctionContext) (interface{}, error) {
 			if m, ok := v.(map[string]interface{}); ok {
@@ -714,7 +714,7 @@ var _ = registerSimpleMethod(
 			`{"foo":{"first":"bar","second":"baz"}}`,
 			`{"foo_len":2}`,
 		),
-	),
+	).Accepts(ValueString, ValueBytes, ValueArray, ValueObject).Returns(ValueNumber),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v interface{}, ctx FunctionContext) (interface{}, error) {
 			var length int64
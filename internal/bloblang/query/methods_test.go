@@ -661,6 +661,105 @@ func TestMethods(t *testing.T) {
 			},
 			output: "foo & bar",
 		},
+		"check parse url opaque": {
+			input: methods(
+				literalFn("mailto:foo@example.com?subject=hi"),
+				method("parse_url"),
+			),
+			output: map[string]interface{}{
+				"scheme":    "mailto",
+				"opaque":    "foo@example.com",
+				"host":      "",
+				"port":      "",
+				"path":      "",
+				"raw_query": "subject=hi",
+				"query":     map[string]interface{}{"subject": "hi"},
+				"fragment":  "",
+			},
+		},
+		"check parse url ipv6": {
+			input: methods(
+				literalFn("http://[::1]:4195/post"),
+				method("parse_url"),
+				method("without", "query", "raw_query", "fragment"),
+			),
+			output: map[string]interface{}{
+				"scheme": "http",
+				"host":   "::1",
+				"port":   "4195",
+				"path":   "/post",
+			},
+		},
+		"check parse url error": {
+			input: methods(
+				literalFn("http://foo bar:baz"),
+				method("parse_url"),
+			),
+			err: `string literal: parse "http://foo bar:baz": invalid port ":baz" after host`,
+		},
+		"check format url round trip": {
+			input: methods(
+				literalFn("https://user@[::1]:4195/foo%20bar?b=2&a=1&a=3#frag"),
+				method("parse_url"),
+				method("format_url"),
+			),
+			output: "https://user@[::1]:4195/foo%20bar?a=1&a=3&b=2#frag",
+		},
+		"check format url raw query": {
+			input: methods(
+				literalFn(map[string]interface{}{
+					"scheme":    "http",
+					"host":      "::1",
+					"port":      int64(80),
+					"raw_query": "b=2&a=1",
+				}),
+				method("format_url"),
+			),
+			output: "http://[::1]:80?b=2&a=1",
+		},
+		"check format url bad query": {
+			input: methods(
+				literalFn(map[string]interface{}{
+					"host":  "example.com",
+					"query": map[string]interface{}{"a": map[string]interface{}{}},
+				}),
+				method("format_url"),
+			),
+			err: "object literal: field query: field a: expected string, number, bool or array value, got object",
+		},
+		"check parse form url encoded bytes": {
+			input: methods(
+				function("content"),
+				method("parse_form_url_encoded"),
+			),
+			messages: []easyMsg{
+				{content: `a=1&b=&a=2&c`},
+			},
+			output: map[string]interface{}{
+				"a": []interface{}{"1", "2"},
+				"b": "",
+				"c": "",
+			},
+		},
+		"check format form url encoded": {
+			input: methods(
+				literalFn(map[string]interface{}{
+					"b": []interface{}{true, 1.5},
+					"a": "foo & bar",
+				}),
+				method("format_form_url_encoded"),
+			),
+			output: "a=foo+%26+bar&b=true&b=1.5",
+		},
+		"check format form url encoded bad array": {
+			input: methods(
+				literalFn(map[string]interface{}{
+					"a": []interface{}{"foo", nil},
+				}),
+				method("format_form_url_encoded"),
+			),
+			err: "object literal: field a: index 1: expected string, number or bool value, got null",
+		},
 		"check flatten": {
 			input: methods(
 				function("json"),
@@ -0,0 +1,326 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rickb777/date/period"
+)
+
+// timeShift is a duration parsed from a method argument, which is either a
+// fixed duration or an ISO 8601 period with calendar units.
+type timeShift struct {
+	fixed  time.Duration
+	period *period.Period
+}
+
+// parseTimeShift parses a duration argument, which can either be an integer of
+// nanoseconds, a duration string such as "1h30m", or an ISO 8601 duration such
+// as "P1M2D".
+func parseTimeShift(v interface{}) (timeShift, error) {
+	if b, isBytes := v.([]byte); isBytes {
+		v = string(b)
+	}
+	switch t := ISanitize(v).(type) {
+	case string:
+		if strings.HasPrefix(strings.TrimPrefix(t, "-"), "P") {
+			p, err := period.Parse(t, false)
+			if err != nil {
+				return timeShift{}, fmt.Errorf("failed to parse ISO 8601 duration: %w", err)
+			}
+			return timeShift{period: &p}, nil
+		}
+		d, err := time.ParseDuration(t)
+		if err != nil {
+			return timeShift{}, err
+		}
+		return timeShift{fixed: d}, nil
+	case int64, uint64, float64:
+		i, err := IGetInt(t)
+		if err != nil {
+			return timeShift{}, err
+		}
+		return timeShift{fixed: time.Duration(i)}, nil
+	}
+	return timeShift{}, NewTypeError(v, ValueString, ValueNumber)
+}
+
+func (s timeShift) addTo(t time.Time, negate bool) time.Time {
+	if s.period != nil {
+		p := *s.period
+		if negate {
+			p = p.Negate()
+		}
+		res, _ := p.AddTo(t)
+		return res
+	}
+	if negate {
+		return t.Add(-s.fixed)
+	}
+	return t.Add(s.fixed)
+}
+
+// parseFixedDuration parses a duration argument that must not contain calendar
+// units, as they do not have a fixed length.
+func parseFixedDuration(v interface{}) (time.Duration, error) {
+	s, err := parseTimeShift(v)
+	if err != nil {
+		return 0, err
+	}
+	if s.period != nil {
+		return 0, errors.New("ISO 8601 durations are not supported, use a duration string such as 1h instead")
+	}
+	if s.fixed <= 0 {
+		return 0, fmt.Errorf("duration must be greater than zero, got %v", s.fixed)
+	}
+	return s.fixed, nil
+}
+
+func timestampMethod(fn func(t time.Time) (interface{}, error)) simpleMethod {
+	return func(v interface{}, ctx FunctionContext) (interface{}, error) {
+		t, err := IGetTimestamp(v)
+		if err != nil {
+			return nil, err
+		}
+		return fn(t)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"ts_parse", "",
+	).InCategory(
+		MethodCategoryTime,
+		"Attempts to parse a string as a timestamp following a specified format and returns a timestamp value, which can be used with other timestamp methods without being parsed again. The input format is defined by showing how the reference time, defined to be Mon Jan 2 15:04:05 -0700 MST 2006, would be displayed if it were the value. When a timestamp value is written to a document it is formatted following ISO 8601.",
+		NewExampleSpec("",
+			`root.doc.timestamp = this.doc.timestamp.ts_parse("2006-Jan-02 15:04")`,
+			`{"doc":{"timestamp":"2020-Aug-14 11:50"}}`,
+			`{"doc":{"timestamp":"2020-08-14T11:50:00Z"}}`,
+		),
+		NewExampleSpec("An optional timezone can be specified, which is used when the format does not include one.",
+			`root.doc.timestamp = this.doc.timestamp.ts_parse("2006-Jan-02 15:04", "Europe/Paris")`,
+			`{"doc":{"timestamp":"2020-Aug-14 11:50"}}`,
+			`{"doc":{"timestamp":"2020-08-14T11:50:00+02:00"}}`,
+		),
+	).Beta().
+		Param(ParamString("format", "The format of the target string.")).
+		Param(ParamString("tz", "An optional timezone to parse the string in, otherwise UTC is used unless the format includes a timezone.").Optional()).
+		Accepts(ValueString, ValueBytes).
+		Returns(ValueTimestamp),
+	func(args *ParsedParams) (simpleMethod, error) {
+		layout, err := args.FieldString("format")
+		if err != nil {
+			return nil, err
+		}
+		timezone := time.UTC
+		tzOpt, err := args.FieldOptionalString("tz")
+		if err != nil {
+			return nil, err
+		}
+		if tzOpt != nil {
+			if timezone, err = time.LoadLocation(*tzOpt); err != nil {
+				return nil, fmt.Errorf("failed to parse timezone location name: %w", err)
+			}
+		}
+		return stringMethod(func(s string) (interface{}, error) {
+			return time.ParseInLocation(layout, s, timezone)
+		}), nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"ts_add", "",
+	).InCategory(
+		MethodCategoryTime,
+		"Adds a duration to a timestamp and returns a timestamp value. The duration can either be an integer of nanoseconds, a duration string such as `1h30m`, or an ISO 8601 duration such as `P1M2D`, where calendar units are added following the calendar of the timestamp. Timestamp values can either be a timestamp, a numerical unix time in seconds (with up to nanosecond precision via decimals), or a string in ISO 8601 format.",
+		NewExampleSpec("",
+			`root.expires_at = this.created_at.ts_add("1h30m")`,
+			`{"created_at":"2020-08-14T11:50:26Z"}`,
+			`{"expires_at":"2020-08-14T13:20:26Z"}`,
+		),
+		NewExampleSpec("",
+			`root.renews_at = this.created_at.ts_add("P1M")`,
+			`{"created_at":"2020-01-31T00:00:00Z"}`,
+			`{"renews_at":"2020-03-02T00:00:00Z"}`,
+		),
+	).Beta().
+		Param(ParamAny("duration", "The duration to add.")).
+		Accepts(ValueTimestamp, ValueNumber, ValueString, ValueBytes).
+		Returns(ValueTimestamp),
+	func(args *ParsedParams) (simpleMethod, error) {
+		return tsShiftMethod(args, false)
+	},
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"ts_sub", "",
+	).InCategory(
+		MethodCategoryTime,
+		"Subtracts a duration from a timestamp and returns a timestamp value. The duration can either be an integer of nanoseconds, a duration string such as `1h30m`, or an ISO 8601 duration such as `P1M2D`, where calendar units are subtracted following the calendar of the timestamp. Timestamp values can either be a timestamp, a numerical unix time in seconds (with up to nanosecond precision via decimals), or a string in ISO 8601 format.",
+		NewExampleSpec("",
+			`root.window_start = this.created_at.ts_sub("15m")`,
+			`{"created_at":"2020-08-14T11:50:26Z"}`,
+			`{"window_start":"2020-08-14T11:35:26Z"}`,
+		),
+		NewExampleSpec("",
+			`root.previous_week = this.created_at.ts_sub("P1W")`,
+			`{"created_at":"2020-08-14T11:50:26Z"}`,
+			`{"previous_week":"2020-08-07T11:50:26Z"}`,
+		),
+	).Beta().
+		Param(ParamAny("duration", "The duration to subtract.")).
+		Accepts(ValueTimestamp, ValueNumber, ValueString, ValueBytes).
+		Returns(ValueTimestamp),
+	func(args *ParsedParams) (simpleMethod, error) {
+		return tsShiftMethod(args, true)
+	},
+)
+
+func tsShiftMethod(args *ParsedParams, negate bool) (simpleMethod, error) {
+	v, err := args.Field("duration")
+	if err != nil {
+		return nil, err
+	}
+	shift, err := parseTimeShift(v)
+	if err != nil {
+		return nil, err
+	}
+	return timestampMethod(func(t time.Time) (interface{}, error) {
+		return shift.addTo(t, negate), nil
+	}), nil
+}
+
+//------------------------------------------------------------------------------
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"ts_round", "",
+	).InCategory(
+		MethodCategoryTime,
+		"Rounds a timestamp to the nearest multiple of a duration since the zero time and returns a timestamp value, where halfway values are rounded up. The duration can either be an integer of nanoseconds or a duration string such as `1h`. Timestamp values can either be a timestamp, a numerical unix time in seconds (with up to nanosecond precision via decimals), or a string in ISO 8601 format.",
+		NewExampleSpec("",
+			`root.created_at_hour = this.created_at.ts_round("1h")`,
+			`{"created_at":"2020-08-14T11:50:26Z"}`,
+			`{"created_at_hour":"2020-08-14T12:00:00Z"}`,
+		),
+	).Beta().
+		Param(ParamAny("duration", "The duration to round to.")).
+		Accepts(ValueTimestamp, ValueNumber, ValueString, ValueBytes).
+		Returns(ValueTimestamp),
+	func(args *ParsedParams) (simpleMethod, error) {
+		v, err := args.Field("duration")
+		if err != nil {
+			return nil, err
+		}
+		d, err := parseFixedDuration(v)
+		if err != nil {
+			return nil, err
+		}
+		return timestampMethod(func(t time.Time) (interface{}, error) {
+			return t.Round(d), nil
+		}), nil
+	},
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"ts_truncate", "",
+	).InCategory(
+		MethodCategoryTime,
+		"Rounds a timestamp down to a multiple of a duration since the zero time and returns a timestamp value. The duration can either be an integer of nanoseconds or a duration string such as `1h`. Timestamp values can either be a timestamp, a numerical unix time in seconds (with up to nanosecond precision via decimals), or a string in ISO 8601 format.",
+		NewExampleSpec("",
+			`root.created_at_hour = this.created_at.ts_truncate("1h")`,
+			`{"created_at":"2020-08-14T11:50:26Z"}`,
+			`{"created_at_hour":"2020-08-14T11:00:00Z"}`,
+		),
+	).Beta().
+		Param(ParamAny("duration", "The duration to truncate to.")).
+		Accepts(ValueTimestamp, ValueNumber, ValueString, ValueBytes).
+		Returns(ValueTimestamp),
+	func(args *ParsedParams) (simpleMethod, error) {
+		v, err := args.Field("duration")
+		if err != nil {
+			return nil, err
+		}
+		d, err := parseFixedDuration(v)
+		if err != nil {
+			return nil, err
+		}
+		return timestampMethod(func(t time.Time) (interface{}, error) {
+			return t.Truncate(d), nil
+		}), nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"ts_tz", "",
+	).InCategory(
+		MethodCategoryTime,
+		"Converts a timestamp to an IANA timezone and returns a timestamp value, which represents the same instant in time. Timestamp values can either be a timestamp, a numerical unix time in seconds (with up to nanosecond precision via decimals), or a string in ISO 8601 format.",
+		NewExampleSpec("",
+			`root.created_at_local = this.created_at.ts_tz("America/New_York")`,
+			`{"created_at":"2020-08-14T11:50:26Z"}`,
+			`{"created_at_local":"2020-08-14T07:50:26-04:00"}`,
+		),
+		NewExampleSpec("",
+			`root.created_at_utc = this.created_at.ts_tz("UTC")`,
+			`{"created_at":"2020-08-14T11:50:26+02:00"}`,
+			`{"created_at_utc":"2020-08-14T09:50:26Z"}`,
+		),
+	).Beta().
+		Param(ParamString("tz", "The timezone to convert to, such as `Europe/London`, `UTC` or `Local`.")).
+		Accepts(ValueTimestamp, ValueNumber, ValueString, ValueBytes).
+		Returns(ValueTimestamp),
+	func(args *ParsedParams) (simpleMethod, error) {
+		tz, err := args.FieldString("tz")
+		if err != nil {
+			return nil, err
+		}
+		timezone, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse timezone location name: %w", err)
+		}
+		return timestampMethod(func(t time.Time) (interface{}, error) {
+			return t.In(timezone), nil
+		}), nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"ts_diff", "",
+	).InCategory(
+		MethodCategoryTime,
+		"Returns the duration in nanoseconds between a timestamp and another, which is negative when the other timestamp is later. Timestamp values can either be a timestamp, a numerical unix time in seconds (with up to nanosecond precision via decimals), or a string in ISO 8601 format.",
+		NewExampleSpec("",
+			`root.took_seconds = this.finished_at.ts_diff(this.started_at) / 1000000000`,
+			`{"started_at":"2020-08-14T11:50:26Z","finished_at":"2020-08-14T11:52:00.5Z"}`,
+			`{"took_seconds":94.5}`,
+		),
+	).Beta().
+		Param(ParamTimestamp("other", "The timestamp to subtract.")).
+		Accepts(ValueTimestamp, ValueNumber, ValueString, ValueBytes).
+		Returns(ValueNumber),
+	func(args *ParsedParams) (simpleMethod, error) {
+		other, err := args.FieldTimestamp("other")
+		if err != nil {
+			return nil, err
+		}
+		return timestampMethod(func(t time.Time) (interface{}, error) {
+			return t.Sub(other).Nanoseconds(), nil
+		}), nil
+	},
+)
@@ -0,0 +1,175 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimestampMethods(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+
+	refTime := time.Date(2020, 8, 14, 11, 50, 26, 371000000, time.UTC)
+
+	testCases := []struct {
+		name   string
+		method string
+		target interface{}
+		args   []interface{}
+		exp    interface{}
+		err    string
+	}{
+		{
+			name:   "parse with timezone",
+			method: "ts_parse",
+			target: "2020-Aug-14 11:50",
+			args:   []interface{}{"2006-Jan-02 15:04", "Europe/Paris"},
+			exp:    time.Date(2020, 8, 14, 11, 50, 0, 0, paris),
+		},
+		{
+			name:   "add duration string",
+			method: "ts_add",
+			target: refTime,
+			args:   []interface{}{"1h30m"},
+			exp:    refTime.Add(90 * time.Minute),
+		},
+		{
+			name:   "add nanoseconds to string",
+			method: "ts_add",
+			target: "2020-08-14T11:50:26.371Z",
+			args:   []interface{}{int64(1000)},
+			exp:    refTime.Add(time.Microsecond),
+		},
+		{
+			name:   "add iso duration",
+			method: "ts_add",
+			target: refTime,
+			args:   []interface{}{"P1Y2M"},
+			exp:    time.Date(2021, 10, 14, 11, 50, 26, 371000000, time.UTC),
+		},
+		{
+			name:   "add to unix timestamp",
+			method: "ts_add",
+			target: int64(1597405826),
+			args:   []interface{}{"1s"},
+			exp:    time.Unix(1597405827, 0),
+		},
+		{
+			name:   "add bad duration",
+			method: "ts_add",
+			target: refTime,
+			args:   []interface{}{"nope"},
+			err:    `time: invalid duration "nope"`,
+		},
+		{
+			name:   "sub iso duration",
+			method: "ts_sub",
+			target: refTime,
+			args:   []interface{}{"P1DT1H"},
+			exp:    time.Date(2020, 8, 13, 10, 50, 26, 371000000, time.UTC),
+		},
+		{
+			name:   "sub negative duration",
+			method: "ts_sub",
+			target: refTime,
+			args:   []interface{}{"-1m"},
+			exp:    refTime.Add(time.Minute),
+		},
+		{
+			name:   "round to second",
+			method: "ts_round",
+			target: refTime,
+			args:   []interface{}{"1s"},
+			exp:    time.Date(2020, 8, 14, 11, 50, 26, 0, time.UTC),
+		},
+		{
+			name:   "round iso duration",
+			method: "ts_round",
+			target: refTime,
+			args:   []interface{}{"P1D"},
+			err:    "ISO 8601 durations are not supported, use a duration string such as 1h instead",
+		},
+		{
+			name:   "round zero duration",
+			method: "ts_round",
+			target: refTime,
+			args:   []interface{}{int64(0)},
+			err:    "duration must be greater than zero, got 0s",
+		},
+		{
+			name:   "truncate to minute",
+			method: "ts_truncate",
+			target: refTime,
+			args:   []interface{}{"1m"},
+			exp:    time.Date(2020, 8, 14, 11, 50, 0, 0, time.UTC),
+		},
+		{
+			name:   "convert timezone",
+			method: "ts_tz",
+			target: refTime,
+			args:   []interface{}{"Europe/Paris"},
+			exp:    refTime.In(paris),
+		},
+		{
+			name:   "convert bad timezone",
+			method: "ts_tz",
+			target: refTime,
+			args:   []interface{}{"Nope/Nope"},
+			err:    "failed to parse timezone location name: unknown time zone Nope/Nope",
+		},
+		{
+			name:   "diff timestamps",
+			method: "ts_diff",
+			target: refTime,
+			args:   []interface{}{"2020-08-14T11:50:27Z"},
+			exp:    int64(-629000000),
+		},
+		{
+			name:   "diff bad target",
+			method: "ts_diff",
+			target: true,
+			args:   []interface{}{refTime},
+			err:    "expected timestamp, number or string value, got bool",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			fn, err := InitMethodHelper(test.method, NewLiteralFunction("", test.target), test.args...)
+			if err == nil {
+				var res interface{}
+				if res, err = fn.Exec(FunctionContext{}); err == nil {
+					require.Empty(t, test.err)
+					resTime, isTime := res.(time.Time)
+					expTime, expIsTime := test.exp.(time.Time)
+					if isTime && expIsTime {
+						assert.True(t, expTime.Equal(resTime), "%v != %v", expTime, resTime)
+						assert.Equal(t, expTime.Location().String(), resTime.Location().String())
+					} else {
+						assert.Equal(t, test.exp, res)
+					}
+					return
+				}
+			}
+			require.NotEmpty(t, test.err, err.Error())
+			assert.Contains(t, err.Error(), test.err)
+		})
+	}
+}
+
+func TestTimestampValues(t *testing.T) {
+	ts := time.Date(2020, 8, 14, 11, 50, 26, 0, time.FixedZone("", 7200))
+
+	assert.Equal(t, ValueTimestamp, ITypeOf(ts))
+	assert.Equal(t, "2020-08-14T11:50:26+02:00", ISanitize(ts))
+	assert.Equal(t, "2020-08-14T11:50:26+02:00", IToString(ts))
+	assert.Equal(t, []byte("2020-08-14T11:50:26+02:00"), IToBytes(ts))
+
+	res, err := IGetTimestamp(ts)
+	require.NoError(t, err)
+	assert.Equal(t, ts, res)
+}
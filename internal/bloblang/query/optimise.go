@@ -0,0 +1,221 @@
+package query
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Jeffail/gabs/v2"
+)
+
+// getPath returns the value found at a path within a structured value, or nil
+// if the path does not exist. The rules for walking the path are the same as
+// gabs.Search, but intermediate containers are not allocated.
+func getPath(v interface{}, path []string) interface{} {
+	for i, p := range path {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			v = t[p]
+		case []interface{}:
+			if p == "*" {
+				return gabs.Wrap(t).Search(path[i:]...).Data()
+			}
+			index, err := strconv.Atoi(p)
+			if err != nil || index < 0 || index >= len(t) {
+				return nil
+			}
+			v = t[index]
+		default:
+			return nil
+		}
+	}
+	return v
+}
+
+//------------------------------------------------------------------------------
+
+// FieldCachePlan describes the field path prefixes that are shared by multiple
+// queries of a mapping and are therefore resolved only once per execution of
+// the mapping and cached.
+type FieldCachePlan struct {
+	slots int
+}
+
+type fieldCacheEntry struct {
+	key   *interface{}
+	value interface{}
+}
+
+type fieldCache struct {
+	plan    *FieldCachePlan
+	entries []fieldCacheEntry
+}
+
+// WithFieldCache returns a copy of the function context with an empty cache
+// for the field path prefixes of a plan. The cache must not be shared across
+// executions of a mapping.
+func (ctx FunctionContext) WithFieldCache(plan *FieldCachePlan) FunctionContext {
+	if plan == nil {
+		ctx.fieldCache = nil
+		return ctx
+	}
+	ctx.fieldCache = &fieldCache{
+		plan:    plan,
+		entries: make([]fieldCacheEntry, plan.slots),
+	}
+	return ctx
+}
+
+// cachedPrefix returns the value of a field prefix from the cache, resolving it
+// when it hasn't yet been cached for the current context value. Cached values
+// are keyed by the context value they were resolved from, and therefore field
+// functions executed with a different context value are never given a stale
+// result.
+func (c *fieldCache) cachedPrefix(slot int, value *interface{}, prefix []string) interface{} {
+	entry := &c.entries[slot]
+	if entry.key != value {
+		entry.key = value
+		entry.value = getPath(*value, prefix)
+	}
+	return entry.value
+}
+
+// OptimiseFieldPaths identifies field paths of the context value that share a
+// common prefix amongst a set of query functions, and configures them so that
+// the prefix is resolved once per execution and shared. A plan is returned that
+// must be provided to executions with WithFieldCache in order for the cache to
+// be used, or nil if no prefixes are shared.
+//
+// Only fields that are queried from the main context of the functions are
+// considered, as the context value within lambdas and match expressions varies
+// during execution.
+func OptimiseFieldPaths(fns ...Function) *FieldCachePlan {
+	var fields []*fieldFunction
+	ctx := TargetsContext{
+		fieldVisitor: func(f *fieldFunction) {
+			fields = append(fields, f)
+		},
+	}
+	for _, fn := range fns {
+		_, _ = fn.QueryTargets(ctx)
+	}
+
+	prefixCounts := map[string]int{}
+	for _, f := range fields {
+		for i := 2; i <= cacheablePrefixLen(f.path); i++ {
+			prefixCounts[strings.Join(f.path[:i], ".")]++
+		}
+	}
+
+	plan := &FieldCachePlan{}
+	slots := map[string]int{}
+	for _, f := range fields {
+		f.cachePlan = nil
+		for i := cacheablePrefixLen(f.path); i >= 2; i-- {
+			key := strings.Join(f.path[:i], ".")
+			if prefixCounts[key] < 2 {
+				continue
+			}
+			slot, exists := slots[key]
+			if !exists {
+				slot = plan.slots
+				slots[key] = slot
+				plan.slots++
+			}
+			f.cachePlan = plan
+			f.cacheSlot = slot
+			f.cachePrefix = i
+			break
+		}
+	}
+	if plan.slots == 0 {
+		return nil
+	}
+	return plan
+}
+
+// cacheablePrefixLen returns the length of the longest prefix of a path that
+// can be cached. Wildcard segments flatten the values beneath them and so a
+// prefix must end before the first wildcard.
+func cacheablePrefixLen(path []string) int {
+	for i, p := range path {
+		if p == "*" {
+			return i
+		}
+	}
+	return len(path)
+}
+
+//------------------------------------------------------------------------------
+
+// freshResult is implemented by functions that are able to determine whether
+// the values they return are newly allocated for each execution.
+type freshResult interface {
+	isFreshResult() bool
+}
+
+// IsFreshResult returns true if the values returned by a function are always
+// newly allocated by each execution and do not share structure with any other
+// value, meaning they can be modified by an assignment without being copied.
+func IsFreshResult(fn Function) bool {
+	if f, ok := fn.(freshResult); ok {
+		return f.isFreshResult()
+	}
+	return isScalarType(Analyse(NewAnalysisContext(nil, nil), fn).ValueType())
+}
+
+func isFreshValue(v interface{}) bool {
+	if fn, isFn := v.(Function); isFn {
+		return IsFreshResult(fn)
+	}
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		return false
+	}
+	return true
+}
+
+// isScalarType returns true if values of a type are never structured.
+func isScalarType(t ValueType) bool {
+	switch t {
+	case ValueString, ValueBytes, ValueNumber, ValueBool, ValueTimestamp, ValueNull:
+		return true
+	}
+	return false
+}
+
+func (m *mapLiteral) isFreshResult() bool {
+	for _, kv := range m.keyValues {
+		if !isFreshValue(kv[1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *arrayLiteral) isFreshResult() bool {
+	for _, v := range a.values {
+		if !isFreshValue(v) {
+			return false
+		}
+	}
+	return true
+}
+
+//------------------------------------------------------------------------------
+
+// foldMethod attempts to execute a method that targets a literal value with
+// static arguments in order to replace it with the literal result. Methods that
+// fail, or that depend on the context of an execution, are not folded and are
+// instead left to be executed normally.
+func foldMethod(fn Function) (lit *Literal, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			lit, ok = nil, false
+		}
+	}()
+	v, err := fn.Exec(FunctionContext{})
+	if err != nil {
+		return nil, false
+	}
+	return NewLiteralFunction(fn.Annotation(), v), true
+}
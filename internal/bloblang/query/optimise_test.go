@@ -0,0 +1,204 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPath(t *testing.T) {
+	doc := map[string]interface{}{
+		"a": map[string]interface{}{
+			"b": []interface{}{
+				map[string]interface{}{"c": "first"},
+				map[string]interface{}{"c": "second"},
+				"not an object",
+			},
+			"d": nil,
+			"e": "foo",
+		},
+	}
+
+	tests := map[string][]string{
+		"empty path":         {},
+		"object field":       {"a", "e"},
+		"nested object":      {"a"},
+		"null field":         {"a", "d"},
+		"missing field":      {"a", "nope"},
+		"field of a string":  {"a", "e", "f"},
+		"field of a null":    {"a", "d", "f"},
+		"array index":        {"a", "b", "1", "c"},
+		"array out of range": {"a", "b", "3", "c"},
+		"negative index":     {"a", "b", "-1", "c"},
+		"non numeric index":  {"a", "b", "c"},
+		"array wildcard":     {"a", "b", "*", "c"},
+		"wildcard of object": {"a", "*"},
+	}
+
+	for name, path := range tests {
+		path := path
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, gabs.Wrap(doc).Search(path...).Data(), getPath(doc, path))
+		})
+	}
+}
+
+func TestOptimiseFieldPaths(t *testing.T) {
+	abc := NewFieldFunction("a.b.c")
+	abd := NewFieldFunction("a.b.d")
+	ae := NewFieldFunction("a.e")
+	f := NewFieldFunction("f")
+	root := NewRootFieldFunction("a.b.c")
+
+	plan := OptimiseFieldPaths(abc, abd, ae, f, root)
+	require.NotNil(t, plan)
+	assert.Equal(t, 1, plan.slots)
+
+	for _, fn := range []Function{abc, abd} {
+		field := fn.(*fieldFunction)
+		assert.Equal(t, plan, field.cachePlan)
+		assert.Equal(t, 0, field.cacheSlot)
+		assert.Equal(t, 2, field.cachePrefix)
+	}
+	for _, fn := range []Function{ae, f, root} {
+		assert.Nil(t, fn.(*fieldFunction).cachePlan)
+	}
+
+	assert.Nil(t, OptimiseFieldPaths(NewFieldFunction("a.b"), NewFieldFunction("c.d")))
+}
+
+func TestOptimiseFieldPathsWildcard(t *testing.T) {
+	itemsA := NewFieldFunction("items.*.a")
+	itemsB := NewFieldFunction("items.*.b")
+	assert.Nil(t, OptimiseFieldPaths(itemsA, itemsB))
+
+	nestedA := NewFieldFunction("doc.items.*.a")
+	nestedB := NewFieldFunction("doc.items.*.b")
+	plan := OptimiseFieldPaths(nestedA, nestedB)
+	require.NotNil(t, plan)
+
+	for _, fn := range []Function{nestedA, nestedB} {
+		assert.Equal(t, 2, fn.(*fieldFunction).cachePrefix)
+	}
+
+	doc := map[string]interface{}{
+		"doc": map[string]interface{}{
+			"items": []interface{}{
+				map[string]interface{}{"a": 1, "b": 2},
+				map[string]interface{}{"a": 3, "b": 4},
+			},
+		},
+	}
+	ctx := FunctionContext{}.WithValue(doc).WithFieldCache(plan)
+
+	res, err := nestedA.Exec(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{1, 3}, res)
+
+	res, err = nestedB.Exec(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{2, 4}, res)
+}
+
+func TestFieldCacheExec(t *testing.T) {
+	abc := NewFieldFunction("a.b.c")
+	abd := NewFieldFunction("a.b.d")
+	plan := OptimiseFieldPaths(abc, abd)
+	require.NotNil(t, plan)
+
+	docOne := map[string]interface{}{
+		"a": map[string]interface{}{
+			"b": map[string]interface{}{"c": "c1", "d": "d1"},
+		},
+	}
+	docTwo := map[string]interface{}{
+		"a": map[string]interface{}{
+			"b": map[string]interface{}{"c": "c2"},
+		},
+	}
+
+	ctx := FunctionContext{}.WithValue(docOne).WithFieldCache(plan)
+
+	res, err := abc.Exec(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "c1", res)
+
+	res, err = abd.Exec(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "d1", res)
+
+	// A different context value must not be given cached results.
+	twoCtx := ctx.WithValue(docTwo)
+
+	res, err = abc.Exec(twoCtx)
+	require.NoError(t, err)
+	assert.Equal(t, "c2", res)
+
+	res, err = abd.Exec(twoCtx)
+	require.NoError(t, err)
+	assert.Nil(t, res)
+
+	// Neither should executions with the cache of a different plan.
+	otherCtx := FunctionContext{}.WithValue(docTwo).WithFieldCache(&FieldCachePlan{slots: 1})
+
+	res, err = abd.Exec(otherCtx)
+	require.NoError(t, err)
+	assert.Nil(t, res)
+}
+
+func TestIsFreshResult(t *testing.T) {
+	tests := map[string]struct {
+		fn    Function
+		fresh bool
+	}{
+		"field": {
+			fn:    NewFieldFunction("foo"),
+			fresh: false,
+		},
+		"string literal": {
+			fn:    NewLiteralFunction("", "foo"),
+			fresh: true,
+		},
+		"object literal": {
+			fn:    NewLiteralFunction("", map[string]interface{}{"foo": "bar"}),
+			fresh: false,
+		},
+		"array of fields": {
+			fn: &arrayLiteral{
+				values: []interface{}{"foo", NewFieldFunction("foo")},
+			},
+			fresh: false,
+		},
+		"array of scalars": {
+			fn: &arrayLiteral{
+				values: []interface{}{"foo", NewLiteralFunction("", "bar")},
+			},
+			fresh: true,
+		},
+		"object of static structures": {
+			fn: &mapLiteral{
+				keyValues: [][2]interface{}{
+					{"foo", []interface{}{"bar"}},
+				},
+			},
+			fresh: false,
+		},
+		"object of fresh structures": {
+			fn: &mapLiteral{
+				keyValues: [][2]interface{}{
+					{"foo", &arrayLiteral{values: []interface{}{"bar"}}},
+				},
+			},
+			fresh: true,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.fresh, IsFreshResult(test.fn))
+		})
+	}
+}
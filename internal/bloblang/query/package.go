@@ -74,6 +74,8 @@ type FunctionContext struct {
 	value      *interface{}
 	nextValue  *interface{}
 	namedValue *namedContextValue
+	fieldCache *fieldCache
+	tracer     Tracer
 
 	// Used to track how many maps we've entered.
 	stackCount int
@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // ParamDefinition describes a single parameter for a function or method.
@@ -79,6 +80,15 @@ func ParamObject(name, description string) ParamDefinition {
 	}
 }
 
+// ParamTimestamp creates a new timestamp typed parameter, which also accepts
+// numerical unix timestamps and strings in RFC3339 format.
+func ParamTimestamp(name, description string) ParamDefinition {
+	return ParamDefinition{
+		Name: name, Description: description,
+		ValueType: ValueTimestamp,
+	}
+}
+
 // ParamQuery creates a new query typed parameter. The field wrapScalars
 // determines whether non-query arguments are allowed, in which case they will
 // be converted into literal functions.
@@ -147,6 +157,8 @@ func (d ParamDefinition) parseArgValue(v interface{}) (interface{}, error) {
 		if _, isObj := v.(map[string]interface{}); isObj {
 			return v, nil
 		}
+	case ValueTimestamp:
+		return IGetTimestamp(v)
 	case ValueQuery:
 		if _, isDyn := v.(Function); isDyn {
 			return v, nil
@@ -162,7 +174,9 @@ func (d ParamDefinition) parseArgValue(v interface{}) (interface{}, error) {
 
 //------------------------------------------------------------------------------
 
-// Params defines the expected arguments of a function or method.
+// Params defines the expected arguments of a function or method. When variadic
+// any number of nameless arguments are considered valid following the defined
+// parameters.
 type Params struct {
 	Variadic    bool              `json:"variadic,omitempty"`
 	Definitions []ParamDefinition `json:"named,omitempty"`
@@ -228,15 +242,14 @@ func (p Params) PopulateNamed(args map[string]interface{}) (*ParsedParams, error
 }
 
 func (p Params) validate() error {
-	if p.Variadic && len(p.Definitions) > 0 {
-		return errors.New("cannot add named parameters to a variadic parameter definition")
-	}
-
 	seen := map[string]struct{}{}
 	for _, param := range p.Definitions {
 		if err := param.validate(); err != nil {
 			return err
 		}
+		if p.Variadic && (param.IsOptional || param.DefaultValue != nil) {
+			return fmt.Errorf("parameter %v of a variadic parameter definition cannot be optional", param.Name)
+		}
 		if _, exists := seen[param.Name]; exists {
 			return fmt.Errorf("duplicate parameter name: %v", param.Name)
 		}
@@ -252,19 +265,11 @@ type dynamicArgIndex struct {
 }
 
 func (p Params) gatherDynamicArgs(args []interface{}) (dynArgs []dynamicArgIndex) {
-	if p.Variadic {
-		for i, arg := range args {
-			if fn, isFn := arg.(Function); isFn {
-				dynArgs = append(dynArgs, dynamicArgIndex{index: i, fn: fn})
-			}
-		}
-		return
-	}
-	for i, param := range p.Definitions {
-		if param.ValueType == ValueQuery {
+	for i, arg := range args {
+		if i < len(p.Definitions) && p.Definitions[i].ValueType == ValueQuery {
 			continue
 		}
-		if fn, isFn := args[i].(Function); isFn {
+		if fn, isFn := arg.(Function); isFn {
 			dynArgs = append(dynArgs, dynamicArgIndex{index: i, fn: fn})
 		}
 	}
@@ -282,9 +287,21 @@ func expandLiteralArgs(args []interface{}) {
 // processNameless attempts to validate a list of unnamed arguments, and
 // populates elements with default values if they are omitted.
 func (p Params) processNameless(args []interface{}) ([]interface{}, error) {
-	if p.Variadic {
-		expandLiteralArgs(args)
-		return args, nil
+	if p.Variadic && len(args) >= len(p.Definitions) {
+		// Variadic arguments following the defined parameters are passed
+		// through as they are.
+		tail := args[len(p.Definitions):]
+		expandLiteralArgs(tail)
+		if len(p.Definitions) == 0 {
+			return args, nil
+		}
+		fixed := p
+		fixed.Variadic = false
+		head, err := fixed.processNameless(args[:len(p.Definitions):len(p.Definitions)])
+		if err != nil {
+			return nil, err
+		}
+		return append(head, tail...), nil
 	}
 
 	if len(args) > len(p.Definitions) {
@@ -340,7 +357,7 @@ func (p Params) processNameless(args []interface{}) ([]interface{}, error) {
 // processNamed attempts to validate a map of named arguments, and populates
 // elements with default values if they are omitted.
 func (p Params) processNamed(args map[string]interface{}) ([]interface{}, error) {
-	if p.Variadic {
+	if p.Variadic && len(p.Definitions) == 0 {
 		return nil, errors.New("named arguments are not supported")
 	}
 
@@ -434,6 +451,23 @@ func (p *ParsedParams) dynamic() []Function {
 	return fns
 }
 
+// isStatic returns true if none of the arguments are functions, and therefore
+// the arguments are the same for every execution.
+func (p *ParsedParams) isStatic() bool {
+	if p == nil {
+		return true
+	}
+	if len(p.dynArgs) > 0 {
+		return false
+	}
+	for _, v := range p.values {
+		if _, isFn := v.(Function); isFn {
+			return false
+		}
+	}
+	return true
+}
+
 // ResolveDynamic attempts to execute all dynamic arguments with a given context
 // and populate a new parsed parameters set with the values, ready to be used in
 // a function or method.
@@ -642,6 +676,19 @@ func (p *ParsedParams) FieldOptionalBool(n string) (*bool, error) {
 	return &b, nil
 }
 
+// FieldTimestamp returns a timestamp argument value with a given name.
+func (p *ParsedParams) FieldTimestamp(n string) (time.Time, error) {
+	v, err := p.Field(n)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, ok := v.(time.Time)
+	if !ok {
+		return time.Time{}, NewTypeError(v, ValueTimestamp)
+	}
+	return t, nil
+}
+
 // FieldQuery returns a query argument value with a given name.
 func (p *ParsedParams) FieldQuery(n string) (Function, error) {
 	v, err := p.Field(n)
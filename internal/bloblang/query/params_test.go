@@ -30,7 +30,13 @@ func TestParamsValidation(t *testing.T) {
 				Add(ParamString("first", "")).
 				Add(ParamInt64("second", "").Default(5)).
 				Add(ParamBool("third", "").Default(true)),
-			errContains: "cannot add named parameters to a variadic parameter definition",
+			errContains: "parameter second of a variadic parameter definition cannot be optional",
+		},
+		{
+			name: "variadic following fields",
+			params: VariadicParams().
+				Add(ParamString("first", "")).
+				Add(ParamInt64("second", "")),
 		},
 		{
 			name: "empty field name",
@@ -114,6 +120,24 @@ func TestParamsNameless(t *testing.T) {
 				"bar", nil, nil,
 			},
 		},
+		{
+			name: "variadic following fields",
+			params: VariadicParams().
+				Add(ParamString("first", "")).
+				Add(ParamInt64("second", "")),
+			input: []interface{}{"foo", 5, "bar", NewLiteralFunction("", "baz"), NewFieldFunction("nah")},
+			output: []interface{}{
+				"foo", int64(5), "bar", "baz", NewFieldFunction("nah"),
+			},
+		},
+		{
+			name: "variadic following fields missing field",
+			params: VariadicParams().
+				Add(ParamString("first", "")).
+				Add(ParamInt64("second", "")),
+			input:       []interface{}{"foo"},
+			errContains: "missing parameter: second",
+		},
 		{
 			name: "missing field",
 			params: NewParams().
@@ -61,6 +61,15 @@ type TargetsContext struct {
 	mainContext   []TargetPath
 	prevContext   *prevContextPath
 	namedContext  *namedContextPath
+	visitedMaps   *visitedMap
+
+	// Called with each field function that queries the main context.
+	fieldVisitor func(f *fieldFunction)
+}
+
+type visitedMap struct {
+	name string
+	next *visitedMap
 }
 
 type prevContextPath struct {
@@ -74,6 +83,22 @@ type namedContextPath struct {
 	next  *namedContextPath
 }
 
+// withVisitedMap returns a targets context that records a map as being
+// visited, which prevents recursive maps from being walked indefinitely.
+func (ctx TargetsContext) withVisitedMap(name string) TargetsContext {
+	ctx.visitedMaps = &visitedMap{name: name, next: ctx.visitedMaps}
+	return ctx
+}
+
+func (ctx TargetsContext) hasVisitedMap(name string) bool {
+	for current := ctx.visitedMaps; current != nil; current = current.next {
+		if current.name == name {
+			return true
+		}
+	}
+	return false
+}
+
 // Value returns the current value of the targets context, which is the path(s)
 // being executed upon by methods.
 func (ctx TargetsContext) Value() []TargetPath {
@@ -0,0 +1,85 @@
+package query
+
+// TraceKind describes the type of a step within a traced execution.
+type TraceKind int
+
+// TraceKind variants.
+const (
+	// TraceFunction is the execution of a function or method.
+	TraceFunction TraceKind = iota
+	// TraceMatchCase is the selection of a case of a match expression.
+	TraceMatchCase
+	// TraceAssignment is the execution of a mapping statement that assigns the
+	// result of a query.
+	TraceAssignment
+	// TraceIfCase is the selection of a case of an if statement.
+	TraceIfCase
+)
+
+// TraceEvent describes a step within a traced execution.
+type TraceEvent struct {
+	Kind TraceKind
+
+	// Function is the function, method or match expression that the step
+	// belongs to, which is nil for steps of mapping statements.
+	Function Function
+
+	// Input is the source of the step when it is known at execution time,
+	// beginning with the first rune of the step and extending to the end of
+	// the input it was parsed from.
+	Input []rune
+
+	// Case is the index of the case that was selected by a match expression
+	// or if statement, where an else block of an if statement is the index
+	// following the last if condition.
+	Case int
+
+	// Target describes where the result of an assignment was written.
+	Target string
+
+	// Value is the result of the step, if it succeeded.
+	Value interface{}
+
+	// Err is the error returned by the step, if it failed.
+	Err error
+}
+
+// Tracer receives the steps of a traced execution in the order that they
+// complete, which means the steps of nested queries are received before the
+// step of the query that contains them.
+type Tracer func(e TraceEvent)
+
+// WithTracer returns a copy of the function context where the steps of
+// executions are passed to a tracer.
+func (ctx FunctionContext) WithTracer(t Tracer) FunctionContext {
+	ctx.tracer = t
+	return ctx
+}
+
+// Tracing returns true if the function context has a tracer.
+func (ctx FunctionContext) Tracing() bool {
+	return ctx.tracer != nil
+}
+
+// Trace passes a step to the tracer of the function context, if there is one.
+func (ctx FunctionContext) Trace(e TraceEvent) {
+	if ctx.tracer != nil {
+		ctx.tracer(e)
+	}
+}
+
+func (s *specFunction) Exec(ctx FunctionContext) (interface{}, error) {
+	v, err := s.Function.Exec(ctx)
+	if ctx.tracer != nil {
+		ctx.tracer(TraceEvent{Kind: TraceFunction, Function: s, Value: v, Err: err})
+	}
+	return v, err
+}
+
+func (s *specMethod) Exec(ctx FunctionContext) (interface{}, error) {
+	v, err := s.Function.Exec(ctx)
+	if ctx.tracer != nil {
+		ctx.tracer(TraceEvent{Kind: TraceFunction, Function: s, Value: v, Err: err})
+	}
+	return v, err
+}
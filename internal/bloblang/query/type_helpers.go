@@ -30,17 +30,18 @@ type ValueType string
 
 // ValueType variants.
 var (
-	ValueString  ValueType = "string"
-	ValueBytes   ValueType = "bytes"
-	ValueNumber  ValueType = "number"
-	ValueBool    ValueType = "bool"
-	ValueArray   ValueType = "array"
-	ValueObject  ValueType = "object"
-	ValueNull    ValueType = "null"
-	ValueDelete  ValueType = "delete"
-	ValueNothing ValueType = "nothing"
-	ValueQuery   ValueType = "query expression"
-	ValueUnknown ValueType = "unknown"
+	ValueString    ValueType = "string"
+	ValueBytes     ValueType = "bytes"
+	ValueNumber    ValueType = "number"
+	ValueBool      ValueType = "bool"
+	ValueArray     ValueType = "array"
+	ValueObject    ValueType = "object"
+	ValueNull      ValueType = "null"
+	ValueTimestamp ValueType = "timestamp"
+	ValueDelete    ValueType = "delete"
+	ValueNothing   ValueType = "nothing"
+	ValueQuery     ValueType = "query expression"
+	ValueUnknown   ValueType = "unknown"
 
 	// Specialised and not generally known over ValueNumber.
 	ValueInt   ValueType = "integer"
@@ -63,6 +64,8 @@ func ITypeOf(i interface{}) ValueType {
 		return ValueArray
 	case map[string]interface{}:
 		return ValueObject
+	case time.Time:
+		return ValueTimestamp
 	case Delete:
 		return ValueDelete
 	case Nothing:
@@ -194,9 +197,12 @@ func IGetBytes(v interface{}) ([]byte, error) {
 }
 
 // IGetTimestamp takes a boxed value and attempts to coerce it into a timestamp,
-// either by interpretting a numerical value as a unix timestamp, or by parsing
-// a string value as RFC3339Nano.
+// either by returning a timestamp value as is, by interpretting a numerical
+// value as a unix timestamp, or by parsing a string value as RFC3339Nano.
 func IGetTimestamp(v interface{}) (time.Time, error) {
+	if t, isTime := v.(time.Time); isTime {
+		return t, nil
+	}
 	switch t := ISanitize(v).(type) {
 	case int64:
 		return time.Unix(t, 0), nil
@@ -221,7 +227,7 @@ func IGetTimestamp(v interface{}) (time.Time, error) {
 	case string:
 		return time.Parse(time.RFC3339Nano, t)
 	}
-	return time.Time{}, NewTypeError(v, ValueNumber, ValueString)
+	return time.Time{}, NewTypeError(v, ValueTimestamp, ValueNumber, ValueString)
 }
 
 // IIsNull returns whether a bloblang type is null, this includes Delete and
@@ -240,6 +246,9 @@ func IIsNull(i interface{}) bool {
 // ISanitize takes a boxed value of any type and attempts to convert it into one
 // of the following types: string, []byte, int64, uint64, float64, bool,
 // []interface{}, map[string]interface{}, Delete, Nothing.
+//
+// Timestamps are converted into RFC3339Nano strings, and therefore code that
+// supports timestamp values must check for them before sanitising.
 func ISanitize(i interface{}) interface{} {
 	switch t := i.(type) {
 	case string, []byte, int64, uint64, float64, bool, []interface{}, map[string]interface{}, Delete, Nothing:
@@ -288,6 +297,8 @@ func IToBytes(i interface{}) []byte {
 			return []byte("true")
 		}
 		return []byte("false")
+	case time.Time:
+		return []byte(t.Format(time.RFC3339Nano))
 	case nil:
 		return []byte(`null`)
 	}
@@ -312,6 +323,8 @@ func IToString(i interface{}) string {
 			return "true"
 		}
 		return "false"
+	case time.Time:
+		return t.Format(time.RFC3339Nano)
 	case nil:
 		return `null`
 	}
@@ -0,0 +1,317 @@
+package circuitbreaker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/old/util/retries"
+)
+
+// ErrOpen is returned by a breaker when requests are not permitted.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State describes the state of a circuit breaker.
+type State int
+
+// The possible states of a circuit breaker.
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+// String returns a human readable representation of the state.
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half_open"
+	}
+	return "unknown"
+}
+
+//------------------------------------------------------------------------------
+
+// Breaker tracks the outcome of requests to a target and, once the ratio of
+// failures within a window exceeds a threshold, opens in order to reject
+// further requests. After an open period a limited number of probe requests
+// are permitted (half-open), and if these succeed the breaker closes again.
+type Breaker struct {
+	failureRatio     float64
+	minRequests      int
+	window           time.Duration
+	halfOpenRequests int
+	boff             *backoff.ExponentialBackOff
+
+	mut            sync.Mutex
+	state          State
+	generation     uint64
+	windowStart    time.Time
+	successes      int
+	failures       int
+	openUntil      time.Time
+	probesInFlight int
+	probeSuccesses int
+	lastErr        string
+
+	now func() time.Time
+
+	mState    metrics.StatGauge
+	mOpened   metrics.StatCounter
+	mRejected metrics.StatCounter
+}
+
+// New creates a new circuit breaker from a config.
+func New(conf Config, stats metrics.Type) (*Breaker, error) {
+	if conf.FailureRatio <= 0 || conf.FailureRatio > 1 {
+		return nil, fmt.Errorf("failure ratio must be greater than 0 and less than or equal to 1, got %v", conf.FailureRatio)
+	}
+	if conf.MinRequests < 1 {
+		return nil, fmt.Errorf("min requests must be greater than 0, got %v", conf.MinRequests)
+	}
+	if conf.HalfOpenRequests < 1 {
+		return nil, fmt.Errorf("half open requests must be greater than 0, got %v", conf.HalfOpenRequests)
+	}
+
+	window, err := time.ParseDuration(conf.Window)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse window: %v", err)
+	}
+
+	rConf := retries.Config{Backoff: conf.Backoff}
+	boffCtor, err := rConf.GetCtor()
+	if err != nil {
+		return nil, err
+	}
+	boff := boffCtor().(*backoff.ExponentialBackOff)
+	boff.Reset()
+
+	b := &Breaker{
+		failureRatio:     conf.FailureRatio,
+		minRequests:      conf.MinRequests,
+		window:           window,
+		halfOpenRequests: conf.HalfOpenRequests,
+		boff:             boff,
+		now:              time.Now,
+		mState:           stats.GetGauge("circuit_breaker_state"),
+		mOpened:          stats.GetCounter("circuit_breaker_opened"),
+		mRejected:        stats.GetCounter("circuit_breaker_rejected"),
+	}
+	b.windowStart = b.now()
+	b.mState.Set(int64(StateClosed))
+	return b, nil
+}
+
+func (b *Breaker) setState(s State) {
+	b.state = s
+	b.mState.Set(int64(s))
+}
+
+func (b *Breaker) trip(now time.Time) {
+	b.setState(StateOpen)
+	b.generation++
+	openFor := b.boff.NextBackOff()
+	if openFor == backoff.Stop {
+		// Once the max elapsed time of the backoff is reached the open period
+		// stops growing.
+		openFor = b.boff.MaxInterval
+	}
+	b.openUntil = now.Add(openFor)
+	b.probesInFlight, b.probeSuccesses = 0, 0
+	b.mOpened.Incr(1)
+}
+
+func (b *Breaker) reset(now time.Time) {
+	b.setState(StateClosed)
+	b.generation++
+	b.boff.Reset()
+	b.windowStart = now
+	b.successes, b.failures = 0, 0
+	b.probesInFlight, b.probeSuccesses = 0, 0
+}
+
+// Ticket represents a request that was permitted by a breaker, and carries
+// the state the breaker was in at the time so that the outcome is attributed
+// correctly.
+type Ticket struct {
+	b          *Breaker
+	state      State
+	generation uint64
+	finished   bool
+}
+
+// Allow returns ErrOpen if a request should not be attempted. When a ticket is
+// returned the outcome of the request must be reported with Done, or the
+// ticket released with Cancel if the request was never attempted.
+func (b *Breaker) Allow() (*Ticket, error) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	now := b.now()
+	switch b.state {
+	case StateOpen:
+		if now.Before(b.openUntil) {
+			b.mRejected.Incr(1)
+			return nil, ErrOpen
+		}
+		b.setState(StateHalfOpen)
+		fallthrough
+	case StateHalfOpen:
+		if b.probesInFlight >= b.halfOpenRequests {
+			b.mRejected.Incr(1)
+			return nil, ErrOpen
+		}
+		b.probesInFlight++
+		return &Ticket{b: b, state: StateHalfOpen, generation: b.generation}, nil
+	}
+
+	if now.Sub(b.windowStart) >= b.window {
+		b.windowStart = now
+		b.successes, b.failures = 0, 0
+	}
+	return &Ticket{b: b, state: StateClosed, generation: b.generation}, nil
+}
+
+// RetryAfter returns the period after which the breaker might next permit a
+// request, which is zero when the breaker is closed.
+func (b *Breaker) RetryAfter() time.Duration {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if d := b.openUntil.Sub(b.now()); d > 0 {
+			return d
+		}
+	case StateHalfOpen:
+		if b.probesInFlight >= b.halfOpenRequests {
+			return b.boff.InitialInterval
+		}
+	}
+	return 0
+}
+
+// Done reports the outcome of a request that was permitted by Allow. Outcomes
+// of requests permitted before the breaker last changed state are ignored.
+func (t *Ticket) Done(err error) {
+	b := t.b
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	if t.finished {
+		return
+	}
+	t.finished = true
+
+	if err != nil {
+		b.lastErr = err.Error()
+	}
+	if t.generation != b.generation {
+		return
+	}
+
+	now := b.now()
+	switch t.state {
+	case StateHalfOpen:
+		b.probesInFlight--
+		if err != nil {
+			b.trip(now)
+			return
+		}
+		if b.probeSuccesses++; b.probeSuccesses >= b.halfOpenRequests {
+			b.reset(now)
+		}
+	case StateClosed:
+		if b.state != StateClosed {
+			return
+		}
+		if err != nil {
+			b.failures++
+		} else {
+			b.successes++
+		}
+		total := b.successes + b.failures
+		if total >= b.minRequests && float64(b.failures)/float64(total) >= b.failureRatio {
+			b.trip(now)
+		}
+	}
+}
+
+// Cancel releases a ticket for a request that was never attempted, without
+// reporting an outcome.
+func (t *Ticket) Cancel() {
+	b := t.b
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	if t.finished {
+		return
+	}
+	t.finished = true
+
+	if t.state == StateHalfOpen && t.generation == b.generation {
+		b.probesInFlight--
+	}
+}
+
+// State returns the current state of the breaker.
+func (b *Breaker) State() State {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	return b.state
+}
+
+//------------------------------------------------------------------------------
+
+type statusJSON struct {
+	State     string `json:"state"`
+	Successes int    `json:"successes"`
+	Failures  int    `json:"failures"`
+	OpenUntil string `json:"open_until,omitempty"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// HandleStatus is an HTTP handler that writes the current status of the
+// breaker as a JSON object.
+func (b *Breaker) HandleStatus(w http.ResponseWriter, r *http.Request) {
+	b.mut.Lock()
+	status := statusJSON{
+		State:     b.state.String(),
+		Successes: b.successes,
+		Failures:  b.failures,
+		LastError: b.lastErr,
+	}
+	if b.state != StateClosed {
+		status.OpenUntil = b.openUntil.Format(time.RFC3339Nano)
+	}
+	b.mut.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+// EndpointRegistrar is an interface implemented by components that are able
+// to register HTTP endpoints.
+type EndpointRegistrar interface {
+	RegisterEndpoint(path, desc string, h http.HandlerFunc)
+}
+
+// RegisterEndpoint registers an HTTP endpoint that exposes the status of the
+// breaker, identified by the path of the component that owns it.
+func (b *Breaker) RegisterEndpoint(mgr EndpointRegistrar, componentPath string) {
+	mgr.RegisterEndpoint(
+		"/circuit_breakers/"+componentPath,
+		"Returns the current state of the circuit breaker of a component.",
+		b.HandleStatus,
+	)
+}
@@ -0,0 +1,206 @@
+package circuitbreaker
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+)
+
+func testBreaker(t *testing.T, conf Config) (*Breaker, *time.Time) {
+	t.Helper()
+
+	b, err := New(conf, metrics.Noop())
+	require.NoError(t, err)
+
+	now := time.Unix(1000, 0)
+	b.now = func() time.Time { return now }
+	b.windowStart = now
+	b.boff.RandomizationFactor = 0
+	b.boff.Multiplier = 2
+	b.boff.Reset()
+	return b, &now
+}
+
+func allow(t *testing.T, b *Breaker) *Ticket {
+	t.Helper()
+
+	ticket, err := b.Allow()
+	require.NoError(t, err)
+	return ticket
+}
+
+func assertOpen(t *testing.T, b *Breaker) {
+	t.Helper()
+
+	_, err := b.Allow()
+	assert.Equal(t, ErrOpen, err)
+}
+
+func TestBreakerConfigErrors(t *testing.T) {
+	for _, mod := range []func(c *Config){
+		func(c *Config) { c.FailureRatio = 0 },
+		func(c *Config) { c.FailureRatio = 1.5 },
+		func(c *Config) { c.MinRequests = 0 },
+		func(c *Config) { c.HalfOpenRequests = 0 },
+		func(c *Config) { c.Window = "nope" },
+		func(c *Config) { c.Backoff.InitialInterval = "nope" },
+		func(c *Config) { c.Backoff.MaxInterval = "nope" },
+		func(c *Config) { c.Backoff.MaxElapsedTime = "nope" },
+	} {
+		conf := NewConfig()
+		mod(&conf)
+		_, err := New(conf, metrics.Noop())
+		assert.Error(t, err)
+	}
+}
+
+func TestBreakerLifecycle(t *testing.T) {
+	conf := NewConfig()
+	conf.MinRequests = 4
+	conf.FailureRatio = 0.5
+	conf.HalfOpenRequests = 2
+	conf.Backoff.InitialInterval = "1s"
+	conf.Backoff.MaxInterval = "10s"
+
+	b, now := testBreaker(t, conf)
+	errBad := errors.New("bad")
+
+	// Not enough requests to trip yet.
+	for i := 0; i < 3; i++ {
+		allow(t, b).Done(errBad)
+	}
+	assert.Equal(t, StateClosed, b.State())
+
+	allow(t, b).Done(nil)
+	assert.Equal(t, StateOpen, b.State())
+	assertOpen(t, b)
+
+	// After the open period probes are permitted, limited to
+	// half_open_requests.
+	*now = now.Add(time.Second)
+	probeA := allow(t, b)
+	assert.Equal(t, StateHalfOpen, b.State())
+	probeB := allow(t, b)
+	assertOpen(t, b)
+
+	// A failed probe reopens for a longer period.
+	probeA.Done(nil)
+	probeB.Done(errBad)
+	assert.Equal(t, StateOpen, b.State())
+
+	*now = now.Add(time.Second)
+	assertOpen(t, b)
+
+	*now = now.Add(time.Second)
+	probeA, probeB = allow(t, b), allow(t, b)
+	probeA.Done(nil)
+	assert.Equal(t, StateHalfOpen, b.State())
+	probeB.Done(nil)
+	assert.Equal(t, StateClosed, b.State())
+
+	// Closed again with fresh counts.
+	for i := 0; i < 3; i++ {
+		allow(t, b).Done(errBad)
+	}
+	assert.Equal(t, StateClosed, b.State())
+}
+
+func TestBreakerStaleOutcomes(t *testing.T) {
+	conf := NewConfig()
+	conf.MinRequests = 1
+	conf.FailureRatio = 1
+	conf.HalfOpenRequests = 1
+	conf.Backoff.InitialInterval = "1s"
+
+	b, now := testBreaker(t, conf)
+
+	// A request admitted whilst closed that completes after the breaker has
+	// tripped does not affect the probes of the half-open state.
+	slow := allow(t, b)
+	allow(t, b).Done(errors.New("bad"))
+	assert.Equal(t, StateOpen, b.State())
+
+	*now = now.Add(time.Second)
+	probe := allow(t, b)
+	assert.Equal(t, StateHalfOpen, b.State())
+
+	slow.Done(nil)
+	slow.Done(nil)
+	assert.Equal(t, StateHalfOpen, b.State())
+	assertOpen(t, b)
+
+	// A probe that was never attempted frees its slot.
+	probe.Cancel()
+	probe.Done(errors.New("bad"))
+	assert.Equal(t, StateHalfOpen, b.State())
+
+	allow(t, b).Done(nil)
+	assert.Equal(t, StateClosed, b.State())
+}
+
+func TestBreakerRetryAfter(t *testing.T) {
+	conf := NewConfig()
+	conf.MinRequests = 1
+	conf.Backoff.InitialInterval = "2s"
+
+	b, now := testBreaker(t, conf)
+	assert.Equal(t, time.Duration(0), b.RetryAfter())
+
+	allow(t, b).Done(errors.New("bad"))
+	assert.Equal(t, time.Second*2, b.RetryAfter())
+
+	*now = now.Add(time.Second)
+	assert.Equal(t, time.Second, b.RetryAfter())
+
+	*now = now.Add(time.Second)
+	probe := allow(t, b)
+	assert.Equal(t, time.Second*2, b.RetryAfter())
+
+	probe.Done(nil)
+	assert.Equal(t, time.Duration(0), b.RetryAfter())
+}
+
+func TestBreakerWindowReset(t *testing.T) {
+	conf := NewConfig()
+	conf.MinRequests = 2
+	conf.FailureRatio = 1
+	conf.Window = "10s"
+
+	b, now := testBreaker(t, conf)
+
+	allow(t, b).Done(errors.New("bad"))
+
+	*now = now.Add(time.Second * 11)
+
+	allow(t, b).Done(errors.New("bad"))
+	assert.Equal(t, StateClosed, b.State())
+
+	allow(t, b).Done(errors.New("bad"))
+	assert.Equal(t, StateOpen, b.State())
+}
+
+func TestBreakerHandleStatus(t *testing.T) {
+	conf := NewConfig()
+	conf.MinRequests = 1
+
+	b, _ := testBreaker(t, conf)
+
+	allow(t, b).Done(errors.New("bad"))
+
+	w := httptest.NewRecorder()
+	b.HandleStatus(w, httptest.NewRequest("GET", "/circuit_breakers/root.output", nil))
+
+	var status map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.Equal(t, "open", status["state"])
+	assert.Equal(t, "bad", status["last_error"])
+	assert.Equal(t, float64(1), status["failures"])
+	assert.NotEmpty(t, status["open_until"])
+}
@@ -0,0 +1,44 @@
+package circuitbreaker
+
+import (
+	"github.com/benthosdev/benthos/v4/internal/old/util/retries"
+)
+
+// Config contains configuration params for a circuit breaker.
+type Config struct {
+	FailureRatio     float64         `json:"failure_ratio" yaml:"failure_ratio"`
+	MinRequests      int             `json:"min_requests" yaml:"min_requests"`
+	Window           string          `json:"window" yaml:"window"`
+	HalfOpenRequests int             `json:"half_open_requests" yaml:"half_open_requests"`
+	Backoff          retries.Backoff `json:"backoff" yaml:"backoff"`
+}
+
+// NewConfig creates a new Config with default values.
+func NewConfig() Config {
+	return Config{
+		FailureRatio:     0.5,
+		MinRequests:      10,
+		Window:           "30s",
+		HalfOpenRequests: 1,
+		Backoff: retries.Backoff{
+			InitialInterval: "5s",
+			MaxInterval:     "5m",
+			MaxElapsedTime:  "0s",
+		},
+	}
+}
+
+// ToggledConfig contains configuration params for a circuit breaker that is
+// disabled by default.
+type ToggledConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	Config  `json:",inline" yaml:",inline"`
+}
+
+// NewToggledConfig creates a new ToggledConfig with default values.
+func NewToggledConfig() ToggledConfig {
+	return ToggledConfig{
+		Enabled: false,
+		Config:  NewConfig(),
+	}
+}
@@ -0,0 +1,29 @@
+package circuitbreaker
+
+import "github.com/benthosdev/benthos/v4/internal/docs"
+
+// FieldSpecs returns documentation specs for circuit breaker fields.
+func FieldSpecs() docs.FieldSpecs {
+	return docs.FieldSpecs{
+		docs.FieldFloat("failure_ratio", "The ratio of failed requests within a window, between 0 and 1, at which the breaker opens.").HasDefault(0.5),
+		docs.FieldInt("min_requests", "The minimum number of requests within a window before the failure ratio is considered.").HasDefault(10),
+		docs.FieldString("window", "The period of time over which requests are counted when the breaker is closed, at the end of which the counts are reset.").HasDefault("30s"),
+		docs.FieldInt("half_open_requests", "The number of probe requests allowed once the breaker becomes half-open, all of which must succeed in order for the breaker to close. A single failed probe opens the breaker again.").Advanced().HasDefault(1),
+		docs.FieldAdvanced("backoff", "Control the period that the breaker remains open for, which grows exponentially each time a half-open probe fails.").WithChildren(
+			docs.FieldString("initial_interval", "The initial period to remain open for.").HasDefault("5s"),
+			docs.FieldString("max_interval", "The maximum period to remain open for.").HasDefault("5m"),
+			docs.FieldString("max_elapsed_time", "The maximum period over which the open period continues to grow, after which it remains at `max_interval`. If zero then no limit is used.").HasDefault("0s"),
+		),
+	}
+}
+
+// ToggledFieldSpec returns a documentation spec for a circuit breaker field
+// that is disabled by default.
+func ToggledFieldSpec() docs.FieldSpec {
+	return docs.FieldAdvanced(
+		"circuit_breaker",
+		"Configure a circuit breaker that stops requests from being attempted whilst the target is failing. When open, requests fail immediately without reaching the target.",
+	).WithChildren(append(docs.FieldSpecs{
+		docs.FieldBool("enabled", "Whether the circuit breaker is enabled.").HasDefault(false),
+	}, FieldSpecs()...)...)
+}
@@ -0,0 +1,4 @@
+// Package circuitbreaker implements a circuit breaker that can be placed in
+// front of a downstream target in order to stop repeatedly calling it whilst
+// it is failing.
+package circuitbreaker
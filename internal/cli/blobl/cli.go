@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 
 	"github.com/Jeffail/gabs/v2"
@@ -60,6 +61,11 @@ Find out more about Bloblang at: https://benthos.dev/docs/guides/bloblang/about`
 				Usage: "Set the buffer size for document lines.",
 				Value: bufio.MaxScanTokenSize,
 			},
+			&cli.BoolFlag{
+				Name:    "debug",
+				Aliases: []string{"d"},
+				Usage:   "print a trace of each step of the mapping to stderr, including the results of methods, variables and match cases.",
+			},
 		},
 		Action: run,
 		Subcommands: []*cli.Command{
@@ -98,6 +104,12 @@ Find out more about Bloblang at: https://benthos.dev/docs/guides/bloblang/about`
 						Aliases: []string{"i"},
 						Usage:   "an optional path to an input file to load as the initial input to the mapping within the app.",
 					},
+					&cli.BoolFlag{
+						Name:    "debug",
+						Value:   false,
+						Aliases: []string{"d"},
+						Usage:   "show a trace of each step of the mapping within the app, including the results of methods, variables and match cases.",
+					},
 					&cli.BoolFlag{
 						Name:    "write",
 						Value:   false,
@@ -122,7 +134,7 @@ func newExecCache() *execCache {
 	}
 }
 
-func (e *execCache) executeMapping(exec *mapping.Executor, rawInput, prettyOutput bool, input []byte) (string, error) {
+func (e *execCache) executeMapping(exec *mapping.Executor, tracer query.Tracer, rawInput, prettyOutput bool, input []byte) (string, error) {
 	e.msg.Get(0).Set(input)
 
 	var valuePtr *interface{}
@@ -159,7 +171,7 @@ func (e *execCache) executeMapping(exec *mapping.Executor, rawInput, prettyOutpu
 		MsgBatch: e.msg,
 		NewMeta:  e.msg.Get(0),
 		NewValue: &result,
-	}.WithValueFunc(lazyValue), mapping.AssignmentContext{
+	}.WithValueFunc(lazyValue).WithTracer(tracer), mapping.AssignmentContext{
 		Vars:  e.vars,
 		Meta:  e.msg.Get(0),
 		Value: &result,
@@ -204,6 +216,38 @@ func (e *execCache) executeMapping(exec *mapping.Executor, rawInput, prettyOutpu
 	return resultStr, nil
 }
 
+// formatTraceValue returns a short representation of the result of a traced
+// step of a mapping.
+func formatTraceValue(v interface{}) string {
+	switch t := v.(type) {
+	case query.Delete:
+		return "deleted()"
+	case query.Nothing:
+		return "nothing"
+	case []byte:
+		v = string(t)
+	}
+	return gabs.Wrap(v).String()
+}
+
+// formatTraceSteps returns a human readable trace of the steps of a mapping
+// execution, where each step is written on its own line.
+func formatTraceSteps(steps []mapping.TraceStep) string {
+	var buf strings.Builder
+	for _, step := range steps {
+		pos := "imported"
+		if step.Line > 0 {
+			pos = fmt.Sprintf("%v:%v", step.Line, step.Column)
+		}
+		result := formatTraceValue(step.Value)
+		if step.Err != nil {
+			result = red(fmt.Sprintf("error: %v", step.Err))
+		}
+		fmt.Fprintf(&buf, "%v %v (%v) -> %v\n", pos, step.Source, step.What, result)
+	}
+	return buf.String()
+}
+
 func run(c *cli.Context) error {
 	t := c.Int("threads")
 	if t < 1 {
@@ -211,6 +255,7 @@ func run(c *cli.Context) error {
 	}
 	raw := c.Bool("raw")
 	pretty := c.Bool("pretty")
+	debug := c.Bool("debug")
 	file := c.String("file")
 	m := c.Args().First()
 
@@ -230,6 +275,9 @@ func run(c *cli.Context) error {
 	}
 
 	bEnv := bloblang.NewEnvironment().WithImporterRelativeToFile(file)
+	if debug {
+		bEnv = bEnv.WithTracing()
+	}
 	exec, err := bEnv.NewMapping(m)
 	if err != nil {
 		if perr, ok := err.(*parser.Error); ok {
@@ -275,7 +323,18 @@ func run(c *cli.Context) error {
 					return
 				}
 
-				resultStr, err := execCache.executeMapping(exec, raw, pretty, input)
+				var tracer query.Tracer
+				var steps []mapping.TraceStep
+				if debug {
+					tracer = exec.NewTracer(func(step mapping.TraceStep) {
+						steps = append(steps, step)
+					})
+				}
+
+				resultStr, err := execCache.executeMapping(exec, tracer, raw, pretty, input)
+				if debug {
+					fmt.Fprint(os.Stderr, formatTraceSteps(steps))
+				}
 				if err != nil {
 					fmt.Fprintln(os.Stderr, red(fmt.Sprintf("failed to execute map: %v", err)))
 					continue
@@ -20,7 +20,9 @@ import (
 	"github.com/urfave/cli/v2"
 
 	"github.com/benthosdev/benthos/v4/internal/bloblang"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
 	"github.com/benthosdev/benthos/v4/internal/bloblang/parser"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
 
 	_ "embed"
 )
@@ -138,6 +140,31 @@ func (f *fileSync) mapping() string {
 	return f.mappingString
 }
 
+// traceStep is a step of a traced mapping execution as presented by the app.
+type traceStep struct {
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Source string `json:"source"`
+	What   string `json:"what"`
+	Value  string `json:"value"`
+	Error  string `json:"error"`
+}
+
+func newTraceStep(step mapping.TraceStep) traceStep {
+	t := traceStep{
+		Line:   step.Line,
+		Column: step.Column,
+		Source: step.Source,
+		What:   step.What,
+	}
+	if step.Err != nil {
+		t.Error = step.Err.Error()
+	} else {
+		t.Value = formatTraceValue(step.Value)
+	}
+	return t
+}
+
 func runServer(c *cli.Context) error {
 	fSync := newFileSync(c.String("input-file"), c.String("mapping-file"), c.Bool("write"))
 	defer fSync.write()
@@ -145,6 +172,12 @@ func runServer(c *cli.Context) error {
 	mux := http.NewServeMux()
 	execCache := newExecCache()
 
+	debug := c.Bool("debug")
+	bEnv := bloblang.GlobalEnvironment()
+	if debug {
+		bEnv = bEnv.WithTracing()
+	}
+
 	mux.HandleFunc("/execute", func(w http.ResponseWriter, r *http.Request) {
 		req := struct {
 			Mapping string `json:"mapping"`
@@ -159,9 +192,10 @@ func runServer(c *cli.Context) error {
 		fSync.update(req.Input, req.Mapping)
 
 		res := struct {
-			ParseError   string `json:"parse_error"`
-			MappingError string `json:"mapping_error"`
-			Result       string `json:"result"`
+			ParseError   string      `json:"parse_error"`
+			MappingError string      `json:"mapping_error"`
+			Result       string      `json:"result"`
+			Trace        []traceStep `json:"trace,omitempty"`
 		}{}
 		defer func() {
 			resBytes, err := json.Marshal(res)
@@ -172,7 +206,7 @@ func runServer(c *cli.Context) error {
 			w.Write(resBytes)
 		}()
 
-		exec, err := bloblang.GlobalEnvironment().NewMapping(req.Mapping)
+		exec, err := bEnv.NewMapping(req.Mapping)
 		if err != nil {
 			if perr, ok := err.(*parser.Error); ok {
 				res.ParseError = fmt.Sprintf("failed to parse mapping: %v\n", perr.ErrorAtPositionStructured("", []rune(req.Mapping)))
@@ -182,7 +216,15 @@ func runServer(c *cli.Context) error {
 			return
 		}
 
-		output, err := execCache.executeMapping(exec, false, true, []byte(req.Input))
+		var tracer query.Tracer
+		if debug {
+			res.Trace = []traceStep{}
+			tracer = exec.NewTracer(func(step mapping.TraceStep) {
+				res.Trace = append(res.Trace, newTraceStep(step))
+			})
+		}
+
+		output, err := execCache.executeMapping(exec, tracer, false, true, []byte(req.Input))
 		if err != nil {
 			res.MappingError = err.Error()
 		} else {
@@ -196,9 +238,11 @@ func runServer(c *cli.Context) error {
 		err := indexTemplate.Execute(w, struct {
 			InitialInput   string
 			InitialMapping string
+			Debug          bool
 		}{
 			fSync.input(),
 			fSync.mapping(),
+			debug,
 		})
 		if err != nil {
 			http.Error(w, "Template error", http.StatusBadGateway)
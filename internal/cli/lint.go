@@ -22,15 +22,49 @@ var red = color.New(color.FgRed).SprintFunc()
 var yellow = color.New(color.FgYellow).SprintFunc()
 
 type pathLint struct {
-	source string
-	line   int
-	lint   string
-	err    string
+	source  string
+	line    int
+	lint    string
+	warning string
+	err     string
+}
+
+func lintConfigBytes(source string, line int, configBytes []byte, rejectDeprecated bool) (pathLints []pathLint) {
+	lintCtx := docs.NewLintContext()
+	lintCtx.RejectDeprecated = rejectDeprecated
+
+	lints, err := config.LintBytes(lintCtx, configBytes)
+	if err != nil {
+		return []pathLint{{source: source, line: line, err: err.Error()}}
+	}
+	for _, l := range lints {
+		pathLints = append(pathLints, pathLint{
+			source: source,
+			line:   line,
+			lint:   l,
+		})
+	}
+
+	warnings, err := config.LintWarningBytes(lintCtx, configBytes)
+	if err != nil {
+		return append(pathLints, pathLint{source: source, line: line, err: err.Error()})
+	}
+	for _, w := range warnings {
+		pathLints = append(pathLints, pathLint{
+			source:  source,
+			line:    line,
+			warning: w,
+		})
+	}
+	return
 }
 
 func lintFile(path string, rejectDeprecated bool) (pathLints []pathLint) {
-	conf := config.New()
-	lints, err := config.ReadFileLinted(path, rejectDeprecated, &conf)
+	configBytes, lints, err := config.ReadFileEnvSwap(path)
+	if err == nil {
+		conf := config.New()
+		err = yaml.Unmarshal(configBytes, &conf)
+	}
 	if err != nil {
 		pathLints = append(pathLints, pathLint{
 			source: path,
@@ -44,7 +78,7 @@ func lintFile(path string, rejectDeprecated bool) (pathLints []pathLint) {
 			lint:   l,
 		})
 	}
-	return
+	return append(pathLints, lintConfigBytes(path, 0, configBytes, rejectDeprecated)...)
 }
 
 func lintMDSnippets(path string, rejectDeprecated bool) (pathLints []pathLint) {
@@ -91,23 +125,7 @@ func lintMDSnippets(path string, rejectDeprecated bool) (pathLints []pathLint) {
 				err:    err.Error(),
 			})
 		} else {
-			lintCtx := docs.NewLintContext()
-			lintCtx.RejectDeprecated = rejectDeprecated
-			lints, err := config.LintBytes(lintCtx, configBytes)
-			if err != nil {
-				pathLints = append(pathLints, pathLint{
-					source: path,
-					line:   snippetLine,
-					err:    err.Error(),
-				})
-			}
-			for _, l := range lints {
-				pathLints = append(pathLints, pathLint{
-					source: path,
-					line:   snippetLine,
-					lint:   l,
-				})
-			}
+			pathLints = append(pathLints, lintConfigBytes(path, snippetLine, configBytes, rejectDeprecated)...)
 		}
 
 		if nextSnippet = bytes.Index(rawBytes[endOfSnippet:], []byte("```yaml")); nextSnippet != -1 {
@@ -122,7 +140,9 @@ func lintCliCommand() *cli.Command {
 		Name:  "lint",
 		Usage: "Parse Benthos configs and report any linting errors",
 		Description: `
-Exits with a status code 1 if any linting errors are detected:
+Exits with a status code 1 if any linting errors are detected, warnings of
+potential problems such as Bloblang mappings that are likely to fail are
+printed but do not affect the status code:
 
   benthos -c target.yaml lint
   benthos lint ./configs/*.yaml
@@ -183,10 +203,16 @@ files with the .yaml or .yml extension.`[1:],
 			if len(pathLints) == 0 {
 				os.Exit(0)
 			}
+			failed := false
 			for _, lint := range pathLints {
 				message := yellow(lint.lint)
 				if len(lint.err) > 0 {
 					message = red(lint.err)
+				} else if len(lint.warning) > 0 {
+					message = "warning: " + lint.warning
+				}
+				if len(lint.warning) == 0 {
+					failed = true
 				}
 				if lint.line > 0 {
 					fmt.Fprintf(os.Stderr, "%v: from snippet at line %v: %v\n", lint.source, lint.line, message)
@@ -194,6 +220,9 @@ files with the .yaml or .yml extension.`[1:],
 					fmt.Fprintf(os.Stderr, "%v: %v\n", lint.source, message)
 				}
 			}
+			if !failed {
+				os.Exit(0)
+			}
 			os.Exit(1)
 			return nil
 		},
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"runtime/debug"
+	"time"
 
 	"github.com/urfave/cli/v2"
 	"gopkg.in/yaml.v3"
@@ -16,7 +17,7 @@ import (
 	"github.com/benthosdev/benthos/v4/internal/cli/test"
 	"github.com/benthosdev/benthos/v4/internal/config"
 	"github.com/benthosdev/benthos/v4/internal/docs"
-	"github.com/benthosdev/benthos/v4/internal/filepath"
+	strmmgr "github.com/benthosdev/benthos/v4/internal/stream/manager"
 	"github.com/benthosdev/benthos/v4/internal/template"
 )
 
@@ -168,6 +169,11 @@ func Run() {
 			Value:   false,
 			Usage:   "EXPERIMENTAL: watch config files for changes and automatically apply them",
 		},
+		&cli.DurationFlag{
+			Name:  "remote-poll-interval",
+			Value: time.Minute,
+			Usage: "the interval at which config files given as URLs are polled for changes when --watcher is set",
+		},
 	}
 	if len(customFlags) > 0 {
 		flags = append(flags, customFlags...)
@@ -199,7 +205,7 @@ Either run Benthos as a stream processor or choose a command:
 				}
 			}
 
-			templatesPaths, err := filepath.Globs(c.StringSlice("templates"))
+			templatesPaths, err := config.Globs(c.StringSlice("templates"))
 			if err != nil {
 				fmt.Printf("Failed to resolve template glob pattern: %v\n", err)
 				os.Exit(1)
@@ -236,7 +242,8 @@ Either run Benthos as a stream processor or choose a command:
 				c.Bool("watcher"),
 				false,
 				false,
-				nil,
+				streamsModeOpts{},
+				c.Duration("remote-poll-interval"),
 			))
 			return nil
 		},
@@ -247,13 +254,21 @@ Either run Benthos as a stream processor or choose a command:
 				Description: `
 This simple command is useful for sanity checking a config if it isn't
 behaving as expected, as it shows you a normalised version after environment
-variables have been resolved:
+variables have been resolved. Fields containing secrets, and credentials
+embedded within URLs, are redacted unless the flag --show-secrets is set:
 
   benthos -c ./config.yaml echo | less`[1:],
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "show-secrets",
+						Value: false,
+						Usage: "Print the values of fields containing secrets rather than redacting them.",
+					},
+				},
 				Action: func(c *cli.Context) error {
 					confReader := readConfig(c.String("config"), false, c.StringSlice("resources"), nil, c.StringSlice("set"))
 					conf := config.New()
-					if _, err := confReader.Read(&conf); err != nil {
+					if _, _, err := confReader.Read(&conf); err != nil {
 						fmt.Fprintf(os.Stderr, "Configuration file read error: %v\n", err)
 						os.Exit(1)
 					}
@@ -262,6 +277,7 @@ variables have been resolved:
 					if err == nil {
 						err = config.Spec().SanitiseYAML(&node, docs.SanitiseConfig{
 							RemoveTypeField: true,
+							RedactSecrets:   !c.Bool("show-secrets"),
 						})
 					}
 					if err == nil {
@@ -303,6 +319,31 @@ https://benthos.dev/docs/guides/streams_mode/about`[1:],
 						Value: false,
 						Usage: "Disable the HTTP API for streams mode",
 					},
+					&cli.StringFlag{
+						Name:  "persist-dir",
+						Value: "",
+						Usage: "A directory to persist streams and resources created via the HTTP API, which are reloaded on start up",
+					},
+					&cli.StringFlag{
+						Name:  "persist-cache",
+						Value: "",
+						Usage: "The name of a cache resource to persist streams and resources created via the HTTP API, which are reloaded on start up",
+					},
+					&cli.IntFlag{
+						Name:  "quota-max-threads",
+						Value: 0,
+						Usage: "The default maximum number of processing threads of each stream, zero means unlimited",
+					},
+					&cli.IntFlag{
+						Name:  "quota-max-in-flight",
+						Value: 0,
+						Usage: "The default maximum number of unacknowledged messages of each stream, zero means unlimited",
+					},
+					&cli.Float64Flag{
+						Name:  "quota-max-rate",
+						Value: 0,
+						Usage: "The default maximum number of messages per second consumed by each stream, zero means unlimited",
+					},
 				},
 				Action: func(c *cli.Context) error {
 					os.Exit(cmdService(
@@ -314,7 +355,17 @@ https://benthos.dev/docs/guides/streams_mode/about`[1:],
 						c.Bool("watcher"),
 						!c.Bool("no-api"),
 						true,
-						c.Args().Slice(),
+						streamsModeOpts{
+							paths:        c.Args().Slice(),
+							persistDir:   c.String("persist-dir"),
+							persistCache: c.String("persist-cache"),
+							quotas: strmmgr.Quotas{
+								MaxThreads:  c.Int("quota-max-threads"),
+								MaxInFlight: c.Int("quota-max-in-flight"),
+								MaxRate:     c.Float64("quota-max-rate"),
+							},
+						},
+						c.Duration("remote-poll-interval"),
 					))
 					return nil
 				},
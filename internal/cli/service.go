@@ -2,6 +2,7 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 	"runtime/pprof"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -21,8 +23,10 @@ import (
 	"github.com/benthosdev/benthos/v4/internal/component/tracer"
 	"github.com/benthosdev/benthos/v4/internal/config"
 	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/health"
 	"github.com/benthosdev/benthos/v4/internal/log"
 	"github.com/benthosdev/benthos/v4/internal/manager"
+	"github.com/benthosdev/benthos/v4/internal/secrets"
 	"github.com/benthosdev/benthos/v4/internal/stream"
 	strmmgr "github.com/benthosdev/benthos/v4/internal/stream/manager"
 )
@@ -37,7 +41,7 @@ type stoppable interface {
 
 //------------------------------------------------------------------------------
 
-func readConfig(path string, streamsMode bool, resourcesPaths, streamsPaths, overrides []string) *config.Reader {
+func readConfig(path string, streamsMode bool, resourcesPaths, streamsPaths, overrides []string, extraOpts ...config.OptFunc) *config.Reader {
 	if path == "" {
 		// Iterate default config paths
 		for _, dpath := range []string{
@@ -59,26 +63,51 @@ func readConfig(path string, streamsMode bool, resourcesPaths, streamsPaths, ove
 	if streamsMode {
 		opts = append(opts, config.OptSetStreamPaths(streamsPaths...))
 	}
+	opts = append(opts, extraOpts...)
 	return config.NewReader(path, resourcesPaths, opts...)
 }
 
 //------------------------------------------------------------------------------
 
+// streamsModeOpts contains options specific to running in streams mode.
+type streamsModeOpts struct {
+	paths        []string
+	persistDir   string
+	persistCache string
+	quotas       strmmgr.Quotas
+}
+
 func initStreamsMode(
 	strict, watching, enableAPI bool,
 	confReader *config.Reader,
+	opts streamsModeOpts,
 	strmAPITimeout time.Duration,
 	manager *manager.Type,
 	logger log.Modular,
 	stats *metrics.Namespaced,
 ) stoppable {
-	streamMgr := strmmgr.New(manager,
+	strmOpts := []func(*strmmgr.Type){
 		strmmgr.OptSetAPITimeout(strmAPITimeout),
 		strmmgr.OptAPIEnabled(enableAPI),
-	)
+		strmmgr.OptSetDefaultQuotas(opts.quotas),
+	}
+	switch {
+	case opts.persistDir != "" && opts.persistCache != "":
+		fmt.Fprintln(os.Stderr, "Only one of --persist-dir and --persist-cache can be specified")
+		os.Exit(1)
+	case opts.persistDir != "":
+		strmOpts = append(strmOpts, strmmgr.OptSetStore(strmmgr.NewDirectoryStore(opts.persistDir)))
+	case opts.persistCache != "":
+		if !manager.ProbeCache(opts.persistCache) {
+			fmt.Fprintf(os.Stderr, "Cache resource '%v' for persisting streams was not found\n", opts.persistCache)
+			os.Exit(1)
+		}
+		strmOpts = append(strmOpts, strmmgr.OptSetStore(strmmgr.NewCacheStore(manager, opts.persistCache)))
+	}
+	streamMgr := strmmgr.New(manager, strmOpts...)
 
 	streamConfs := map[string]stream.Config{}
-	lints, err := confReader.ReadStreams(streamConfs)
+	lints, warnings, err := confReader.ReadStreams(streamConfs)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Stream configuration file read error: %v\n", err)
 		os.Exit(1)
@@ -93,6 +122,9 @@ func initStreamsMode(
 	for _, lint := range lints {
 		logger.Infoln(lint)
 	}
+	for _, warning := range warnings {
+		logger.Warnln(warning)
+	}
 
 	for id, conf := range streamConfs {
 		if err := streamMgr.Create(id, conf); err != nil {
@@ -100,6 +132,14 @@ func initStreamsMode(
 			os.Exit(1)
 		}
 	}
+
+	loadCtx, done := context.WithTimeout(context.Background(), strmAPITimeout)
+	err = streamMgr.LoadFromStore(loadCtx)
+	done()
+	if err != nil {
+		logger.Errorf("Failed to load persisted streams: %v\n", err)
+		os.Exit(1)
+	}
 	logger.Infoln("Launching benthos in streams mode, use CTRL+C to close.")
 
 	if err := confReader.SubscribeStreamChanges(func(id string, newStreamConf stream.Config) bool {
@@ -126,10 +166,16 @@ func initStreamsMode(
 	return streamMgr
 }
 
+type reloadable interface {
+	Reload(conf stream.Config, timeout time.Duration) ([]string, error)
+}
+
 type swappableStopper struct {
-	stopped bool
-	current stoppable
-	mut     sync.Mutex
+	stopped   bool
+	replacing int32
+	current   stoppable
+	events    *api.Events
+	mut       sync.Mutex
 }
 
 func (s *swappableStopper) Stop(timeout time.Duration) error {
@@ -141,18 +187,47 @@ func (s *swappableStopper) Stop(timeout time.Duration) error {
 	}
 
 	s.stopped = true
+	if s.events != nil {
+		defer s.events.Close()
+	}
 	return s.current.Stop(timeout)
 }
 
-func (s *swappableStopper) Replace(fn func() (stoppable, error)) error {
+// isReplacing returns true whilst the active stream is being stopped in order
+// to be replaced.
+func (s *swappableStopper) isReplacing() bool {
+	return atomic.LoadInt32(&s.replacing) == 1
+}
+
+// Reload applies a new stream config to the active stream, replacing only the
+// components that have changed where possible, and otherwise replacing the
+// stream with a new one created by fn. The paths of the components that were
+// replaced are returned.
+func (s *swappableStopper) Reload(conf stream.Config, fn func() (stoppable, error)) ([]string, error) {
 	s.mut.Lock()
 	defer s.mut.Unlock()
 
 	if s.stopped {
 		// If the outter stream has been stopped then do not create a new one.
-		return nil
+		return nil, nil
+	}
+
+	r, ok := s.current.(reloadable)
+	if !ok {
+		return []string{"stream"}, s.replaceLocked(fn)
 	}
 
+	replaced, err := r.Reload(conf, time.Second*30)
+	if err == nil || !errors.Is(err, stream.ErrRestartRequired) {
+		return replaced, err
+	}
+	return replaced, s.replaceLocked(fn)
+}
+
+func (s *swappableStopper) replaceLocked(fn func() (stoppable, error)) error {
+	atomic.StoreInt32(&s.replacing, 1)
+	defer atomic.StoreInt32(&s.replacing, 0)
+
 	if err := s.current.Stop(time.Second * 30); err != nil {
 		return fmt.Errorf("failed to stop active stream: %w", err)
 	}
@@ -166,6 +241,19 @@ func (s *swappableStopper) Replace(fn func() (stoppable, error)) error {
 	return nil
 }
 
+// watchedStream is a stream that has the connection state of its input and
+// output published as events, which stops before the stream is stopped in order
+// to avoid publishing events for disconnects that were requested.
+type watchedStream struct {
+	*stream.Type
+	stopWatching func()
+}
+
+func (w *watchedStream) Stop(timeout time.Duration) error {
+	w.stopWatching()
+	return w.Type.Stop(timeout)
+}
+
 func initNormalMode(
 	conf config.Type,
 	strict, watching bool,
@@ -173,38 +261,72 @@ func initNormalMode(
 	manager *manager.Type,
 	logger log.Modular,
 	stats *metrics.Namespaced,
-) (newStream stoppable, stoppedChan chan struct{}) {
+) (newStream stoppable, reload func() ([]string, error), stoppedChan chan struct{}) {
 	stoppedChan = make(chan struct{})
 
-	streamInit := func() (stoppable, error) {
-		return stream.New(
-			conf.Config, manager,
-			stream.OptOnClose(func() {
-				if !watching {
-					close(stoppedChan)
-				}
-			}),
-		)
-	}
+	events := api.NewEvents(100)
+	manager.RegisterEndpoint(
+		"/events",
+		"GET: Connection, error and warning events of the stream as server-sent"+
+			" events. Events can be filtered with the URL param `type`, a comma"+
+			" separated list of event types.",
+		events.HandleSSE,
+	)
+	strmMgr := manager.WithLogger(strmmgr.NewEventLogger(manager.Logger(), events, "")).(bundle.NewManagement)
+
+	stoppableStream := swappableStopper{events: events}
+
+	streamInit := func(streamConf stream.Config) func() (stoppable, error) {
+		return func() (stoppable, error) {
+			var stopWatchOnce sync.Once
+			stopWatch := make(chan struct{})
+			stopWatching := func() {
+				stopWatchOnce.Do(func() {
+					close(stopWatch)
+				})
+			}
 
-	var stoppableStream swappableStopper
+			strm, err := stream.New(
+				streamConf, strmMgr,
+				stream.OptOnClose(func() {
+					stopWatching()
+					if !watching && !stoppableStream.isReplacing() {
+						close(stoppedChan)
+					}
+				}),
+			)
+			if err != nil {
+				return nil, err
+			}
+			go strmmgr.WatchConnections(events, "", strm, stopWatch)
+			return &watchedStream{Type: strm, stopWatching: stopWatching}, nil
+		}
+	}
 
 	var err error
-	if stoppableStream.current, err = streamInit(); err != nil {
+	if stoppableStream.current, err = streamInit(conf.Config)(); err != nil {
 		logger.Errorf("Service closing due to: %v\n", err)
 		os.Exit(1)
 	}
 	logger.Infoln("Launching a benthos instance, use CTRL+C to close.")
 
+	reloadStream := func(newStreamConf stream.Config) ([]string, error) {
+		replaced, err := stoppableStream.Reload(newStreamConf, streamInit(newStreamConf))
+		if err != nil {
+			return nil, fmt.Errorf("failed to update stream: %w", err)
+		}
+		if len(replaced) > 0 {
+			logger.Infof("Replaced the following components: %v", strings.Join(replaced, ", "))
+			events.Publish(api.Event{Type: strmmgr.EventStreamUpdated})
+		}
+		return replaced, nil
+	}
+
 	if err := confReader.SubscribeConfigChanges(func(newStreamConf stream.Config) bool {
-		if err := stoppableStream.Replace(func() (stoppable, error) {
-			conf.Config = newStreamConf
-			return streamInit()
-		}); err != nil {
-			logger.Errorf("Failed to update stream: %v", err)
+		if _, err := reloadStream(newStreamConf); err != nil {
+			logger.Errorln(err.Error())
 			return false
 		}
-
 		logger.Infoln("Updated main config from file.")
 		return true
 	}); err != nil {
@@ -219,10 +341,74 @@ func initNormalMode(
 		}
 	}
 
+	reload = func() ([]string, error) {
+		newStreamConf, replaced, err := confReader.ReloadMain(manager, strict)
+		if err != nil {
+			return nil, &reloadConfigErr{err: err}
+		}
+		sReplaced, err := reloadStream(newStreamConf)
+		return append(replaced, sReplaced...), err
+	}
+	manager.RegisterEndpoint(
+		"/reload",
+		"POST: Reload the config files and apply changes to the running stream,"+
+			" replacing only the components and resources that have changed.",
+		reloadHandler(reload),
+	)
+
 	newStream = &stoppableStream
 	return
 }
 
+// reloadConfigErr is returned when a reload fails due to the config files
+// being invalid.
+type reloadConfigErr struct {
+	err error
+}
+
+func (r *reloadConfigErr) Error() string {
+	return fmt.Sprintf("failed to read config: %v", r.err)
+}
+
+func (r *reloadConfigErr) Unwrap() error {
+	return r.err
+}
+
+func reloadHandler(reload func() ([]string, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			http.Error(w, "Method not supported", http.StatusBadRequest)
+			return
+		}
+
+		replaced, err := reload()
+		if err != nil {
+			var cErr *reloadConfigErr
+			if errors.As(err, &cErr) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			} else {
+				http.Error(w, err.Error(), http.StatusBadGateway)
+			}
+			return
+		}
+		if replaced == nil {
+			replaced = []string{}
+		}
+
+		resBytes, err := json.Marshal(struct {
+			Replaced []string `json:"replaced"`
+		}{
+			Replaced: replaced,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(resBytes)
+	}
+}
+
 func cmdService(
 	confPath string,
 	resourcesPaths []string,
@@ -230,12 +416,16 @@ func cmdService(
 	overrideLogLevel string,
 	strict, watching, enableStreamsAPI bool,
 	streamsMode bool,
-	streamsPaths []string,
+	streamsOpts streamsModeOpts,
+	remotePollInterval time.Duration,
 ) int {
-	confReader := readConfig(confPath, streamsMode, resourcesPaths, streamsPaths, confOverrides)
+	confReader := readConfig(
+		confPath, streamsMode, resourcesPaths, streamsOpts.paths, confOverrides,
+		config.OptSetRemotePollInterval(remotePollInterval),
+	)
 	conf := config.New()
 
-	lints, err := confReader.Read(&conf)
+	lints, warnings, err := confReader.Read(&conf)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Configuration file read error: %v\n", err)
 		return 1
@@ -270,10 +460,23 @@ func cmdService(
 	for _, lint := range lints {
 		logger.Infoln(lint)
 	}
+	for _, warning := range warnings {
+		logger.Warnln(warning)
+	}
+
+	// Secret references within the service wide components are resolved from
+	// copies of their configs so that they are not visible from the HTTP API.
+	metricsConf, tracerConf, httpConf := conf.Metrics, conf.Tracer, conf.HTTP
+	for _, c := range []interface{}{&metricsConf, &tracerConf, &httpConf} {
+		if err = secrets.GlobalProviders.Resolve(context.Background(), c); err != nil {
+			logger.Errorf("Failed to resolve secrets: %v\n", err)
+			return 1
+		}
+	}
 
 	// Create our metrics type.
 	var stats *metrics.Namespaced
-	stats, err = bundle.AllMetrics.Init(conf.Metrics, logger)
+	stats, err = bundle.AllMetrics.Init(metricsConf, logger)
 	for err != nil {
 		logger.Errorf("Failed to connect to metrics aggregator: %v\n", err)
 		return 1
@@ -286,7 +489,7 @@ func cmdService(
 
 	// Create our tracer type.
 	var trac tracer.Type
-	if trac, err = bundle.AllTracers.Init(conf.Tracer); err != nil {
+	if trac, err = bundle.AllTracers.Init(tracerConf); err != nil {
 		logger.Errorf("Failed to initialise tracer: %v\n", err)
 		return 1
 	}
@@ -298,25 +501,39 @@ func cmdService(
 	if err == nil {
 		err = config.Spec().SanitiseYAML(&sanitNode, docs.SanitiseConfig{
 			RemoveTypeField: true,
+			RedactSecrets:   true,
 		})
 	}
 	if err != nil {
 		logger.Warnf("Failed to generate sanitised config: %v\n", err)
 	}
 	var httpServer *api.Type
-	if httpServer, err = api.New(Version, DateBuilt, conf.HTTP, sanitNode, logger, stats); err != nil {
+	if httpServer, err = api.New(Version, DateBuilt, httpConf, sanitNode, logger, stats); err != nil {
 		logger.Errorf("Failed to initialise API: %v\n", err)
 		return 1
 	}
 
+	healthThresholds, err := conf.HTTP.Health.Thresholds()
+	if err != nil {
+		logger.Errorf("Failed to parse health thresholds: %v\n", err)
+		return 1
+	}
+	healthReg := health.NewRegistry()
+	httpServer.RegisterEndpoint(
+		"/health",
+		"Returns the health of each input, output and resource as a JSON object. A 503 is returned if any component is unhealthy.",
+		healthReg.Handler(healthThresholds),
+	)
+
 	// Create resource manager.
-	manager, err := manager.NewV2(conf.ResourceConfig, httpServer, logger, stats)
+	manager, err := manager.NewV2(conf.ResourceConfig, httpServer, logger, stats, manager.OptSetHealthRegistry(healthReg))
 	if err != nil {
 		logger.Errorf("Failed to create resource: %v\n", err)
 		return 1
 	}
 
 	var stoppableStream stoppable
+	var reloadFn func() ([]string, error)
 	var dataStreamClosedChan chan struct{}
 
 	strmAPITimeout := 5 * time.Second
@@ -328,9 +545,9 @@ func cmdService(
 
 	// Create data streams.
 	if streamsMode {
-		stoppableStream = initStreamsMode(strict, watching, enableStreamsAPI, confReader, strmAPITimeout, manager, logger, stats)
+		stoppableStream = initStreamsMode(strict, watching, enableStreamsAPI, confReader, streamsOpts, strmAPITimeout, manager, logger, stats)
 	} else {
-		stoppableStream, dataStreamClosedChan = initNormalMode(conf, strict, watching, confReader, manager, logger, stats)
+		stoppableStream, reloadFn, dataStreamClosedChan = initNormalMode(conf, strict, watching, confReader, manager, logger, stats)
 	}
 
 	// Start HTTP server.
@@ -396,18 +613,31 @@ func cmdService(
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
+	hupChan := make(chan os.Signal, 1)
+	if reloadFn != nil {
+		signal.Notify(hupChan, syscall.SIGHUP)
+	}
+
 	// Wait for termination signal
-	select {
-	case <-sigChan:
-		logger.Infoln("Received SIGTERM, the service is closing.")
-	case <-dataStreamClosedChan:
-		logger.Infoln("Pipeline has terminated. Shutting down the service.")
-	case <-httpServerClosedChan:
-		logger.Infoln("HTTP Server has terminated. Shutting down the service.")
-	case <-optContext.Done():
-		logger.Infoln("Run context was cancelled. Shutting down the service.")
-	}
-	return 0
+	for {
+		select {
+		case <-hupChan:
+			logger.Infoln("Received SIGHUP, reloading config.")
+			if _, err := reloadFn(); err != nil {
+				logger.Errorf("Failed to reload config: %v", err)
+			}
+			continue
+		case <-sigChan:
+			logger.Infoln("Received SIGTERM, the service is closing.")
+		case <-dataStreamClosedChan:
+			logger.Infoln("Pipeline has terminated. Shutting down the service.")
+		case <-httpServerClosedChan:
+			logger.Infoln("HTTP Server has terminated. Shutting down the service.")
+		case <-optContext.Done():
+			logger.Infoln("Run context was cancelled. Shutting down the service.")
+		}
+		return 0
+	}
 }
 
 //------------------------------------------------------------------------------
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	yaml "gopkg.in/yaml.v3"
 
@@ -69,14 +70,18 @@ func (i *InputPart) UnmarshalYAML(value *yaml.Node) error {
 // Case contains a definition of a single Benthos config test case.
 type Case struct {
 	Name             string               `yaml:"name"`
+	Skip             bool                 `yaml:"skip"`
 	Environment      map[string]string    `yaml:"environment"`
 	TargetProcessors string               `yaml:"target_processors"`
 	TargetMapping    string               `yaml:"target_mapping"`
 	Mocks            map[string]yaml.Node `yaml:"mocks"`
 	InputBatch       []InputPart          `yaml:"input_batch"`
 	OutputBatches    [][]ConditionsMap    `yaml:"output_batches"`
+	InputGenerator   *InputGenerator      `yaml:"input_generator"`
+	OutputInvariants ConditionsMap        `yaml:"output_invariants"`
 
-	line int
+	line            int
+	updateSnapshots bool
 }
 
 // AtLine returns a test case at a given line.
@@ -114,6 +119,15 @@ func (c *Case) UnmarshalYAML(value *yaml.Node) error {
 
 //------------------------------------------------------------------------------
 
+// CaseResult encapsulates the outcome of executing a single test case.
+type CaseResult struct {
+	Name     string
+	TestLine int
+	Skipped  bool
+	Duration time.Duration
+	Failures []CaseFailure
+}
+
 // CaseFailure encapsulates information about a failed test case.
 type CaseFailure struct {
 	Name     string
@@ -156,6 +170,10 @@ func (c *Case) executeFrom(dir string, provider ProcProvider) (failures []CaseFa
 		return nil, fmt.Errorf("failed to initialise processors '%v': %v", c.TargetProcessors, err)
 	}
 
+	if c.InputGenerator != nil {
+		return c.executeGenerated(dir, procSet)
+	}
+
 	reportFailure := func(reason string) {
 		failures = append(failures, CaseFailure{
 			Name:     c.Name,
@@ -203,7 +221,7 @@ func (c *Case) executeFrom(dir string, provider ProcProvider) (failures []CaseFa
 				reportFailure(fmt.Sprintf("unexpected message from batch %v: %s", i, part.Get()))
 				return nil
 			}
-			condErrs := expectedBatch[i2].checkAllFrom(dir, part)
+			condErrs := expectedBatch[i2].checkAllFrom(dir, c.updateSnapshots, part)
 			for _, condErr := range condErrs {
 				reportFailure(fmt.Sprintf("batch %v message %v: %v", i, i2, condErr))
 			}
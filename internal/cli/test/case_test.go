@@ -326,3 +326,125 @@ output_batches:
 		},
 	}, fails)
 }
+
+func TestCaseSnapshotUpdate(t *testing.T) {
+	provider := mockProvider{}
+
+	procConf := processor.NewConfig()
+	procConf.Type = "bloblang"
+	procConf.Bloblang = `root.doc = content().string().uppercase()`
+
+	proc, err := processor.New(procConf, mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	provider["/pipeline/processors"] = []iprocessor.V1{proc}
+
+	tmpDir := t.TempDir()
+
+	c := NewCase()
+	require.NoError(t, yaml.Unmarshal([]byte(`
+name: snapshotted
+input_batch:
+  - content: foo bar
+output_batches:
+-
+  - snapshot_equals: ./snapshots/foo.json
+`), &c))
+
+	fails, err := c.executeFrom(tmpDir, provider)
+	require.NoError(t, err)
+	require.Len(t, fails, 1)
+	assert.Contains(t, fails[0].Reason, "does not exist")
+
+	c.updateSnapshots = true
+	fails, err = c.executeFrom(tmpDir, provider)
+	require.NoError(t, err)
+	assert.Empty(t, fails)
+
+	c.updateSnapshots = false
+	fails, err = c.executeFrom(tmpDir, provider)
+	require.NoError(t, err)
+	assert.Empty(t, fails)
+}
+
+func TestCaseGeneratedInputs(t *testing.T) {
+	color.NoColor = true
+
+	provider := mockProvider{}
+
+	procConf := processor.NewConfig()
+	procConf.Type = "bloblang"
+	procConf.Bloblang = `root.name = this.name.uppercase()
+root.count = this.tags.length()`
+
+	proc, err := processor.New(procConf, mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	provider["/pipeline/processors"] = []iprocessor.V1{proc}
+
+	procConf.Bloblang = `root.count = this.tags.or([]).length()`
+	tolerantProc, err := processor.New(procConf, mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	provider["/tolerant"] = []iprocessor.V1{tolerantProc}
+
+	c := NewCase()
+	require.NoError(t, yaml.Unmarshal([]byte(`
+name: never fails
+input_generator:
+  seed: 10
+  count: 50
+  json_schema:
+    type: object
+    required: [ name, tags ]
+    properties:
+      name: { type: string }
+      tags: { type: array, items: { type: string } }
+output_invariants:
+  bloblang: 'this.count >= 0 && this.name == this.name.uppercase()'
+`), &c))
+
+	fails, err := c.Execute(provider)
+	require.NoError(t, err)
+	assert.Empty(t, fails)
+
+	c = NewCase()
+	require.NoError(t, yaml.Unmarshal([]byte(`
+name: fails on nulls
+input_generator:
+  seed: 10
+  json_schema:
+    type: object
+    required: [ name, tags ]
+    properties:
+      name: { type: [ string, "null" ] }
+      tags: { type: array, items: { type: string } }
+`), &c))
+
+	fails, err = c.Execute(provider)
+	require.NoError(t, err)
+	require.Len(t, fails, 1)
+	assert.Equal(t, 2, fails[0].TestLine)
+	assert.Contains(t, fails[0].Reason, "(seed 10), minimal counterexample after")
+	assert.Contains(t, fails[0].Reason, `shrinks: {"name":null,"tags":[]}`)
+	assert.Contains(t, fails[0].Reason, "message flagged with processing error")
+
+	c = NewCase()
+	require.NoError(t, yaml.Unmarshal([]byte(`
+name: invariant fails
+target_processors: /tolerant
+input_generator:
+  mapping: |
+    root.name = "foo"
+    root.tags = if random_int() % 2 == 0 { [] } else { [ "a", "b" ] }
+output_invariants:
+  bloblang: 'this.count > 0'
+`), &c))
+
+	fails, err = c.Execute(provider)
+	require.NoError(t, err)
+	require.Len(t, fails, 1)
+	assert.Contains(t, fails[0].Reason, "minimal counterexample after 1 shrinks: {}")
+	assert.NotContains(t, fails[0].Reason, "seed")
+	assert.Contains(t, fails[0].Reason, "batch 0 message 0: bloblang: bloblang expression was false")
+}
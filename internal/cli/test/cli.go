@@ -22,6 +22,8 @@ fail the process will report the errors and exit with a status code 1.
   benthos test ./path/to/configs/...
   benthos test ./foo_configs/*.yaml ./bar_configs/*.yaml
   benthos test ./foo.yaml
+  benthos test --format junit ./... > report.xml
+  benthos test --update-snapshots ./foo.yaml
 
 For more information check out the docs at:
 https://benthos.dev/docs/configuration/unit_testing`[1:],
@@ -31,6 +33,16 @@ https://benthos.dev/docs/configuration/unit_testing`[1:],
 				Value: "",
 				Usage: "allow components to write logs at a provided level to stdout.",
 			},
+			&cli.StringFlag{
+				Name:  "format",
+				Value: "text",
+				Usage: "print test results in a specific format. Options are text, json or junit.",
+			},
+			&cli.BoolFlag{
+				Name:  "update-snapshots",
+				Value: false,
+				Usage: "overwrite the golden files of snapshot_equals conditions with the actual output of tests.",
+			},
 		},
 		Action: func(c *cli.Context) error {
 			if len(c.StringSlice("set")) > 0 {
@@ -43,6 +55,11 @@ https://benthos.dev/docs/configuration/unit_testing`[1:],
 				fmt.Printf("Failed to resolve resource glob pattern: %v\n", err)
 				os.Exit(1)
 			}
+			rep, err := newReporter(c.String("format"), os.Stdout)
+			if err != nil {
+				fmt.Printf("Failed to init reporter: %v\n", err)
+				os.Exit(1)
+			}
 			if logLevel := c.String("log"); len(logLevel) > 0 {
 				logConf := log.NewConfig()
 				logConf.LogLevel = logLevel
@@ -51,10 +68,10 @@ https://benthos.dev/docs/configuration/unit_testing`[1:],
 					fmt.Printf("Failed to init logger: %v\n", err)
 					os.Exit(1)
 				}
-				if runAll(c.Args().Slice(), testSuffix, true, logger, resourcesPaths) {
+				if runAll(c.Args().Slice(), testSuffix, true, logger, resourcesPaths, c.Bool("update-snapshots"), rep) {
 					os.Exit(0)
 				}
-			} else if runAll(c.Args().Slice(), testSuffix, true, log.Noop(), resourcesPaths) {
+			} else if runAll(c.Args().Slice(), testSuffix, true, log.Noop(), resourcesPaths, c.Bool("update-snapshots"), rep) {
 				os.Exit(0)
 			}
 			os.Exit(1)
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	yaml "gopkg.in/yaml.v3"
@@ -59,6 +60,7 @@ func getDefinition(targetPath, definitionPath string) (*Definition, error) {
 	if err := yaml.Unmarshal(defBytes, &definition); err != nil {
 		return nil, fmt.Errorf("failed to parse test definition from '%v': %v", definitionPath, err)
 	}
+	definition.path = definitionPath
 	return &definition, nil
 }
 
@@ -110,17 +112,17 @@ func Run(path, testSuffix string, lint bool) bool {
 // a config file, a config files test definition file, a directory, or the
 // wildcard pattern './...'.
 func RunAll(paths []string, testSuffix string, lint bool) bool {
-	return runAll(paths, testSuffix, lint, log.Noop(), nil)
+	return runAll(paths, testSuffix, lint, log.Noop(), nil, false, &textReporter{w: os.Stdout})
 }
 
 // RunAllWithLogger executes the test command for a slice of paths. The path can
 // either be a config file, a config files test definition file, a directory, or
 // the wildcard pattern './...'.
 func RunAllWithLogger(paths []string, testSuffix string, lint bool, logger log.Modular) bool {
-	return runAll(paths, testSuffix, lint, logger, nil)
+	return runAll(paths, testSuffix, lint, logger, nil, false, &textReporter{w: os.Stdout})
 }
 
-func runAll(paths []string, testSuffix string, lint bool, logger log.Modular, resourcesPaths []string) bool {
+func runAll(paths []string, testSuffix string, lint bool, logger log.Modular, resourcesPaths []string, updateSnapshots bool, rep reporter) bool {
 	targets, err := GetTestTargets(paths, testSuffix)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to obtain test targets: %v\n", err)
@@ -131,71 +133,45 @@ func runAll(paths []string, testSuffix string, lint bool, logger log.Modular, re
 		return false
 	}
 
-	type failedTarget struct {
-		target string
-		lints  []string
-		cases  []CaseFailure
-	}
-	fails := []failedTarget{}
-
 	targetPaths := make([]string, 0, len(targets))
 	for k := range targets {
 		targetPaths = append(targetPaths, k)
 	}
 	sort.Strings(targetPaths)
 
+	failed := false
+	results := make([]TargetResult, 0, len(targetPaths))
 	for _, target := range targetPaths {
-		var lints []string
-		var failCases []CaseFailure
+		def := targets[target]
+		res := TargetResult{
+			ConfigPath:     target,
+			DefinitionPath: def.path,
+		}
 		if lint {
-			if lints, err = lintTarget(target, testSuffix); err != nil {
+			if res.Lints, err = lintTarget(target, testSuffix); err != nil {
 				fmt.Fprintf(os.Stderr, "Failed to execute test target '%v': %v\n", target, err)
 				return false
 			}
 		}
-		if failCases, err = targets[target].execute(target, resourcesPaths, logger); err != nil {
+		tStarted := time.Now()
+		coverage := newCoverageRecorder()
+		if res.Cases, err = def.executeCases(target, resourcesPaths, updateSnapshots, logger, coverage); err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to execute test target '%v': %v\n", target, err)
 			return false
 		}
-		if len(lints) > 0 || len(failCases) > 0 {
-			fails = append(fails, failedTarget{
-				target: target,
-				lints:  lints,
-				cases:  failCases,
-			})
-			fmt.Printf("Test '%v' %v\n", target, red("failed"))
-		} else {
-			fmt.Printf("Test '%v' %v\n", target, green("succeeded"))
+		res.Duration = time.Since(tStarted)
+		if res.Coverage, err = GetProcessorCoverage(target, coverage.paths()); err != nil {
+			logger.Warnf("Failed to determine processor coverage of '%v': %v\n", target, err)
 		}
-	}
-	if len(fails) > 0 {
-		fmt.Printf("\nFailures:\n\n")
-		for i, fail := range fails {
-			if i > 0 {
-				fmt.Println("")
-			}
-			fmt.Printf("--- %v ---\n\n", fail.target)
-			for _, lint := range fail.lints {
-				fmt.Printf("Lint: %v\n", lint)
-			}
-			if len(fail.cases) > 0 {
-				if len(fail.lints) > 0 {
-					fmt.Println("")
-				}
-				var namePrev string
-				for i, fail := range fail.cases {
-					if namePrev != fail.Name {
-						if i > 0 {
-							fmt.Println("")
-						}
-						fmt.Printf("%v [line %v]:\n", fail.Name, fail.TestLine)
-						namePrev = fail.Name
-					}
-					fmt.Println(fail.Reason)
-				}
-			}
+		if res.Failed() {
+			failed = true
 		}
+		rep.target(res)
+		results = append(results, res)
+	}
+	if err := rep.finish(results); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write test report: %v\n", err)
 		return false
 	}
-	return true
+	return !failed
 }
@@ -9,7 +9,9 @@ import (
 	"path/filepath"
 	"regexp"
 	"sort"
+	"sync"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/nsf/jsondiff"
 	yaml "gopkg.in/yaml.v3"
 
@@ -78,6 +80,12 @@ func (c *ConditionsMap) UnmarshalYAML(value *yaml.Node) error {
 				return fmt.Errorf("line %v: %v", v.Line, err)
 			}
 			cond = val
+		case "snapshot_equals":
+			val := SnapshotEqualsCondition("")
+			if err := v.Decode(&val); err != nil {
+				return fmt.Errorf("line %v: %v", v.Line, err)
+			}
+			cond = val
 		case "metadata_equals":
 			val := MetadataEqualsCondition{}
 			if err := v.Decode(&val); err != nil {
@@ -95,17 +103,23 @@ func (c *ConditionsMap) UnmarshalYAML(value *yaml.Node) error {
 // CheckAll checks all conditions against a message part. Conditions are
 // executed in alphabetical order.
 func (c ConditionsMap) CheckAll(part *message.Part) (errs []error) {
-	return c.checkAllFrom("", part)
+	return c.checkAllFrom("", false, part)
 }
 
-func (c ConditionsMap) checkAllFrom(dir string, part *message.Part) (errs []error) {
+func (c ConditionsMap) checkAllFrom(dir string, updateSnapshots bool, part *message.Part) (errs []error) {
 	condTypes := []string{}
 	for k := range c {
 		condTypes = append(condTypes, k)
 	}
 	sort.Strings(condTypes)
 	for _, k := range condTypes {
-		if relCheck, ok := c[k].(interface {
+		if snapCheck, ok := c[k].(interface {
+			checkSnapshot(string, bool, *message.Part) error
+		}); ok {
+			if err := snapCheck.checkSnapshot(dir, updateSnapshots, part); err != nil {
+				errs = append(errs, fmt.Errorf("%v: %v", k, err))
+			}
+		} else if relCheck, ok := c[k].(interface {
 			checkFrom(string, *message.Part) error
 		}); ok {
 			if err := relCheck.checkFrom(dir, part); err != nil {
@@ -234,8 +248,11 @@ func (c FileEqualsCondition) checkFrom(dir string, p *message.Part) error {
 	if err != nil {
 		return fmt.Errorf("failed to read comparison file: %w", err)
 	}
+	return compareFileContent(fileContent, p.Get())
+}
 
-	if exp, act := string(fileContent), string(p.Get()); exp != act {
+func compareFileContent(fileContent, content []byte) error {
+	if exp, act := string(fileContent), string(content); exp != act {
 		return fmt.Errorf("content mismatch\n  expected: %v\n  received: %v", blue(exp), red(act))
 	}
 	return nil
@@ -243,6 +260,108 @@ func (c FileEqualsCondition) checkFrom(dir string, p *message.Part) error {
 
 //------------------------------------------------------------------------------
 
+// SnapshotEqualsCondition is a string condition that reads a golden file at the
+// string path and compares it against the contents of a message. Files with a
+// .json, .yaml or .yml extension are compared structurally, any other files are
+// compared byte for byte. When snapshots are being updated the file is instead
+// overwritten with the contents of the message.
+type SnapshotEqualsCondition string
+
+// Check this condition against a message part.
+func (c SnapshotEqualsCondition) Check(p *message.Part) error {
+	return c.checkSnapshot("", false, p)
+}
+
+func (c SnapshotEqualsCondition) checkSnapshot(dir string, update bool, p *message.Part) error {
+	relPath := filepath.Join(dir, string(c))
+	if update {
+		return writeSnapshot(relPath, p.Get())
+	}
+
+	var compare func(expected, actual []byte) error
+	switch filepath.Ext(relPath) {
+	case ".json":
+		compare = compareJSONSnapshot
+	case ".yaml", ".yml":
+		compare = compareYAMLSnapshot
+	}
+
+	fileContent, err := os.ReadFile(relPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("snapshot file '%v' does not exist, run with --update-snapshots in order to create it", string(c))
+		}
+		return fmt.Errorf("failed to read snapshot file: %w", err)
+	}
+	if compare == nil {
+		return compareFileContent(fileContent, p.Get())
+	}
+	return compare(fileContent, p.Get())
+}
+
+// snapshotLocks contains a mutex for each snapshot file path, which prevents
+// test cases that are executed in parallel from writing the same file at the
+// same time.
+var snapshotLocks sync.Map
+
+func writeSnapshot(path string, content []byte) error {
+	if absPath, err := filepath.Abs(path); err == nil {
+		path = absPath
+	}
+	mut, _ := snapshotLocks.LoadOrStore(path, &sync.Mutex{})
+	mut.(*sync.Mutex).Lock()
+	defer mut.(*sync.Mutex).Unlock()
+
+	// JSON snapshots are indented in order to make them easier to review, the
+	// document is otherwise written exactly as it was received.
+	if filepath.Ext(path) == ".json" {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, content, "", "  "); err == nil {
+			buf.WriteByte('\n')
+			content = buf.Bytes()
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write snapshot file: %w", err)
+	}
+	return nil
+}
+
+func compareJSONSnapshot(expected, actual []byte) error {
+	jdopts := jsondiff.DefaultConsoleOptions()
+	diff, explanation := jsondiff.Compare(actual, expected, &jdopts)
+	switch diff {
+	case jsondiff.FullMatch:
+		return nil
+	case jsondiff.FirstArgIsInvalidJson:
+		return fmt.Errorf("snapshot mismatch, message is not valid JSON\n  received: %v", red(string(actual)))
+	case jsondiff.SecondArgIsInvalidJson:
+		return errors.New("snapshot file is not valid JSON")
+	}
+	return fmt.Errorf("JSON snapshot mismatch\n%v", explanation)
+}
+
+func compareYAMLSnapshot(expected, actual []byte) error {
+	// Documents are compared as decoded values rather than converted to JSON,
+	// since YAML permits keys that cannot be represented in JSON.
+	var expV, actV interface{}
+	if err := yaml.Unmarshal(expected, &expV); err != nil {
+		return fmt.Errorf("snapshot file is not valid YAML: %w", err)
+	}
+	if err := yaml.Unmarshal(actual, &actV); err != nil {
+		return fmt.Errorf("snapshot mismatch, message is not valid YAML\n  received: %v", red(string(actual)))
+	}
+	if diff := cmp.Diff(expV, actV); diff != "" {
+		return fmt.Errorf("YAML snapshot mismatch (-snapshot +received)\n%v", diff)
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
 // MetadataEqualsCondition checks whether a metadata keys contents matches a
 // value.
 type MetadataEqualsCondition map[string]string
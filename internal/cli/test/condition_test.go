@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"sync"
 	"testing"
 
 	"github.com/fatih/color"
@@ -476,3 +477,155 @@ func TestFileEqualsCondition(t *testing.T) {
 		})
 	}
 }
+
+func TestSnapshotEqualsCondition(t *testing.T) {
+	color.NoColor = true
+
+	tmpDir := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "snapshots"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "snapshots", "doc.json"), []byte(`{
+  "id": "foo",
+  "tags": ["a","b"]
+}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "snapshots", "doc.yaml"), []byte(`
+id: foo
+tags: [ a, b ]
+`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "snapshots", "codes.yaml"), []byte(`
+200: ok
+404: [ not, found ]
+`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "snapshots", "doc.txt"), []byte(`foo bar`), 0o644))
+
+	type testCase struct {
+		name        string
+		path        string
+		input       string
+		errContains string
+	}
+
+	tests := []testCase{
+		{
+			name:  "json positive",
+			path:  `./snapshots/doc.json`,
+			input: `{"tags":["a","b"],"id":"foo"}`,
+		},
+		{
+			name:        "json negative",
+			path:        `./snapshots/doc.json`,
+			input:       `{"tags":["a","c"],"id":"foo"}`,
+			errContains: "JSON snapshot mismatch",
+		},
+		{
+			name:        "json invalid",
+			path:        `./snapshots/doc.json`,
+			input:       `not json`,
+			errContains: "message is not valid JSON",
+		},
+		{
+			name:  "yaml positive",
+			path:  `./snapshots/doc.yaml`,
+			input: "tags:\n  - a\n  - b\nid: foo\n",
+		},
+		{
+			name:  "yaml positive from json",
+			path:  `./snapshots/doc.yaml`,
+			input: `{"id":"foo","tags":["a","b"]}`,
+		},
+		{
+			name:        "yaml negative",
+			path:        `./snapshots/doc.yaml`,
+			input:       "id: bar\ntags: [ a, b ]\n",
+			errContains: "YAML snapshot mismatch",
+		},
+		{
+			name:  "yaml non-string keys positive",
+			path:  `./snapshots/codes.yaml`,
+			input: "404:\n  - not\n  - found\n200: ok\n",
+		},
+		{
+			name:        "yaml non-string keys negative",
+			path:        `./snapshots/codes.yaml`,
+			input:       "200: ok\n404: [ not, here ]\n",
+			errContains: "YAML snapshot mismatch",
+		},
+		{
+			name:  "raw positive",
+			path:  `./snapshots/doc.txt`,
+			input: `foo bar`,
+		},
+		{
+			name:        "raw negative",
+			path:        `./snapshots/doc.txt`,
+			input:       `foo baz`,
+			errContains: "content mismatch",
+		},
+		{
+			name:        "missing file",
+			path:        `./snapshots/nope.json`,
+			input:       `{}`,
+			errContains: "run with --update-snapshots",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			actErr := SnapshotEqualsCondition(test.path).checkSnapshot(tmpDir, false, message.NewPart([]byte(test.input)))
+			if test.errContains == "" {
+				assert.NoError(t, actErr)
+			} else {
+				require.Error(t, actErr)
+				assert.Contains(t, actErr.Error(), test.errContains)
+			}
+		})
+	}
+}
+
+func TestSnapshotEqualsConditionUpdate(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	jsonCond := SnapshotEqualsCondition("./snapshots/new.json")
+	require.NoError(t, jsonCond.checkSnapshot(tmpDir, true, message.NewPart([]byte(`{"id":"foo","tags":["a"]}`))))
+
+	jsonBytes, err := os.ReadFile(filepath.Join(tmpDir, "snapshots", "new.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"id\": \"foo\",\n  \"tags\": [\n    \"a\"\n  ]\n}\n", string(jsonBytes))
+
+	require.NoError(t, jsonCond.checkSnapshot(tmpDir, false, message.NewPart([]byte(`{"tags":["a"],"id":"foo"}`))))
+	require.Error(t, jsonCond.checkSnapshot(tmpDir, false, message.NewPart([]byte(`{"tags":["b"],"id":"foo"}`))))
+
+	// Large integers and HTML characters must be written as they were received.
+	require.NoError(t, jsonCond.checkSnapshot(tmpDir, true, message.NewPart([]byte(`{"id":9007199254740993,"html":"<b>&</b>"}`))))
+
+	jsonBytes, err = os.ReadFile(filepath.Join(tmpDir, "snapshots", "new.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"id\": 9007199254740993,\n  \"html\": \"<b>&</b>\"\n}\n", string(jsonBytes))
+
+	yamlCond := SnapshotEqualsCondition("new.yaml")
+	require.NoError(t, yamlCond.checkSnapshot(tmpDir, true, message.NewPart([]byte("id: foo\ntags: [ a ]\n"))))
+
+	yamlBytes, err := os.ReadFile(filepath.Join(tmpDir, "new.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "id: foo\ntags: [ a ]\n", string(yamlBytes))
+}
+
+func TestSnapshotEqualsConditionParallelUpdate(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cond := SnapshotEqualsCondition("shared.txt")
+			assert.NoError(t, cond.checkSnapshot(tmpDir, true, message.NewPart([]byte("foo bar baz"))))
+		}()
+	}
+	wg.Wait()
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "shared.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "foo bar baz", string(content))
+}
@@ -0,0 +1,186 @@
+package test
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	iprocessor "github.com/benthosdev/benthos/v4/internal/component/processor"
+	"github.com/benthosdev/benthos/v4/internal/config"
+	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/old/processor"
+)
+
+//------------------------------------------------------------------------------
+
+// ProcessorCoverage describes a processor found within a config file and
+// whether it was executed by any of the test cases targeting that file.
+type ProcessorCoverage struct {
+	Path    string
+	Type    string
+	Label   string
+	Covered bool
+}
+
+func jsonPointerFromPath(path []string) string {
+	if len(path) == 0 {
+		return ""
+	}
+	escaped := make([]string, len(path))
+	for i, p := range path {
+		escaped[i] = strings.ReplaceAll(strings.ReplaceAll(p, "~", "~0"), "/", "~1")
+	}
+	return "/" + strings.Join(escaped, "/")
+}
+
+func pointerContains(parent, child string) bool {
+	return child == parent || strings.HasPrefix(child, parent+"/")
+}
+
+// GetProcessorCoverage walks the processors of a config file and determines
+// which of them were executed, where executedPaths is a list of JSON pointers
+// to the processors within the file that were executed by test cases.
+func GetProcessorCoverage(configPath string, executedPaths []string) ([]ProcessorCoverage, error) {
+	configBytes, _, err := config.ReadFileEnvSwap(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &yaml.Node{}
+	if err := yaml.Unmarshal(configBytes, root); err != nil {
+		return nil, err
+	}
+
+	executed := make(map[string]struct{}, len(executedPaths))
+	for _, p := range executedPaths {
+		executed[p] = struct{}{}
+	}
+
+	var coverage []ProcessorCoverage
+	config.Spec().WalkYAMLComponents(nil, root, nil, func(cType docs.Type, name string, path []string, node *yaml.Node) {
+		if cType != docs.TypeProcessor {
+			return
+		}
+		var label string
+		if labelNode, err := docs.GetYAMLPath(node, "label"); err == nil {
+			label = labelNode.Value
+		}
+		pathStr := jsonPointerFromPath(path)
+		_, covered := executed[pathStr]
+		coverage = append(coverage, ProcessorCoverage{
+			Path:    pathStr,
+			Type:    name,
+			Label:   label,
+			Covered: covered,
+		})
+	})
+	return coverage, nil
+}
+
+//------------------------------------------------------------------------------
+
+// coverageRecorder collects the JSON pointers of processors within a config
+// file that have been executed by test cases.
+type coverageRecorder struct {
+	mut      sync.Mutex
+	executed map[string]struct{}
+}
+
+func newCoverageRecorder() *coverageRecorder {
+	return &coverageRecorder{
+		executed: map[string]struct{}{},
+	}
+}
+
+func (c *coverageRecorder) record(path string) {
+	c.mut.Lock()
+	c.executed[path] = struct{}{}
+	c.mut.Unlock()
+}
+
+// paths returns a sorted list of the processor paths that were executed.
+func (c *coverageRecorder) paths() []string {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	paths := make([]string, 0, len(c.executed))
+	for k := range c.executed {
+		paths = append(paths, k)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// environment returns a variant of an environment where processors are wrapped
+// in order to record their execution. The function resolve converts the path
+// and label of a processor into a JSON pointer within the config file, and
+// returns false if the processor should not be recorded.
+func (c *coverageRecorder) environment(env *bundle.Environment, resolve func(path []string, label string) (string, bool)) *bundle.Environment {
+	recordedEnv := env.Clone()
+	for _, spec := range env.ProcessorDocs() {
+		_ = recordedEnv.ProcessorAdd(func(conf processor.Config, nm bundle.NewManagement) (iprocessor.V1, error) {
+			p, err := env.ProcessorInit(conf, nm)
+			if err != nil {
+				return nil, err
+			}
+			if path, ok := resolve(nm.Path(), nm.Label()); ok {
+				p = &recordedProcessor{path: path, rec: c, wrapped: p}
+			}
+			return p, nil
+		}, spec)
+	}
+	return recordedEnv
+}
+
+// resolveCoveragePath returns a function that converts the component path and
+// label of a processor, as provided to its constructor, into a JSON pointer
+// within the config file of a test target. Processors within mocked paths are
+// not resolved as they aren't part of the config being tested. Top level
+// processor resources are resolved by their label, their children cannot be
+// identified and are therefore not resolved.
+func resolveCoveragePath(mockedPaths []string, resourceIndexes map[string]int) func(path []string, label string) (string, bool) {
+	return func(path []string, label string) (string, bool) {
+		if len(path) > 0 && path[0] == "processor_resources" {
+			if len(path) > 1 {
+				return "", false
+			}
+			i, exists := resourceIndexes[label]
+			if !exists {
+				return "", false
+			}
+			path = []string{"processor_resources", strconv.Itoa(i)}
+		}
+		pathStr := jsonPointerFromPath(path)
+		for _, mocked := range mockedPaths {
+			if pointerContains(mocked, pathStr) {
+				return "", false
+			}
+		}
+		return pathStr, true
+	}
+}
+
+type recordedProcessor struct {
+	path    string
+	rec     *coverageRecorder
+	wrapped iprocessor.V1
+}
+
+func (r *recordedProcessor) ProcessMessage(m *message.Batch) ([]*message.Batch, error) {
+	r.rec.record(r.path)
+	return r.wrapped.ProcessMessage(m)
+}
+
+func (r *recordedProcessor) CloseAsync() {
+	r.wrapped.CloseAsync()
+}
+
+func (r *recordedProcessor) WaitForClose(timeout time.Duration) error {
+	return r.wrapped.WaitForClose(timeout)
+}
@@ -0,0 +1,86 @@
+package test_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/cli/test"
+	"github.com/benthosdev/benthos/v4/internal/log"
+)
+
+func TestProcessorCoverage(t *testing.T) {
+	testDir, err := initTestFiles(t, map[string]string{
+		"foo.yaml": `
+input:
+  generate:
+    mapping: 'root = "hello world"'
+  processors:
+    - bloblang: 'root = content().uppercase()'
+
+pipeline:
+  processors:
+    - label: first
+      bloblang: 'root = content().lowercase()'
+    - label: second
+      branch:
+        processors:
+          - bloblang: 'root = this.foo'
+    - label: third
+      switch:
+        - check: 'content() == "nope"'
+          processors:
+            - bloblang: 'root = "nope"'
+        - processors:
+            - resource: upper
+
+processor_resources:
+  - label: upper
+    bloblang: 'root = content().uppercase()'
+`,
+		"foo_benthos_test.yaml": `
+tests:
+  - name: first test
+    target_processors: '/pipeline/processors'
+    mocks:
+      second:
+        bloblang: 'root = this'
+    input_batch:
+      - content: 'example content'
+    output_batches:
+      - - content_equals: EXAMPLE CONTENT
+  - name: skipped test
+    skip: true
+    target_processors: '/input/processors'
+    input_batch:
+      - content: 'example content'
+    output_batches:
+      - - content_equals: EXAMPLE CONTENT
+`,
+	})
+	require.NoError(t, err)
+
+	targets, err := test.GetTestTargets([]string{testDir + "/..."}, "_benthos_test")
+	require.NoError(t, err)
+
+	configPath := filepath.Join(testDir, "foo.yaml")
+	require.Contains(t, targets, configPath)
+
+	results, coverage, err := targets[configPath].ExecuteWithCoverage(configPath, log.Noop())
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Empty(t, results[0].Failures)
+
+	assert.Equal(t, []test.ProcessorCoverage{
+		{Path: "/input/processors/0", Type: "bloblang", Covered: false},
+		{Path: "/pipeline/processors/0", Type: "bloblang", Label: "first", Covered: true},
+		{Path: "/pipeline/processors/1", Type: "branch", Label: "second", Covered: false},
+		{Path: "/pipeline/processors/1/branch/processors/0", Type: "bloblang", Covered: false},
+		{Path: "/pipeline/processors/2", Type: "switch", Label: "third", Covered: true},
+		{Path: "/pipeline/processors/2/switch/0/processors/0", Type: "bloblang", Covered: false},
+		{Path: "/pipeline/processors/2/switch/1/processors/0", Type: "resource", Covered: true},
+		{Path: "/processor_resources/0", Type: "bloblang", Label: "upper", Covered: true},
+	}, coverage)
+}
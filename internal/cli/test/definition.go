@@ -3,6 +3,7 @@ package test
 import (
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
@@ -15,6 +16,8 @@ import (
 type Definition struct {
 	Parallel bool   `yaml:"parallel"`
 	Cases    []Case `yaml:"tests"`
+
+	path string
 }
 
 // ExampleDefinition returns a Definition containing an example case.
@@ -59,14 +62,64 @@ func (d Definition) Execute(filepath string) ([]CaseFailure, error) {
 }
 
 func (d Definition) execute(testFilePath string, resourcesPaths []string, logger log.Modular) ([]CaseFailure, error) {
+	results, err := d.executeCases(testFilePath, resourcesPaths, false, logger, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var totalFailures []CaseFailure
+	for _, res := range results {
+		totalFailures = append(totalFailures, res.Failures...)
+	}
+	return totalFailures, nil
+}
+
+// ExecuteWithCoverage attempts to run a test definition on a target config
+// file. Returns the results of each test case along with the coverage of
+// processors within the config file by the executed cases, or an error.
+func (d Definition) ExecuteWithCoverage(filepath string, logger log.Modular) ([]CaseResult, []ProcessorCoverage, error) {
+	rec := newCoverageRecorder()
+	results, err := d.executeCases(filepath, nil, false, logger, rec)
+	if err != nil {
+		return nil, nil, err
+	}
+	coverage, err := GetProcessorCoverage(filepath, rec.paths())
+	if err != nil {
+		return nil, nil, err
+	}
+	return results, coverage, nil
+}
+
+func (c *Case) executeTimed(dir string, provider ProcProvider) (CaseResult, error) {
+	res := CaseResult{
+		Name:     c.Name,
+		TestLine: c.line,
+		Skipped:  c.Skip,
+	}
+	if c.Skip {
+		return res, nil
+	}
+
+	tStarted := time.Now()
+	failures, err := c.executeFrom(dir, provider)
+	res.Duration = time.Since(tStarted)
+	res.Failures = failures
+	return res, err
+}
+
+func (d Definition) executeCases(testFilePath string, resourcesPaths []string, updateSnapshots bool, logger log.Modular, coverage *coverageRecorder) ([]CaseResult, error) {
 	procsProvider := NewProcessorsProvider(
 		testFilePath,
 		OptAddResourcesPaths(resourcesPaths),
 		OptProcessorsProviderSetLogger(logger),
+		optProcessorsProviderRecordCoverage(coverage),
 	)
 	if d.Parallel {
 		// Warm the cache of processor configs.
 		for _, c := range d.Cases {
+			if c.Skip {
+				continue
+			}
 			if _, err := procsProvider.getConfs(c.TargetProcessors, c.Environment, c.Mocks); err != nil {
 				return nil, err
 			}
@@ -75,31 +128,32 @@ func (d Definition) execute(testFilePath string, resourcesPaths []string, logger
 
 	dir := filepath.Dir(testFilePath)
 
-	var totalFailures []CaseFailure
+	results := make([]CaseResult, len(d.Cases))
 	if !d.Parallel {
 		for i, c := range d.Cases {
+			c.updateSnapshots = updateSnapshots
 			cleanupEnv := setEnvironment(c.Environment)
-			failures, err := c.executeFrom(dir, procsProvider)
+			res, err := c.executeTimed(dir, procsProvider)
 			if err != nil {
 				cleanupEnv()
 				return nil, fmt.Errorf("test case %v failed: %v", i, err)
 			}
-			totalFailures = append(totalFailures, failures...)
+			results[i] = res
 			cleanupEnv()
 		}
 	} else {
 		var g errgroup.Group
 
-		failureSlices := make([][]CaseFailure, len(d.Cases))
 		for i, c := range d.Cases {
 			i := i
 			c := c
+			c.updateSnapshots = updateSnapshots
 			g.Go(func() error {
-				failures, err := c.executeFrom(dir, procsProvider)
+				res, err := c.executeTimed(dir, procsProvider)
 				if err != nil {
 					return fmt.Errorf("test case %v failed: %v", i, err)
 				}
-				failureSlices[i] = failures
+				results[i] = res
 				return nil
 			})
 		}
@@ -108,13 +162,9 @@ func (d Definition) execute(testFilePath string, resourcesPaths []string, logger
 		if err := g.Wait(); err != nil {
 			return nil, err
 		}
-
-		for _, fs := range failureSlices {
-			totalFailures = append(totalFailures, fs...)
-		}
 	}
 
-	return totalFailures, nil
+	return results, nil
 }
 
 //------------------------------------------------------------------------------
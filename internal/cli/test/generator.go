@@ -0,0 +1,425 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/xeipuuv/gojsonschema"
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+//------------------------------------------------------------------------------
+
+// InputGenerator defines how random input documents are generated for a
+// property based test case, either from a Bloblang mapping or a JSON Schema.
+type InputGenerator struct {
+	Mapping    string
+	JSONSchema string
+	Count      int
+	Seed       int64
+
+	hasSeed   bool
+	exec      *mapping.Executor
+	schema    map[string]interface{}
+	validator *gojsonschema.Schema
+}
+
+// UnmarshalYAML extracts an InputGenerator from a YAML node.
+func (i *InputGenerator) UnmarshalYAML(value *yaml.Node) error {
+	rawMap := map[string]yaml.Node{}
+	if err := value.Decode(&rawMap); err != nil {
+		return fmt.Errorf("line %v: %v", value.Line, err)
+	}
+	i.Count = 100
+	for k, v := range rawMap {
+		switch k {
+		case "mapping":
+			if err := v.Decode(&i.Mapping); err != nil {
+				return fmt.Errorf("line %v: %v", v.Line, err)
+			}
+			exec, err := bloblang.GlobalEnvironment().NewMapping(i.Mapping)
+			if err != nil {
+				return fmt.Errorf("line %v: %v", v.Line, err)
+			}
+			i.exec = exec
+		case "json_schema":
+			if err := yamlNodeToTestString(&v, &i.JSONSchema); err != nil {
+				return fmt.Errorf("line %v: %v", v.Line, err)
+			}
+			if err := json.Unmarshal([]byte(i.JSONSchema), &i.schema); err != nil {
+				return fmt.Errorf("line %v: failed to parse json_schema: %v", v.Line, err)
+			}
+			validator, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(i.schema))
+			if err != nil {
+				return fmt.Errorf("line %v: failed to parse json_schema: %v", v.Line, err)
+			}
+			i.validator = validator
+		case "count":
+			if err := v.Decode(&i.Count); err != nil {
+				return fmt.Errorf("line %v: %v", v.Line, err)
+			}
+			if i.Count < 1 {
+				return fmt.Errorf("line %v: count must be greater than zero", v.Line)
+			}
+		case "seed":
+			if err := v.Decode(&i.Seed); err != nil {
+				return fmt.Errorf("line %v: %v", v.Line, err)
+			}
+			i.hasSeed = true
+		default:
+			return fmt.Errorf("line %v: input generator field not recognised: %v", v.Line, k)
+		}
+	}
+	if (i.exec == nil) == (i.schema == nil) {
+		return fmt.Errorf("line %v: input generator requires exactly one of the fields mapping or json_schema", value.Line)
+	}
+	return nil
+}
+
+// generate a single random document.
+func (i *InputGenerator) generate(rnd *rand.Rand) (interface{}, error) {
+	if i.exec != nil {
+		res, err := i.exec.Exec(query.FunctionContext{
+			Maps:     i.exec.Maps(),
+			Vars:     map[string]interface{}{},
+			MsgBatch: message.QuickBatch(nil),
+		}.WithValue(nil))
+		if err != nil {
+			return nil, err
+		}
+		switch res.(type) {
+		case query.Delete, query.Nothing:
+			return nil, errors.New("generator mapping did not produce a document")
+		}
+		return res, nil
+	}
+	return (&schemaGenerator{rnd: rnd}).generate(i.schema, 0), nil
+}
+
+// seeded returns true if the documents generated are determined by the seed
+// of the random source provided to generate, which isn't the case for mappings
+// as Bloblang functions such as random_int use their own source.
+func (i *InputGenerator) seeded() bool {
+	return i.exec == nil
+}
+
+// valid returns true if a document is a valid input of the generator, which is
+// used in order to discard shrink candidates that violate the JSON Schema.
+// Documents of mapping based generators are not constrained.
+func (i *InputGenerator) valid(doc interface{}) bool {
+	if i.validator == nil {
+		return true
+	}
+	res, err := i.validator.Validate(gojsonschema.NewGoLoader(doc))
+	return err == nil && res.Valid()
+}
+
+//------------------------------------------------------------------------------
+
+// schemaGenerator produces random documents that satisfy a subset of JSON
+// Schema. Generated values are biased towards edge cases such as empty
+// strings, empty arrays, zero values and range boundaries as these are
+// commonly mishandled by mappings.
+type schemaGenerator struct {
+	rnd *rand.Rand
+}
+
+const (
+	schemaGenMaxDepth = 8
+	schemaGenMaxItems = 5
+	schemaGenMaxStr   = 12
+)
+
+var schemaGenAllTypes = []string{"null", "boolean", "integer", "number", "string", "array", "object"}
+
+var schemaGenChars = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 _-.,:/\"'\\\n\tüé日本🙂")
+
+func (g *schemaGenerator) edgeCase() bool {
+	return g.rnd.Intn(4) == 0
+}
+
+func (g *schemaGenerator) pickType(schema map[string]interface{}, depth int) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		if len(t) > 0 {
+			if s, ok := t[g.rnd.Intn(len(t))].(string); ok {
+				return s
+			}
+		}
+	}
+	if _, exists := schema["properties"]; exists {
+		return "object"
+	}
+	if _, exists := schema["items"]; exists {
+		return "array"
+	}
+	types := schemaGenAllTypes
+	if depth >= schemaGenMaxDepth {
+		types = types[:5]
+	}
+	return types[g.rnd.Intn(len(types))]
+}
+
+func schemaNumber(schema map[string]interface{}, key string) (float64, bool) {
+	v, ok := schema[key].(float64)
+	return v, ok
+}
+
+func (g *schemaGenerator) generate(schema map[string]interface{}, depth int) interface{} {
+	if c, exists := schema["const"]; exists {
+		return c
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[g.rnd.Intn(len(enum))]
+	}
+	for _, k := range []string{"anyOf", "oneOf"} {
+		if subs, ok := schema[k].([]interface{}); ok && len(subs) > 0 {
+			if sub, ok := subs[g.rnd.Intn(len(subs))].(map[string]interface{}); ok {
+				return g.generate(sub, depth)
+			}
+		}
+	}
+	if nullable, _ := schema["nullable"].(bool); nullable && g.edgeCase() {
+		return nil
+	}
+
+	switch g.pickType(schema, depth) {
+	case "null":
+		return nil
+	case "boolean":
+		return g.rnd.Intn(2) == 0
+	case "integer":
+		return g.genInteger(schema)
+	case "number":
+		return g.genNumber(schema)
+	case "string":
+		return g.genString(schema)
+	case "array":
+		return g.genArray(schema, depth)
+	case "object":
+		return g.genObject(schema, depth)
+	}
+	return nil
+}
+
+func (g *schemaGenerator) genInteger(schema map[string]interface{}) interface{} {
+	min, max := int64(-1000), int64(1000)
+	if v, ok := schemaNumber(schema, "minimum"); ok {
+		min = int64(math.Ceil(v))
+	}
+	if v, ok := schemaNumber(schema, "maximum"); ok {
+		max = int64(math.Floor(v))
+	}
+	if max < min {
+		max = min
+	}
+	if g.edgeCase() {
+		candidates := []int64{min, max}
+		if min <= 0 && max >= 0 {
+			candidates = append(candidates, 0)
+		}
+		return candidates[g.rnd.Intn(len(candidates))]
+	}
+	return min + g.rnd.Int63n(max-min+1)
+}
+
+func (g *schemaGenerator) genNumber(schema map[string]interface{}) interface{} {
+	min, max := -1000.0, 1000.0
+	if v, ok := schemaNumber(schema, "minimum"); ok {
+		min = v
+	}
+	if v, ok := schemaNumber(schema, "maximum"); ok {
+		max = v
+	}
+	if max < min {
+		max = min
+	}
+	if g.edgeCase() {
+		candidates := []float64{min, max}
+		if min <= 0 && max >= 0 {
+			candidates = append(candidates, 0)
+		}
+		return candidates[g.rnd.Intn(len(candidates))]
+	}
+	return min + g.rnd.Float64()*(max-min)
+}
+
+func (g *schemaGenerator) genString(schema map[string]interface{}) interface{} {
+	minLen, maxLen := 0, schemaGenMaxStr
+	if v, ok := schemaNumber(schema, "minLength"); ok {
+		minLen = int(v)
+	}
+	if v, ok := schemaNumber(schema, "maxLength"); ok {
+		maxLen = int(v)
+	}
+	if maxLen < minLen {
+		maxLen = minLen
+	}
+	l := minLen
+	if !g.edgeCase() {
+		l += g.rnd.Intn(maxLen - minLen + 1)
+	}
+	runes := make([]rune, l)
+	for i := range runes {
+		runes[i] = schemaGenChars[g.rnd.Intn(len(schemaGenChars))]
+	}
+	return string(runes)
+}
+
+func (g *schemaGenerator) genArray(schema map[string]interface{}, depth int) interface{} {
+	minItems, maxItems := 0, schemaGenMaxItems
+	if v, ok := schemaNumber(schema, "minItems"); ok {
+		minItems = int(v)
+	}
+	if v, ok := schemaNumber(schema, "maxItems"); ok {
+		maxItems = int(v)
+	}
+	if maxItems < minItems {
+		maxItems = minItems
+	}
+	l := minItems
+	if !g.edgeCase() {
+		l += g.rnd.Intn(maxItems - minItems + 1)
+	}
+	itemSchema, _ := schema["items"].(map[string]interface{})
+	arr := make([]interface{}, l)
+	for i := range arr {
+		arr[i] = g.generate(itemSchema, depth+1)
+	}
+	return arr
+}
+
+func (g *schemaGenerator) genObject(schema map[string]interface{}, depth int) interface{} {
+	required := map[string]struct{}{}
+	if reqs, ok := schema["required"].([]interface{}); ok {
+		for _, r := range reqs {
+			if s, ok := r.(string); ok {
+				required[s] = struct{}{}
+			}
+		}
+	}
+
+	props, _ := schema["properties"].(map[string]interface{})
+
+	// Iterate keys in a stable order so that seeds are reproducible.
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	obj := make(map[string]interface{}, len(keys))
+	for _, k := range keys {
+		if _, isRequired := required[k]; !isRequired && g.rnd.Intn(2) == 0 {
+			continue
+		}
+		propSchema, _ := props[k].(map[string]interface{})
+		obj[k] = g.generate(propSchema, depth+1)
+	}
+	return obj
+}
+
+//------------------------------------------------------------------------------
+
+// shrinkCandidates returns a list of values that are structurally smaller than
+// the provided value, with the most aggressive reductions first.
+func shrinkCandidates(v interface{}) []interface{} {
+	var candidates []interface{}
+	switch t := v.(type) {
+	case map[string]interface{}:
+		if len(t) == 0 {
+			return nil
+		}
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		candidates = append(candidates, map[string]interface{}{})
+		for _, k := range keys {
+			without := make(map[string]interface{}, len(t)-1)
+			for k2, v2 := range t {
+				if k2 != k {
+					without[k2] = v2
+				}
+			}
+			candidates = append(candidates, without)
+		}
+		for _, k := range keys {
+			for _, shrunk := range shrinkCandidates(t[k]) {
+				replaced := make(map[string]interface{}, len(t))
+				for k2, v2 := range t {
+					replaced[k2] = v2
+				}
+				replaced[k] = shrunk
+				candidates = append(candidates, replaced)
+			}
+		}
+	case []interface{}:
+		if len(t) == 0 {
+			return nil
+		}
+		candidates = append(candidates, []interface{}{})
+		for i := range t {
+			without := make([]interface{}, 0, len(t)-1)
+			without = append(without, t[:i]...)
+			without = append(without, t[i+1:]...)
+			candidates = append(candidates, without)
+		}
+		for i := range t {
+			for _, shrunk := range shrinkCandidates(t[i]) {
+				replaced := make([]interface{}, len(t))
+				copy(replaced, t)
+				replaced[i] = shrunk
+				candidates = append(candidates, replaced)
+			}
+		}
+	case string:
+		if t != "" {
+			candidates = append(candidates, "")
+			if r := []rune(t); len(r) > 1 {
+				candidates = append(candidates, string(r[:len(r)/2]))
+			}
+		}
+	case bool:
+		if t {
+			candidates = append(candidates, false)
+		}
+	case float64:
+		if t != 0 {
+			candidates = append(candidates, float64(0))
+			if t != math.Trunc(t) {
+				candidates = append(candidates, math.Trunc(t))
+			} else if h := math.Trunc(t / 2); h != 0 {
+				candidates = append(candidates, h)
+			}
+		}
+	case int64:
+		if t != 0 {
+			candidates = append(candidates, int64(0))
+			if h := t / 2; h != 0 {
+				candidates = append(candidates, h)
+			}
+		}
+	case uint64:
+		if t != 0 {
+			candidates = append(candidates, uint64(0))
+			if h := t / 2; h != 0 {
+				candidates = append(candidates, h)
+			}
+		}
+	}
+	return candidates
+}
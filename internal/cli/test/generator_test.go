@@ -0,0 +1,149 @@
+package test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
+)
+
+func TestInputGeneratorUnmarshal(t *testing.T) {
+	var gen InputGenerator
+	require.NoError(t, yaml.Unmarshal([]byte(`
+json_schema:
+  type: object
+  properties:
+    id:
+      type: string
+count: 10
+seed: 5
+`), &gen))
+	assert.Equal(t, 10, gen.Count)
+	assert.Equal(t, int64(5), gen.Seed)
+	assert.True(t, gen.hasSeed)
+	assert.NotNil(t, gen.schema)
+
+	gen = InputGenerator{}
+	require.NoError(t, yaml.Unmarshal([]byte(`mapping: 'root.id = uuid_v4()'`), &gen))
+	assert.Equal(t, 100, gen.Count)
+	assert.NotNil(t, gen.exec)
+
+	doc, err := gen.generate(rand.New(rand.NewSource(0)))
+	require.NoError(t, err)
+	assert.Contains(t, doc, "id")
+
+	for _, input := range []string{
+		`count: 10`,
+		`{ mapping: 'root = 5', json_schema: { type: object } }`,
+		`{ mapping: 'root = 5', count: 0 }`,
+		`{ mapping: 'root = 5', nope: 0 }`,
+		`mapping: 'root = '`,
+	} {
+		gen = InputGenerator{}
+		assert.Error(t, yaml.Unmarshal([]byte(input), &gen), input)
+	}
+}
+
+func TestSchemaGeneratorConformance(t *testing.T) {
+	var gen InputGenerator
+	require.NoError(t, yaml.Unmarshal([]byte(`
+json_schema:
+  type: object
+  required: [ id, tags, score, kind ]
+  properties:
+    id:
+      type: string
+      minLength: 2
+      maxLength: 4
+    tags:
+      type: array
+      maxItems: 3
+      items:
+        type: integer
+        minimum: 1
+        maximum: 10
+    score:
+      type: [ number, "null" ]
+      minimum: 0
+      maximum: 1
+    kind:
+      enum: [ foo, bar ]
+    nested:
+      type: object
+      properties:
+        flag:
+          type: boolean
+`), &gen))
+
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		doc, err := gen.generate(rnd)
+		require.NoError(t, err)
+
+		obj, ok := doc.(map[string]interface{})
+		require.True(t, ok)
+
+		id, ok := obj["id"].(string)
+		require.True(t, ok)
+		assert.GreaterOrEqual(t, len([]rune(id)), 2)
+		assert.LessOrEqual(t, len([]rune(id)), 4)
+
+		tags, ok := obj["tags"].([]interface{})
+		require.True(t, ok)
+		assert.LessOrEqual(t, len(tags), 3)
+		for _, tag := range tags {
+			v, ok := tag.(int64)
+			require.True(t, ok)
+			assert.GreaterOrEqual(t, v, int64(1))
+			assert.LessOrEqual(t, v, int64(10))
+		}
+
+		if score := obj["score"]; score != nil {
+			v, ok := score.(float64)
+			require.True(t, ok)
+			assert.GreaterOrEqual(t, v, 0.0)
+			assert.LessOrEqual(t, v, 1.0)
+		}
+
+		assert.Contains(t, []interface{}{"foo", "bar"}, obj["kind"])
+
+		if nested, exists := obj["nested"]; exists {
+			nestedObj, ok := nested.(map[string]interface{})
+			require.True(t, ok)
+			if flag, exists := nestedObj["flag"]; exists {
+				_, ok := flag.(bool)
+				assert.True(t, ok)
+			}
+		}
+	}
+}
+
+func TestShrinkCandidates(t *testing.T) {
+	assert.Nil(t, shrinkCandidates(nil))
+	assert.Nil(t, shrinkCandidates(""))
+	assert.Nil(t, shrinkCandidates(map[string]interface{}{}))
+	assert.Nil(t, shrinkCandidates([]interface{}{}))
+	assert.Equal(t, []interface{}{false}, shrinkCandidates(true))
+	assert.Equal(t, []interface{}{"", "fo"}, shrinkCandidates("fooo"))
+	assert.Equal(t, []interface{}{int64(0), int64(5)}, shrinkCandidates(int64(10)))
+	assert.Equal(t, []interface{}{float64(0), float64(1)}, shrinkCandidates(1.5))
+
+	assert.Equal(t, []interface{}{
+		[]interface{}{},
+		[]interface{}{"b"},
+		[]interface{}{"a"},
+		[]interface{}{"", "b"},
+		[]interface{}{"a", ""},
+	}, shrinkCandidates([]interface{}{"a", "b"}))
+
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{},
+		map[string]interface{}{"b": true},
+		map[string]interface{}{"a": int64(2)},
+		map[string]interface{}{"a": int64(0), "b": true},
+		map[string]interface{}{"a": int64(1), "b": true},
+		map[string]interface{}{"a": int64(2), "b": false},
+	}, shrinkCandidates(map[string]interface{}{"a": int64(2), "b": true}))
+}
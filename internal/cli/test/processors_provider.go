@@ -5,12 +5,14 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/Jeffail/gabs/v2"
 	yaml "gopkg.in/yaml.v3"
 
 	"github.com/benthosdev/benthos/v4/internal/bloblang/parser"
+	"github.com/benthosdev/benthos/v4/internal/bundle"
 	"github.com/benthosdev/benthos/v4/internal/component/metrics"
 	iprocessor "github.com/benthosdev/benthos/v4/internal/component/processor"
 	"github.com/benthosdev/benthos/v4/internal/config"
@@ -24,8 +26,10 @@ import (
 //------------------------------------------------------------------------------
 
 type cachedConfig struct {
-	mgr   manager.ResourceConfig
-	procs []processor.Config
+	mgr       manager.ResourceConfig
+	procs     []processor.Config
+	procPaths [][]string
+	env       *bundle.Environment
 }
 
 // ProcessorsProvider consumes a Benthos config and, given a JSON Pointer,
@@ -34,6 +38,7 @@ type ProcessorsProvider struct {
 	targetPath     string
 	resourcesPaths []string
 	cachedConfigs  map[string]cachedConfig
+	coverage       *coverageRecorder
 
 	logger log.Modular
 }
@@ -67,6 +72,14 @@ func OptProcessorsProviderSetLogger(logger log.Modular) func(*ProcessorsProvider
 	}
 }
 
+// optProcessorsProviderRecordCoverage sets a recorder of the processors within
+// the target file that are executed.
+func optProcessorsProviderRecordCoverage(rec *coverageRecorder) func(*ProcessorsProvider) {
+	return func(p *ProcessorsProvider) {
+		p.coverage = rec
+	}
+}
+
 //------------------------------------------------------------------------------
 
 // Provide attempts to extract an array of processors from a Benthos config. If
@@ -114,14 +127,18 @@ func (p *ProcessorsProvider) ProvideBloblang(pathStr string) ([]iprocessor.V1, e
 //------------------------------------------------------------------------------
 
 func (p *ProcessorsProvider) initProcs(confs cachedConfig) ([]iprocessor.V1, error) {
-	mgr, err := manager.NewV2(confs.mgr, mock.NewManager(), p.logger, metrics.Noop())
+	var opts []manager.OptFunc
+	if confs.env != nil {
+		opts = append(opts, manager.OptSetEnvironment(confs.env))
+	}
+	mgr, err := manager.NewV2(confs.mgr, mock.NewManager(), p.logger, metrics.Noop(), opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialise resources: %v", err)
 	}
 
 	procs := make([]iprocessor.V1, len(confs.procs))
 	for i, conf := range confs.procs {
-		if procs[i], err = processor.New(conf, mgr, p.logger, metrics.Noop()); err != nil {
+		if procs[i], err = processor.New(conf, mgr.IntoPath(confs.procPaths[i]...), p.logger, metrics.Noop()); err != nil {
 			return nil, fmt.Errorf("failed to initialise processor index '%v': %v", i, err)
 		}
 	}
@@ -212,6 +229,7 @@ func (p *ProcessorsProvider) getConfs(jsonPtr string, environment map[string]str
 	for k, v := range mocks {
 		remainingMocks[k] = v
 	}
+	var mockedPaths []string
 
 	configBytes, _, err := config.ReadFileEnvSwap(targetPath)
 	if err != nil {
@@ -223,6 +241,7 @@ func (p *ProcessorsProvider) getConfs(jsonPtr string, environment map[string]str
 		return confs, fmt.Errorf("failed to parse config file '%v': %v", targetPath, err)
 	}
 
+	targetResources := len(mgrWrapper.ResourceProcessors)
 	for _, path := range p.resourcesPaths {
 		resourceBytes, _, err := config.ReadFileEnvSwap(path)
 		if err != nil {
@@ -258,6 +277,7 @@ func (p *ProcessorsProvider) getConfs(jsonPtr string, environment map[string]str
 		if err = confSpec.SetYAMLPath(nil, root, &v, mockPathSlice...); err != nil {
 			return confs, fmt.Errorf("failed to set mock '%v': %w", k, err)
 		}
+		mockedPaths = append(mockedPaths, jsonPointerFromPath(mockPathSlice))
 		delete(remainingMocks, k)
 	}
 
@@ -272,6 +292,7 @@ func (p *ProcessorsProvider) getConfs(jsonPtr string, environment map[string]str
 			if err = confSpec.SetYAMLPath(nil, root, &v, mockPathSlice...); err != nil {
 				return confs, fmt.Errorf("failed to set mock '%v': %w", k, err)
 			}
+			mockedPaths = append(mockedPaths, jsonPointerFromPath(mockPathSlice))
 			delete(remainingMocks, k)
 		}
 	}
@@ -288,12 +309,26 @@ func (p *ProcessorsProvider) getConfs(jsonPtr string, environment map[string]str
 		if err = root.Decode(&confs.procs); err != nil {
 			return confs, fmt.Errorf("failed to resolve case processors from '%v': %v", targetPath, err)
 		}
+		for i := range confs.procs {
+			confs.procPaths = append(confs.procPaths, append(pathSlice[:len(pathSlice):len(pathSlice)], strconv.Itoa(i)))
+		}
 	} else {
 		var procConf processor.Config
 		if err = root.Decode(&procConf); err != nil {
 			return confs, fmt.Errorf("failed to resolve case processors from '%v': %v", targetPath, err)
 		}
 		confs.procs = append(confs.procs, procConf)
+		confs.procPaths = append(confs.procPaths, pathSlice)
+	}
+
+	// Only processors of the file being tested are recorded for coverage,
+	// where processor resources are identified by their label.
+	if p.coverage != nil && filepath.Clean(targetPath) == filepath.Clean(p.targetPath) {
+		resourceIndexes := map[string]int{}
+		for i := 0; i < targetResources; i++ {
+			resourceIndexes[mgrWrapper.ResourceProcessors[i].Label] = i
+		}
+		confs.env = p.coverage.environment(bundle.GlobalEnvironment, resolveCoveragePath(mockedPaths, resourceIndexes))
 	}
 
 	p.cachedConfigs[cacheKey] = confs
@@ -0,0 +1,125 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	iprocessor "github.com/benthosdev/benthos/v4/internal/component/processor"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/old/processor"
+)
+
+// The maximum number of candidates that are tested whilst shrinking a failed
+// input down to a minimal counterexample.
+const maxShrinkAttempts = 1000
+
+func generatedPart(doc interface{}) *message.Part {
+	part := message.NewPart(nil)
+	switch t := doc.(type) {
+	case string:
+		part.Set([]byte(t))
+	case []byte:
+		part.Set(t)
+	default:
+		part.SetJSON(doc)
+	}
+	return part
+}
+
+// checkGenerated executes processors against a generated document and returns
+// the reasons for any invariant violations.
+func (c *Case) checkGenerated(dir string, procSet []iprocessor.V1, doc interface{}) (reasons []string) {
+	inputMsg := message.QuickBatch(nil)
+	inputMsg.Append(generatedPart(doc))
+
+	outputBatches, result := processor.ExecuteAll(procSet, inputMsg)
+	if result != nil {
+		reasons = append(reasons, fmt.Sprintf("processors resulted in error: %v", result))
+	}
+	for i, v := range outputBatches {
+		_ = v.Iter(func(i2 int, part *message.Part) error {
+			if procErr := processor.GetFail(part); len(procErr) > 0 {
+				reasons = append(reasons, fmt.Sprintf("batch %v message %v: message flagged with processing error: %v", i, i2, red(procErr)))
+			}
+			for _, condErr := range c.OutputInvariants.checkAllFrom(dir, false, part) {
+				reasons = append(reasons, fmt.Sprintf("batch %v message %v: %v", i, i2, condErr))
+			}
+			return nil
+		})
+	}
+	return
+}
+
+func (c *Case) executeGenerated(dir string, procSet []iprocessor.V1) (failures []CaseFailure, err error) {
+	if len(c.InputBatch) > 0 || len(c.OutputBatches) > 0 {
+		return nil, errors.New("input_generator cannot be combined with input_batch or output_batches")
+	}
+
+	seed := c.InputGenerator.Seed
+	if !c.InputGenerator.hasSeed {
+		seed = time.Now().UnixNano()
+	}
+	rnd := rand.New(rand.NewSource(seed))
+
+	for i := 0; i < c.InputGenerator.Count; i++ {
+		var doc interface{}
+		if doc, err = c.InputGenerator.generate(rnd); err != nil {
+			return nil, fmt.Errorf("failed to generate input %v: %w", i, err)
+		}
+
+		reasons := c.checkGenerated(dir, procSet, doc)
+		if len(reasons) == 0 {
+			continue
+		}
+
+		// Greedily shrink the failing input by accepting the first smaller
+		// candidate that is still a valid input and still fails until no
+		// candidates remain.
+		shrinks, attempts := 0, 0
+	shrinkLoop:
+		for attempts < maxShrinkAttempts {
+			for _, candidate := range shrinkCandidates(doc) {
+				if !c.InputGenerator.valid(candidate) {
+					continue
+				}
+				if attempts++; attempts > maxShrinkAttempts {
+					break shrinkLoop
+				}
+				if candidateReasons := c.checkGenerated(dir, procSet, candidate); len(candidateReasons) > 0 {
+					doc, reasons = candidate, candidateReasons
+					shrinks++
+					continue shrinkLoop
+				}
+			}
+			break
+		}
+
+		docBytes := generatedPart(doc).Get()
+		if _, isStr := doc.(string); isStr {
+			docBytes, _ = json.Marshal(doc)
+		}
+
+		// The seed is only reported when it can be used to reproduce the run.
+		var seedStr string
+		if c.InputGenerator.seeded() {
+			seedStr = fmt.Sprintf(" (seed %v)", seed)
+		}
+		reason := fmt.Sprintf(
+			"property failed on generated input %v of %v%v, minimal counterexample after %v shrinks: %s",
+			i+1, c.InputGenerator.Count, seedStr, shrinks, blue(string(docBytes)),
+		)
+		for _, r := range reasons {
+			reason += "\n" + r
+		}
+		failures = append(failures, CaseFailure{
+			Name:     c.Name,
+			TestLine: c.line,
+			Reason:   reason,
+		})
+		return failures, nil
+	}
+	return nil, nil
+}
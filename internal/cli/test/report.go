@@ -0,0 +1,404 @@
+package test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+//------------------------------------------------------------------------------
+
+// TargetResult encapsulates the outcome of executing the test definition of a
+// single config file.
+type TargetResult struct {
+	ConfigPath     string
+	DefinitionPath string
+	Lints          []string
+	Cases          []CaseResult
+	Duration       time.Duration
+	Coverage       []ProcessorCoverage
+}
+
+// Failed returns true if the target has any lint errors or failed cases.
+func (t TargetResult) Failed() bool {
+	if len(t.Lints) > 0 {
+		return true
+	}
+	for _, c := range t.Cases {
+		if len(c.Failures) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (t TargetResult) counts() (tests, failures, skipped int) {
+	for _, c := range t.Cases {
+		tests++
+		if c.Skipped {
+			skipped++
+		} else if len(c.Failures) > 0 {
+			failures++
+		}
+	}
+	return
+}
+
+func (t TargetResult) coveredCount() (covered int) {
+	for _, p := range t.Coverage {
+		if p.Covered {
+			covered++
+		}
+	}
+	return
+}
+
+//------------------------------------------------------------------------------
+
+// reporter writes the results of test targets in a particular format. The
+// method target is called as each target completes and finish is called once
+// all targets have been executed.
+type reporter interface {
+	target(res TargetResult)
+	finish(results []TargetResult) error
+}
+
+// ReportFormats lists the formats supported for reporting test results.
+var ReportFormats = []string{"text", "json", "junit"}
+
+func newReporter(format string, w io.Writer) (reporter, error) {
+	switch format {
+	case "", "text":
+		return &textReporter{w: w}, nil
+	case "json":
+		return &jsonReporter{w: w}, nil
+	case "junit":
+		return &junitReporter{w: w}, nil
+	}
+	return nil, fmt.Errorf("format not recognised: %v, expected one of: %v", format, strings.Join(ReportFormats, ", "))
+}
+
+var colorEscapeRegexp = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// stripColor removes terminal color escape sequences from failure reasons, as
+// structured reports shouldn't contain them.
+func stripColor(s string) string {
+	return colorEscapeRegexp.ReplaceAllString(s, "")
+}
+
+//------------------------------------------------------------------------------
+
+type textReporter struct {
+	w io.Writer
+}
+
+func (t *textReporter) target(res TargetResult) {
+	if res.Failed() {
+		fmt.Fprintf(t.w, "Test '%v' %v\n", res.ConfigPath, red("failed"))
+	} else {
+		fmt.Fprintf(t.w, "Test '%v' %v\n", res.ConfigPath, green("succeeded"))
+	}
+}
+
+func (t *textReporter) finish(results []TargetResult) error {
+	var fails []TargetResult
+	for _, res := range results {
+		if res.Failed() {
+			fails = append(fails, res)
+		}
+	}
+	if len(fails) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(t.w, "\nFailures:\n\n")
+	for i, fail := range fails {
+		if i > 0 {
+			fmt.Fprintln(t.w, "")
+		}
+		fmt.Fprintf(t.w, "--- %v ---\n\n", fail.ConfigPath)
+		for _, lint := range fail.Lints {
+			fmt.Fprintf(t.w, "Lint: %v\n", lint)
+		}
+
+		var failCases []CaseFailure
+		for _, c := range fail.Cases {
+			failCases = append(failCases, c.Failures...)
+		}
+		if len(failCases) > 0 {
+			if len(fail.Lints) > 0 {
+				fmt.Fprintln(t.w, "")
+			}
+			var namePrev string
+			for i, fail := range failCases {
+				if namePrev != fail.Name {
+					if i > 0 {
+						fmt.Fprintln(t.w, "")
+					}
+					fmt.Fprintf(t.w, "%v [line %v]:\n", fail.Name, fail.TestLine)
+					namePrev = fail.Name
+				}
+				fmt.Fprintln(t.w, fail.Reason)
+			}
+		}
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+type jsonReporter struct {
+	w io.Writer
+}
+
+type jsonCase struct {
+	Name            string   `json:"name"`
+	Line            int      `json:"line"`
+	Status          string   `json:"status"`
+	DurationSeconds float64  `json:"duration_seconds"`
+	Failures        []string `json:"failures,omitempty"`
+}
+
+type jsonProcessorCoverage struct {
+	Path    string `json:"path"`
+	Type    string `json:"type"`
+	Label   string `json:"label,omitempty"`
+	Covered bool   `json:"covered"`
+}
+
+type jsonCoverage struct {
+	Total      int                     `json:"total"`
+	Covered    int                     `json:"covered"`
+	Processors []jsonProcessorCoverage `json:"processors"`
+}
+
+type jsonTarget struct {
+	ConfigPath      string       `json:"config_path"`
+	DefinitionPath  string       `json:"definition_path"`
+	Status          string       `json:"status"`
+	DurationSeconds float64      `json:"duration_seconds"`
+	Lints           []string     `json:"lints,omitempty"`
+	Cases           []jsonCase   `json:"cases"`
+	Coverage        jsonCoverage `json:"coverage"`
+}
+
+type jsonReport struct {
+	Tests    int          `json:"tests"`
+	Failures int          `json:"failures"`
+	Skipped  int          `json:"skipped"`
+	Lints    int          `json:"lints"`
+	Targets  []jsonTarget `json:"targets"`
+}
+
+func statusOf(failed, skipped bool) string {
+	if skipped {
+		return "skipped"
+	}
+	if failed {
+		return "failed"
+	}
+	return "passed"
+}
+
+func (j *jsonReporter) target(res TargetResult) {}
+
+func (j *jsonReporter) finish(results []TargetResult) error {
+	report := jsonReport{
+		Targets: []jsonTarget{},
+	}
+	for _, res := range results {
+		tests, failures, skipped := res.counts()
+		report.Tests += tests
+		report.Failures += failures
+		report.Skipped += skipped
+		report.Lints += len(res.Lints)
+
+		target := jsonTarget{
+			ConfigPath:      res.ConfigPath,
+			DefinitionPath:  res.DefinitionPath,
+			Status:          statusOf(res.Failed(), false),
+			DurationSeconds: res.Duration.Seconds(),
+			Lints:           res.Lints,
+			Cases:           []jsonCase{},
+			Coverage: jsonCoverage{
+				Total:      len(res.Coverage),
+				Covered:    res.coveredCount(),
+				Processors: []jsonProcessorCoverage{},
+			},
+		}
+		for _, c := range res.Cases {
+			jCase := jsonCase{
+				Name:            c.Name,
+				Line:            c.TestLine,
+				Status:          statusOf(len(c.Failures) > 0, c.Skipped),
+				DurationSeconds: c.Duration.Seconds(),
+			}
+			for _, f := range c.Failures {
+				jCase.Failures = append(jCase.Failures, stripColor(f.Reason))
+			}
+			target.Cases = append(target.Cases, jCase)
+		}
+		for _, p := range res.Coverage {
+			target.Coverage.Processors = append(target.Coverage.Processors, jsonProcessorCoverage(p))
+		}
+		report.Targets = append(report.Targets, target)
+	}
+
+	enc := json.NewEncoder(j.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+//------------------------------------------------------------------------------
+
+type junitReporter struct {
+	w io.Writer
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Content string `xml:",chardata"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitSuite struct {
+	Name       string          `xml:"name,attr"`
+	File       string          `xml:"file,attr,omitempty"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitCase     `xml:"testcase"`
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+func junitTime(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+func (j *junitReporter) target(res TargetResult) {}
+
+func (j *junitReporter) finish(results []TargetResult) error {
+	suites := junitSuites{
+		Name: "benthos",
+	}
+
+	var totalTime time.Duration
+	for _, res := range results {
+		tests, failures, skipped := res.counts()
+		suites.Tests += tests
+		suites.Failures += failures
+		suites.Skipped += skipped
+		totalTime += res.Duration
+
+		suite := junitSuite{
+			Name:     res.ConfigPath,
+			File:     res.DefinitionPath,
+			Tests:    tests,
+			Failures: failures,
+			Skipped:  skipped,
+			Time:     junitTime(res.Duration),
+			Properties: []junitProperty{
+				{Name: "processors.total", Value: fmt.Sprintf("%v", len(res.Coverage))},
+				{Name: "processors.covered", Value: fmt.Sprintf("%v", res.coveredCount())},
+			},
+		}
+		for _, p := range res.Coverage {
+			status := "uncovered"
+			if p.Covered {
+				status = "covered"
+			}
+			suite.Properties = append(suite.Properties, junitProperty{
+				Name:  "processor:" + p.Path,
+				Value: status,
+			})
+		}
+
+		if len(res.Lints) > 0 {
+			suite.Properties = append(suite.Properties, junitProperty{
+				Name:  "lints",
+				Value: fmt.Sprintf("%v", len(res.Lints)),
+			})
+
+			// Lint errors fail a target, and so they're reported as a failed
+			// case in order for the target to be counted as a failure.
+			suite.Tests++
+			suite.Failures++
+			suites.Tests++
+			suites.Failures++
+			suite.Cases = append(suite.Cases, junitCase{
+				Name:      "lint",
+				ClassName: res.ConfigPath,
+				File:      res.ConfigPath,
+				Time:      junitTime(0),
+				Failure: &junitMessage{
+					Message: fmt.Sprintf("%v lint errors", len(res.Lints)),
+					Content: strings.Join(res.Lints, "\n"),
+				},
+			})
+		}
+		for _, c := range res.Cases {
+			jCase := junitCase{
+				Name:      c.Name,
+				ClassName: res.ConfigPath,
+				File:      res.DefinitionPath,
+				Line:      c.TestLine,
+				Time:      junitTime(c.Duration),
+			}
+			if c.Skipped {
+				jCase.Skipped = &junitMessage{}
+			} else if len(c.Failures) > 0 {
+				reasons := make([]string, 0, len(c.Failures))
+				for _, f := range c.Failures {
+					reasons = append(reasons, stripColor(f.Reason))
+				}
+				jCase.Failure = &junitMessage{
+					Message: fmt.Sprintf("%v failed conditions", len(c.Failures)),
+					Content: strings.Join(reasons, "\n"),
+				}
+			}
+			suite.Cases = append(suite.Cases, jCase)
+		}
+		suites.Suites = append(suites.Suites, suite)
+	}
+	suites.Time = junitTime(totalTime)
+
+	if _, err := io.WriteString(j.w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(j.w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(j.w, "\n")
+	return err
+}
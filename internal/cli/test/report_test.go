@@ -0,0 +1,126 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testReportResults() []TargetResult {
+	return []TargetResult{
+		{
+			ConfigPath:     "foo.yaml",
+			DefinitionPath: "foo_benthos_test.yaml",
+			Duration:       time.Millisecond * 20,
+			Cases: []CaseResult{
+				{Name: "passes", TestLine: 3, Duration: time.Millisecond * 5},
+				{
+					Name: "fails", TestLine: 10, Duration: time.Millisecond * 15,
+					Failures: []CaseFailure{
+						{Name: "fails", TestLine: 10, Reason: "batch 0 message 0: content_equals: content mismatch"},
+						{Name: "fails", TestLine: 10, Reason: "batch 0 message 0: \x1b[31mfailed\x1b[0m"},
+					},
+				},
+				{Name: "skips", TestLine: 20, Skipped: true},
+			},
+			Coverage: []ProcessorCoverage{
+				{Path: "/pipeline/processors/0", Type: "bloblang", Covered: true},
+				{Path: "/pipeline/processors/1", Type: "http", Label: "fetch"},
+			},
+		},
+		{
+			ConfigPath:     "bar.yaml",
+			DefinitionPath: "bar.yaml",
+			Lints:          []string{"line 2: field meow not recognised"},
+		},
+	}
+}
+
+func TestReportJSON(t *testing.T) {
+	var buf bytes.Buffer
+	rep, err := newReporter("json", &buf)
+	require.NoError(t, err)
+	require.NoError(t, rep.finish(testReportResults()))
+
+	var report map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+
+	assert.Equal(t, float64(3), report["tests"])
+	assert.Equal(t, float64(1), report["failures"])
+	assert.Equal(t, float64(1), report["skipped"])
+	assert.Equal(t, float64(1), report["lints"])
+
+	targets := report["targets"].([]interface{})
+	require.Len(t, targets, 2)
+
+	foo := targets[0].(map[string]interface{})
+	assert.Equal(t, "failed", foo["status"])
+	assert.Equal(t, "foo_benthos_test.yaml", foo["definition_path"])
+
+	cases := foo["cases"].([]interface{})
+	require.Len(t, cases, 3)
+	assert.Equal(t, "passed", cases[0].(map[string]interface{})["status"])
+	assert.Equal(t, 0.005, cases[0].(map[string]interface{})["duration_seconds"])
+	assert.Equal(t, "failed", cases[1].(map[string]interface{})["status"])
+	assert.Equal(t, []interface{}{"batch 0 message 0: content_equals: content mismatch", "batch 0 message 0: failed"}, cases[1].(map[string]interface{})["failures"])
+	assert.Equal(t, "skipped", cases[2].(map[string]interface{})["status"])
+
+	coverage := foo["coverage"].(map[string]interface{})
+	assert.Equal(t, float64(2), coverage["total"])
+	assert.Equal(t, float64(1), coverage["covered"])
+
+	bar := targets[1].(map[string]interface{})
+	assert.Equal(t, "failed", bar["status"])
+	assert.Equal(t, []interface{}{"line 2: field meow not recognised"}, bar["lints"])
+}
+
+func TestReportJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	rep, err := newReporter("junit", &buf)
+	require.NoError(t, err)
+	require.NoError(t, rep.finish(testReportResults()))
+
+	var suites junitSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &suites))
+
+	assert.Equal(t, 4, suites.Tests)
+	assert.Equal(t, 2, suites.Failures)
+	assert.Equal(t, 1, suites.Skipped)
+	require.Len(t, suites.Suites, 2)
+
+	foo := suites.Suites[0]
+	assert.Equal(t, "foo.yaml", foo.Name)
+	assert.Equal(t, "0.020", foo.Time)
+	assert.Contains(t, foo.Properties, junitProperty{Name: "processors.covered", Value: "1"})
+	assert.Contains(t, foo.Properties, junitProperty{Name: "processor:/pipeline/processors/1", Value: "uncovered"})
+
+	require.Len(t, foo.Cases, 3)
+	assert.Nil(t, foo.Cases[0].Failure)
+	assert.Equal(t, "foo_benthos_test.yaml", foo.Cases[0].File)
+	assert.Equal(t, 3, foo.Cases[0].Line)
+	require.NotNil(t, foo.Cases[1].Failure)
+	assert.Equal(t, "batch 0 message 0: content_equals: content mismatch\nbatch 0 message 0: failed", foo.Cases[1].Failure.Content)
+	assert.NotNil(t, foo.Cases[2].Skipped)
+
+	bar := suites.Suites[1]
+	assert.Equal(t, 1, bar.Tests)
+	assert.Equal(t, 1, bar.Failures)
+	assert.Contains(t, bar.Properties, junitProperty{Name: "lints", Value: "1"})
+
+	require.Len(t, bar.Cases, 1)
+	assert.Equal(t, "lint", bar.Cases[0].Name)
+	assert.Equal(t, "bar.yaml", bar.Cases[0].File)
+	require.NotNil(t, bar.Cases[0].Failure)
+	assert.Equal(t, "1 lint errors", bar.Cases[0].Failure.Message)
+	assert.Equal(t, "line 2: field meow not recognised", bar.Cases[0].Failure.Content)
+}
+
+func TestReportUnknownFormat(t *testing.T) {
+	_, err := newReporter("nope", &bytes.Buffer{})
+	require.Error(t, err)
+}
@@ -1,6 +1,9 @@
 package processor
 
 import (
+	"context"
+	"time"
+
 	"github.com/benthosdev/benthos/v4/internal/message"
 	"github.com/benthosdev/benthos/v4/internal/tracing"
 )
@@ -30,3 +33,86 @@ func MarkErr(part *message.Part, span *tracing.Span, err error) {
 func GetFail(part *message.Part) string {
 	return part.MetaGet(message.FailFlagKey)
 }
+
+type failSourceKey struct{}
+
+type failSource struct {
+	path string
+	err  string
+}
+
+// GetFailSource returns the path of the processor that flagged the current
+// error of a message part, or an empty string if the part has not failed or
+// the processor that flagged it is unknown.
+func GetFailSource(part *message.Part) string {
+	fail := GetFail(part)
+	if fail == "" {
+		return ""
+	}
+	ctx := part.GetContext()
+	if ctx == nil {
+		return ""
+	}
+	if src, ok := ctx.Value(failSourceKey{}).(failSource); ok && src.err == fail {
+		return src.path
+	}
+	return ""
+}
+
+// WithFailSource wraps a processor so that messages it flags with a new error
+// are annotated with the path of the processor, which can then be obtained
+// with GetFailSource.
+func WithFailSource(path string, p V1) V1 {
+	return &failSourceProcessor{path: path, wrapped: p}
+}
+
+type failSourceProcessor struct {
+	path    string
+	wrapped V1
+}
+
+func (f *failSourceProcessor) ProcessMessage(msg *message.Batch) ([]*message.Batch, error) {
+	msgs, res := f.wrapped.ProcessMessage(msg)
+	for _, m := range msgs {
+		var parts []*message.Part
+		_ = m.Iter(func(i int, p *message.Part) error {
+			fail := GetFail(p)
+			if fail == "" || GetFailSource(p) != "" {
+				return nil
+			}
+			if parts == nil {
+				parts = make([]*message.Part, m.Len())
+				_ = m.Iter(func(j int, p *message.Part) error {
+					parts[j] = p
+					return nil
+				})
+			}
+			ctx := p.GetContext()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			parts[i] = p.WithContext(context.WithValue(ctx, failSourceKey{}, failSource{
+				path: f.path,
+				err:  fail,
+			}))
+			return nil
+		})
+		if parts != nil {
+			m.SetAll(parts)
+		}
+	}
+	return msgs, res
+}
+
+// Unwrap returns the processor wrapped by this one.
+func (f *failSourceProcessor) Unwrap() V1 {
+	return f.wrapped
+}
+
+func (f *failSourceProcessor) CloseAsync() {
+	f.wrapped.CloseAsync()
+}
+
+func (f *failSourceProcessor) WaitForClose(timeout time.Duration) error {
+	return f.wrapped.WaitForClose(timeout)
+}
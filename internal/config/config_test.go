@@ -21,7 +21,7 @@ func TestSetOverridesOnNothing(t *testing.T) {
 		"output.type=amqp_0_9",
 	))
 
-	lints, err := rdr.Read(&conf)
+	lints, _, err := rdr.Read(&conf)
 	require.NoError(t, err)
 	assert.Empty(t, lints)
 
@@ -62,7 +62,7 @@ func TestSetOverrideErrors(t *testing.T) {
 		conf := config.New()
 		rdr := config.NewReader("", nil, config.OptAddOverrides(test.input))
 
-		_, err := rdr.Read(&conf)
+		_, _, err := rdr.Read(&conf)
 		assert.Contains(t, err.Error(), test.err)
 	}
 }
@@ -88,7 +88,7 @@ input:
 		"output.kafka.topic=foobar",
 	))
 
-	lints, err := rdr.Read(&conf)
+	lints, _, err := rdr.Read(&conf)
 	require.NoError(t, err)
 	assert.Empty(t, lints)
 
@@ -140,7 +140,7 @@ tests:
 	conf := config.New()
 	rdr := config.NewReader(fullPath, []string{resourceOnePath, resourceTwoPath, resourceThreePath})
 
-	lints, err := rdr.Read(&conf)
+	lints, _, err := rdr.Read(&conf)
 	require.NoError(t, err)
 	assert.Empty(t, lints)
 
@@ -190,7 +190,7 @@ cache_resources:
 	conf := config.New()
 	rdr := config.NewReader(fullPath, []string{resourceOnePath, resourceTwoPath})
 
-	lints, err := rdr.Read(&conf)
+	lints, _, err := rdr.Read(&conf)
 	require.NoError(t, err)
 	require.Len(t, lints, 3)
 	assert.Contains(t, lints[0], "/main.yaml: line 3: field meow1 ")
@@ -209,3 +209,29 @@ cache_resources:
 	assert.Equal(t, "bar", conf.ResourceCaches[1].Label)
 	assert.Equal(t, "memory", conf.ResourceCaches[1].Type)
 }
+
+func TestReadLintWarnings(t *testing.T) {
+	dir := t.TempDir()
+
+	fullPath := filepath.Join(dir, "main.yaml")
+	require.NoError(t, os.WriteFile(fullPath, []byte(`
+input:
+  generate:
+    mapping: |
+      let unused = "hello"
+      root = "hello"
+output:
+  drop: {}
+`), 0o644))
+
+	rdr := config.NewReader(fullPath, nil)
+
+	conf := config.New()
+	lints, warnings, err := rdr.Read(&conf)
+	require.NoError(t, err)
+	assert.Empty(t, lints)
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "line 5: variable unused is declared but never used")
+
+	assert.Equal(t, "generate", conf.Input.Type)
+}
@@ -10,6 +10,7 @@ import (
 var (
 	envRegex        = regexp.MustCompile(`\${[0-9A-Za-z_.]+(:((\${[^}]+})|[^}])+)?}`)
 	escapedEnvRegex = regexp.MustCompile(`\${({[0-9A-Za-z_.]+(:((\${[^}]+})|[^}])+)?})}`)
+	secretPrefix    = []byte("${secret:")
 )
 
 // ReplaceEnvVariables will search a blob of data for the pattern `${FOO:bar}`,
@@ -21,8 +22,14 @@ var (
 // respective environment variable will be read and will replace the pattern. If
 // the environment variable is empty or does not exist then either the default
 // value is used or the field will be left empty.
+//
+// Secret references of the form `${secret:<provider>:<key>}` are left intact,
+// as they are resolved at the point at which components are constructed.
 func ReplaceEnvVariables(inBytes []byte) []byte {
 	replaced := envRegex.ReplaceAllFunc(inBytes, func(content []byte) []byte {
+		if bytes.HasPrefix(content, secretPrefix) {
+			return content
+		}
 		var value string
 		if len(content) > 3 {
 			if colonIndex := bytes.IndexByte(content, ':'); colonIndex == -1 {
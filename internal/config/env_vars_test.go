@@ -33,6 +33,7 @@ func TestEnvSwapping(t *testing.T) {
 		"foo ${{BENTHOS_TEST_FOO:bar}} baz":                                        "foo ${BENTHOS_TEST_FOO:bar} baz",
 		"foo ${{BENTHOS_TEST_FOO}} baz":                                            "foo ${BENTHOS_TEST_FOO} baz",
 		"foo ${BENTHOS.TEST.BAR} baz":                                              "foo test\\nbar baz",
+		"foo ${secret:file:/run/secrets/foo} baz":                                  "foo ${secret:file:/run/secrets/foo} baz",
 	}
 
 	for in, exp := range tests {
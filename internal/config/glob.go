@@ -0,0 +1,31 @@
+package config
+
+import (
+	"github.com/benthosdev/benthos/v4/internal/config/remote"
+	ifilepath "github.com/benthosdev/benthos/v4/internal/filepath"
+)
+
+// Globs attempts to expand a list of config paths, which may include glob
+// patterns, to a list of explicit file paths. Paths that are URLs are returned
+// as they are. The paths are de-duplicated but are not sorted.
+func Globs(paths []string) ([]string, error) {
+	var expandedPaths []string
+	seenPaths := map[string]struct{}{}
+
+	for _, path := range paths {
+		globbed := []string{path}
+		if !remote.IsURL(path) {
+			var err error
+			if globbed, err = ifilepath.Globs(globbed); err != nil {
+				return nil, err
+			}
+		}
+		for _, gPath := range globbed {
+			if _, seen := seenPaths[gPath]; !seen {
+				expandedPaths = append(expandedPaths, gPath)
+				seenPaths[gPath] = struct{}{}
+			}
+		}
+	}
+	return expandedPaths, nil
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobsURLs(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yaml"), nil, 0o644))
+
+	paths, err := Globs([]string{
+		"https://example.com/config.yaml?version=*",
+		filepath.Join(dir, "*.yaml"),
+		"s3://bucket/resources/*.yaml",
+		"https://example.com/config.yaml?version=*",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"https://example.com/config.yaml?version=*",
+		filepath.Join(dir, "a.yaml"),
+		"s3://bucket/resources/*.yaml",
+	}, paths)
+}
@@ -3,11 +3,11 @@ package config
 import (
 	"bytes"
 	"fmt"
-	"os"
 	"unicode/utf8"
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/benthosdev/benthos/v4/internal/config/remote"
 	"github.com/benthosdev/benthos/v4/internal/docs"
 )
 
@@ -36,6 +36,17 @@ func ReadFileLinted(path string, rejectDeprecated bool, config *Type) ([]string,
 // LintBytes attempts to report errors within a user config. Returns a slice of
 // lint results.
 func LintBytes(ctx docs.LintContext, rawBytes []byte) ([]string, error) {
+	return lintBytesOfLevel(ctx, rawBytes, docs.LintError)
+}
+
+// LintWarningBytes attempts to report warnings within a user config, which
+// describe potential problems that do not prevent the config from being run.
+// Returns a slice of lint results.
+func LintWarningBytes(ctx docs.LintContext, rawBytes []byte) ([]string, error) {
+	return lintBytesOfLevel(ctx, rawBytes, docs.LintWarning)
+}
+
+func lintBytesOfLevel(ctx docs.LintContext, rawBytes []byte, level docs.LintLevel) ([]string, error) {
 	if bytes.HasPrefix(rawBytes, []byte("# BENTHOS LINT DISABLE")) {
 		return nil, nil
 	}
@@ -47,19 +58,19 @@ func LintBytes(ctx docs.LintContext, rawBytes []byte) ([]string, error) {
 
 	var lintStrs []string
 	for _, lint := range Spec().LintYAML(ctx, &rawNode) {
-		if lint.Level == docs.LintError {
+		if lint.Level == level {
 			lintStrs = append(lintStrs, fmt.Sprintf("line %v: %v", lint.Line, lint.What))
 		}
 	}
 	return lintStrs, nil
 }
 
-// ReadFileEnvSwap reads a file and replaces any environment variable
+// ReadFileEnvSwap reads a file, which can also be a URL, and replaces any environment variable
 // interpolations before returning the contents. Linting errors are returned if
 // the file has an unexpected higher level format, such as invalid utf-8
 // encoding.
 func ReadFileEnvSwap(path string) (configBytes []byte, lints []string, err error) {
-	configBytes, err = os.ReadFile(path)
+	configBytes, err = remote.ReadFile(path)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -71,3 +82,17 @@ func ReadFileEnvSwap(path string) (configBytes []byte, lints []string, err error
 	configBytes = ReplaceEnvVariables(configBytes)
 	return configBytes, lints, nil
 }
+
+// formatLints converts lints into messages with a prefix, separating errors,
+// which are counted towards strict mode, from warnings, which are only logged.
+func formatLints(prefix string, lints []docs.Lint) (errs, warnings []string) {
+	for _, lint := range lints {
+		msg := fmt.Sprintf("%vline %v: %v", prefix, lint.Line, lint.What)
+		if lint.Level == docs.LintError {
+			errs = append(errs, msg)
+		} else {
+			warnings = append(warnings, msg)
+		}
+	}
+	return
+}
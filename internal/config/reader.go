@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -15,15 +16,27 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/config/remote"
 	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/manager"
 	"github.com/benthosdev/benthos/v4/internal/stream"
 )
 
 const (
 	defaultChangeFlushPeriod = 50 * time.Millisecond
 	defaultChangeDelayPeriod = time.Second
+	defaultRemotePollPeriod  = time.Minute
 )
 
+// cleanPath returns the shortest equivalent of a config path, URLs are
+// returned as they are.
+func cleanPath(path string) string {
+	if remote.IsURL(path) {
+		return path
+	}
+	return filepath.Clean(path)
+}
+
 type configFileInfo struct {
 	updatedAt time.Time
 }
@@ -53,6 +66,10 @@ type Reader struct {
 	// Tracks the details of the config file when we last read it.
 	configFileInfo configFileInfo
 
+	// Tracks the resources of the main config file when we last read it,
+	// guarded by resourceFileInfoMut.
+	mainResourceInfo *resourceFileInfo
+
 	// Tracks the details of stream config files when we last read them.
 	streamFileInfo map[string]streamFileInfo
 
@@ -68,6 +85,11 @@ type Reader struct {
 
 	changeFlushPeriod time.Duration
 	changeDelayPeriod time.Duration
+
+	// Config paths that are URLs are polled for changes at this interval
+	// whilst watching.
+	remotePollPeriod time.Duration
+	remoteCancel     func()
 }
 
 // NewReader creates a new config reader.
@@ -80,6 +102,7 @@ func NewReader(mainPath string, resourcePaths []string, opts ...OptFunc) *Reader
 		resourceFileInfo:  map[string]resourceFileInfo{},
 		changeFlushPeriod: defaultChangeFlushPeriod,
 		changeDelayPeriod: defaultChangeDelayPeriod,
+		remotePollPeriod:  defaultRemotePollPeriod,
 	}
 	for _, opt := range opts {
 		opt(r)
@@ -118,25 +141,40 @@ func OptSetStreamPaths(streamsPaths ...string) OptFunc {
 	}
 }
 
+// OptSetRemotePollInterval sets the interval at which config paths that are
+// URLs are polled for changes whilst watching.
+func OptSetRemotePollInterval(interval time.Duration) OptFunc {
+	return func(r *Reader) {
+		r.remotePollPeriod = interval
+	}
+}
+
 //------------------------------------------------------------------------------
 
-// Read a Benthos config from the files and options specified.
-func (r *Reader) Read(conf *Type) (lints []string, err error) {
-	if lints, err = r.readMain(conf); err != nil {
+// Read a Benthos config from the files and options specified. Lint errors are
+// returned separately from lint warnings, which describe potential problems
+// that should not prevent the config from being run.
+func (r *Reader) Read(conf *Type) (lints, warnings []string, err error) {
+	if lints, warnings, err = r.readMain(conf); err != nil {
 		return
 	}
-	var rLints []string
-	if rLints, err = r.readResources(&conf.ResourceConfig); err != nil {
+	mainResInfo := resInfoFromConfig(&conf.ResourceConfig)
+	r.mainResourceInfo = &mainResInfo
+
+	var rLints, rWarnings []string
+	if rLints, rWarnings, err = r.readResources(&conf.ResourceConfig); err != nil {
 		return
 	}
 	lints = append(lints, rLints...)
+	warnings = append(warnings, rWarnings...)
 	return
 }
 
 // ReadStreams attempts to read Benthos stream configs from one or more paths.
 // Stream configs are extracted and added to a provided map, where the id is
-// derived from the path of the stream config file.
-func (r *Reader) ReadStreams(confs map[string]stream.Config) (lints []string, err error) {
+// derived from the path of the stream config file. Lint errors are returned
+// separately from lint warnings.
+func (r *Reader) ReadStreams(confs map[string]stream.Config) (lints, warnings []string, err error) {
 	return r.readStreamFiles(confs)
 }
 
@@ -181,7 +219,8 @@ func (r *Reader) SubscribeStreamChanges(fn StreamUpdateFunc) error {
 }
 
 // BeginFileWatching creates a goroutine that watches all active configuration
-// files for changes. If a resource is changed then it is swapped out
+// files for changes, and polls configuration paths that are URLs. If a resource
+// is changed then it is swapped out
 // automatically through the provided manager. If a main config or stream config
 // changes then the closures registered with either SubscribeConfigChanges or
 // SubscribeStreamChanges will be called.
@@ -203,6 +242,27 @@ func (r *Reader) BeginFileWatching(mgr bundle.NewManagement, strict bool) error
 	}
 	r.watcher = watcher
 
+	var localPaths, remotePaths []string
+	if !r.streamsMode && r.mainPath != "" {
+		localPaths = append(localPaths, r.mainPath)
+	}
+	localPaths = append(localPaths, r.streamsPaths...)
+	localPaths = append(localPaths, r.resourcePaths...)
+	for i := 0; i < len(localPaths); i++ {
+		if remote.IsURL(localPaths[i]) {
+			remotePaths = append(remotePaths, localPaths[i])
+			localPaths = append(localPaths[:i], localPaths[i+1:]...)
+			i--
+		}
+	}
+
+	remoteChanges := make(chan string)
+	if len(remotePaths) > 0 && r.remotePollPeriod > 0 {
+		var ctx context.Context
+		ctx, r.remoteCancel = context.WithCancel(context.Background())
+		go r.pollRemotePaths(ctx, mgr, remotePaths, remoteChanges)
+	}
+
 	go func() {
 		ticker := time.NewTicker(r.changeFlushPeriod)
 		defer ticker.Stop()
@@ -224,13 +284,15 @@ func (r *Reader) BeginFileWatching(mgr bundle.NewManagement, strict bool) error
 					_ = watcher.Remove(event.Name)
 					lostNames[filepath.Clean(event.Name)] = struct{}{}
 				}
+			case name := <-remoteChanges:
+				collapsedChanges[name] = time.Now()
 			case <-ticker.C:
 				for nameClean, changed := range collapsedChanges {
 					if time.Since(changed) < r.changeDelayPeriod {
 						continue
 					}
 					var succeeded bool
-					if nameClean == filepath.Clean(r.mainPath) {
+					if nameClean == cleanPath(r.mainPath) {
 						succeeded = r.reactMainUpdate(mgr, strict)
 					} else if _, exists := r.streamFileInfo[nameClean]; exists {
 						succeeded = r.reactStreamUpdate(mgr, strict, nameClean)
@@ -258,29 +320,53 @@ func (r *Reader) BeginFileWatching(mgr bundle.NewManagement, strict bool) error
 		}
 	}()
 
-	if !r.streamsMode && r.mainPath != "" {
-		if err := watcher.Add(r.mainPath); err != nil {
-			_ = watcher.Close()
-			return err
-		}
-	}
-	for _, p := range r.streamsPaths {
+	for _, p := range localPaths {
 		if err := watcher.Add(p); err != nil {
-			_ = watcher.Close()
+			_ = r.Close(context.Background())
 			return err
 		}
 	}
-	for _, p := range r.resourcePaths {
-		if err := watcher.Add(p); err != nil {
-			_ = watcher.Close()
-			return err
+	return nil
+}
+
+// pollRemotePaths periodically fetches config paths that are URLs, and emits
+// the paths of those that have changed since they were last read.
+func (r *Reader) pollRemotePaths(ctx context.Context, mgr bundle.NewManagement, paths []string, changes chan<- string) {
+	ticker := time.NewTicker(r.remotePollPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		for _, p := range paths {
+			changed, err := remote.GlobalFetchers.Poll(ctx, p)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				mgr.Logger().Errorf("Failed to poll config %v: %v", remote.StripUserInfo(p), err)
+				continue
+			}
+			if !changed {
+				continue
+			}
+			select {
+			case changes <- p:
+			case <-ctx.Done():
+				return
+			}
 		}
 	}
-	return nil
 }
 
 // Close the reader, when this method exits all reloading will be stopped.
 func (r *Reader) Close(ctx context.Context) error {
+	if r.remoteCancel != nil {
+		r.remoteCancel()
+	}
 	if r.watcher != nil {
 		return r.watcher.Close()
 	}
@@ -313,10 +399,10 @@ func applyOverrides(specs docs.FieldSpecs, root *yaml.Node, overrides ...string)
 	return nil
 }
 
-func (r *Reader) readMain(conf *Type) (lints []string, err error) {
+func (r *Reader) readMain(conf *Type) (lints, warnings []string, err error) {
 	defer func() {
 		if err != nil && r.mainPath != "" {
-			err = fmt.Errorf("%v: %w", r.mainPath, err)
+			err = fmt.Errorf("%v: %w", remote.StripUserInfo(r.mainPath), err)
 		}
 	}()
 
@@ -355,11 +441,11 @@ func (r *Reader) readMain(conf *Type) (lints []string, err error) {
 	if !bytes.HasPrefix(confBytes, []byte("# BENTHOS LINT DISABLE")) {
 		lintFilePrefix := ""
 		if r.mainPath != "" {
-			lintFilePrefix = fmt.Sprintf("%v: ", r.mainPath)
-		}
-		for _, lint := range confSpec.LintYAML(docs.NewLintContext(), &rawNode) {
-			lints = append(lints, fmt.Sprintf("%vline %v: %v", lintFilePrefix, lint.Line, lint.What))
+			lintFilePrefix = fmt.Sprintf("%v: ", remote.StripUserInfo(r.mainPath))
 		}
+		lintErrs, lintWarnings := formatLints(lintFilePrefix, confSpec.LintYAML(docs.NewLintContext(), &rawNode))
+		lints = append(lints, lintErrs...)
+		warnings = append(warnings, lintWarnings...)
 	}
 
 	err = rawNode.Decode(conf)
@@ -374,7 +460,7 @@ func (r *Reader) reactMainUpdate(mgr bundle.NewManagement, strict bool) bool {
 	mgr.Logger().Infoln("Main config updated, attempting to update pipeline.")
 
 	conf := New()
-	lints, err := r.readMain(&conf)
+	lints, warnings, err := r.readMain(&conf)
 	if err != nil {
 		mgr.Logger().Errorf("Failed to read updated config: %v", err)
 
@@ -386,6 +472,9 @@ func (r *Reader) reactMainUpdate(mgr bundle.NewManagement, strict bool) bool {
 	for _, lint := range lints {
 		lintlog.Infoln(lint)
 	}
+	for _, warning := range warnings {
+		lintlog.Warnln(warning)
+	}
 	if strict && len(lints) > 0 {
 		mgr.Logger().Errorln("Rejecting updated main config due to linter errors, to allow linting errors run Benthos with --chilled")
 
@@ -393,10 +482,93 @@ func (r *Reader) reactMainUpdate(mgr bundle.NewManagement, strict bool) bool {
 		return true
 	}
 
-	// Update any resources within the file.
-	if newInfo := resInfoFromConfig(&conf.ResourceConfig); !newInfo.applyChanges(mgr) {
+	// Update any resources within the file that have changed.
+	if _, err := r.applyMainResources(mgr, &conf.ResourceConfig); err != nil {
+		mgr.Logger().Errorln(err.Error())
 		return false
 	}
 
 	return r.mainUpdateFn(conf.Config)
 }
+
+func (r *Reader) applyMainResources(mgr bundle.NewManagement, conf *manager.ResourceConfig) ([]string, error) {
+	r.resourceFileInfoMut.Lock()
+	defer r.resourceFileInfoMut.Unlock()
+
+	newInfo := resInfoFromConfig(conf)
+	replaced, err := newInfo.applyChanges(mgr, r.mainResourceInfo)
+	if err != nil {
+		return nil, err
+	}
+	r.mainResourceInfo = &newInfo
+	return replaced, nil
+}
+
+// ReloadMain reads the main config file and all resource files, storing
+// resources that are new or have changed since they were last read through the
+// provided manager. The stream config of the main file is returned along with
+// the paths of the resources that were replaced, and it is the responsibility
+// of the caller to apply the stream config.
+func (r *Reader) ReloadMain(mgr bundle.NewManagement, strict bool) (conf stream.Config, replaced []string, err error) {
+	newConf := New()
+	lints, warnings, err := r.readMain(&newConf)
+	if err != nil {
+		return
+	}
+
+	resourcesPaths, err := Globs(r.resourcePaths)
+	if err != nil {
+		err = fmt.Errorf("failed to resolve resource glob pattern: %w", err)
+		return
+	}
+	sort.Strings(resourcesPaths)
+
+	resConfs := make([]manager.ResourceConfig, len(resourcesPaths))
+	for i, path := range resourcesPaths {
+		resConfs[i] = manager.NewResourceConfig()
+		var rLints, rWarnings []string
+		if rLints, rWarnings, err = readResource(path, &resConfs[i]); err != nil {
+			return
+		}
+		lints = append(lints, rLints...)
+		warnings = append(warnings, rWarnings...)
+	}
+
+	for _, lint := range lints {
+		mgr.Logger().Infoln(lint)
+	}
+	for _, warning := range warnings {
+		mgr.Logger().Warnln(warning)
+	}
+	if strict && len(lints) > 0 {
+		err = fmt.Errorf("config contains linter errors: %v", strings.Join(lints, "; "))
+		return
+	}
+
+	if replaced, err = r.applyMainResources(mgr, &newConf.ResourceConfig); err != nil {
+		return
+	}
+
+	r.resourceFileInfoMut.Lock()
+	defer r.resourceFileInfoMut.Unlock()
+
+	for i, path := range resourcesPaths {
+		path = cleanPath(path)
+
+		var prevInfo *resourceFileInfo
+		if info, exists := r.resourceFileInfo[path]; exists {
+			prevInfo = &info
+		}
+
+		newInfo := resInfoFromConfig(&resConfs[i])
+		var fileReplaced []string
+		if fileReplaced, err = newInfo.applyChanges(mgr, prevInfo); err != nil {
+			return
+		}
+		replaced = append(replaced, fileReplaced...)
+		r.resourceFileInfo[path] = newInfo
+	}
+
+	conf = newConf.Config
+	return
+}
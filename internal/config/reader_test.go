@@ -1,8 +1,12 @@
 package config
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -127,3 +131,133 @@ output:
 	assert.Equal(t, "kafka", updatedConf.Input.Type)
 	assert.Equal(t, "aws_s3", updatedConf.Output.Type)
 }
+
+func TestReaderRemotePolling(t *testing.T) {
+	var confBody atomic.Value
+	confBody.Store(`
+input:
+  kafka: {}
+output:
+  aws_s3: {}
+`)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(confBody.Load().(string)))
+	}))
+	t.Cleanup(server.Close)
+
+	rdr := newDummyReader(server.URL + "/main.yaml")
+	rdr.remotePollPeriod = 5 * time.Millisecond
+
+	conf := New()
+	_, _, err := rdr.Read(&conf)
+	require.NoError(t, err)
+	assert.Equal(t, "kafka", conf.Input.Type)
+
+	changeChan := make(chan struct{})
+	var updatedConf stream.Config
+	require.NoError(t, rdr.SubscribeConfigChanges(func(conf stream.Config) bool {
+		updatedConf = conf
+		close(changeChan)
+		return true
+	}))
+
+	testMgr, err := manager.NewV2(manager.NewResourceConfig(), nil, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+	require.NoError(t, rdr.BeginFileWatching(testMgr, true))
+	t.Cleanup(func() {
+		_ = rdr.Close(context.Background())
+	})
+
+	confBody.Store(`
+input:
+  nats: {}
+output:
+  aws_s3: {}
+`)
+
+	select {
+	case <-changeChan:
+	case <-time.After(time.Second):
+		require.FailNow(t, "Expected a config change to be triggered")
+	}
+
+	assert.Equal(t, "nats", updatedConf.Input.Type)
+	assert.Equal(t, "aws_s3", updatedConf.Output.Type)
+}
+
+func TestReaderReloadMain(t *testing.T) {
+	confDir := t.TempDir()
+
+	confFilePath := filepath.Join(confDir, "main.yaml")
+	resFilePath := filepath.Join(confDir, "res.yaml")
+
+	require.NoError(t, os.WriteFile(confFilePath, []byte(`
+input:
+  generate:
+    mapping: 'root = "foo"'
+cache_resources:
+  - label: foo
+    memory: {}
+`), 0o644))
+	require.NoError(t, os.WriteFile(resFilePath, []byte(`
+cache_resources:
+  - label: bar
+    memory: {}
+  - label: baz
+    memory: {}
+`), 0o644))
+
+	rdr := NewReader(confFilePath, []string{resFilePath})
+
+	conf := New()
+	_, _, err := rdr.Read(&conf)
+	require.NoError(t, err)
+
+	testMgr, err := manager.NewV2(conf.ResourceConfig, nil, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	newConf, replaced, err := rdr.ReloadMain(testMgr, true)
+	require.NoError(t, err)
+	assert.Empty(t, replaced)
+	assert.Equal(t, "generate", newConf.Input.Type)
+
+	require.NoError(t, os.WriteFile(confFilePath, []byte(`
+input:
+  generate:
+    mapping: 'root = "bar"'
+cache_resources:
+  - label: foo
+    memory:
+      default_ttl: 1m
+`), 0o644))
+	require.NoError(t, os.WriteFile(resFilePath, []byte(`
+cache_resources:
+  - label: bar
+    memory: {}
+  - label: baz
+    memory:
+      default_ttl: 1m
+  - label: buz
+    memory: {}
+`), 0o644))
+
+	newConf, replaced, err = rdr.ReloadMain(testMgr, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"resources.cache.foo",
+		"resources.cache.baz",
+		"resources.cache.buz",
+	}, replaced)
+	assert.Equal(t, `root = "bar"`, newConf.Input.Generate.Mapping)
+	assert.True(t, testMgr.ProbeCache("buz"))
+
+	require.NoError(t, os.WriteFile(confFilePath, []byte(`
+input:
+  generate:
+    nope: 'root = "bar"'
+`), 0o644))
+
+	_, _, err = rdr.ReloadMain(testMgr, true)
+	require.Error(t, err)
+}
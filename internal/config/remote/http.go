@@ -0,0 +1,51 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// httpFetcher obtains configs from HTTP servers. Basic authentication can be
+// specified with credentials embedded within the URL.
+type httpFetcher struct {
+	client *http.Client
+}
+
+func newHTTPFetcher() *httpFetcher {
+	return &httpFetcher{
+		client: &http.Client{Timeout: time.Second * 30},
+	}
+}
+
+func (h *httpFetcher) Fetch(ctx context.Context, u *url.URL, etag string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	res, err := h.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotModified {
+		return nil, "", ErrNotModified
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, "", fmt.Errorf("unexpected status code: %v", res.StatusCode)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return body, res.Header.Get("ETag"), nil
+}
@@ -0,0 +1,4 @@
+// Package remote provides a pluggable mechanism for fetching config files from
+// URLs, such as HTTP servers and object storage, with conditional requests that
+// allow remote configs to be polled for changes cheaply.
+package remote
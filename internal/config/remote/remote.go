@@ -0,0 +1,248 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotModified is returned by a fetcher when the contents of a URL have not
+// changed since they were last fetched, as indicated by an ETag.
+var ErrNotModified = errors.New("not modified")
+
+// Fetcher obtains the contents of URLs of a given scheme.
+type Fetcher interface {
+	// Fetch the contents of a URL along with an ETag that identifies the
+	// version of the contents obtained, which can be empty when unsupported.
+	// When the provided etag is non-empty and matches the current version
+	// ErrNotModified should be returned instead.
+	Fetch(ctx context.Context, u *url.URL, etag string) (body []byte, newETag string, err error)
+}
+
+// FetcherFunc is a closure that implements Fetcher.
+type FetcherFunc func(ctx context.Context, u *url.URL, etag string) ([]byte, string, error)
+
+// Fetch the contents of a URL.
+func (f FetcherFunc) Fetch(ctx context.Context, u *url.URL, etag string) ([]byte, string, error) {
+	return f(ctx, u, etag)
+}
+
+// IsURL returns true if a config path is a URL rather than a local file path.
+func IsURL(path string) bool {
+	i := strings.Index(path, "://")
+	if i <= 0 {
+		return false
+	}
+	for _, c := range path[:i] {
+		if !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && !(c >= '0' && c <= '9') && c != '+' && c != '-' && c != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// StripUserInfo removes any user info, such as basic authentication
+// credentials, from a config path that is a URL in order for it to be safely
+// logged. Paths that are not URLs are returned as they are.
+func StripUserInfo(path string) string {
+	if !IsURL(path) {
+		return path
+	}
+	start := strings.Index(path, "://") + 3
+	authority := path[start:]
+	if i := strings.IndexAny(authority, "/?#"); i >= 0 {
+		authority = authority[:i]
+	}
+	if i := strings.LastIndex(authority, "@"); i >= 0 {
+		return path[:start] + path[start+i+1:]
+	}
+	return path
+}
+
+// ReadFile reads the contents of a config path, which is fetched with the
+// global fetchers when the path is a URL and is otherwise read from the local
+// filesystem.
+func ReadFile(path string) ([]byte, error) {
+	if !IsURL(path) {
+		return os.ReadFile(path)
+	}
+	ctx, done := context.WithTimeout(context.Background(), time.Minute)
+	defer done()
+	return GlobalFetchers.Read(ctx, path)
+}
+
+//------------------------------------------------------------------------------
+
+type cachedFile struct {
+	body []byte
+	etag string
+}
+
+// Fetchers is a collection of fetchers identified by the URL schemes they
+// support, which caches the most recently fetched contents of each URL.
+type Fetchers struct {
+	mut      sync.RWMutex
+	fetchers map[string]Fetcher
+
+	cacheMut sync.Mutex
+	cache    map[string]cachedFile
+}
+
+// NewFetchers returns an empty collection of fetchers.
+func NewFetchers() *Fetchers {
+	return &Fetchers{
+		fetchers: map[string]Fetcher{},
+		cache:    map[string]cachedFile{},
+	}
+}
+
+// GlobalFetchers contains the fetchers available to the config readers of the
+// service, which includes http and https by default.
+var GlobalFetchers = NewFetchers()
+
+func init() {
+	httpFetcher := newHTTPFetcher()
+	for _, scheme := range []string{"http", "https"} {
+		if err := GlobalFetchers.Add(scheme, httpFetcher); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Add a fetcher for a URL scheme, returns an error if the scheme is already
+// supported.
+func (f *Fetchers) Add(scheme string, fetcher Fetcher) error {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	if _, exists := f.fetchers[scheme]; exists {
+		return fmt.Errorf("fetcher for scheme '%v' already exists", scheme)
+	}
+	f.fetchers[scheme] = fetcher
+	return nil
+}
+
+// Schemes returns the sorted URL schemes supported.
+func (f *Fetchers) Schemes() []string {
+	f.mut.RLock()
+	defer f.mut.RUnlock()
+	schemes := make([]string, 0, len(f.fetchers))
+	for k := range f.fetchers {
+		schemes = append(schemes, k)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// Read the contents of a URL. When the contents have not changed since the
+// URL was last fetched the cached contents are returned.
+func (f *Fetchers) Read(ctx context.Context, path string) ([]byte, error) {
+	body, _, err := f.fetch(ctx, path)
+	return body, err
+}
+
+// Poll the contents of a URL, returning true if they have changed since the
+// URL was last fetched.
+func (f *Fetchers) Poll(ctx context.Context, path string) (bool, error) {
+	_, changed, err := f.fetch(ctx, path)
+	return changed, err
+}
+
+func (f *Fetchers) fetch(ctx context.Context, path string) (body []byte, changed bool, err error) {
+	u, err := url.Parse(path)
+	if err != nil {
+		// Parse errors contain the URL, which might include credentials.
+		var uErr *url.Error
+		if errors.As(err, &uErr) {
+			err = uErr.Err
+		}
+		return nil, false, fmt.Errorf("failed to parse config URL: %w", err)
+	}
+
+	f.mut.RLock()
+	fetcher, exists := f.fetchers[u.Scheme]
+	f.mut.RUnlock()
+	if !exists {
+		return nil, false, fmt.Errorf("config URL scheme '%v' is not supported, expected one of: %v", u.Scheme, f.Schemes())
+	}
+
+	// The fragment is reserved for checksums and is therefore not part of the
+	// fetched URL.
+	checksum := u.Fragment
+	u.Fragment = ""
+
+	f.cacheMut.Lock()
+	prev, hasPrev := f.cache[path]
+	f.cacheMut.Unlock()
+
+	var etag string
+	if hasPrev {
+		etag = prev.etag
+	}
+
+	newETag := ""
+	if body, newETag, err = fetcher.Fetch(ctx, u, etag); err != nil {
+		if hasPrev && errors.Is(err, ErrNotModified) {
+			return prev.body, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to fetch config: %w", err)
+	}
+	if hasPrev && bytes.Equal(prev.body, body) {
+		f.cacheMut.Lock()
+		f.cache[path] = cachedFile{body: body, etag: newETag}
+		f.cacheMut.Unlock()
+		return body, false, nil
+	}
+
+	if checksum != "" {
+		if err = f.verify(ctx, fetcher, u, checksum, body); err != nil {
+			return nil, false, err
+		}
+	}
+
+	f.cacheMut.Lock()
+	f.cache[path] = cachedFile{body: body, etag: newETag}
+	f.cacheMut.Unlock()
+	return body, true, nil
+}
+
+// verify the checksum of fetched contents, where the checksum is specified as
+// the URL fragment either in the form `sha256=<hex>`, or `sha256` in order to
+// obtain the checksum from a file at the same URL suffixed with `.sha256`.
+func (f *Fetchers) verify(ctx context.Context, fetcher Fetcher, u *url.URL, checksum string, body []byte) error {
+	algo, expected := checksum, ""
+	if i := strings.Index(checksum, "="); i != -1 {
+		algo, expected = checksum[:i], checksum[i+1:]
+	}
+	if algo != "sha256" {
+		return fmt.Errorf("checksum algorithm '%v' is not supported, expected sha256", algo)
+	}
+
+	if expected == "" {
+		sumURL := *u
+		sumURL.Path += ".sha256"
+		sumURL.RawPath = ""
+		sumBytes, _, err := fetcher.Fetch(ctx, &sumURL, "")
+		if err != nil {
+			return fmt.Errorf("failed to fetch config checksum: %w", err)
+		}
+		// Checksum files are commonly in the format `<checksum>  <filename>`.
+		if fields := strings.Fields(string(sumBytes)); len(fields) > 0 {
+			expected = fields[0]
+		}
+	}
+
+	sum := sha256.Sum256(body)
+	if actual := hex.EncodeToString(sum[:]); !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("config checksum mismatch: expected %v, got %v", expected, actual)
+	}
+	return nil
+}
@@ -4,7 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"path/filepath"
+	"sort"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -12,8 +12,8 @@ import (
 	"github.com/benthosdev/benthos/v4/internal/bundle"
 	"github.com/benthosdev/benthos/v4/internal/component/cache"
 	"github.com/benthosdev/benthos/v4/internal/component/ratelimit"
+	"github.com/benthosdev/benthos/v4/internal/config/remote"
 	"github.com/benthosdev/benthos/v4/internal/docs"
-	ifilepath "github.com/benthosdev/benthos/v4/internal/filepath"
 	"github.com/benthosdev/benthos/v4/internal/manager"
 	"github.com/benthosdev/benthos/v4/internal/old/input"
 	"github.com/benthosdev/benthos/v4/internal/old/output"
@@ -46,50 +46,56 @@ func resInfoFromConfig(conf *manager.ResourceConfig) resourceFileInfo {
 
 	// New style
 	for _, c := range conf.ResourceInputs {
+		c := c
 		resInfo.inputs[c.Label] = &c
 	}
 	for _, c := range conf.ResourceProcessors {
+		c := c
 		resInfo.processors[c.Label] = &c
 	}
 	for _, c := range conf.ResourceOutputs {
+		c := c
 		resInfo.outputs[c.Label] = &c
 	}
 	for _, c := range conf.ResourceCaches {
+		c := c
 		resInfo.caches[c.Label] = &c
 	}
 	for _, c := range conf.ResourceRateLimits {
+		c := c
 		resInfo.rateLimits[c.Label] = &c
 	}
 
 	return resInfo
 }
 
-func (r *Reader) readResources(conf *manager.ResourceConfig) (lints []string, err error) {
-	resourcesPaths, err := ifilepath.Globs(r.resourcePaths)
+func (r *Reader) readResources(conf *manager.ResourceConfig) (lints, warnings []string, err error) {
+	resourcesPaths, err := Globs(r.resourcePaths)
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve resource glob pattern: %w", err)
+		return nil, nil, fmt.Errorf("failed to resolve resource glob pattern: %w", err)
 	}
 	for _, path := range resourcesPaths {
 		rconf := manager.NewResourceConfig()
-		var rLints []string
-		if rLints, err = readResource(path, &rconf); err != nil {
+		var rLints, rWarnings []string
+		if rLints, rWarnings, err = readResource(path, &rconf); err != nil {
 			return
 		}
 		lints = append(lints, rLints...)
+		warnings = append(warnings, rWarnings...)
 
 		if err = conf.AddFrom(&rconf); err != nil {
-			err = fmt.Errorf("%v: %w", path, err)
+			err = fmt.Errorf("%v: %w", remote.StripUserInfo(path), err)
 			return
 		}
-		r.resourceFileInfo[filepath.Clean(path)] = resInfoFromConfig(&rconf)
+		r.resourceFileInfo[cleanPath(path)] = resInfoFromConfig(&rconf)
 	}
 	return
 }
 
-func readResource(path string, conf *manager.ResourceConfig) (lints []string, err error) {
+func readResource(path string, conf *manager.ResourceConfig) (lints, warnings []string, err error) {
 	defer func() {
 		if err != nil {
-			err = fmt.Errorf("%v: %w", path, err)
+			err = fmt.Errorf("%v: %w", remote.StripUserInfo(path), err)
 		}
 	}()
 
@@ -106,9 +112,9 @@ func readResource(path string, conf *manager.ResourceConfig) (lints []string, er
 		allowTest := append(docs.FieldSpecs{
 			TestsField,
 		}, manager.Spec()...)
-		for _, lint := range allowTest.LintYAML(docs.NewLintContext(), &rawNode) {
-			lints = append(lints, fmt.Sprintf("resource file %v: line %v: %v", path, lint.Line, lint.What))
-		}
+		lintErrs, lintWarnings := formatLints(fmt.Sprintf("resource file %v: ", remote.StripUserInfo(path)), allowTest.LintYAML(docs.NewLintContext(), &rawNode))
+		lints = append(lints, lintErrs...)
+		warnings = append(warnings, lintWarnings...)
 	}
 
 	err = rawNode.Decode(conf)
@@ -120,14 +126,14 @@ func (r *Reader) reactResourceUpdate(mgr bundle.NewManagement, strict bool, path
 	defer r.resourceFileInfoMut.Unlock()
 
 	if _, exists := r.resourceFileInfo[path]; !exists {
-		mgr.Logger().Warnf("Skipping resource update for unknown path: %v", path)
+		mgr.Logger().Warnf("Skipping resource update for unknown path: %v", remote.StripUserInfo(path))
 		return true
 	}
 
-	mgr.Logger().Infof("Resource %v config updated, attempting to update resources.", path)
+	mgr.Logger().Infof("Resource %v config updated, attempting to update resources.", remote.StripUserInfo(path))
 
 	newResConf := manager.NewResourceConfig()
-	lints, err := readResource(path, &newResConf)
+	lints, warnings, err := readResource(path, &newResConf)
 	if err != nil {
 		mgr.Logger().Errorf("Failed to read updated resources config: %v", err)
 		return true
@@ -137,17 +143,21 @@ func (r *Reader) reactResourceUpdate(mgr bundle.NewManagement, strict bool, path
 	for _, lint := range lints {
 		lintlog.Infoln(lint)
 	}
+	for _, warning := range warnings {
+		lintlog.Warnln(warning)
+	}
 	if strict && len(lints) > 0 {
 		mgr.Logger().Errorln("Rejecting updated resource config due to linter errors, to allow linting errors run Benthos with --chilled")
 		return true
 	}
 
 	// TODO: Should we error out if the new config is missing some resources?
-	// (as they will continue to exist). Also, we could avoid restarting
-	// resources where the config hasn't changed.
+	// (as they will continue to exist).
 
+	prevInfo := r.resourceFileInfo[path]
 	newInfo := resInfoFromConfig(&newResConf)
-	if !newInfo.applyChanges(mgr) {
+	if _, err := newInfo.applyChanges(mgr, &prevInfo); err != nil {
+		mgr.Logger().Errorln(err.Error())
 		return false
 	}
 
@@ -155,49 +165,67 @@ func (r *Reader) reactResourceUpdate(mgr bundle.NewManagement, strict bool, path
 	return true
 }
 
-func (i *resourceFileInfo) applyChanges(mgr bundle.NewManagement) bool {
+// applyChanges stores resources through the manager that are either new or have
+// a config that differs from a previous read, returning the paths of the
+// resources that were stored. When prev is nil all resources are stored.
+func (i *resourceFileInfo) applyChanges(mgr bundle.NewManagement, prev *resourceFileInfo) (replaced []string, err error) {
 	// Kind of arbitrary, but I feel better about having some sort of timeout.
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
 	defer cancel()
 
+	stored := func(typeStr, k string, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to update resource %v: %w", k, err)
+		}
+		mgr.Logger().Infof("Updated resource %v config from file.", k)
+		replaced = append(replaced, fmt.Sprintf("resources.%v.%v", typeStr, k))
+		return nil
+	}
+
 	// WARNING: The order here is actually kind of important, we want to start
 	// with components that could be dependencies of other components. This is
 	// a "best attempt", so not all edge cases need to be accounted for.
 	for k, v := range i.rateLimits {
-		if err := mgr.StoreRateLimit(ctx, k, *v); err != nil {
-			mgr.Logger().Errorf("Failed to update resource %v: %v", k, err)
-			return false
+		if prev != nil && docs.ConfigsEqual(prev.rateLimits[k], v) {
+			continue
+		}
+		if err = stored("rate_limit", k, mgr.StoreRateLimit(ctx, k, *v)); err != nil {
+			return
 		}
-		mgr.Logger().Infof("Updated resource %v config from file.", k)
 	}
 	for k, v := range i.caches {
-		if err := mgr.StoreCache(ctx, k, *v); err != nil {
-			mgr.Logger().Errorf("Failed to update resource %v: %v", k, err)
-			return false
+		if prev != nil && docs.ConfigsEqual(prev.caches[k], v) {
+			continue
+		}
+		if err = stored("cache", k, mgr.StoreCache(ctx, k, *v)); err != nil {
+			return
 		}
-		mgr.Logger().Infof("Updated resource %v config from file.", k)
 	}
 	for k, v := range i.processors {
-		if err := mgr.StoreProcessor(ctx, k, *v); err != nil {
-			mgr.Logger().Errorf("Failed to update resource %v: %v", k, err)
-			return false
+		if prev != nil && docs.ConfigsEqual(prev.processors[k], v) {
+			continue
+		}
+		if err = stored("processor", k, mgr.StoreProcessor(ctx, k, *v)); err != nil {
+			return
 		}
-		mgr.Logger().Infof("Updated resource %v config from file.", k)
 	}
 	for k, v := range i.inputs {
-		if err := mgr.StoreInput(ctx, k, *v); err != nil {
-			mgr.Logger().Errorf("Failed to update resource %v: %v", k, err)
-			return false
+		if prev != nil && docs.ConfigsEqual(prev.inputs[k], v) {
+			continue
+		}
+		if err = stored("input", k, mgr.StoreInput(ctx, k, *v)); err != nil {
+			return
 		}
-		mgr.Logger().Infof("Updated resource %v config from file.", k)
 	}
 	for k, v := range i.outputs {
-		if err := mgr.StoreOutput(ctx, k, *v); err != nil {
-			mgr.Logger().Errorf("Failed to update resource %v: %v", k, err)
-			return false
+		if prev != nil && docs.ConfigsEqual(prev.outputs[k], v) {
+			continue
+		}
+		if err = stored("output", k, mgr.StoreOutput(ctx, k, *v)); err != nil {
+			return
 		}
-		mgr.Logger().Infof("Updated resource %v config from file.", k)
 	}
 
-	return true
+	sort.Strings(replaced)
+	return
 }
@@ -3,7 +3,9 @@ package config
 import (
 	"bytes"
 	"fmt"
+	"net/url"
 	"os"
+	gopath "path"
 	"path/filepath"
 	"strings"
 	"time"
@@ -11,8 +13,8 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/config/remote"
 	"github.com/benthosdev/benthos/v4/internal/docs"
-	ifilepath "github.com/benthosdev/benthos/v4/internal/filepath"
 	"github.com/benthosdev/benthos/v4/internal/stream"
 )
 
@@ -23,7 +25,13 @@ import (
 // unique names.
 func InferStreamID(dir, path string) (string, error) {
 	var id string
-	if len(dir) > 0 {
+	if remote.IsURL(path) {
+		u, err := url.Parse(path)
+		if err != nil {
+			return "", err
+		}
+		id = gopath.Base(u.Path)
+	} else if len(dir) > 0 {
 		var err error
 		if id, err = filepath.Rel(dir, path); err != nil {
 			return "", err
@@ -40,8 +48,9 @@ func InferStreamID(dir, path string) (string, error) {
 	return id, nil
 }
 
-// ReadStreamFile attempts to read a stream config and returns the result
-func ReadStreamFile(path string) (conf stream.Config, lints []string, err error) {
+// ReadStreamFile attempts to read a stream config and returns the result along
+// with any lint errors and lint warnings.
+func ReadStreamFile(path string) (conf stream.Config, lints, warnings []string, err error) {
 	conf = stream.NewConfig()
 
 	var confBytes []byte
@@ -58,33 +67,33 @@ func ReadStreamFile(path string) (conf stream.Config, lints []string, err error)
 	confSpec = append(confSpec, TestsField)
 
 	if !bytes.HasPrefix(confBytes, []byte("# BENTHOS LINT DISABLE")) {
-		for _, lint := range confSpec.LintYAML(docs.NewLintContext(), &rawNode) {
-			lints = append(lints, fmt.Sprintf("%v: line %v: %v", path, lint.Line, lint.What))
-		}
+		lintErrs, lintWarnings := formatLints(fmt.Sprintf("%v: ", remote.StripUserInfo(path)), confSpec.LintYAML(docs.NewLintContext(), &rawNode))
+		lints = append(lints, lintErrs...)
+		warnings = append(warnings, lintWarnings...)
 	}
 
 	err = rawNode.Decode(&conf)
 	return
 }
 
-func (r *Reader) readStreamFile(dir, path string, confs map[string]stream.Config) ([]string, error) {
+func (r *Reader) readStreamFile(dir, path string, confs map[string]stream.Config) (lints, warnings []string, err error) {
 	id, err := InferStreamID(dir, path)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Do not run unit test files
 	if len(r.testSuffix) > 0 && strings.HasSuffix(id, r.testSuffix) {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	if _, exists := confs[id]; exists {
-		return nil, fmt.Errorf("stream id (%v) collision from file: %v", id, path)
+		return nil, nil, fmt.Errorf("stream id (%v) collision from file: %v", id, remote.StripUserInfo(path))
 	}
 
-	conf, lints, err := ReadStreamFile(path)
+	conf, lints, warnings, err := ReadStreamFile(path)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	strmInfo := streamFileInfo{id: id}
@@ -94,27 +103,38 @@ func (r *Reader) readStreamFile(dir, path string, confs map[string]stream.Config
 	r.streamFileInfo[path] = strmInfo
 
 	confs[id] = conf
-	return lints, nil
+	return lints, warnings, nil
 }
 
-func (r *Reader) readStreamFiles(streamMap map[string]stream.Config) ([]string, error) {
-	streamsPaths, err := ifilepath.Globs(r.streamsPaths)
+func (r *Reader) readStreamFiles(streamMap map[string]stream.Config) (pathLints, pathWarnings []string, err error) {
+	streamsPaths, err := Globs(r.streamsPaths)
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve stream glob pattern: %w", err)
+		return nil, nil, fmt.Errorf("failed to resolve stream glob pattern: %w", err)
 	}
 
-	pathLints := []string{}
+	pathLints = []string{}
 	for _, target := range streamsPaths {
-		target = filepath.Clean(target)
+		target = cleanPath(target)
+
+		if remote.IsURL(target) {
+			tmpPathLints, tmpPathWarnings, err := r.readStreamFile("", target, streamMap)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to load config '%v': %v", remote.StripUserInfo(target), err)
+			}
+			pathLints = append(pathLints, tmpPathLints...)
+			pathWarnings = append(pathWarnings, tmpPathWarnings...)
+			continue
+		}
 
 		if info, err := os.Stat(target); err != nil {
-			return nil, err
+			return nil, nil, err
 		} else if !info.IsDir() {
-			tmpPathLints, err := r.readStreamFile("", target, streamMap)
+			tmpPathLints, tmpPathWarnings, err := r.readStreamFile("", target, streamMap)
 			if err != nil {
-				return nil, fmt.Errorf("failed to load config '%v': %v", target, err)
+				return nil, nil, fmt.Errorf("failed to load config '%v': %v", target, err)
 			}
 			pathLints = append(pathLints, tmpPathLints...)
+			pathWarnings = append(pathWarnings, tmpPathWarnings...)
 			continue
 		}
 
@@ -128,18 +148,19 @@ func (r *Reader) readStreamFiles(streamMap map[string]stream.Config) ([]string,
 				return nil
 			}
 
-			var lints []string
-			if lints, werr = r.readStreamFile(target, path, streamMap); werr != nil {
+			var lints, warnings []string
+			if lints, warnings, werr = r.readStreamFile(target, path, streamMap); werr != nil {
 				return fmt.Errorf("failed to load config '%v': %v", path, werr)
 			}
 
 			pathLints = append(pathLints, lints...)
+			pathWarnings = append(pathWarnings, warnings...)
 			return nil
 		}); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
-	return pathLints, nil
+	return pathLints, pathWarnings, nil
 }
 
 func (r *Reader) reactStreamUpdate(mgr bundle.NewManagement, strict bool, path string) bool {
@@ -149,13 +170,13 @@ func (r *Reader) reactStreamUpdate(mgr bundle.NewManagement, strict bool, path s
 
 	info, exists := r.streamFileInfo[path]
 	if !exists {
-		mgr.Logger().Warnf("Skipping resource update for unknown path: %v", path)
+		mgr.Logger().Warnf("Skipping resource update for unknown path: %v", remote.StripUserInfo(path))
 		return true
 	}
 
 	mgr.Logger().Infof("Stream %v config updated, attempting to update stream.", info.id)
 
-	conf, lints, err := ReadStreamFile(path)
+	conf, lints, warnings, err := ReadStreamFile(path)
 	if err != nil {
 		mgr.Logger().Errorf("Failed to read updated stream config: %v", err)
 		return true
@@ -165,6 +186,9 @@ func (r *Reader) reactStreamUpdate(mgr bundle.NewManagement, strict bool, path s
 	for _, lint := range lints {
 		lintlog.Infoln(lint)
 	}
+	for _, warning := range warnings {
+		lintlog.Warnln(warning)
+	}
 	if strict && len(lints) > 0 {
 		mgr.Logger().Errorf("Rejecting updated stream %v config due to linter errors, to allow linting errors run Benthos with --chilled", info.id)
 		return true
@@ -41,12 +41,12 @@ cache_resources:
 	rdr := config.NewReader("", nil, config.OptSetStreamPaths(streamOnePath, streamTwoPath))
 
 	conf := config.New()
-	lints, err := rdr.Read(&conf)
+	lints, _, err := rdr.Read(&conf)
 	require.NoError(t, err)
 	require.Len(t, lints, 0)
 
 	streamConfs := map[string]stream.Config{}
-	lints, err = rdr.ReadStreams(streamConfs)
+	lints, _, err = rdr.ReadStreams(streamConfs)
 	require.NoError(t, err)
 
 	require.Len(t, lints, 2)
@@ -89,12 +89,12 @@ pipeline:
 	rdr := config.NewReader("", nil, config.OptSetStreamPaths(streamOnePath, filepath.Join(dir, "nested")))
 
 	conf := config.New()
-	lints, err := rdr.Read(&conf)
+	lints, _, err := rdr.Read(&conf)
 	require.NoError(t, err)
 	require.Len(t, lints, 0)
 
 	streamConfs := map[string]stream.Config{}
-	lints, err = rdr.ReadStreams(streamConfs)
+	lints, _, err = rdr.ReadStreams(streamConfs)
 	require.NoError(t, err)
 	require.Len(t, lints, 0)
 
@@ -10,7 +10,8 @@ import (
 )
 
 // LintBloblangMapping is function for linting a config field expected to be a
-// bloblang mapping.
+// bloblang mapping. Parsing errors are reported as lint errors, and potential
+// problems found by a static analysis of the mapping are reported as warnings.
 func LintBloblangMapping(ctx LintContext, line, col int, v interface{}) []Lint {
 	str, ok := v.(string)
 	if !ok {
@@ -19,9 +20,15 @@ func LintBloblangMapping(ctx LintContext, line, col int, v interface{}) []Lint {
 	if str == "" {
 		return nil
 	}
-	_, err := ctx.BloblangEnv.NewMapping(str)
+	exec, err := ctx.BloblangEnv.NewMapping(str)
 	if err == nil {
-		return nil
+		var lints []Lint
+		for _, l := range exec.Lint(nil) {
+			lint := NewLintWarning(line+l.Line-1, l.What)
+			lint.Column = col + l.Column
+			lints = append(lints, lint)
+		}
+		return lints
 	}
 	if mErr, ok := err.(*parser.Error); ok {
 		bline, bcol := parser.LineAndColOf([]rune(str), mErr.Input)
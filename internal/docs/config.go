@@ -199,6 +199,10 @@ type SanitiseConfig struct {
 	ForExample       bool
 	Filter           FieldFilter
 	DocsProvider     Provider
+
+	// RedactSecrets replaces the values of fields marked as secret, and
+	// credentials embedded within URLs, with a mask.
+	RedactSecrets bool
 }
 
 // GetDocs attempts to obtain documentation for a component implementation from
@@ -105,6 +105,11 @@ type FieldSpec struct {
 	// Bloblang indicates that a string field is a Bloblang mapping.
 	Bloblang bool `json:"bloblang,omitempty"`
 
+	// IsSecret indicates that the field contains sensitive information, such
+	// as a password, and should therefore be redacted when a config is
+	// printed.
+	IsSecret bool `json:"is_secret,omitempty"`
+
 	// Examples is a slice of optional example values for a field.
 	Examples []interface{} `json:"examples,omitempty"`
 
@@ -139,6 +144,13 @@ func (f FieldSpec) IsBloblang() FieldSpec {
 	return f
 }
 
+// Secret marks this field as containing sensitive information, which is
+// redacted when a config is printed.
+func (f FieldSpec) Secret() FieldSpec {
+	f.IsSecret = true
+	return f
+}
+
 // HasType returns a new FieldSpec that specifies a specific type.
 func (f FieldSpec) HasType(t FieldType) FieldSpec {
 	f.Type = t
@@ -380,6 +380,9 @@ func (f FieldSpecs) SanitiseYAML(node *yaml.Node, conf SanitiseConfig) error {
 		if err := field.SanitiseYAML(value, conf); err != nil {
 			return err
 		}
+		if conf.RedactSecrets {
+			field.redactYAML(value)
+		}
 		var keyNode yaml.Node
 		if err := keyNode.Encode(field.Name); err != nil {
 			return err
@@ -0,0 +1,23 @@
+package docs
+
+import (
+	"bytes"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigsEqual returns true if two configs are equivalent when marshalled as
+// YAML. Unlike reflect.DeepEqual this ignores the line and column positions of
+// parsed YAML nodes, such as those of plugin configs, and therefore configs
+// that have only been moved within a file are considered equal.
+func ConfigsEqual(a, b interface{}) bool {
+	aBytes, err := yaml.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bBytes, err := yaml.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(aBytes, bBytes)
+}
@@ -283,3 +283,72 @@ func (f FieldSpec) YAMLLabelsToPaths(docsProvider Provider, node *yaml.Node, lab
 		}
 	}
 }
+
+//------------------------------------------------------------------------------
+
+// WalkYAMLComponents walks a YAML tree using a field spec as a reference point
+// and calls the provided closure for each component of the tree along with its
+// type, plugin name and path.
+func (f FieldSpecs) WalkYAMLComponents(docsProvider Provider, node *yaml.Node, path []string, fn func(cType Type, name string, path []string, node *yaml.Node)) {
+	node = unwrapDocumentNode(node)
+
+	fieldMap := map[string]FieldSpec{}
+	for _, spec := range f {
+		fieldMap[spec.Name] = spec
+	}
+
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		key := node.Content[i].Value
+		if spec, exists := fieldMap[key]; exists {
+			spec.WalkYAMLComponents(docsProvider, node.Content[i+1], append(path, key), fn)
+		}
+	}
+}
+
+// WalkYAMLComponents walks a YAML tree using a field spec as a reference point
+// and calls the provided closure for each component of the tree along with its
+// type, plugin name and path.
+func (f FieldSpec) WalkYAMLComponents(docsProvider Provider, node *yaml.Node, path []string, fn func(cType Type, name string, path []string, node *yaml.Node)) {
+	node = unwrapDocumentNode(node)
+
+	switch f.Kind {
+	case Kind2DArray:
+		nextSpec := f.Array()
+		for i, child := range node.Content {
+			nextSpec.WalkYAMLComponents(docsProvider, child, append(path, strconv.Itoa(i)), fn)
+		}
+	case KindArray:
+		nextSpec := f.Scalar()
+		for i, child := range node.Content {
+			nextSpec.WalkYAMLComponents(docsProvider, child, append(path, strconv.Itoa(i)), fn)
+		}
+	case KindMap:
+		nextSpec := f.Scalar()
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			key := node.Content[i].Value
+			nextSpec.WalkYAMLComponents(docsProvider, node.Content[i+1], append(path, key), fn)
+		}
+	default:
+		if coreType, isCore := f.Type.IsCoreComponent(); isCore {
+			if docsProvider == nil {
+				docsProvider = globalProvider
+			}
+			coreFields := FieldSpecs{}
+			for _, f := range reservedFieldsByType(coreType) {
+				coreFields = append(coreFields, f)
+			}
+			if inferred, cSpec, err := GetInferenceCandidateFromYAML(docsProvider, coreType, node); err == nil {
+				pathCopy := make([]string, len(path))
+				copy(pathCopy, path)
+				fn(coreType, inferred, pathCopy, node)
+
+				conf := cSpec.Config
+				conf.Name = inferred
+				coreFields = append(coreFields, conf)
+			}
+			coreFields.WalkYAMLComponents(docsProvider, node, path, fn)
+		} else if len(f.Children) > 0 {
+			f.Children.WalkYAMLComponents(docsProvider, node, path, fn)
+		}
+	}
+}
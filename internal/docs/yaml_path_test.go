@@ -586,3 +586,65 @@ pipeline:
 		})
 	}
 }
+
+func TestWalkYAMLComponents(t *testing.T) {
+	mockProv := docs.NewMappedDocsProvider()
+	mockProv.RegisterDocs(docs.ComponentSpec{
+		Name: "kafka",
+		Type: docs.TypeInput,
+		Config: docs.FieldComponent().WithChildren(
+			docs.FieldString("addresses", "").Array(),
+			docs.FieldString("topics", "").Array(),
+		),
+	})
+	mockProv.RegisterDocs(docs.ComponentSpec{
+		Name: "compress",
+		Type: docs.TypeProcessor,
+		Config: docs.FieldComponent().WithChildren(
+			docs.FieldString("algorithm", ""),
+		),
+	})
+	mockProv.RegisterDocs(docs.ComponentSpec{
+		Name: "for_each",
+		Type: docs.TypeProcessor,
+		Config: docs.FieldComponent().WithChildren(
+			docs.FieldCommon("things", "").HasType(docs.FieldTypeProcessor).Array(),
+		),
+	})
+
+	var input yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(`
+input:
+  kafka:
+    addresses: [ "foo" ]
+    topics: [ "bar" ]
+  processors:
+    - compress:
+        algorithm: gzip
+
+pipeline:
+  processors:
+    - label: fooproc
+      for_each:
+        things:
+          - compress:
+              algorithm: nahm8
+`), &input))
+
+	type walked struct {
+		cType docs.Type
+		name  string
+		path  []string
+	}
+	var components []walked
+	config.Spec().WalkYAMLComponents(mockProv, &input, nil, func(cType docs.Type, name string, path []string, node *yaml.Node) {
+		components = append(components, walked{cType: cType, name: name, path: path})
+	})
+
+	assert.Equal(t, []walked{
+		{cType: docs.TypeInput, name: "kafka", path: []string{"input"}},
+		{cType: docs.TypeProcessor, name: "compress", path: []string{"input", "processors", "0"}},
+		{cType: docs.TypeProcessor, name: "for_each", path: []string{"pipeline", "processors", "0"}},
+		{cType: docs.TypeProcessor, name: "compress", path: []string{"pipeline", "processors", "0", "for_each", "things", "0"}},
+	}, components)
+}
@@ -1000,6 +1000,20 @@ func TestYAMLLinting(t *testing.T) {
 				docs.NewLintError(1, "field baz is required"),
 			},
 		},
+		{
+			name: "bloblang mapping warnings",
+			inputSpec: docs.FieldCommon("foo", "").WithChildren(
+				docs.FieldBloblang("bar", ""),
+			),
+			inputConf: `bar: |
+  root = this
+  root.baz = $baz.uppercase()
+  root.buz = this.buz.number().lowercase()`,
+			res: []docs.Lint{
+				{Line: 3, Column: 7, Level: docs.LintWarning, What: "variable baz is referenced but never declared"},
+				{Line: 4, Column: 7, Level: docs.LintWarning, What: "method lowercase: expected string or bytes value, got number from method number"},
+			},
+		},
 	}
 
 	for _, test := range tests {
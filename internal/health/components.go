@@ -0,0 +1,189 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/cache"
+	iinput "github.com/benthosdev/benthos/v4/internal/component/input"
+	ioutput "github.com/benthosdev/benthos/v4/internal/component/output"
+	iprocessor "github.com/benthosdev/benthos/v4/internal/component/processor"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+type trackedInput struct {
+	iinput.Streamed
+	tracker *Tracker
+}
+
+// TrackInput registers the connection status of an input with a tracker, and
+// deregisters the input once it begins closing. Successful reads are recorded
+// in place by the input via the logger of the tracker, see RecordSuccess.
+func TrackInput(t *Tracker, in iinput.Streamed) iinput.Streamed {
+	if in == nil {
+		t.Close()
+		return nil
+	}
+	t.SetConnected(in.Connected)
+	return &trackedInput{Streamed: in, tracker: t}
+}
+
+func (i *trackedInput) CloseAsync() {
+	i.tracker.Close()
+	i.Streamed.CloseAsync()
+}
+
+//------------------------------------------------------------------------------
+
+type trackedOutput struct {
+	ioutput.Streamed
+	tracker *Tracker
+}
+
+// TrackOutput registers the connection status of an output with a tracker, and
+// deregisters the output once it begins closing. Successful writes are recorded
+// in place by the output via the logger of the tracker, see RecordSuccess.
+func TrackOutput(t *Tracker, out ioutput.Streamed) ioutput.Streamed {
+	if out == nil {
+		t.Close()
+		return nil
+	}
+	t.SetConnected(out.Connected)
+	return &trackedOutput{Streamed: out, tracker: t}
+}
+
+func (o *trackedOutput) MaxInFlight() (int, bool) {
+	return ioutput.GetMaxInFlight(o.Streamed)
+}
+
+func (o *trackedOutput) CloseAsync() {
+	o.tracker.Close()
+	o.Streamed.CloseAsync()
+}
+
+//------------------------------------------------------------------------------
+
+type trackedProcessor struct {
+	p       iprocessor.V1
+	tracker *Tracker
+}
+
+// TrackProcessor wraps a processor with a struct that records the result of
+// each call to ProcessMessage, including messages that the processor flags as
+// having failed. The processor is deregistered from the tracker
+// once it begins closing.
+func TrackProcessor(t *Tracker, p iprocessor.V1) iprocessor.V1 {
+	if p == nil {
+		t.Close()
+		return nil
+	}
+	return &trackedProcessor{p: p, tracker: t}
+}
+
+func (p *trackedProcessor) ProcessMessage(msg *message.Batch) ([]*message.Batch, error) {
+	// Messages flagged with errors prior to processing are ignored so that only
+	// the failures of this processor are recorded.
+	var priorFails map[string]struct{}
+	_ = msg.Iter(func(i int, part *message.Part) error {
+		if fail := iprocessor.GetFail(part); fail != "" {
+			if priorFails == nil {
+				priorFails = map[string]struct{}{}
+			}
+			priorFails[fail] = struct{}{}
+		}
+		return nil
+	})
+
+	msgs, err := p.p.ProcessMessage(msg)
+	if err != nil {
+		p.tracker.Error(err)
+		return msgs, err
+	}
+
+	var failErr error
+	for _, m := range msgs {
+		_ = m.Iter(func(i int, part *message.Part) error {
+			if fail := iprocessor.GetFail(part); fail != "" && failErr == nil {
+				if _, exists := priorFails[fail]; !exists {
+					failErr = errors.New(fail)
+				}
+			}
+			return nil
+		})
+	}
+	if failErr != nil {
+		p.tracker.Error(failErr)
+	} else {
+		p.tracker.Success()
+	}
+	return msgs, err
+}
+
+// Unwrap returns the underlying processor.
+func (p *trackedProcessor) Unwrap() iprocessor.V1 {
+	return p.p
+}
+
+func (p *trackedProcessor) CloseAsync() {
+	p.tracker.Close()
+	p.p.CloseAsync()
+}
+
+func (p *trackedProcessor) WaitForClose(timeout time.Duration) error {
+	return p.p.WaitForClose(timeout)
+}
+
+//------------------------------------------------------------------------------
+
+type trackedCache struct {
+	c       cache.V1
+	tracker *Tracker
+}
+
+// TrackCache wraps a cache with a struct that records the result of each
+// operation. Missing and duplicate keys are not considered errors. The cache is
+// deregistered from the tracker once it begins closing.
+func TrackCache(t *Tracker, c cache.V1) cache.V1 {
+	if c == nil {
+		t.Close()
+		return nil
+	}
+	return &trackedCache{c: c, tracker: t}
+}
+
+func (c *trackedCache) record(err error) error {
+	if err == nil || errors.Is(err, component.ErrKeyNotFound) || errors.Is(err, component.ErrKeyAlreadyExists) {
+		c.tracker.Success()
+	} else {
+		c.tracker.Error(err)
+	}
+	return err
+}
+
+func (c *trackedCache) Get(ctx context.Context, key string) ([]byte, error) {
+	b, err := c.c.Get(ctx, key)
+	return b, c.record(err)
+}
+
+func (c *trackedCache) Set(ctx context.Context, key string, value []byte, ttl *time.Duration) error {
+	return c.record(c.c.Set(ctx, key, value, ttl))
+}
+
+func (c *trackedCache) SetMulti(ctx context.Context, items map[string]cache.TTLItem) error {
+	return c.record(c.c.SetMulti(ctx, items))
+}
+
+func (c *trackedCache) Add(ctx context.Context, key string, value []byte, ttl *time.Duration) error {
+	return c.record(c.c.Add(ctx, key, value, ttl))
+}
+
+func (c *trackedCache) Delete(ctx context.Context, key string) error {
+	return c.record(c.c.Delete(ctx, key))
+}
+
+func (c *trackedCache) Close(ctx context.Context) error {
+	c.tracker.Close()
+	return c.c.Close(ctx)
+}
@@ -0,0 +1,55 @@
+package health
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/docs"
+)
+
+// Config contains thresholds that determine when components are considered
+// unhealthy.
+type Config struct {
+	InactivityTimeout string `json:"inactivity_timeout" yaml:"inactivity_timeout"`
+	ErrorTimeout      string `json:"error_timeout" yaml:"error_timeout"`
+}
+
+// NewConfig returns a health config with default values, where no thresholds
+// are set.
+func NewConfig() Config {
+	return Config{
+		InactivityTimeout: "",
+		ErrorTimeout:      "",
+	}
+}
+
+// Thresholds parses the durations of the config into a set of thresholds.
+func (c Config) Thresholds() (t Thresholds, err error) {
+	if c.InactivityTimeout != "" {
+		if t.InactivityTimeout, err = time.ParseDuration(c.InactivityTimeout); err != nil {
+			return t, fmt.Errorf("failed to parse inactivity_timeout: %w", err)
+		}
+	}
+	if c.ErrorTimeout != "" {
+		if t.ErrorTimeout, err = time.ParseDuration(c.ErrorTimeout); err != nil {
+			return t, fmt.Errorf("failed to parse error_timeout: %w", err)
+		}
+	}
+	return t, nil
+}
+
+// Spec returns a field spec for the health configuration fields.
+func Spec() docs.FieldSpec {
+	return docs.FieldObject(
+		"health", "Thresholds that determine when the components reported by the `/health` endpoint are considered unhealthy.",
+	).WithChildren(
+		docs.FieldString(
+			"inactivity_timeout", "An optional duration after which inputs and outputs that have not successfully consumed or delivered a message are considered unhealthy. Leave empty to disable.",
+			"5m", "1h",
+		).HasDefault(""),
+		docs.FieldString(
+			"error_timeout", "An optional duration after which components that have continuously failed, with errors occurring and no successes, are considered unhealthy. Components that are failing for a shorter period are reported as degraded. Leave empty to disable.",
+			"30s", "5m",
+		).HasDefault(""),
+	).Advanced()
+}
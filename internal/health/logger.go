@@ -0,0 +1,50 @@
+package health
+
+import (
+	"fmt"
+
+	"github.com/benthosdev/benthos/v4/internal/log"
+)
+
+// trackedLogger wraps the logger of a component and records each error log as
+// an error of the component.
+type trackedLogger struct {
+	log.Modular
+
+	tracker *Tracker
+}
+
+// Logger returns a wrapped variant of a logger that records errors logged by
+// the component, and any children that derive their logger from it, with the
+// tracker.
+func (t *Tracker) Logger(l log.Modular) log.Modular {
+	return &trackedLogger{Modular: l, tracker: t}
+}
+
+func (l *trackedLogger) WithFields(fields map[string]string) log.Modular {
+	return &trackedLogger{Modular: l.Modular.WithFields(fields), tracker: l.tracker}
+}
+
+func (l *trackedLogger) With(keyValues ...interface{}) log.Modular {
+	return &trackedLogger{Modular: l.Modular.With(keyValues...), tracker: l.tracker}
+}
+
+func (l *trackedLogger) Errorf(format string, v ...interface{}) {
+	l.Modular.Errorf(format, v...)
+	l.tracker.errorMessage(fmt.Sprintf(format, v...))
+}
+
+func (l *trackedLogger) Errorln(message string) {
+	l.Modular.Errorln(message)
+	l.tracker.errorMessage(message)
+}
+
+// RecordSuccess records a successful operation with the tracker of a logger
+// obtained from Tracker.Logger, which allows components to record their
+// successes in place alongside the errors they log. This is a no-op for loggers
+// that are not tracked.
+func RecordSuccess(l log.Modular) {
+	if tl, ok := l.(*trackedLogger); ok {
+		tl.tracker.Success()
+	}
+}
@@ -0,0 +1,4 @@
+// Package health provides per-component tracking of connection state, errors
+// and successful activity, which is used in order to report the health of the
+// inputs, outputs, caches and processors of a Benthos service.
+package health
@@ -0,0 +1,270 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kinds of component tracked by a registry.
+const (
+	KindInput     = "input"
+	KindOutput    = "output"
+	KindCache     = "cache"
+	KindProcessor = "processor"
+)
+
+// States of health reported for a component.
+const (
+	StateHealthy   = "healthy"
+	StateDegraded  = "degraded"
+	StateUnhealthy = "unhealthy"
+)
+
+// Thresholds determine when a component is considered unhealthy. A zero value
+// disables the respective check.
+type Thresholds struct {
+	// The duration after which an input or output that has not successfully
+	// consumed or delivered a message is unhealthy.
+	InactivityTimeout time.Duration
+
+	// The duration after which a component that has continuously failed is
+	// unhealthy.
+	ErrorTimeout time.Duration
+}
+
+// Status describes the health of a single component at a point in time.
+type Status struct {
+	Stream      string     `json:"stream,omitempty"`
+	Kind        string     `json:"kind"`
+	Path        string     `json:"path"`
+	Label       string     `json:"label,omitempty"`
+	State       string     `json:"state"`
+	Reason      string     `json:"reason,omitempty"`
+	Connected   *bool      `json:"connected,omitempty"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+}
+
+//------------------------------------------------------------------------------
+
+// Registry keeps track of the components of a service.
+type Registry struct {
+	mut      sync.Mutex
+	trackers map[*Tracker]struct{}
+
+	now func() time.Time
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		trackers: map[*Tracker]struct{}{},
+		now:      time.Now,
+	}
+}
+
+// Register a new component with the registry and returns a tracker used for
+// recording its activity. The component remains registered until the tracker
+// is closed.
+func (r *Registry) Register(stream, kind, path, label string) *Tracker {
+	t := &Tracker{
+		reg:        r,
+		stream:     stream,
+		kind:       kind,
+		path:       path,
+		label:      label,
+		registered: r.now(),
+	}
+	r.mut.Lock()
+	r.trackers[t] = struct{}{}
+	r.mut.Unlock()
+	return t
+}
+
+func (r *Registry) deregister(t *Tracker) {
+	r.mut.Lock()
+	delete(r.trackers, t)
+	r.mut.Unlock()
+}
+
+// Statuses returns the current health of all registered components evaluated
+// against a set of thresholds, sorted by stream and then path.
+func (r *Registry) Statuses(thresholds Thresholds) []Status {
+	r.mut.Lock()
+	trackers := make([]*Tracker, 0, len(r.trackers))
+	for t := range r.trackers {
+		trackers = append(trackers, t)
+	}
+	r.mut.Unlock()
+
+	now := r.now()
+	statuses := make([]Status, 0, len(trackers))
+	for _, t := range trackers {
+		statuses = append(statuses, t.status(now, thresholds))
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Stream != statuses[j].Stream {
+			return statuses[i].Stream < statuses[j].Stream
+		}
+		return statuses[i].Path < statuses[j].Path
+	})
+	return statuses
+}
+
+// Handler returns an HTTP handler that responds with the current health of all
+// registered components as a JSON object. When any component is unhealthy the
+// response has a 503 status code. A stream query parameter can be provided in
+// order to only report components of a given stream.
+func (r *Registry) Handler(thresholds Thresholds) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		stream := req.URL.Query().Get("stream")
+
+		healthy := true
+		components := []Status{}
+		for _, s := range r.Statuses(thresholds) {
+			if stream != "" && s.Stream != stream {
+				continue
+			}
+			if s.State == StateUnhealthy {
+				healthy = false
+			}
+			components = append(components, s)
+		}
+
+		resBytes, err := json.Marshal(struct {
+			Healthy    bool     `json:"healthy"`
+			Components []Status `json:"components"`
+		}{
+			Healthy:    healthy,
+			Components: components,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_, _ = w.Write(resBytes)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// Tracker records the activity of a single registered component.
+type Tracker struct {
+	reg *Registry
+
+	stream     string
+	kind       string
+	path       string
+	label      string
+	registered time.Time
+
+	mut          sync.Mutex
+	connected    func() bool
+	lastSuccess  time.Time
+	lastError    string
+	lastErrorAt  time.Time
+	failingSince time.Time
+}
+
+// SetConnected sets a function used to determine whether the component is
+// currently connected to its target.
+func (t *Tracker) SetConnected(fn func() bool) {
+	t.mut.Lock()
+	t.connected = fn
+	t.mut.Unlock()
+}
+
+// Success records a successful operation of the component.
+func (t *Tracker) Success() {
+	now := t.reg.now()
+	t.mut.Lock()
+	t.lastSuccess = now
+	t.failingSince = time.Time{}
+	t.mut.Unlock()
+}
+
+// Error records an error from the component.
+func (t *Tracker) Error(err error) {
+	t.errorMessage(err.Error())
+}
+
+func (t *Tracker) errorMessage(msg string) {
+	now := t.reg.now()
+	t.mut.Lock()
+	t.lastError = strings.TrimSpace(msg)
+	t.lastErrorAt = now
+	if t.failingSince.IsZero() {
+		t.failingSince = now
+	}
+	t.mut.Unlock()
+}
+
+// Close removes the component from the registry.
+func (t *Tracker) Close() {
+	t.reg.deregister(t)
+}
+
+func (t *Tracker) status(now time.Time, thresholds Thresholds) Status {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	s := Status{
+		Stream:    t.stream,
+		Kind:      t.kind,
+		Path:      t.path,
+		Label:     t.label,
+		State:     StateHealthy,
+		LastError: t.lastError,
+	}
+	if !t.lastSuccess.IsZero() {
+		lastSuccess := t.lastSuccess
+		s.LastSuccess = &lastSuccess
+	}
+	if !t.lastErrorAt.IsZero() {
+		lastErrorAt := t.lastErrorAt
+		s.LastErrorAt = &lastErrorAt
+	}
+
+	if t.connected != nil {
+		connected := t.connected()
+		s.Connected = &connected
+		if !connected {
+			s.State, s.Reason = StateUnhealthy, "not connected"
+			return s
+		}
+	}
+
+	if thresholds.InactivityTimeout > 0 && (t.kind == KindInput || t.kind == KindOutput) {
+		lastActive := t.lastSuccess
+		if lastActive.IsZero() {
+			lastActive = t.registered
+		}
+		if inactive := now.Sub(lastActive); inactive > thresholds.InactivityTimeout {
+			s.State = StateUnhealthy
+			s.Reason = fmt.Sprintf("no messages for %v", inactive.Round(time.Second))
+			return s
+		}
+	}
+
+	if !t.failingSince.IsZero() {
+		failing := now.Sub(t.failingSince)
+		if thresholds.ErrorTimeout > 0 && failing > thresholds.ErrorTimeout {
+			s.State = StateUnhealthy
+		} else {
+			s.State = StateDegraded
+		}
+		s.Reason = fmt.Sprintf("failing for %v", failing.Round(time.Second))
+	}
+	return s
+}
@@ -0,0 +1,167 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/log"
+)
+
+func TestRegistryStatuses(t *testing.T) {
+	now := time.Unix(1000, 0)
+	reg := NewRegistry()
+	reg.now = func() time.Time { return now }
+
+	in := reg.Register("", KindInput, "root.input", "")
+	connected := true
+	in.SetConnected(func() bool { return connected })
+
+	out := reg.Register("", KindOutput, "root.output", "foo")
+	cache := reg.Register("", KindCache, "root.cache_resources", "bar")
+
+	thresholds := Thresholds{
+		InactivityTimeout: time.Minute,
+		ErrorTimeout:      time.Minute,
+	}
+
+	now = now.Add(time.Second * 30)
+	in.Success()
+	out.Success()
+
+	statuses := reg.Statuses(thresholds)
+	require.Len(t, statuses, 3)
+	assert.Equal(t, "root.cache_resources", statuses[0].Path)
+	assert.Equal(t, "root.input", statuses[1].Path)
+	assert.Equal(t, "root.output", statuses[2].Path)
+	for _, s := range statuses {
+		assert.Equal(t, StateHealthy, s.State, s.Path)
+	}
+	require.NotNil(t, statuses[1].Connected)
+	assert.True(t, *statuses[1].Connected)
+	assert.Nil(t, statuses[0].Connected)
+
+	connected = false
+	out.Error(errors.New("nope"))
+	now = now.Add(time.Second * 50)
+	cache.Success()
+
+	statuses = reg.Statuses(thresholds)
+	assert.Equal(t, StateHealthy, statuses[0].State)
+	assert.Equal(t, StateUnhealthy, statuses[1].State)
+	assert.Equal(t, "not connected", statuses[1].Reason)
+	assert.Equal(t, StateDegraded, statuses[2].State)
+	assert.Equal(t, "failing for 50s", statuses[2].Reason)
+	assert.Equal(t, "nope", statuses[2].LastError)
+
+	connected = true
+	now = now.Add(time.Second * 20)
+
+	statuses = reg.Statuses(thresholds)
+	assert.Equal(t, StateHealthy, statuses[0].State)
+	assert.Equal(t, StateUnhealthy, statuses[1].State)
+	assert.Equal(t, "no messages for 1m10s", statuses[1].Reason)
+	assert.Equal(t, StateUnhealthy, statuses[2].State)
+	assert.Equal(t, "no messages for 1m10s", statuses[2].Reason)
+
+	// Caches are not subject to the inactivity timeout.
+	now = now.Add(time.Hour)
+	out.Success()
+	in.Close()
+
+	statuses = reg.Statuses(thresholds)
+	require.Len(t, statuses, 2)
+	assert.Equal(t, StateHealthy, statuses[0].State)
+	assert.Equal(t, StateHealthy, statuses[1].State)
+	assert.Equal(t, "nope", statuses[1].LastError)
+
+	// Without thresholds failures are only ever degraded.
+	out.Error(errors.New("nope again"))
+	now = now.Add(time.Hour)
+
+	statuses = reg.Statuses(Thresholds{})
+	assert.Equal(t, StateHealthy, statuses[0].State)
+	assert.Equal(t, StateDegraded, statuses[1].State)
+
+	cache.Error(errors.New("cache down"))
+	now = now.Add(time.Minute * 2)
+
+	statuses = reg.Statuses(Thresholds{ErrorTimeout: time.Minute})
+	assert.Equal(t, StateUnhealthy, statuses[0].State)
+	assert.Equal(t, "failing for 2m0s", statuses[0].Reason)
+	assert.Equal(t, "cache down", statuses[0].LastError)
+	assert.Equal(t, StateUnhealthy, statuses[1].State)
+	assert.Equal(t, "failing for 1h2m0s", statuses[1].Reason)
+}
+
+func TestRegistryHandler(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register("foo", KindInput, "root.input", "").Success()
+	reg.Register("bar", KindOutput, "root.output", "").SetConnected(func() bool { return false })
+
+	type response struct {
+		Healthy    bool     `json:"healthy"`
+		Components []Status `json:"components"`
+	}
+
+	get := func(url string) (int, response) {
+		rec := httptest.NewRecorder()
+		reg.Handler(Thresholds{})(rec, httptest.NewRequest("GET", url, nil))
+
+		var res response
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		return rec.Code, res
+	}
+
+	code, res := get("/health")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.False(t, res.Healthy)
+	require.Len(t, res.Components, 2)
+	assert.Equal(t, "bar", res.Components[0].Stream)
+	assert.Equal(t, StateUnhealthy, res.Components[0].State)
+	assert.Equal(t, "foo", res.Components[1].Stream)
+	assert.Equal(t, StateHealthy, res.Components[1].State)
+
+	code, res = get("/health?stream=foo")
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, res.Healthy)
+	require.Len(t, res.Components, 1)
+	assert.Equal(t, KindInput, res.Components[0].Kind)
+}
+
+func TestTrackerLogger(t *testing.T) {
+	reg := NewRegistry()
+	tracker := reg.Register("", KindInput, "root.input", "")
+
+	logger := tracker.Logger(log.Noop()).WithFields(map[string]string{"foo": "bar"})
+	logger.Warnln("not an error")
+	assert.Equal(t, "", reg.Statuses(Thresholds{})[0].LastError)
+
+	logger.Errorf("failed to connect: %v\n", "nope")
+	status := reg.Statuses(Thresholds{})[0]
+	assert.Equal(t, "failed to connect: nope", status.LastError)
+	assert.Equal(t, StateDegraded, status.State)
+}
+
+func TestRecordSuccess(t *testing.T) {
+	reg := NewRegistry()
+	tracker := reg.Register("", KindOutput, "root.output", "")
+
+	logger := tracker.Logger(log.Noop())
+	logger.Errorln("failed to send")
+	assert.Equal(t, StateDegraded, reg.Statuses(Thresholds{})[0].State)
+
+	RecordSuccess(logger.WithFields(map[string]string{"foo": "bar"}))
+	status := reg.Statuses(Thresholds{})[0]
+	assert.NotNil(t, status.LastSuccess)
+	assert.Equal(t, StateHealthy, status.State)
+
+	// Untracked loggers are ignored.
+	RecordSuccess(log.Noop())
+}
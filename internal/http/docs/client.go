@@ -18,7 +18,7 @@ func ClientFieldSpec(forOutput bool, extraChildren ...docs.FieldSpec) docs.Field
 			"Content-Type": "application/octet-stream",
 		}).IsInterpolated().Map().HasDefault(map[string]interface{}{
 			"Content-Type": "application/octet-stream",
-		}),
+		}).Secret(),
 		docs.FieldAdvanced("metadata", "Specify optional matching rules to determine which metadata keys should be added to the HTTP request as headers.").
 			WithChildren(metadata.IncludeFilterDocs()...),
 	}
@@ -41,7 +41,7 @@ func SASLFieldSpec() docs.FieldSpec {
 			"plain", "Plain text SASL authentication.",
 		),
 		docs.FieldCommon("user", "A SASL plain text username. It is recommended that you use environment variables to populate this field.", "${USER}"),
-		docs.FieldCommon("password", "A SASL plain text password. It is recommended that you use environment variables to populate this field.", "${PASSWORD}"),
+		docs.FieldCommon("password", "A SASL plain text password. It is recommended that you use environment variables to populate this field.", "${PASSWORD}").Secret(),
 	)
 }
 
@@ -0,0 +1,86 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+
+	"github.com/benthosdev/benthos/v4/internal/config/remote"
+)
+
+func init() {
+	if err := remote.GlobalFetchers.Add("s3", newS3ConfigFetcher(aws.NewConfig())); err != nil {
+		panic(err)
+	}
+}
+
+// s3ConfigFetcher obtains config files from S3 with URLs of the form
+// `s3://<bucket>/<key>`. Credentials and region are obtained from the
+// environment in the same way as the AWS CLI.
+type s3ConfigFetcher struct {
+	conf *aws.Config
+
+	clientOnce sync.Once
+	client     s3iface.S3API
+	clientErr  error
+}
+
+func newS3ConfigFetcher(conf *aws.Config) *s3ConfigFetcher {
+	return &s3ConfigFetcher{conf: conf}
+}
+
+func (s *s3ConfigFetcher) getClient() (s3iface.S3API, error) {
+	// The session is created lazily as the environment is not necessarily
+	// populated (from env files) at the time that the fetcher is registered.
+	s.clientOnce.Do(func() {
+		var sess *session.Session
+		if sess, s.clientErr = session.NewSessionWithOptions(session.Options{
+			Config:            *s.conf,
+			SharedConfigState: session.SharedConfigEnable,
+		}); s.clientErr == nil {
+			s.client = s3.New(sess)
+		}
+	})
+	return s.client, s.clientErr
+}
+
+func (s *s3ConfigFetcher) Fetch(ctx context.Context, u *url.URL, etag string) ([]byte, string, error) {
+	client, err := s.getClient()
+	if err != nil {
+		return nil, "", err
+	}
+
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(u.Host),
+		Key:    aws.String(strings.TrimPrefix(u.Path, "/")),
+	}
+	if etag != "" {
+		input.IfNoneMatch = aws.String(etag)
+	}
+
+	out, err := client.GetObjectWithContext(ctx, input)
+	if err != nil {
+		var reqErr awserr.RequestFailure
+		if errors.As(err, &reqErr) && reqErr.StatusCode() == http.StatusNotModified {
+			return nil, "", remote.ErrNotModified
+		}
+		return nil, "", err
+	}
+	defer out.Body.Close()
+
+	body, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return body, aws.StringValue(out.ETag), nil
+}
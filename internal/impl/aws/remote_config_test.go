@@ -0,0 +1,58 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/config/remote"
+)
+
+func TestS3ConfigFetcher(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/configs/prod.yaml" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`<Error><Code>NoSuchKey</Code><Message>nope</Message></Error>`))
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("foo: bar"))
+	}))
+	t.Cleanup(server.Close)
+
+	f := newS3ConfigFetcher(aws.NewConfig().
+		WithEndpoint(server.URL).
+		WithS3ForcePathStyle(true).
+		WithRegion("eu-west-1").
+		WithCredentials(credentials.NewStaticCredentials("xxxxx", "xxxxx", "xxxxx")))
+
+	u, err := url.Parse("s3://configs/prod.yaml")
+	require.NoError(t, err)
+
+	body, etag, err := f.Fetch(context.Background(), u, "")
+	require.NoError(t, err)
+	assert.Equal(t, "foo: bar", string(body))
+	assert.Equal(t, `"v1"`, etag)
+
+	_, _, err = f.Fetch(context.Background(), u, etag)
+	assert.True(t, errors.Is(err, remote.ErrNotModified), err)
+
+	u, err = url.Parse("s3://configs/nope.yaml")
+	require.NoError(t, err)
+
+	_, _, err = f.Fetch(context.Background(), u, "")
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, remote.ErrNotModified))
+}
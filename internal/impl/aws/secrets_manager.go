@@ -0,0 +1,88 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+
+	"github.com/benthosdev/benthos/v4/internal/secrets"
+)
+
+func init() {
+	if err := secrets.GlobalProviders.Add("aws_secrets_manager", newSecretsManagerProvider(aws.NewConfig())); err != nil {
+		panic(err)
+	}
+}
+
+// secretsManagerProvider obtains secrets from AWS Secrets Manager, where the
+// key is the name or ARN of the secret followed by an optional field, e.g.
+// `prod/db#password`. When a field is specified the secret is parsed as a JSON
+// object. Credentials and region are obtained from the environment in the same
+// way as the AWS CLI.
+type secretsManagerProvider struct {
+	conf *aws.Config
+
+	clientOnce sync.Once
+	client     secretsmanageriface.SecretsManagerAPI
+	clientErr  error
+}
+
+func newSecretsManagerProvider(conf *aws.Config) *secretsManagerProvider {
+	return &secretsManagerProvider{conf: conf}
+}
+
+func (s *secretsManagerProvider) getClient() (secretsmanageriface.SecretsManagerAPI, error) {
+	// The session is created lazily as the environment is not necessarily
+	// populated (from env files) at the time that the provider is registered.
+	s.clientOnce.Do(func() {
+		var sess *session.Session
+		if sess, s.clientErr = session.NewSessionWithOptions(session.Options{
+			Config:            *s.conf,
+			SharedConfigState: session.SharedConfigEnable,
+		}); s.clientErr == nil {
+			s.client = secretsmanager.New(sess)
+		}
+	})
+	return s.client, s.clientErr
+}
+
+func (s *secretsManagerProvider) Lookup(ctx context.Context, key string) (string, error) {
+	client, err := s.getClient()
+	if err != nil {
+		return "", err
+	}
+
+	id, field := secrets.SplitKey(key)
+	out, err := client.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(id),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var value string
+	switch {
+	case out.SecretString != nil:
+		value = *out.SecretString
+	case out.SecretBinary != nil:
+		value = string(out.SecretBinary)
+	default:
+		return "", errors.New("secret has no value")
+	}
+	if field == "" {
+		return value, nil
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &data); err != nil {
+		return "", fmt.Errorf("failed to parse secret as a JSON object: %w", err)
+	}
+	return secrets.SelectField(data, field)
+}
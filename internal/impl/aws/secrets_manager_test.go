@@ -0,0 +1,72 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsManagerProvider(t *testing.T) {
+	secretValues := map[string]string{
+		"foo":     "foovalue",
+		"prod/db": `{"user":"admin","password":"hunter2"}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" {
+			http.Error(w, "unexpected target", http.StatusBadRequest)
+			return
+		}
+
+		var input struct {
+			SecretID string `json:"SecretId"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&input))
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		value, exists := secretValues[input.SecretID]
+		if !exists {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException","message":"Secrets Manager can't find the specified secret."}`))
+			return
+		}
+
+		resBytes, err := json.Marshal(map[string]interface{}{
+			"Name":         input.SecretID,
+			"SecretString": value,
+		})
+		require.NoError(t, err)
+		_, _ = w.Write(resBytes)
+	}))
+	t.Cleanup(server.Close)
+
+	p := newSecretsManagerProvider(aws.NewConfig().
+		WithEndpoint(server.URL).
+		WithRegion("eu-west-1").
+		WithCredentials(credentials.NewStaticCredentials("xxxxx", "xxxxx", "xxxxx")))
+
+	out, err := p.Lookup(context.Background(), "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "foovalue", out)
+
+	out, err = p.Lookup(context.Background(), "prod/db#password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", out)
+
+	_, err = p.Lookup(context.Background(), "prod/db#nope")
+	require.Error(t, err)
+
+	_, err = p.Lookup(context.Background(), "foo#bar")
+	require.Error(t, err)
+
+	_, err = p.Lookup(context.Background(), "nope")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ResourceNotFoundException")
+}
@@ -12,8 +12,8 @@ func FieldSpecs() docs.FieldSpecs {
 			WithChildren(
 				docs.FieldString("profile", "A profile from `~/.aws/credentials` to use.").HasDefault(""),
 				docs.FieldString("id", "The ID of credentials to use.").HasDefault(""),
-				docs.FieldString("secret", "The secret for the credentials being used.").HasDefault(""),
-				docs.FieldString("token", "The token for the credentials being used, required when using short term credentials.").HasDefault(""),
+				docs.FieldString("secret", "The secret for the credentials being used.").HasDefault("").Secret(),
+				docs.FieldString("token", "The token for the credentials being used, required when using short term credentials.").HasDefault("").Secret(),
 				docs.FieldString("role", "A role ARN to assume.").HasDefault(""),
 				docs.FieldString("role_external_id", "An external ID to provide when assuming a role.").HasDefault(""),
 			),
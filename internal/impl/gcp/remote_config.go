@@ -0,0 +1,70 @@
+package gcp
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+
+	"cloud.google.com/go/storage"
+
+	"github.com/benthosdev/benthos/v4/internal/config/remote"
+)
+
+func init() {
+	f := &gcsConfigFetcher{}
+	for _, scheme := range []string{"gcs", "gs"} {
+		if err := remote.GlobalFetchers.Add(scheme, f); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// gcsConfigFetcher obtains config files from Google Cloud Storage with URLs of
+// the form `gcs://<bucket>/<object>`. Credentials are obtained from the
+// environment in the same way as the gcloud CLI.
+type gcsConfigFetcher struct {
+	clientOnce sync.Once
+	client     *storage.Client
+	clientErr  error
+}
+
+func (g *gcsConfigFetcher) getClient() (*storage.Client, error) {
+	// The client is created lazily as the environment is not necessarily
+	// populated (from env files) at the time that the fetcher is registered.
+	g.clientOnce.Do(func() {
+		g.client, g.clientErr = storage.NewClient(context.Background())
+	})
+	return g.client, g.clientErr
+}
+
+func (g *gcsConfigFetcher) Fetch(ctx context.Context, u *url.URL, etag string) ([]byte, string, error) {
+	client, err := g.getClient()
+	if err != nil {
+		return nil, "", err
+	}
+
+	obj := client.Bucket(u.Host).Object(strings.TrimPrefix(u.Path, "/"))
+	attrs, err := obj.Attrs(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	if etag != "" && attrs.Etag == etag {
+		return nil, "", remote.ErrNotModified
+	}
+
+	// Read the generation described by the attributes so that the contents
+	// match the ETag returned.
+	r, err := obj.Generation(attrs.Generation).NewReader(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	defer r.Close()
+
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, "", err
+	}
+	return body, attrs.Etag, nil
+}
@@ -0,0 +1,247 @@
+package generic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/circuitbreaker"
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/internal/component/processor"
+	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	ooutput "github.com/benthosdev/benthos/v4/internal/old/output"
+	"github.com/benthosdev/benthos/v4/internal/shutdown"
+)
+
+func init() {
+	bundle.AllOutputs.Add(newCircuitBreaker, docs.ComponentSpec{
+		Name:    ooutput.TypeCircuitBreaker,
+		Status:  docs.StatusBeta,
+		Version: "4.0.0",
+		Summary: `
+Wraps a child output with a circuit breaker that stops writes from being attempted whilst the output is failing, either failing them immediately or diverting them to a fallback output.`,
+		Description: `
+Whilst a downstream target is hard down outputs such as ` + "`retry`" + `, along with the backoff of the child output itself, will continue to attempt writes. This output tracks the outcome of each write to the child output, and once the ratio of failed writes within a ` + "`window`" + ` reaches ` + "`failure_ratio`" + ` (with at least ` + "`min_requests`" + ` writes observed) the breaker opens.
+
+Whilst the breaker is open messages are not written to the child output. If a ` + "`fallback`" + ` output is configured then messages are written to it instead, otherwise they are rejected, which results in a nack that is propagated back to the input. In order to avoid a busy loop of redelivered messages a rejection is only made once the remainder of the open period has passed, which also applies backpressure to the input.
+
+After the open period, which starts at ` + "`backoff.initial_interval`" + ` and grows exponentially up to ` + "`backoff.max_interval`" + ` each time the breaker reopens, the breaker becomes half-open and permits ` + "`half_open_requests`" + ` probe writes through to the child output. If all probes succeed the breaker closes, and if any fail it opens once more.
+
+` + "```yaml" + `
+output:
+  circuit_breaker:
+    output:
+      http_client:
+        url: http://foo:4195/post
+    fallback:
+      file:
+        path: /usr/local/benthos/while_foo_is_down.jsonl
+        codec: lines
+    failure_ratio: 0.5
+    min_requests: 20
+` + "```" + `
+
+### Monitoring
+
+The state of the breaker is exposed as the gauge metric ` + "`circuit_breaker_state`" + `, where ` + "`0`" + ` is closed, ` + "`1`" + ` is open and ` + "`2`" + ` is half-open, along with the counters ` + "`circuit_breaker_opened`" + ` and ` + "`circuit_breaker_rejected`" + `.
+
+The state is also available from the HTTP server of Benthos at the endpoint ` + "`/circuit_breakers/<path>`" + `, where ` + "`<path>`" + ` is the path of the child output within the config, e.g. ` + "`/circuit_breakers/root.output.circuit_breaker.output`" + `.`,
+		Categories: []string{
+			"Utility",
+		},
+		Config: docs.FieldComponent().WithChildren(append(docs.FieldSpecs{
+			docs.FieldCommon("output", "The child output to write messages to.").HasType(docs.FieldTypeOutput),
+			docs.FieldCommon("fallback", "An optional output to write messages to whilst the breaker is open.").HasType(docs.FieldTypeOutput).Optional(),
+		}, circuitbreaker.FieldSpecs()...)...).ChildDefaultAndTypesFromStruct(ooutput.NewCircuitBreakerConfig()),
+	})
+}
+
+//------------------------------------------------------------------------------
+
+func newCircuitBreaker(conf ooutput.Config, mgr bundle.NewManagement, pipelines ...processor.PipelineConstructorFunc) (output.Streamed, error) {
+	pipelines = ooutput.AppendProcessorsFromConfig(conf, mgr, pipelines...)
+
+	cConf := conf.CircuitBreaker
+	if cConf.Output == nil {
+		return nil, errors.New("cannot create circuit_breaker output without a child output")
+	}
+
+	oMgr := mgr.IntoPath("circuit_breaker", "output").(bundle.NewManagement)
+	out, err := oMgr.NewOutput(*cConf.Output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output '%v': %v", cConf.Output.Type, err)
+	}
+
+	var fallback output.Streamed
+	if cConf.Fallback != nil {
+		fMgr := mgr.IntoPath("circuit_breaker", "fallback").(bundle.NewManagement)
+		if fallback, err = fMgr.NewOutput(*cConf.Fallback); err != nil {
+			return nil, fmt.Errorf("failed to create fallback '%v': %v", cConf.Fallback.Type, err)
+		}
+	}
+
+	breaker, err := circuitbreaker.New(cConf.Config, mgr.Metrics())
+	if err != nil {
+		return nil, err
+	}
+	breaker.RegisterEndpoint(mgr, "root."+query.SliceToDotPath(oMgr.Path()...))
+
+	var c *circuitBreakerOutput
+	if c, err = newCircuitBreakerOutput(out, fallback, breaker); err != nil {
+		return nil, err
+	}
+	return ooutput.WrapWithPipelines(c, pipelines...)
+}
+
+type circuitBreakerOutput struct {
+	transactions <-chan message.Transaction
+
+	breaker *circuitbreaker.Breaker
+
+	output         output.Streamed
+	outputTSChan   chan message.Transaction
+	fallback       output.Streamed
+	fallbackTSChan chan message.Transaction
+
+	shutSig *shutdown.Signaller
+}
+
+func newCircuitBreakerOutput(out, fallback output.Streamed, breaker *circuitbreaker.Breaker) (*circuitBreakerOutput, error) {
+	c := &circuitBreakerOutput{
+		breaker:      breaker,
+		output:       out,
+		outputTSChan: make(chan message.Transaction),
+		fallback:     fallback,
+		shutSig:      shutdown.NewSignaller(),
+	}
+	if err := c.output.Consume(c.outputTSChan); err != nil {
+		return nil, err
+	}
+	if c.fallback != nil {
+		c.fallbackTSChan = make(chan message.Transaction)
+		if err := c.fallback.Consume(c.fallbackTSChan); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+//------------------------------------------------------------------------------
+
+// Consume assigns a new messages channel for the output to read.
+func (c *circuitBreakerOutput) Consume(ts <-chan message.Transaction) error {
+	if c.transactions != nil {
+		return component.ErrAlreadyStarted
+	}
+	c.transactions = ts
+
+	go c.loop()
+	return nil
+}
+
+// Connected returns a boolean indicating whether this output is currently
+// connected to its target.
+func (c *circuitBreakerOutput) Connected() bool {
+	if c.fallback != nil && c.breaker.State() == circuitbreaker.StateOpen {
+		return c.fallback.Connected()
+	}
+	return c.output.Connected()
+}
+
+//------------------------------------------------------------------------------
+
+func (c *circuitBreakerOutput) loop() {
+	outputs := []output.Streamed{c.output}
+	if c.fallback != nil {
+		outputs = append(outputs, c.fallback)
+	}
+	defer func() {
+		close(c.outputTSChan)
+		if c.fallbackTSChan != nil {
+			close(c.fallbackTSChan)
+		}
+		closeAllOutputs(outputs)
+		c.shutSig.ShutdownComplete()
+	}()
+
+	ctx, done := c.shutSig.CloseAtLeisureCtx(context.Background())
+	defer done()
+
+	for {
+		var open bool
+		var tran message.Transaction
+
+		select {
+		case tran, open = <-c.transactions:
+			if !open {
+				return
+			}
+		case <-c.shutSig.CloseAtLeisureChan():
+			return
+		}
+
+		ticket, err := c.breaker.Allow()
+		if err != nil {
+			if c.fallbackTSChan == nil {
+				if !c.waitFor(c.breaker.RetryAfter()) {
+					return
+				}
+				_ = tran.Ack(ctx, err)
+				continue
+			}
+			select {
+			case c.fallbackTSChan <- tran:
+			case <-c.shutSig.CloseAtLeisureChan():
+				return
+			}
+			continue
+		}
+
+		ackFn := tran.Ack
+		select {
+		case c.outputTSChan <- message.NewTransactionFunc(tran.Payload, func(ctx context.Context, err error) error {
+			ticket.Done(err)
+			return ackFn(ctx, err)
+		}):
+		case <-c.shutSig.CloseAtLeisureChan():
+			ticket.Cancel()
+			return
+		}
+	}
+}
+
+// waitFor blocks for a period of time, returning false if the output was
+// closed in the meantime.
+func (c *circuitBreakerOutput) waitFor(d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-c.shutSig.CloseAtLeisureChan():
+		return false
+	}
+	return true
+}
+
+// CloseAsync shuts down the output and stops processing requests.
+func (c *circuitBreakerOutput) CloseAsync() {
+	c.shutSig.CloseAtLeisure()
+}
+
+// WaitForClose blocks until the output has closed down.
+func (c *circuitBreakerOutput) WaitForClose(timeout time.Duration) error {
+	select {
+	case <-c.shutSig.HasClosedChan():
+	case <-time.After(timeout):
+		return component.ErrTimeout
+	}
+	return nil
+}
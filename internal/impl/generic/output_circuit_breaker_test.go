@@ -0,0 +1,171 @@
+package generic
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/circuitbreaker"
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/internal/manager/mock"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	ooutput "github.com/benthosdev/benthos/v4/internal/old/output"
+)
+
+var _ output.Streamed = &circuitBreakerOutput{}
+
+func testCircuitBreakerOutput(t *testing.T, withFallback bool, openFor string) (readChan chan message.Transaction, out, fallback *mock.OutputChanneled) {
+	t.Helper()
+
+	conf := circuitbreaker.NewConfig()
+	conf.MinRequests = 1
+	conf.FailureRatio = 1
+	conf.Backoff.InitialInterval = openFor
+
+	breaker, err := circuitbreaker.New(conf, metrics.Noop())
+	require.NoError(t, err)
+
+	out = &mock.OutputChanneled{}
+	var fallbackOut output.Streamed
+	if withFallback {
+		fallback = &mock.OutputChanneled{}
+		fallbackOut = fallback
+	}
+
+	c, err := newCircuitBreakerOutput(out, fallbackOut, breaker)
+	require.NoError(t, err)
+
+	readChan = make(chan message.Transaction)
+	require.NoError(t, c.Consume(readChan))
+
+	t.Cleanup(func() {
+		c.CloseAsync()
+		require.NoError(t, c.WaitForClose(time.Second))
+	})
+	return
+}
+
+func TestCircuitBreakerOutputFastFail(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	readChan, out, _ := testCircuitBreakerOutput(t, false, "100ms")
+	resChan := make(chan error, 1)
+
+	select {
+	case readChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("first")}), resChan):
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+
+	var tran message.Transaction
+	select {
+	case tran = <-out.TChan:
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+	require.NoError(t, tran.Ack(tCtx, errors.New("target is down")))
+
+	select {
+	case err := <-resChan:
+		require.EqualError(t, err, "target is down")
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+
+	rejectStarted := time.Now()
+	select {
+	case readChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("second")}), resChan):
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+
+	// The rejection is held back until the open period has passed.
+	select {
+	case err := <-resChan:
+		require.Equal(t, circuitbreaker.ErrOpen, err)
+		assert.GreaterOrEqual(t, int64(time.Since(rejectStarted)), int64(time.Millisecond*50))
+	case <-out.TChan:
+		t.Fatal("unexpected write to child output")
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+}
+
+func TestCircuitBreakerOutputFallback(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	readChan, out, fallback := testCircuitBreakerOutput(t, true, "1h")
+	resChan := make(chan error, 1)
+
+	select {
+	case readChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("first")}), resChan):
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+
+	var tran message.Transaction
+	select {
+	case tran = <-out.TChan:
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+	require.NoError(t, tran.Ack(tCtx, errors.New("target is down")))
+	<-resChan
+
+	select {
+	case readChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("second")}), resChan):
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+
+	select {
+	case tran = <-fallback.TChan:
+	case <-out.TChan:
+		t.Fatal("unexpected write to child output")
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+	assert.Equal(t, "second", string(tran.Payload.Get(0).Get()))
+	require.NoError(t, tran.Ack(tCtx, nil))
+
+	select {
+	case err := <-resChan:
+		require.NoError(t, err)
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+}
+
+func TestCircuitBreakerOutputConfigFallback(t *testing.T) {
+	var conf ooutput.Config
+	require.NoError(t, yaml.Unmarshal([]byte(`
+circuit_breaker:
+  output:
+    drop: {}
+  fallback: {}
+`), &conf))
+	assert.Nil(t, conf.CircuitBreaker.Fallback)
+	require.NotNil(t, conf.CircuitBreaker.Output)
+	assert.Equal(t, "drop", conf.CircuitBreaker.Output.Type)
+
+	require.NoError(t, yaml.Unmarshal([]byte(`
+circuit_breaker:
+  output:
+    drop: {}
+  fallback:
+    reject: nope
+  min_requests: 5
+`), &conf))
+	require.NotNil(t, conf.CircuitBreaker.Fallback)
+	assert.Equal(t, "reject", conf.CircuitBreaker.Fallback.Type)
+	assert.Equal(t, 5, conf.CircuitBreaker.MinRequests)
+	assert.Equal(t, 0.5, conf.CircuitBreaker.FailureRatio)
+}
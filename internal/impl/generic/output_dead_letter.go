@@ -0,0 +1,423 @@
+package generic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+
+	"github.com/benthosdev/benthos/v4/internal/batch"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/internal/component/processor"
+	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	ooutput "github.com/benthosdev/benthos/v4/internal/old/output"
+	"github.com/benthosdev/benthos/v4/internal/old/util/retries"
+	"github.com/benthosdev/benthos/v4/internal/shutdown"
+)
+
+func init() {
+	bundle.AllOutputs.Add(newDeadLetter, docs.ComponentSpec{
+		Name:    ooutput.TypeDeadLetter,
+		Status:  docs.StatusBeta,
+		Version: "4.0.0",
+		Summary: `
+Writes messages to a child output and routes any messages that either failed processing or could not be delivered after a number of retries to a dead letter output, wrapped with the context of the failure.`,
+		Description: `
+Messages that arrive at this output flagged with a processing error (i.e. where ` + "[`errored()`](/docs/guides/bloblang/functions#errored)" + ` would return ` + "`true`" + `) are not written to the child ` + "`output`" + `, and are instead sent directly to the ` + "`dead_letter_output`" + `. All other messages are written to the child ` + "`output`" + `, and if a write fails it is retried according to the ` + "`max_retries`" + ` and ` + "`backoff`" + ` fields. Once retries are exhausted the failed messages are sent to the ` + "`dead_letter_output`" + ` instead.
+
+A message is only acknowledged once it has been successfully written to either the child ` + "`output`" + ` or the ` + "`dead_letter_output`" + `. If the ` + "`dead_letter_output`" + ` also fails then the error is propagated back to the input, where it will be reattempted.
+
+This removes the need for hand written ` + "`switch`" + ` and ` + "`fallback`" + ` combinations in order to achieve a dead letter queue:
+
+` + "```yaml" + `
+output:
+  dead_letter:
+    output:
+      http_client:
+        url: http://foo:4195/post
+    dead_letter_output:
+      file:
+        path: /usr/local/benthos/dead_letters.jsonl
+        codec: lines
+    max_retries: 3
+` + "```" + `
+
+### Dead Letter Format
+
+Messages sent to the ` + "`dead_letter_output`" + ` are replaced with a JSON document describing the failure:
+
+` + "```json" + `
+{
+  "content": "the original raw content of the message",
+  "metadata": { "kafka_key": "the original metadata of the message" },
+  "error": "the error that caused the message to be dead lettered",
+  "component": "root.output.dead_letter.output",
+  "attempts": 4,
+  "timestamp": "2022-03-10T11:24:56.532Z"
+}
+` + "```" + `
+
+The field ` + "`attempts`" + ` is the number of times delivery to the child ` + "`output`" + ` was attempted, which is zero for messages that failed processing. The field ` + "`component`" + ` is the path of the child output that rejected the message, or for messages that failed processing the path of the processor that flagged the error, e.g. ` + "`root.pipeline.processors.0`" + `. It is omitted when the processor that flagged an error cannot be determined, which is the case when the ` + "`dead_letter`" + ` output is a resource rather than part of the stream config. The original metadata of the message is also retained on the dead letter message itself, with the exception of the processing error flag.
+
+When ` + "`max_retries`" + ` is set to zero delivery to the child ` + "`output`" + ` is retried indefinitely, and therefore only messages that failed processing will reach the ` + "`dead_letter_output`" + `.
+
+### Ordering
+
+Batches are written by up to ` + "`max_in_flight`" + ` workers in parallel, where each worker blocks while the batch it is writing is being retried, which applies back pressure to the input. When ` + "`max_in_flight`" + ` is set to one the order of messages is preserved, but if the child ` + "`output`" + ` has a higher ` + "`max_in_flight`" + ` then it is matched automatically.
+
+During a graceful shutdown batches that are being retried are given the chance to complete, and are only abandoned, and therefore rejected, when the shutdown is forced.
+
+### Batching
+
+When the child output returns an error that identifies the individual messages of a batch that failed then only those messages are retried and ultimately dead lettered. Otherwise the whole batch is retried and dead lettered in order to preserve at-least-once delivery guarantees.`,
+		Categories: []string{
+			"Utility",
+		},
+		Config: docs.FieldComponent().WithChildren(append(docs.FieldSpecs{
+			docs.FieldCommon("output", "The child output to write messages to.").HasType(docs.FieldTypeOutput),
+			docs.FieldCommon("dead_letter_output", "An output to write messages to that either failed processing or could not be delivered to the child output.").HasType(docs.FieldTypeOutput),
+			docs.FieldCommon("max_in_flight", "The maximum number of parallel message batches to have in flight at any given time. Note that if the child output has a higher `max_in_flight` then this output will automatically match it."),
+		}, retries.FieldSpecs()...)...).ChildDefaultAndTypesFromStruct(ooutput.NewDeadLetterConfig()),
+	})
+}
+
+//------------------------------------------------------------------------------
+
+func newDeadLetter(conf ooutput.Config, mgr bundle.NewManagement, pipelines ...processor.PipelineConstructorFunc) (output.Streamed, error) {
+	pipelines = ooutput.AppendProcessorsFromConfig(conf, mgr, pipelines...)
+
+	dConf := conf.DeadLetter
+	if dConf.Output == nil {
+		return nil, errors.New("cannot create dead_letter output without a child output")
+	}
+	if dConf.DeadLetterOutput == nil {
+		return nil, errors.New("cannot create dead_letter output without a dead_letter_output")
+	}
+
+	oMgr := mgr.IntoPath("dead_letter", "output").(bundle.NewManagement)
+	out, err := oMgr.NewOutput(*dConf.Output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output '%v': %v", dConf.Output.Type, err)
+	}
+
+	dMgr := mgr.IntoPath("dead_letter", "dead_letter_output").(bundle.NewManagement)
+	dOut, err := dMgr.NewOutput(*dConf.DeadLetterOutput)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dead_letter_output '%v': %v", dConf.DeadLetterOutput.Type, err)
+	}
+
+	boffCtor, err := dConf.GetCtor()
+	if err != nil {
+		return nil, err
+	}
+
+	var d *deadLetterBroker
+	if d, err = newDeadLetterBroker(out, "root."+query.SliceToDotPath(oMgr.Path()...), dOut, dConf.MaxInFlight, boffCtor, mgr.Logger()); err != nil {
+		return nil, err
+	}
+	return ooutput.WrapWithPipelines(d, pipelines...)
+}
+
+type deadLetterBroker struct {
+	transactions <-chan message.Transaction
+
+	output           output.Streamed
+	outputPath       string
+	outputTSChan     chan message.Transaction
+	deadLetter       output.Streamed
+	deadLetterTSChan chan message.Transaction
+	maxInFlight      int
+
+	backoffCtor func() backoff.BackOff
+	log         log.Modular
+
+	shutSig *shutdown.Signaller
+}
+
+func newDeadLetterBroker(
+	out output.Streamed,
+	outPath string,
+	deadLetter output.Streamed,
+	maxInFlight int,
+	backoffCtor func() backoff.BackOff,
+	log log.Modular,
+) (*deadLetterBroker, error) {
+	if mif, ok := output.GetMaxInFlight(out); ok && mif > maxInFlight {
+		maxInFlight = mif
+	}
+	if maxInFlight < 1 {
+		return nil, fmt.Errorf("max_in_flight must be greater than zero, got %v", maxInFlight)
+	}
+	d := &deadLetterBroker{
+		output:           out,
+		outputPath:       outPath,
+		outputTSChan:     make(chan message.Transaction),
+		deadLetter:       deadLetter,
+		deadLetterTSChan: make(chan message.Transaction),
+		maxInFlight:      maxInFlight,
+		backoffCtor:      backoffCtor,
+		log:              log,
+		shutSig:          shutdown.NewSignaller(),
+	}
+	if err := d.output.Consume(d.outputTSChan); err != nil {
+		return nil, err
+	}
+	if err := d.deadLetter.Consume(d.deadLetterTSChan); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+//------------------------------------------------------------------------------
+
+// Consume assigns a new messages channel for the broker to read.
+func (d *deadLetterBroker) Consume(ts <-chan message.Transaction) error {
+	if d.transactions != nil {
+		return component.ErrAlreadyStarted
+	}
+	d.transactions = ts
+
+	go d.loop()
+	return nil
+}
+
+// MaxInFlight returns the maximum number of in flight messages permitted by the
+// output. This value can be used to determine a sensible value for parent
+// outputs, but should not be relied upon as part of dispatcher logic.
+func (d *deadLetterBroker) MaxInFlight() (int, bool) {
+	return d.maxInFlight, true
+}
+
+// Connected returns a boolean indicating whether this output is currently
+// connected to its target.
+func (d *deadLetterBroker) Connected() bool {
+	return d.output.Connected() && d.deadLetter.Connected()
+}
+
+//------------------------------------------------------------------------------
+
+// deadLetterEntry describes a message that is to be dead lettered.
+type deadLetterEntry struct {
+	part      *message.Part
+	err       string
+	component string
+	attempts  int
+}
+
+func (e deadLetterEntry) toPart(ts time.Time) *message.Part {
+	meta := map[string]interface{}{}
+	_ = e.part.MetaIter(func(k, v string) error {
+		if k != message.FailFlagKey {
+			meta[k] = v
+		}
+		return nil
+	})
+
+	envelope := map[string]interface{}{
+		"content":   string(e.part.Get()),
+		"metadata":  meta,
+		"error":     e.err,
+		"attempts":  e.attempts,
+		"timestamp": ts.Format(time.RFC3339Nano),
+	}
+	if e.component != "" {
+		envelope["component"] = e.component
+	}
+
+	p := e.part.Copy()
+	p.MetaDelete(message.FailFlagKey)
+	p.SetJSON(envelope)
+	return p
+}
+
+// write sends a batch to an output and blocks until it is acknowledged.
+func (d *deadLetterBroker) write(ctx context.Context, tChan chan<- message.Transaction, b *message.Batch) error {
+	resChan := make(chan error, 1)
+	select {
+	case tChan <- message.NewTransaction(b, resChan):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-resChan:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// failedParts returns the subset of a batch that failed to be written along
+// with the individual errors of each message. When the error does not identify
+// specific messages the entire batch is considered failed.
+func failedParts(b *message.Batch, err error) (*message.Batch, []error) {
+	var bErr batch.WalkableError
+	if errors.As(err, &bErr) && bErr.IndexedErrors() > 0 {
+		failed := message.QuickBatch(nil)
+		var errs []error
+		total := 0
+		bErr.WalkParts(func(i int, _ *message.Part, pErr error) bool {
+			total++
+			if pErr != nil && i < b.Len() {
+				failed.Append(b.Get(i))
+				errs = append(errs, pErr)
+			}
+			return true
+		})
+		if total == b.Len() && failed.Len() > 0 {
+			return failed, errs
+		}
+	}
+	errs := make([]error, b.Len())
+	for i := range errs {
+		errs[i] = err
+	}
+	return b, errs
+}
+
+// deliver attempts to write a batch to the child output, retrying until either
+// the write succeeds or the backoff is exhausted, at which point the remaining
+// failed messages are returned for dead lettering.
+func (d *deadLetterBroker) deliver(ctx context.Context, b *message.Batch) ([]deadLetterEntry, error) {
+	boff := d.backoffCtor()
+	for attempts := 1; ; attempts++ {
+		err := d.write(ctx, d.outputTSChan, b)
+		if err == nil {
+			return nil, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		var errs []error
+		b, errs = failedParts(b, err)
+
+		nextBackoff := boff.NextBackOff()
+		if nextBackoff == backoff.Stop {
+			d.log.Errorf("Failed to send message, routing to dead letter output: %v\n", err)
+			entries := make([]deadLetterEntry, 0, b.Len())
+			_ = b.Iter(func(i int, p *message.Part) error {
+				entries = append(entries, deadLetterEntry{
+					part:      p,
+					err:       errs[i].Error(),
+					component: d.outputPath,
+					attempts:  attempts,
+				})
+				return nil
+			})
+			return entries, nil
+		}
+		d.log.Warnf("Failed to send message: %v\n", err)
+
+		select {
+		case <-time.After(nextBackoff):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// process routes a batch between the child output and the dead letter output,
+// returning an error only when the batch could not be written to either.
+func (d *deadLetterBroker) process(ctx context.Context, b *message.Batch) error {
+	var entries []deadLetterEntry
+
+	toOutput := message.QuickBatch(nil)
+	_ = b.Iter(func(i int, p *message.Part) error {
+		if fail := processor.GetFail(p); fail != "" {
+			entries = append(entries, deadLetterEntry{
+				part:      p,
+				err:       fail,
+				component: processor.GetFailSource(p),
+			})
+		} else {
+			toOutput.Append(p)
+		}
+		return nil
+	})
+
+	if toOutput.Len() > 0 {
+		failed, err := d.deliver(ctx, toOutput)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, failed...)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	dlBatch := message.QuickBatch(nil)
+	for _, e := range entries {
+		dlBatch.Append(e.toPart(now))
+	}
+	return d.write(ctx, d.deadLetterTSChan, dlBatch)
+}
+
+// loop is an internal loop that brokers incoming messages to the outputs.
+func (d *deadLetterBroker) loop() {
+	// Batches that are already in flight are allowed to finish, including any
+	// retries, unless the shutdown is forced.
+	ctx, done := d.shutSig.CloseNowCtx(context.Background())
+	defer done()
+
+	var wg sync.WaitGroup
+	defer func() {
+		wg.Wait()
+		close(d.outputTSChan)
+		close(d.deadLetterTSChan)
+		closeAllOutputs([]output.Streamed{d.output, d.deadLetter})
+		d.shutSig.ShutdownComplete()
+	}()
+
+	sendLoop := func() {
+		defer wg.Done()
+		for {
+			var open bool
+			var tran message.Transaction
+
+			select {
+			case tran, open = <-d.transactions:
+				if !open {
+					return
+				}
+			case <-d.shutSig.CloseAtLeisureChan():
+				return
+			}
+
+			if err := tran.Ack(ctx, d.process(ctx, tran.Payload)); err != nil && ctx.Err() != nil {
+				return
+			}
+		}
+	}
+
+	for i := 0; i < d.maxInFlight; i++ {
+		wg.Add(1)
+		go sendLoop()
+	}
+}
+
+// CloseAsync shuts down the deadLetterBroker and stops processing requests.
+func (d *deadLetterBroker) CloseAsync() {
+	d.shutSig.CloseAtLeisure()
+}
+
+// WaitForClose blocks until the deadLetterBroker has closed down. If the
+// timeout is reached then batches that are still being retried are abandoned.
+func (d *deadLetterBroker) WaitForClose(timeout time.Duration) error {
+	select {
+	case <-d.shutSig.HasClosedChan():
+	case <-time.After(timeout):
+		d.shutSig.CloseNow()
+		return component.ErrTimeout
+	}
+	return nil
+}
@@ -0,0 +1,397 @@
+package generic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/manager"
+	"github.com/benthosdev/benthos/v4/internal/manager/mock"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	ooutput "github.com/benthosdev/benthos/v4/internal/old/output"
+	"github.com/benthosdev/benthos/v4/internal/stream"
+)
+
+var _ output.Streamed = &deadLetterBroker{}
+
+func TestDeadLetterRouting(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	out, dlq := &mock.OutputChanneled{}, &mock.OutputChanneled{}
+	d, err := newDeadLetterBroker(out, "root.output.dead_letter.output", dlq, 1, func() backoff.BackOff {
+		return backoff.WithMaxRetries(&backoff.ZeroBackOff{}, 2)
+	}, log.Noop())
+	require.NoError(t, err)
+
+	readChan := make(chan message.Transaction)
+	resChan := make(chan error)
+	require.NoError(t, d.Consume(readChan))
+
+	t.Cleanup(func() {
+		d.CloseAsync()
+		require.NoError(t, d.WaitForClose(time.Second))
+	})
+
+	batch := message.QuickBatch([][]byte{[]byte("hello"), []byte("world")})
+	batch.Get(0).MetaSet("foo", "bar")
+	batch.Get(0).MetaSet(message.FailFlagKey, "processing broke")
+	batch.Get(1).MetaSet("baz", "buz")
+
+	select {
+	case readChan <- message.NewTransaction(batch, resChan):
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+
+	for i := 0; i < 3; i++ {
+		var tran message.Transaction
+		select {
+		case tran = <-out.TChan:
+		case <-tCtx.Done():
+			t.Fatal("timed out")
+		}
+		require.Equal(t, 1, tran.Payload.Len())
+		assert.Equal(t, "world", string(tran.Payload.Get(0).Get()))
+		require.NoError(t, tran.Ack(tCtx, errors.New("delivery broke")))
+	}
+
+	var tran message.Transaction
+	select {
+	case tran = <-dlq.TChan:
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+	require.Equal(t, 2, tran.Payload.Len())
+
+	var first map[string]interface{}
+	require.NoError(t, json.Unmarshal(tran.Payload.Get(0).Get(), &first))
+	delete(first, "timestamp")
+	assert.Equal(t, map[string]interface{}{
+		"content":  "hello",
+		"metadata": map[string]interface{}{"foo": "bar"},
+		"error":    "processing broke",
+		"attempts": float64(0),
+	}, first)
+	assert.Equal(t, "bar", tran.Payload.Get(0).MetaGet("foo"))
+	assert.Equal(t, "", tran.Payload.Get(0).MetaGet(message.FailFlagKey))
+
+	var second map[string]interface{}
+	require.NoError(t, json.Unmarshal(tran.Payload.Get(1).Get(), &second))
+	delete(second, "timestamp")
+	assert.Equal(t, map[string]interface{}{
+		"content":   "world",
+		"metadata":  map[string]interface{}{"baz": "buz"},
+		"error":     "delivery broke",
+		"component": "root.output.dead_letter.output",
+		"attempts":  float64(3),
+	}, second)
+
+	require.NoError(t, tran.Ack(tCtx, nil))
+
+	select {
+	case err := <-resChan:
+		require.NoError(t, err)
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+}
+
+func TestDeadLetterOutputFails(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	out, dlq := &mock.OutputChanneled{}, &mock.OutputChanneled{}
+	d, err := newDeadLetterBroker(out, "", dlq, 1, func() backoff.BackOff {
+		return &backoff.StopBackOff{}
+	}, log.Noop())
+	require.NoError(t, err)
+
+	readChan := make(chan message.Transaction)
+	resChan := make(chan error)
+	require.NoError(t, d.Consume(readChan))
+
+	t.Cleanup(func() {
+		d.CloseAsync()
+		require.NoError(t, d.WaitForClose(time.Second))
+	})
+
+	select {
+	case readChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("hello")}), resChan):
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+
+	var tran message.Transaction
+	select {
+	case tran = <-out.TChan:
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+	require.NoError(t, tran.Ack(tCtx, errors.New("delivery broke")))
+
+	select {
+	case tran = <-dlq.TChan:
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+	require.NoError(t, tran.Ack(tCtx, errors.New("dead letter broke")))
+
+	select {
+	case err := <-resChan:
+		require.EqualError(t, err, "dead letter broke")
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+}
+
+func TestDeadLetterGracefulShutdown(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	out, dlq := &mock.OutputChanneled{}, &mock.OutputChanneled{}
+	d, err := newDeadLetterBroker(out, "", dlq, 1, func() backoff.BackOff {
+		return &backoff.ZeroBackOff{}
+	}, log.Noop())
+	require.NoError(t, err)
+
+	readChan := make(chan message.Transaction)
+	resChan := make(chan error)
+	require.NoError(t, d.Consume(readChan))
+
+	select {
+	case readChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("hello")}), resChan):
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+
+	var tran message.Transaction
+	select {
+	case tran = <-out.TChan:
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+	require.NoError(t, tran.Ack(tCtx, errors.New("delivery broke")))
+
+	// A batch being retried is given the chance to complete.
+	d.CloseAsync()
+
+	select {
+	case tran = <-out.TChan:
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+	require.NoError(t, tran.Ack(tCtx, nil))
+
+	select {
+	case err := <-resChan:
+		require.NoError(t, err)
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+	require.NoError(t, d.WaitForClose(time.Second))
+}
+
+func TestDeadLetterForcedShutdown(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	out, dlq := &mock.OutputChanneled{}, &mock.OutputChanneled{}
+	d, err := newDeadLetterBroker(out, "", dlq, 1, func() backoff.BackOff {
+		return backoff.NewConstantBackOff(time.Hour)
+	}, log.Noop())
+	require.NoError(t, err)
+
+	readChan := make(chan message.Transaction)
+	resChan := make(chan error, 1)
+	require.NoError(t, d.Consume(readChan))
+
+	select {
+	case readChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("hello")}), resChan):
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+
+	var tran message.Transaction
+	select {
+	case tran = <-out.TChan:
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+	require.NoError(t, tran.Ack(tCtx, errors.New("delivery broke")))
+
+	d.CloseAsync()
+	require.Error(t, d.WaitForClose(time.Millisecond*50))
+	require.NoError(t, d.WaitForClose(time.Second))
+}
+
+func TestDeadLetterFromConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	var conf ooutput.Config
+	require.NoError(t, yaml.Unmarshal([]byte(`
+dead_letter:
+  output:
+    reject: "nope"
+  dead_letter_output:
+    file:
+      path: `+filepath.Join(dir, "dlq.jsonl")+`
+      codec: lines
+  max_retries: 1
+  backoff:
+    initial_interval: 1ms
+    max_interval: 1ms
+`), &conf))
+	require.Equal(t, "dead_letter", conf.Type)
+
+	mgr, err := manager.NewV2(manager.NewResourceConfig(), nil, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	s, err := mgr.IntoPath("output").(bundle.NewManagement).NewOutput(conf)
+	require.NoError(t, err)
+
+	sendChan := make(chan message.Transaction)
+	resChan := make(chan error)
+	require.NoError(t, s.Consume(sendChan))
+
+	t.Cleanup(func() {
+		s.CloseAsync()
+		require.NoError(t, s.WaitForClose(time.Second))
+	})
+
+	select {
+	case sendChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("hello")}), resChan):
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+	select {
+	case err := <-resChan:
+		require.NoError(t, err)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+
+	fileBytes, err := os.ReadFile(filepath.Join(dir, "dlq.jsonl"))
+	require.NoError(t, err)
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(string(fileBytes))), &doc))
+	assert.Equal(t, "hello", doc["content"])
+	assert.Equal(t, "nope", doc["error"])
+	assert.Equal(t, float64(2), doc["attempts"])
+	assert.Equal(t, "root.output.dead_letter.output", doc["component"])
+}
+
+func TestDeadLetterProcessingErrorComponent(t *testing.T) {
+	dir := t.TempDir()
+
+	var conf ooutput.Config
+	require.NoError(t, yaml.Unmarshal([]byte(`
+dead_letter:
+  output:
+    drop: {}
+  dead_letter_output:
+    file:
+      path: `+filepath.Join(dir, "dlq.jsonl")+`
+      codec: lines
+processors:
+  - bloblang: 'root = this'
+  - bloblang: 'root = throw("nope")'
+`), &conf))
+
+	mgr, err := manager.NewV2(manager.NewResourceConfig(), nil, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	s, err := mgr.WithFailSources().IntoPath("output").(bundle.NewManagement).NewOutput(conf)
+	require.NoError(t, err)
+
+	sendChan := make(chan message.Transaction)
+	resChan := make(chan error)
+	require.NoError(t, s.Consume(sendChan))
+
+	t.Cleanup(func() {
+		s.CloseAsync()
+		require.NoError(t, s.WaitForClose(time.Second))
+	})
+
+	select {
+	case sendChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte(`{"id":"foo"}`)}), resChan):
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+	select {
+	case err := <-resChan:
+		require.NoError(t, err)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+
+	fileBytes, err := os.ReadFile(filepath.Join(dir, "dlq.jsonl"))
+	require.NoError(t, err)
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(string(fileBytes))), &doc))
+	assert.Equal(t, float64(0), doc["attempts"])
+	assert.Equal(t, "root.output.processors.1", doc["component"])
+}
+
+func TestDeadLetterStreamProcessingErrorComponent(t *testing.T) {
+	dir := t.TempDir()
+	dlqPath := filepath.Join(dir, "dlq.jsonl")
+
+	conf := stream.NewConfig()
+	require.NoError(t, yaml.Unmarshal([]byte(`
+input:
+  generate:
+    count: 1
+    interval: ""
+    mapping: 'root.id = "foo"'
+pipeline:
+  processors:
+    - bloblang: 'root = throw("nope")'
+output:
+  dead_letter:
+    output:
+      drop: {}
+    dead_letter_output:
+      file:
+        path: `+dlqPath+`
+        codec: lines
+`), &conf))
+
+	mgr, err := manager.NewV2(manager.NewResourceConfig(), nil, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	// Processors are only annotated with their path when the stream has a
+	// dead_letter output.
+	strm, err := stream.New(conf, mgr)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, strm.Stop(time.Second*5))
+	})
+
+	var fileBytes []byte
+	require.Eventually(t, func() bool {
+		fileBytes, _ = os.ReadFile(dlqPath)
+		return len(fileBytes) > 0
+	}, time.Second*5, time.Millisecond*10)
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(string(fileBytes))), &doc))
+	assert.Contains(t, doc["error"], "nope")
+	assert.Equal(t, "root.pipeline.processors.0", doc["component"])
+}
@@ -0,0 +1,89 @@
+package jwt
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// algorithm describes a family of signing methods along with how keys for
+// verifying and signing tokens are parsed from method arguments.
+type algorithm struct {
+	methods []jwt.SigningMethod
+
+	verifyKeyParam       string
+	verifyKeyDescription string
+	parseVerifyKey       func(string) (interface{}, error)
+
+	signKeyParam       string
+	signKeyDescription string
+	parseSignKey       func(string) (interface{}, error)
+}
+
+var algorithms = []algorithm{
+	{
+		methods: []jwt.SigningMethod{
+			jwt.SigningMethodHS256,
+			jwt.SigningMethodHS384,
+			jwt.SigningMethodHS512,
+		},
+		verifyKeyParam:       "secret",
+		verifyKeyDescription: "The secret the token was signed with.",
+		parseVerifyKey: func(s string) (interface{}, error) {
+			return []byte(s), nil
+		},
+		signKeyParam:       "secret",
+		signKeyDescription: "The secret to sign the token with.",
+		parseSignKey: func(s string) (interface{}, error) {
+			return []byte(s), nil
+		},
+	},
+	{
+		methods: []jwt.SigningMethod{
+			jwt.SigningMethodRS256,
+			jwt.SigningMethodRS384,
+			jwt.SigningMethodRS512,
+		},
+		verifyKeyParam:       "public_key",
+		verifyKeyDescription: "A PEM encoded RSA public key to verify the signature of the token with.",
+		parseVerifyKey: func(s string) (interface{}, error) {
+			return jwt.ParseRSAPublicKeyFromPEM([]byte(s))
+		},
+		signKeyParam:       "private_key",
+		signKeyDescription: "A PEM encoded RSA private key to sign the token with.",
+		parseSignKey: func(s string) (interface{}, error) {
+			return jwt.ParseRSAPrivateKeyFromPEM([]byte(s))
+		},
+	},
+	{
+		methods: []jwt.SigningMethod{
+			jwt.SigningMethodES256,
+			jwt.SigningMethodES384,
+			jwt.SigningMethodES512,
+		},
+		verifyKeyParam:       "public_key",
+		verifyKeyDescription: "A PEM encoded ECDSA public key to verify the signature of the token with.",
+		parseVerifyKey: func(s string) (interface{}, error) {
+			return jwt.ParseECPublicKeyFromPEM([]byte(s))
+		},
+		signKeyParam:       "private_key",
+		signKeyDescription: "A PEM encoded ECDSA private key to sign the token with.",
+		parseSignKey: func(s string) (interface{}, error) {
+			return jwt.ParseECPrivateKeyFromPEM([]byte(s))
+		},
+	},
+}
+
+func methodName(prefix string, method jwt.SigningMethod) string {
+	return fmt.Sprintf("%v_jwt_%v", prefix, strings.ToLower(method.Alg()))
+}
+
+func init() {
+	for _, alg := range algorithms {
+		for _, method := range alg.methods {
+			registerParseMethod(alg, method)
+			registerSignMethod(alg, method)
+		}
+	}
+}
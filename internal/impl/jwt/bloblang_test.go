@@ -0,0 +1,151 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/bloblang"
+)
+
+func pemEncode(t *testing.T, blockType string, der []byte) string {
+	t.Helper()
+	return string(pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}))
+}
+
+func TestJWTRoundTrip(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	rsaPub, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	ecPriv, err := x509.MarshalECPrivateKey(ecKey)
+	require.NoError(t, err)
+
+	ecPub, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		alg       string
+		signKey   string
+		verifyKey string
+	}{
+		{
+			name:      "hmac",
+			alg:       "hs384",
+			signKey:   "foo",
+			verifyKey: "foo",
+		},
+		{
+			name:      "rsa",
+			alg:       "rs256",
+			signKey:   pemEncode(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)),
+			verifyKey: pemEncode(t, "PUBLIC KEY", rsaPub),
+		},
+		{
+			name:      "ecdsa",
+			alg:       "es256",
+			signKey:   pemEncode(t, "EC PRIVATE KEY", ecPriv),
+			verifyKey: pemEncode(t, "PUBLIC KEY", ecPub),
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(fmt.Sprintf(
+				`root = this.sign_jwt_%v(%q).parse_jwt_%v(%q)`,
+				test.alg, test.signKey, test.alg, test.verifyKey,
+			))
+			require.NoError(t, err)
+
+			res, err := exec.Query(map[string]interface{}{
+				"sub":  "user123",
+				"tags": []interface{}{"foo", "bar"},
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, map[string]interface{}{
+				"sub":  "user123",
+				"tags": []interface{}{"foo", "bar"},
+			}, res)
+		})
+	}
+}
+
+func TestJWTParseErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mapping string
+		input   interface{}
+		err     string
+	}{
+		{
+			name:    "wrong secret",
+			mapping: `root = this.sign_jwt_hs256("foo").parse_jwt_hs256("bar")`,
+			input:   map[string]interface{}{"sub": "user123"},
+			err:     "failed to parse token: signature is invalid",
+		},
+		{
+			name:    "wrong algorithm",
+			mapping: `root = this.sign_jwt_hs512("foo").parse_jwt_hs256("foo")`,
+			input:   map[string]interface{}{"sub": "user123"},
+			err:     "failed to parse token: signing method HS512 is invalid",
+		},
+		{
+			name:    "expired",
+			mapping: `root = this.sign_jwt_hs256("foo").parse_jwt_hs256("foo")`,
+			input:   map[string]interface{}{"exp": time.Now().Add(-time.Minute).Unix()},
+			err:     "failed to parse token: Token is expired",
+		},
+		{
+			name:    "not a token",
+			mapping: `root = this.parse_jwt_hs256("foo")`,
+			input:   "nope",
+			err:     "failed to parse token: token contains an invalid number of segments",
+		},
+		{
+			name:    "sign a string",
+			mapping: `root = this.sign_jwt_hs256("foo")`,
+			input:   "nope",
+			err:     "expected object value",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(test.mapping)
+			require.NoError(t, err)
+
+			_, err = exec.Query(test.input)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.err)
+		})
+	}
+}
+
+func TestJWTBadKeys(t *testing.T) {
+	for _, mapping := range []string{
+		`root = this.parse_jwt_rs256("not a key")`,
+		`root = this.sign_jwt_es256("not a key")`,
+	} {
+		_, err := bloblang.Parse(mapping)
+		require.Error(t, err, mapping)
+		assert.Contains(t, err.Error(), "failed to parse", mapping)
+	}
+}
@@ -121,6 +121,6 @@ func ConfigDocs() docs.FieldSpecs {
 		),
 		docs.FieldCommon("database", "The name of the target MongoDB DB."),
 		docs.FieldCommon("username", "The username to connect to the database."),
-		docs.FieldCommon("password", "The password to connect to the database."),
+		docs.FieldCommon("password", "The password to connect to the database.").Secret(),
 	}
 }
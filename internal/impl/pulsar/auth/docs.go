@@ -13,7 +13,7 @@ func FieldSpec() docs.FieldSpec {
 		),
 		docs.FieldAdvanced("token", "Parameters for Pulsar Token authentication.").WithChildren(
 			docs.FieldBool("enabled", "Whether Token Auth is enabled.", true),
-			docs.FieldString("token", "Actual base64 encoded token."),
+			docs.FieldString("token", "Actual base64 encoded token.").Secret(),
 		),
 		docs.FieldAdvanced("tls", "Custom TLS settings can be used to override system defaults.").WithChildren(
 			docs.FieldBool("enabled", "Whether custom TLS settings are enabled.", true),
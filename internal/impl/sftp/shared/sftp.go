@@ -19,9 +19,9 @@ import (
 func CredentialsDocs() docs.FieldSpecs {
 	return docs.FieldSpecs{
 		docs.FieldCommon("username", "The username to connect to the SFTP server."),
-		docs.FieldCommon("password", "The password for the username to connect to the SFTP server."),
+		docs.FieldCommon("password", "The password for the username to connect to the SFTP server.").Secret(),
 		docs.FieldCommon("private_key_file", "The private key for the username to connect to the SFTP server."),
-		docs.FieldCommon("private_key_pass", "Optional passphrase for private key."),
+		docs.FieldCommon("private_key_pass", "Optional passphrase for private key.").Secret(),
 	}
 }
 
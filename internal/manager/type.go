@@ -20,12 +20,14 @@ import (
 	iprocessor "github.com/benthosdev/benthos/v4/internal/component/processor"
 	"github.com/benthosdev/benthos/v4/internal/component/ratelimit"
 	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/health"
 	"github.com/benthosdev/benthos/v4/internal/interop"
 	"github.com/benthosdev/benthos/v4/internal/log"
 	"github.com/benthosdev/benthos/v4/internal/message"
 	"github.com/benthosdev/benthos/v4/internal/old/input"
 	"github.com/benthosdev/benthos/v4/internal/old/output"
 	"github.com/benthosdev/benthos/v4/internal/old/processor"
+	"github.com/benthosdev/benthos/v4/internal/secrets"
 )
 
 // ErrResourceNotFound represents an error where a named resource could not be
@@ -73,9 +75,21 @@ type Type struct {
 	rateLimits   map[string]ratelimit.V1
 	resourceLock *sync.RWMutex
 
+	// When set the resources above are an isolated namespace, and resources
+	// not found within it are accessed from the parent instead.
+	parent *Type
+
 	// Collections of component constructors
 	env      *bundle.Environment
 	bloblEnv *bloblang.Environment
+	secrets  *secrets.Providers
+
+	// Tracks the health of resources.
+	health *health.Registry
+
+	// When set processors annotate the messages that they flag with errors
+	// with their path.
+	failSources bool
 
 	logger log.Modular
 	stats  *metrics.Namespaced
@@ -103,6 +117,22 @@ func OptSetBloblangEnvironment(env *bloblang.Environment) OptFunc {
 	}
 }
 
+// OptSetSecretProviders determines the providers from which the manager
+// resolves secret references within the configs of components it initializes.
+func OptSetSecretProviders(p *secrets.Providers) OptFunc {
+	return func(t *Type) {
+		t.secrets = p
+	}
+}
+
+// OptSetHealthRegistry determines the registry with which the manager tracks
+// the health of resources.
+func OptSetHealthRegistry(r *health.Registry) OptFunc {
+	return func(t *Type) {
+		t.health = r
+	}
+}
+
 // NewV2 returns an instance of manager.Type, which can be shared amongst
 // components and logical threads of a Benthos service.
 func NewV2(conf ResourceConfig, apiReg APIReg, log log.Modular, stats *metrics.Namespaced, opts ...OptFunc) (*Type, error) {
@@ -119,6 +149,9 @@ func NewV2(conf ResourceConfig, apiReg APIReg, log log.Modular, stats *metrics.N
 		// Environment defaults to global (everything that was imported).
 		env:      bundle.GlobalEnvironment,
 		bloblEnv: bloblang.GlobalEnvironment(),
+		secrets:  secrets.GlobalProviders,
+
+		health: health.NewRegistry(),
 
 		logger: log,
 		stats:  stats,
@@ -243,6 +276,40 @@ func (t *Type) forLabel(name string) *Type {
 	return &newT
 }
 
+// WithIsolatedResources returns a variant of this manager with its own empty
+// namespace of resources. Resources stored via the returned manager are only
+// accessible from it, and resources that are not found within the namespace are
+// accessed from the original manager instead. Resources of the namespace are
+// closed by calling CloseAsync and WaitForClose on the returned manager.
+func (t *Type) WithIsolatedResources() interop.Manager {
+	return t.withIsolatedResources()
+}
+
+func (t *Type) withIsolatedResources() *Type {
+	newT := *t
+	newT.inputs = map[string]iinput.Streamed{}
+	newT.caches = map[string]cache.V1{}
+	newT.processors = map[string]iprocessor.V1{}
+	newT.outputs = map[string]ioutput.Sync{}
+	newT.rateLimits = map[string]ratelimit.V1{}
+	newT.resourceLock = &sync.RWMutex{}
+	newT.parent = t
+	return &newT
+}
+
+// ForValidation returns a variant of this manager intended for constructing
+// components in order to validate them, where HTTP endpoints are not
+// registered, metrics are discarded and logs are dropped. Resources remain
+// accessible as normal.
+func (t *Type) ForValidation() interop.Manager {
+	newT := *t
+	newT.apiReg = nil
+	newT.logger = log.Noop()
+	newT.stats = metrics.NewNamespaced(metrics.Noop())
+	newT.health = health.NewRegistry()
+	return &newT
+}
+
 // IntoPath returns a variant of this manager to be used by a particular
 // component path, which is a child of the current component, where
 // observability components will be automatically tagged with the new path.
@@ -283,8 +350,50 @@ func (t *Type) WithAddedMetrics(m metrics.Type) interop.Manager {
 	return &newT
 }
 
+// WithLogger returns a modified version of the manager where logs are written
+// to the provided logger.
+func (t *Type) WithLogger(l log.Modular) interop.Manager {
+	newT := *t
+	newT.logger = l
+	return &newT
+}
+
+// WithFailSources returns a modified version of the manager where processors
+// annotate the messages that they flag with errors with their path, which can
+// be obtained with processor.GetFailSource. This adds a small overhead to each
+// processor and should only be enabled when the path is used.
+func (t *Type) WithFailSources() interop.Manager {
+	newT := *t
+	newT.failSources = true
+	return &newT
+}
+
 //------------------------------------------------------------------------------
 
+// resolveSecrets replaces secret references within a component config, given
+// as a pointer, with their values. The config is copied rather than modified in
+// place and therefore secret values are never visible from the original.
+func (t *Type) resolveSecrets(confPtr interface{}) error {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+	return t.secrets.Resolve(ctx, confPtr)
+}
+
+// TrackHealth registers the component held by the manager with the health
+// registry and returns its tracker along with a variant of the manager to
+// construct the component with, where errors logged by the component are
+// recorded by the tracker. This is for internal use only.
+func (t *Type) TrackHealth(kind, label string) (*health.Tracker, interop.Manager) {
+	return t.trackHealth(kind, label)
+}
+
+func (t *Type) trackHealth(kind, label string) (*health.Tracker, *Type) {
+	tracker := t.health.Register(t.stream, kind, "root."+query.SliceToDotPath(t.componentPath...), label)
+	newT := *t
+	newT.logger = tracker.Logger(t.logger)
+	return tracker, &newT
+}
+
 // RegisterEndpoint registers a server wide HTTP endpoint.
 func (t *Type) RegisterEndpoint(apiPath, desc string, h http.HandlerFunc) {
 	if len(t.stream) > 0 {
@@ -348,6 +457,12 @@ func (t *Type) Environment() *bundle.Environment {
 	return t.env
 }
 
+// Health returns the registry with which the manager tracks the health of
+// components. This is for internal use only.
+func (t *Type) Health() *health.Registry {
+	return t.health
+}
+
 // BloblEnvironment returns a Bloblang environment used by the manager. This is
 // for internal use only.
 func (t *Type) BloblEnvironment() *bloblang.Environment {
@@ -386,6 +501,9 @@ func closeWithContext(ctx context.Context, c oldClosable) error {
 
 // NewBuffer attempts to create a new buffer component from a config.
 func (t *Type) NewBuffer(conf buffer.Config) (buffer.Streamed, error) {
+	if err := t.resolveSecrets(&conf); err != nil {
+		return nil, err
+	}
 	return t.env.BufferInit(conf, t)
 }
 
@@ -393,8 +511,13 @@ func (t *Type) NewBuffer(conf buffer.Config) (buffer.Streamed, error) {
 
 // ProbeCache returns true if a cache resource exists under the provided name.
 func (t *Type) ProbeCache(name string) bool {
-	_, exists := t.caches[name]
-	return exists
+	if _, exists := t.caches[name]; exists {
+		return true
+	}
+	if t.parent != nil {
+		return t.parent.ProbeCache(name)
+	}
+	return false
 }
 
 // AccessCache attempts to access a cache resource by a unique identifier and
@@ -408,17 +531,24 @@ func (t *Type) AccessCache(ctx context.Context, name string, fn func(cache.V1))
 	// TODO: Eventually use ctx to cancel blocking on the mutex lock. Needs
 	// profiling for heavy use within a busy loop.
 	t.resourceLock.RLock()
-	defer t.resourceLock.RUnlock()
 	c, ok := t.caches[name]
 	if !ok || c == nil {
+		t.resourceLock.RUnlock()
+		if t.parent != nil {
+			return t.parent.AccessCache(ctx, name, fn)
+		}
 		return ErrResourceNotFound(name)
 	}
+	defer t.resourceLock.RUnlock()
 	fn(c)
 	return nil
 }
 
 // NewCache attempts to create a new cache component from a config.
 func (t *Type) NewCache(conf cache.Config) (cache.V1, error) {
+	if err := t.resolveSecrets(&conf); err != nil {
+		return nil, err
+	}
 	return t.env.CacheInit(conf, t.forLabel(conf.Label))
 }
 
@@ -439,15 +569,17 @@ func (t *Type) StoreCache(ctx context.Context, name string, conf cache.Config) e
 		}
 	}
 
-	newCache, err := t.intoPath("cache_resources").NewCache(conf)
+	tracker, rMgr := t.intoPath("cache_resources").trackHealth(health.KindCache, name)
+	newCache, err := rMgr.NewCache(conf)
 	if err != nil {
+		tracker.Close()
 		return fmt.Errorf(
 			"failed to create cache resource '%v' of type '%v': %w",
 			name, conf.Type, err,
 		)
 	}
 
-	t.caches[name] = newCache
+	t.caches[name] = health.TrackCache(tracker, newCache)
 	return nil
 }
 
@@ -455,8 +587,13 @@ func (t *Type) StoreCache(ctx context.Context, name string, conf cache.Config) e
 
 // ProbeInput returns true if an input resource exists under the provided name.
 func (t *Type) ProbeInput(name string) bool {
-	_, exists := t.inputs[name]
-	return exists
+	if _, exists := t.inputs[name]; exists {
+		return true
+	}
+	if t.parent != nil {
+		return t.parent.ProbeInput(name)
+	}
+	return false
 }
 
 // AccessInput attempts to access an input resource by a unique identifier and
@@ -470,17 +607,24 @@ func (t *Type) AccessInput(ctx context.Context, name string, fn func(iinput.Stre
 	// TODO: Eventually use ctx to cancel blocking on the mutex lock. Needs
 	// profiling for heavy use within a busy loop.
 	t.resourceLock.RLock()
-	defer t.resourceLock.RUnlock()
 	i, ok := t.inputs[name]
 	if !ok || i == nil {
+		t.resourceLock.RUnlock()
+		if t.parent != nil {
+			return t.parent.AccessInput(ctx, name, fn)
+		}
 		return ErrResourceNotFound(name)
 	}
+	defer t.resourceLock.RUnlock()
 	fn(i)
 	return nil
 }
 
 // NewInput attempts to create a new input component from a config.
 func (t *Type) NewInput(conf input.Config, pipelines ...iprocessor.PipelineConstructorFunc) (iinput.Streamed, error) {
+	if err := t.resolveSecrets(&conf); err != nil {
+		return nil, err
+	}
 	return t.env.InputInit(conf, t.forLabel(conf.Label), pipelines...)
 }
 
@@ -505,15 +649,17 @@ func (t *Type) StoreInput(ctx context.Context, name string, conf input.Config) e
 		return fmt.Errorf("label '%v' must be empty or match the resource name '%v'", conf.Label, name)
 	}
 
-	newInput, err := t.intoPath("input_resources").NewInput(conf)
+	tracker, rMgr := t.intoPath("input_resources").trackHealth(health.KindInput, name)
+	newInput, err := rMgr.NewInput(conf)
 	if err != nil {
+		tracker.Close()
 		return fmt.Errorf(
 			"failed to create input resource '%v' of type '%v': %w",
 			name, conf.Type, err,
 		)
 	}
 
-	t.inputs[name] = newInput
+	t.inputs[name] = health.TrackInput(tracker, newInput)
 	return nil
 }
 
@@ -522,8 +668,13 @@ func (t *Type) StoreInput(ctx context.Context, name string, conf input.Config) e
 // ProbeProcessor returns true if a processor resource exists under the provided
 // name.
 func (t *Type) ProbeProcessor(name string) bool {
-	_, exists := t.processors[name]
-	return exists
+	if _, exists := t.processors[name]; exists {
+		return true
+	}
+	if t.parent != nil {
+		return t.parent.ProbeProcessor(name)
+	}
+	return false
 }
 
 // AccessProcessor attempts to access a processor resource by a unique
@@ -538,18 +689,32 @@ func (t *Type) AccessProcessor(ctx context.Context, name string, fn func(iproces
 	// TODO: Eventually use ctx to cancel blocking on the mutex lock. Needs
 	// profiling for heavy use within a busy loop.
 	t.resourceLock.RLock()
-	defer t.resourceLock.RUnlock()
 	p, ok := t.processors[name]
 	if !ok || p == nil {
+		t.resourceLock.RUnlock()
+		if t.parent != nil {
+			return t.parent.AccessProcessor(ctx, name, fn)
+		}
 		return ErrResourceNotFound(name)
 	}
+	defer t.resourceLock.RUnlock()
 	fn(p)
 	return nil
 }
 
 // NewProcessor attempts to create a new processor component from a config.
 func (t *Type) NewProcessor(conf processor.Config) (iprocessor.V1, error) {
-	return t.env.ProcessorInit(conf, t.forLabel(conf.Label))
+	if err := t.resolveSecrets(&conf); err != nil {
+		return nil, err
+	}
+	p, err := t.env.ProcessorInit(conf, t.forLabel(conf.Label))
+	if err != nil {
+		return nil, err
+	}
+	if !t.failSources {
+		return p, nil
+	}
+	return iprocessor.WithFailSource("root."+query.SliceToDotPath(t.componentPath...), p), nil
 }
 
 // StoreProcessor attempts to store a new processor resource. If an existing
@@ -573,15 +738,17 @@ func (t *Type) StoreProcessor(ctx context.Context, name string, conf processor.C
 		return fmt.Errorf("label '%v' must be empty or match the resource name '%v'", conf.Label, name)
 	}
 
-	newProcessor, err := t.intoPath("processor_resources").NewProcessor(conf)
+	tracker, rMgr := t.intoPath("processor_resources").trackHealth(health.KindProcessor, name)
+	newProcessor, err := rMgr.NewProcessor(conf)
 	if err != nil {
+		tracker.Close()
 		return fmt.Errorf(
 			"failed to create processor resource '%v' of type '%v': %w",
 			name, conf.Type, err,
 		)
 	}
 
-	t.processors[name] = newProcessor
+	t.processors[name] = health.TrackProcessor(tracker, newProcessor)
 	return nil
 }
 
@@ -590,8 +757,13 @@ func (t *Type) StoreProcessor(ctx context.Context, name string, conf processor.C
 // ProbeOutput returns true if an output resource exists under the provided
 // name.
 func (t *Type) ProbeOutput(name string) bool {
-	_, exists := t.outputs[name]
-	return exists
+	if _, exists := t.outputs[name]; exists {
+		return true
+	}
+	if t.parent != nil {
+		return t.parent.ProbeOutput(name)
+	}
+	return false
 }
 
 // AccessOutput attempts to access an output resource by a unique identifier and
@@ -605,17 +777,24 @@ func (t *Type) AccessOutput(ctx context.Context, name string, fn func(ioutput.Sy
 	// TODO: Eventually use ctx to cancel blocking on the mutex lock. Needs
 	// profiling for heavy use within a busy loop.
 	t.resourceLock.RLock()
-	defer t.resourceLock.RUnlock()
 	o, ok := t.outputs[name]
 	if !ok || o == nil {
+		t.resourceLock.RUnlock()
+		if t.parent != nil {
+			return t.parent.AccessOutput(ctx, name, fn)
+		}
 		return ErrResourceNotFound(name)
 	}
+	defer t.resourceLock.RUnlock()
 	fn(o)
 	return nil
 }
 
 // NewOutput attempts to create a new output component from a config.
 func (t *Type) NewOutput(conf output.Config, pipelines ...iprocessor.PipelineConstructorFunc) (ioutput.Streamed, error) {
+	if err := t.resolveSecrets(&conf); err != nil {
+		return nil, err
+	}
 	return t.env.OutputInit(conf, t.forLabel(conf.Label), pipelines...)
 }
 
@@ -640,13 +819,15 @@ func (t *Type) StoreOutput(ctx context.Context, name string, conf output.Config)
 		return fmt.Errorf("label '%v' must be empty or match the resource name '%v'", conf.Label, name)
 	}
 
-	tmpOutput, err := t.intoPath("output_resources").NewOutput(conf)
+	tracker, rMgr := t.intoPath("output_resources").trackHealth(health.KindOutput, name)
+	tmpOutput, err := rMgr.NewOutput(conf)
 	if err == nil {
-		if t.outputs[name], err = wrapOutput(tmpOutput); err != nil {
+		if t.outputs[name], err = wrapOutput(health.TrackOutput(tracker, tmpOutput)); err != nil {
 			tmpOutput.CloseAsync()
 		}
 	}
 	if err != nil {
+		tracker.Close()
 		return fmt.Errorf(
 			"failed to create output resource '%v' of type '%v': %w",
 			name, conf.Type, err,
@@ -660,8 +841,13 @@ func (t *Type) StoreOutput(ctx context.Context, name string, conf output.Config)
 // ProbeRateLimit returns true if a rate limit resource exists under the
 // provided name.
 func (t *Type) ProbeRateLimit(name string) bool {
-	_, exists := t.rateLimits[name]
-	return exists
+	if _, exists := t.rateLimits[name]; exists {
+		return true
+	}
+	if t.parent != nil {
+		return t.parent.ProbeRateLimit(name)
+	}
+	return false
 }
 
 // AccessRateLimit attempts to access a rate limit resource by a unique
@@ -676,17 +862,24 @@ func (t *Type) AccessRateLimit(ctx context.Context, name string, fn func(ratelim
 	// TODO: Eventually use ctx to cancel blocking on the mutex lock. Needs
 	// profiling for heavy use within a busy loop.
 	t.resourceLock.RLock()
-	defer t.resourceLock.RUnlock()
 	r, ok := t.rateLimits[name]
 	if !ok || r == nil {
+		t.resourceLock.RUnlock()
+		if t.parent != nil {
+			return t.parent.AccessRateLimit(ctx, name, fn)
+		}
 		return ErrResourceNotFound(name)
 	}
+	defer t.resourceLock.RUnlock()
 	fn(r)
 	return nil
 }
 
 // NewRateLimit attempts to create a new rate limit component from a config.
 func (t *Type) NewRateLimit(conf ratelimit.Config) (ratelimit.V1, error) {
+	if err := t.resolveSecrets(&conf); err != nil {
+		return nil, err
+	}
 	return t.env.RateLimitInit(conf, t.forLabel(conf.Label))
 }
 
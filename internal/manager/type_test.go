@@ -2,7 +2,9 @@ package manager_test
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -16,12 +18,14 @@ import (
 	iprocessor "github.com/benthosdev/benthos/v4/internal/component/processor"
 	"github.com/benthosdev/benthos/v4/internal/component/ratelimit"
 	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/health"
 	"github.com/benthosdev/benthos/v4/internal/log"
 	"github.com/benthosdev/benthos/v4/internal/manager"
 	"github.com/benthosdev/benthos/v4/internal/message"
 	"github.com/benthosdev/benthos/v4/internal/old/input"
 	"github.com/benthosdev/benthos/v4/internal/old/output"
 	"github.com/benthosdev/benthos/v4/internal/old/processor"
+	"github.com/benthosdev/benthos/v4/internal/secrets"
 
 	_ "github.com/benthosdev/benthos/v4/public/components/all"
 )
@@ -100,6 +104,48 @@ func TestManagerCache(t *testing.T) {
 	require.False(t, mgr.ProbeCache("baz"))
 }
 
+func TestManagerIsolatedResources(t *testing.T) {
+	conf := manager.NewResourceConfig()
+
+	fooCache := cache.NewConfig()
+	fooCache.Label = "foo"
+	conf.ResourceCaches = append(conf.ResourceCaches, fooCache)
+
+	mgr, err := manager.NewV2(conf, nil, log.Noop(), noopStats())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	isoMgr := mgr.WithIsolatedResources().(*manager.Type)
+	require.NoError(t, isoMgr.StoreCache(ctx, "bar", cache.NewConfig()))
+
+	// Resources of the parent remain accessible from the namespace, but not
+	// the other way around.
+	assert.True(t, isoMgr.ProbeCache("foo"))
+	assert.True(t, isoMgr.ProbeCache("bar"))
+	assert.False(t, mgr.ProbeCache("bar"))
+
+	require.NoError(t, isoMgr.AccessCache(ctx, "foo", func(c cache.V1) {
+		require.NoError(t, c.Set(ctx, "key", []byte("from parent"), nil))
+	}))
+	require.NoError(t, mgr.AccessCache(ctx, "foo", func(c cache.V1) {
+		v, err := c.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, "from parent", string(v))
+	}))
+
+	assert.NoError(t, isoMgr.AccessCache(ctx, "bar", func(cache.V1) {}))
+	assert.Error(t, mgr.AccessCache(ctx, "bar", func(cache.V1) {}))
+	assert.Error(t, isoMgr.AccessCache(ctx, "baz", func(cache.V1) {}))
+
+	// Namespaced resources shadow those of the parent.
+	require.NoError(t, isoMgr.StoreCache(ctx, "foo", cache.NewConfig()))
+	require.NoError(t, isoMgr.AccessCache(ctx, "foo", func(c cache.V1) {
+		_, err := c.Get(ctx, "key")
+		assert.Error(t, err)
+	}))
+}
+
 func TestManagerCacheList(t *testing.T) {
 	cacheFoo := cache.NewConfig()
 	cacheFoo.Label = "foo"
@@ -257,6 +303,83 @@ func TestManagerProcessor(t *testing.T) {
 	require.False(t, mgr.ProbeProcessor("baz"))
 }
 
+func TestManagerProcessorSecrets(t *testing.T) {
+	providers := secrets.NewProviders()
+	require.NoError(t, providers.Add("test", secrets.ProviderFunc(func(ctx context.Context, key string) (string, error) {
+		if key != "foo" {
+			return "", errors.New("nope")
+		}
+		return "hunter2", nil
+	})))
+
+	mgr, err := manager.NewV2(manager.NewResourceConfig(), nil, log.Noop(), noopStats(), manager.OptSetSecretProviders(providers))
+	require.NoError(t, err)
+
+	conf := processor.NewConfig()
+	conf.Type = processor.TypeBloblang
+	conf.Bloblang = `root = "${secret:test:foo}"`
+
+	proc, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	msgs, res := proc.ProcessMessage(message.QuickBatch([][]byte{[]byte("hello")}))
+	require.Nil(t, res)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, "hunter2", string(msgs[0].Get(0).Get()))
+
+	// The original config retains the reference.
+	assert.Equal(t, `root = "${secret:test:foo}"`, string(conf.Bloblang))
+
+	conf.Bloblang = `root = "${secret:test:bar}"`
+	_, err = mgr.NewProcessor(conf)
+	require.Error(t, err)
+}
+
+func TestManagerHealth(t *testing.T) {
+	cConf := cache.NewConfig()
+	cConf.Label = "foocache"
+
+	pConf := processor.NewConfig()
+	pConf.Label = "fooproc"
+	pConf.Type = processor.TypeBloblang
+	pConf.Bloblang = `root = this.foo.uppercase()`
+
+	conf := manager.NewResourceConfig()
+	conf.ResourceCaches = append(conf.ResourceCaches, cConf)
+	conf.ResourceProcessors = append(conf.ResourceProcessors, pConf)
+
+	reg := health.NewRegistry()
+	mgr, err := manager.NewV2(conf, nil, log.Noop(), noopStats(), manager.OptSetHealthRegistry(reg))
+	require.NoError(t, err)
+
+	statuses := reg.Statuses(health.Thresholds{})
+	require.Len(t, statuses, 2)
+	assert.Equal(t, health.KindCache, statuses[0].Kind)
+	assert.Equal(t, "root.cache_resources", statuses[0].Path)
+	assert.Equal(t, "foocache", statuses[0].Label)
+	assert.Equal(t, health.KindProcessor, statuses[1].Kind)
+	assert.Equal(t, "root.processor_resources", statuses[1].Path)
+	assert.Equal(t, "fooproc", statuses[1].Label)
+
+	require.NoError(t, mgr.AccessCache(context.Background(), "foocache", func(c cache.V1) {
+		_, err := c.Get(context.Background(), "nope")
+		assert.True(t, errors.Is(err, component.ErrKeyNotFound))
+	}))
+	require.NoError(t, mgr.AccessProcessor(context.Background(), "fooproc", func(p iprocessor.V1) {
+		_, _ = p.ProcessMessage(message.QuickBatch([][]byte{[]byte(`not structured`)}))
+	}))
+
+	statuses = reg.Statuses(health.Thresholds{})
+	assert.Equal(t, health.StateHealthy, statuses[0].State)
+	assert.NotNil(t, statuses[0].LastSuccess)
+	assert.Equal(t, health.StateDegraded, statuses[1].State)
+	assert.Contains(t, statuses[1].LastError, "failed")
+
+	mgr.CloseAsync()
+	require.NoError(t, mgr.WaitForClose(time.Second))
+	assert.Empty(t, reg.Statuses(health.Thresholds{}))
+}
+
 func TestManagerProcessorList(t *testing.T) {
 	cFoo := processor.NewConfig()
 	cFoo.Label = "foo"
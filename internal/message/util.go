@@ -3,6 +3,7 @@ package message
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 //------------------------------------------------------------------------------
@@ -90,7 +91,7 @@ func cloneGeneric(root interface{}) (interface{}, error) {
 		return cloneCheekyMap(t)
 	case []interface{}:
 		return cloneSlice(t)
-	case string, []byte, json.Number, uint64, int, int64, float64, bool, json.RawMessage:
+	case string, []byte, json.Number, uint64, int, int64, float64, bool, json.RawMessage, time.Time:
 		return t, nil
 	default:
 		// Oops, this means we have 'dirty' types within the JSON object. Our
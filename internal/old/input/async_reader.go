@@ -11,6 +11,7 @@ import (
 	"github.com/benthosdev/benthos/v4/internal/component"
 	"github.com/benthosdev/benthos/v4/internal/component/input"
 	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/health"
 	"github.com/benthosdev/benthos/v4/internal/log"
 	"github.com/benthosdev/benthos/v4/internal/message"
 	"github.com/benthosdev/benthos/v4/internal/old/input/reader"
@@ -165,6 +166,7 @@ func (r *AsyncReader) loop() {
 		} else {
 			r.connBackoff.Reset()
 			mRcvd.Incr(int64(msg.Len()))
+			health.RecordSuccess(r.log)
 			r.log.Tracef("Consumed %v messages from '%v'.\n", msg.Len(), r.typeStr)
 		}
 
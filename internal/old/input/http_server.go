@@ -25,6 +25,7 @@ import (
 	"github.com/benthosdev/benthos/v4/internal/component/metrics"
 	"github.com/benthosdev/benthos/v4/internal/component/ratelimit"
 	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/health"
 	httpdocs "github.com/benthosdev/benthos/v4/internal/http/docs"
 	"github.com/benthosdev/benthos/v4/internal/interop"
 	"github.com/benthosdev/benthos/v4/internal/log"
@@ -421,6 +422,7 @@ func (h *HTTPServer) postHandler(w http.ResponseWriter, r *http.Request) {
 	transaction.AddResultStore(msg, store)
 
 	h.mPostRcvd.Incr(int64(msg.Len()))
+	health.RecordSuccess(h.log)
 	h.log.Tracef("Consumed %v messages from POST to '%v'.\n", msg.Len(), h.conf.Path)
 
 	resChan := make(chan error, 1)
@@ -576,6 +578,7 @@ func (h *HTTPServer) wsHandler(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 			h.mWSRcvd.Incr(1)
+			health.RecordSuccess(h.log)
 		}
 
 		if h.conf.RateLimit != "" {
@@ -14,6 +14,7 @@ import (
 	"github.com/benthosdev/benthos/v4/internal/component/input"
 	"github.com/benthosdev/benthos/v4/internal/component/metrics"
 	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/health"
 	"github.com/benthosdev/benthos/v4/internal/interop"
 	"github.com/benthosdev/benthos/v4/internal/log"
 	"github.com/benthosdev/benthos/v4/internal/message"
@@ -287,6 +288,7 @@ acceptLoop:
 					return
 				}
 				t.mRcvd.Incr(int64(len(parts)))
+				health.RecordSuccess(t.log)
 
 				// We simply bounce rejected messages in a loop downstream so
 				// there's no benefit to aggregating acks.
@@ -337,6 +339,7 @@ func (t *SocketServer) udpLoop() {
 			return
 		}
 		t.mRcvd.Incr(int64(len(parts)))
+		health.RecordSuccess(t.log)
 
 		// We simply bounce rejected messages in a loop downstream so
 		// there's no benefit to aggregating acks.
@@ -13,6 +13,7 @@ import (
 	"github.com/benthosdev/benthos/v4/internal/component"
 	"github.com/benthosdev/benthos/v4/internal/component/metrics"
 	"github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/internal/health"
 	"github.com/benthosdev/benthos/v4/internal/interop"
 	"github.com/benthosdev/benthos/v4/internal/log"
 	"github.com/benthosdev/benthos/v4/internal/manager/mock"
@@ -302,6 +303,7 @@ func (w *AsyncWriter) loop() {
 				mBatchSent.Incr(1)
 				mSent.Incr(int64(batch.MessageCollapsedCount(ts.Payload)))
 				mLatency.Timing(latency)
+				health.RecordSuccess(w.log)
 				w.log.Tracef("Successfully wrote %v messages to '%v'.\n", ts.Payload.Len(), w.typeStr)
 			}
 
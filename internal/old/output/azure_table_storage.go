@@ -65,11 +65,11 @@ properties:
 			docs.FieldCommon(
 				"storage_access_key",
 				"The storage account access key. This field is ignored if `storage_connection_string` is set.",
-			),
+			).Secret(),
 			docs.FieldCommon(
 				"storage_connection_string",
 				"A storage account connection string. This field is required if `storage_account` and `storage_access_key` are not set.",
-			),
+			).Secret(),
 			docs.FieldCommon("table_name", "The table to store messages into.",
 				`${!meta("kafka_topic")}`,
 			).IsInterpolated(),
@@ -104,7 +104,7 @@ output:
 			).WithChildren(
 				docs.FieldCommon("enabled", "Whether to use password authentication."),
 				docs.FieldCommon("username", "A username."),
-				docs.FieldCommon("password", "A password."),
+				docs.FieldCommon("password", "A password.").Secret(),
 			),
 			docs.FieldAdvanced(
 				"disable_initial_host_lookup",
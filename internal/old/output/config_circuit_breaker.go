@@ -0,0 +1,85 @@
+package output
+
+import (
+	"encoding/json"
+
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/circuitbreaker"
+)
+
+// CircuitBreakerConfig contains configuration fields for the CircuitBreaker
+// output type.
+type CircuitBreakerConfig struct {
+	Output                *Config `json:"output" yaml:"output"`
+	Fallback              *Config `json:"fallback" yaml:"fallback"`
+	circuitbreaker.Config `json:",inline" yaml:",inline"`
+}
+
+// NewCircuitBreakerConfig creates a new CircuitBreakerConfig with default
+// values.
+func NewCircuitBreakerConfig() CircuitBreakerConfig {
+	return CircuitBreakerConfig{
+		Output:   nil,
+		Fallback: nil,
+		Config:   circuitbreaker.NewConfig(),
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type dummyCircuitBreakerConfig struct {
+	Output                interface{} `json:"output" yaml:"output"`
+	Fallback              interface{} `json:"fallback" yaml:"fallback"`
+	circuitbreaker.Config `json:",inline" yaml:",inline"`
+}
+
+func (c CircuitBreakerConfig) dummy() dummyCircuitBreakerConfig {
+	dummy := dummyCircuitBreakerConfig{
+		Output:   c.Output,
+		Fallback: c.Fallback,
+		Config:   c.Config,
+	}
+	if c.Output == nil {
+		dummy.Output = struct{}{}
+	}
+	if c.Fallback == nil {
+		dummy.Fallback = struct{}{}
+	}
+	return dummy
+}
+
+// UnmarshalYAML ensures that an empty fallback is treated as not set.
+func (c *CircuitBreakerConfig) UnmarshalYAML(value *yaml.Node) error {
+	aliased := struct {
+		Output                *Config   `yaml:"output"`
+		Fallback              yaml.Node `yaml:"fallback"`
+		circuitbreaker.Config `yaml:",inline"`
+	}{
+		Output: c.Output,
+		Config: c.Config,
+	}
+	if err := value.Decode(&aliased); err != nil {
+		return err
+	}
+
+	c.Output, c.Config, c.Fallback = aliased.Output, aliased.Config, nil
+	if aliased.Fallback.Kind == yaml.MappingNode && len(aliased.Fallback.Content) > 0 {
+		fallback := NewConfig()
+		if err := aliased.Fallback.Decode(&fallback); err != nil {
+			return err
+		}
+		c.Fallback = &fallback
+	}
+	return nil
+}
+
+// MarshalJSON prints empty objects instead of nil.
+func (c CircuitBreakerConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.dummy())
+}
+
+// MarshalYAML prints empty objects instead of nil.
+func (c CircuitBreakerConfig) MarshalYAML() (interface{}, error) {
+	return c.dummy(), nil
+}
@@ -0,0 +1,66 @@
+package output
+
+import (
+	"encoding/json"
+
+	"github.com/benthosdev/benthos/v4/internal/old/util/retries"
+)
+
+// DeadLetterConfig contains configuration fields for the DeadLetter output
+// type.
+type DeadLetterConfig struct {
+	Output           *Config `json:"output" yaml:"output"`
+	DeadLetterOutput *Config `json:"dead_letter_output" yaml:"dead_letter_output"`
+	MaxInFlight      int     `json:"max_in_flight" yaml:"max_in_flight"`
+	retries.Config   `json:",inline" yaml:",inline"`
+}
+
+// NewDeadLetterConfig creates a new DeadLetterConfig with default values.
+func NewDeadLetterConfig() DeadLetterConfig {
+	rConf := retries.NewConfig()
+	rConf.MaxRetries = 3
+	rConf.Backoff.InitialInterval = "500ms"
+	rConf.Backoff.MaxInterval = "3s"
+	rConf.Backoff.MaxElapsedTime = "0s"
+	return DeadLetterConfig{
+		Output:           nil,
+		DeadLetterOutput: nil,
+		MaxInFlight:      1,
+		Config:           rConf,
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type dummyDeadLetterConfig struct {
+	Output           interface{} `json:"output" yaml:"output"`
+	DeadLetterOutput interface{} `json:"dead_letter_output" yaml:"dead_letter_output"`
+	MaxInFlight      int         `json:"max_in_flight" yaml:"max_in_flight"`
+	retries.Config   `json:",inline" yaml:",inline"`
+}
+
+func (d DeadLetterConfig) dummy() dummyDeadLetterConfig {
+	dummy := dummyDeadLetterConfig{
+		Output:           d.Output,
+		DeadLetterOutput: d.DeadLetterOutput,
+		MaxInFlight:      d.MaxInFlight,
+		Config:           d.Config,
+	}
+	if d.Output == nil {
+		dummy.Output = struct{}{}
+	}
+	if d.DeadLetterOutput == nil {
+		dummy.DeadLetterOutput = struct{}{}
+	}
+	return dummy
+}
+
+// MarshalJSON prints empty objects instead of nil.
+func (d DeadLetterConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.dummy())
+}
+
+// MarshalYAML prints empty objects instead of nil.
+func (d DeadLetterConfig) MarshalYAML() (interface{}, error) {
+	return d.dummy(), nil
+}
@@ -150,6 +150,8 @@ const (
 	TypeBroker             = "broker"
 	TypeCache              = "cache"
 	TypeCassandra          = "cassandra"
+	TypeCircuitBreaker     = "circuit_breaker"
+	TypeDeadLetter         = "dead_letter"
 	TypeDrop               = "drop"
 	TypeDropOn             = "drop_on"
 	TypeDynamic            = "dynamic"
@@ -210,6 +212,8 @@ type Config struct {
 	Broker             BrokerConfig                   `json:"broker" yaml:"broker"`
 	Cache              writer.CacheConfig             `json:"cache" yaml:"cache"`
 	Cassandra          CassandraConfig                `json:"cassandra" yaml:"cassandra"`
+	CircuitBreaker     CircuitBreakerConfig           `json:"circuit_breaker" yaml:"circuit_breaker"`
+	DeadLetter         DeadLetterConfig               `json:"dead_letter" yaml:"dead_letter"`
 	Drop               writer.DropConfig              `json:"drop" yaml:"drop"`
 	DropOn             DropOnConfig                   `json:"drop_on" yaml:"drop_on"`
 	Dynamic            DynamicConfig                  `json:"dynamic" yaml:"dynamic"`
@@ -270,6 +274,8 @@ func NewConfig() Config {
 		Broker:             NewBrokerConfig(),
 		Cache:              writer.NewCacheConfig(),
 		Cassandra:          NewCassandraConfig(),
+		CircuitBreaker:     NewCircuitBreakerConfig(),
+		DeadLetter:         NewDeadLetterConfig(),
 		Drop:               writer.NewDropConfig(),
 		DropOn:             NewDropOnConfig(),
 		Dynamic:            NewDynamicConfig(),
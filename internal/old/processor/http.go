@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"strconv"
 
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/internal/circuitbreaker"
 	"github.com/benthosdev/benthos/v4/internal/component"
 	"github.com/benthosdev/benthos/v4/internal/component/metrics"
 	"github.com/benthosdev/benthos/v4/internal/component/processor"
@@ -67,6 +69,12 @@ field ` + "`http_status_code`" + ` on the resulting message.
 Use the field ` + "`extract_headers`" + ` to specify rules for which other
 headers should be copied into the resulting message from the response.
 
+## Circuit Breaking
+
+When the field ` + "`circuit_breaker.enabled`" + ` is set to ` + "`true`" + ` requests are tracked by a circuit breaker, and once the ratio of failed requests within a window reaches ` + "`circuit_breaker.failure_ratio`" + ` the breaker opens. Whilst open, requests are not attempted and messages are instead immediately flagged as failed with the error ` + "`circuit breaker is open`" + `. After an open period a number of probe requests are permitted, and if they succeed the breaker closes again.
+
+The state of the breaker is exposed as the gauge metric ` + "`circuit_breaker_state`" + ` (` + "`0`" + ` closed, ` + "`1`" + ` open, ` + "`2`" + ` half-open), and from the HTTP server of Benthos at the endpoint ` + "`/circuit_breakers/<path>`" + `, where ` + "`<path>`" + ` is the path of the processor within the config, e.g. ` + "`/circuit_breakers/root.pipeline.processors.0`" + `.
+
 ## Error Handling
 
 When all retry attempts for a message are exhausted the processor cancels the
@@ -75,7 +83,8 @@ can be dropped or placed in a dead letter queue according to your config, you
 can read about these patterns [here](/docs/configuration/error_handling).`,
 		config: ihttpdocs.ClientFieldSpec(false,
 			docs.FieldBool("batch_as_multipart", "Send message batches as a single request using [RFC1341](https://www.w3.org/Protocols/rfc1341/7_2_Multipart.html).").Advanced().HasDefault(false),
-			docs.FieldBool("parallel", "When processing batched messages, whether to send messages of the batch in parallel, otherwise they are sent serially.").HasDefault(false)),
+			docs.FieldBool("parallel", "When processing batched messages, whether to send messages of the batch in parallel, otherwise they are sent serially.").HasDefault(false),
+			circuitbreaker.ToggledFieldSpec()),
 		Examples: []docs.AnnotatedExample{
 			{
 				Title: "Branched Request",
@@ -101,8 +110,9 @@ pipeline:
 
 // HTTPConfig contains configuration fields for the HTTP processor.
 type HTTPConfig struct {
-	BatchAsMultipart bool `json:"batch_as_multipart" yaml:"batch_as_multipart"`
-	Parallel         bool `json:"parallel" yaml:"parallel"`
+	BatchAsMultipart bool                         `json:"batch_as_multipart" yaml:"batch_as_multipart"`
+	Parallel         bool                         `json:"parallel" yaml:"parallel"`
+	CircuitBreaker   circuitbreaker.ToggledConfig `json:"circuit_breaker" yaml:"circuit_breaker"`
 	ihttpdocs.Config `json:",inline" yaml:",inline"`
 }
 
@@ -111,6 +121,7 @@ func NewHTTPConfig() HTTPConfig {
 	return HTTPConfig{
 		BatchAsMultipart: false,
 		Parallel:         false,
+		CircuitBreaker:   circuitbreaker.NewToggledConfig(),
 		Config:           ihttpdocs.NewConfig(),
 	}
 }
@@ -119,6 +130,7 @@ func NewHTTPConfig() HTTPConfig {
 
 type httpProc struct {
 	client      *http.Client
+	breaker     *circuitbreaker.Breaker
 	asMultipart bool
 	parallel    bool
 	rawURL      string
@@ -142,15 +154,34 @@ func newHTTPProc(conf HTTPConfig, mgr interop.Manager) (processor.V2Batched, err
 	); err != nil {
 		return nil, err
 	}
+	if conf.CircuitBreaker.Enabled {
+		if g.breaker, err = circuitbreaker.New(conf.CircuitBreaker.Config, mgr.Metrics()); err != nil {
+			return nil, fmt.Errorf("failed to create circuit breaker: %w", err)
+		}
+		g.breaker.RegisterEndpoint(mgr, "root."+query.SliceToDotPath(mgr.Path()...))
+	}
 	return g, nil
 }
 
+func (h *httpProc) send(msg *message.Batch) (*message.Batch, error) {
+	if h.breaker == nil {
+		return h.client.Send(context.Background(), msg, msg)
+	}
+	ticket, err := h.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	res, err := h.client.Send(context.Background(), msg, msg)
+	ticket.Done(err)
+	return res, err
+}
+
 func (h *httpProc) ProcessBatch(ctx context.Context, spans []*tracing.Span, msg *message.Batch) ([]*message.Batch, error) {
 	var responseMsg *message.Batch
 
 	if h.asMultipart || msg.Len() == 1 {
 		// Easy, just do a single request.
-		resultMsg, err := h.client.Send(context.Background(), msg, msg)
+		resultMsg, err := h.send(msg)
 		if err != nil {
 			var codeStr string
 			var hErr component.ErrUnexpectedHTTPRes
@@ -190,7 +221,7 @@ func (h *httpProc) ProcessBatch(ctx context.Context, spans []*tracing.Span, msg
 		_ = msg.Iter(func(i int, p *message.Part) error {
 			tmpMsg := message.QuickBatch(nil)
 			tmpMsg.Append(p)
-			result, err := h.client.Send(context.Background(), tmpMsg, tmpMsg)
+			result, err := h.send(tmpMsg)
 			if err != nil {
 				h.log.Errorf("HTTP request to '%v' failed: %v", h.rawURL, err)
 
@@ -230,7 +261,7 @@ func (h *httpProc) ProcessBatch(ctx context.Context, spans []*tracing.Span, msg
 				for index := range reqChan {
 					tmpMsg := message.QuickBatch(nil)
 					tmpMsg.Append(msg.Get(index))
-					result, err := h.client.Send(context.Background(), tmpMsg, tmpMsg)
+					result, err := h.send(tmpMsg)
 					if err == nil && result.Len() != 1 {
 						err = fmt.Errorf("unexpected response size: %v", result.Len())
 					}
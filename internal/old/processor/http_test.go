@@ -61,6 +61,37 @@ func TestHTTPClientRetries(t *testing.T) {
 	}
 }
 
+func TestHTTPClientCircuitBreaker(t *testing.T) {
+	var reqCount uint32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddUint32(&reqCount, 1)
+		http.Error(w, "test error", http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	conf := NewConfig()
+	conf.Type = "http"
+	conf.HTTP.Config.URL = ts.URL + "/testpost"
+	conf.HTTP.Config.NumRetries = 0
+	conf.HTTP.CircuitBreaker.Enabled = true
+	conf.HTTP.CircuitBreaker.MinRequests = 2
+	conf.HTTP.CircuitBreaker.Backoff.InitialInterval = "1h"
+
+	h, err := New(conf, mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		msgs, res := h.ProcessMessage(message.QuickBatch([][]byte{[]byte("test")}))
+		require.NoError(t, res)
+		require.Len(t, msgs, 1)
+		require.True(t, HasFailed(msgs[0].Get(0)))
+		if i == 2 {
+			assert.Equal(t, "circuit breaker is open", GetFail(msgs[0].Get(0)))
+		}
+	}
+	assert.Equal(t, uint32(2), atomic.LoadUint32(&reqCount))
+}
+
 func TestHTTPClientBasic(t *testing.T) {
 	i := 0
 	expPayloads := []string{"foo", "bar", "baz"}
@@ -183,6 +183,14 @@ func (r *resourcedBranch) lock() (branch *Branch, unlockFn func()) {
 
 	go func() {
 		_ = r.mgr.AccessProcessor(context.Background(), r.name, func(p processor.V1) {
+			// Resources may be wrapped, e.g. in order to track their health.
+			for {
+				u, ok := p.(interface{ Unwrap() processor.V1 })
+				if !ok {
+					break
+				}
+				p = u.Unwrap()
+			}
 			branch, _ = p.(*Branch)
 			openOnce.Do(func() {
 				close(open)
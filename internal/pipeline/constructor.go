@@ -6,6 +6,7 @@ import (
 
 	iprocessor "github.com/benthosdev/benthos/v4/internal/component/processor"
 	"github.com/benthosdev/benthos/v4/internal/interop"
+	"github.com/benthosdev/benthos/v4/internal/log"
 	"github.com/benthosdev/benthos/v4/internal/old/processor"
 )
 
@@ -43,8 +44,14 @@ func New(conf Config, mgr interop.Manager) (Type, error) {
 			return nil, fmt.Errorf("failed to create processor '%v': %v", procConf.Type, err)
 		}
 	}
-	if conf.Threads == 1 {
+	return NewFromProcessors(conf.Threads, mgr.Logger(), processors...)
+}
+
+// NewFromProcessors creates a pipeline from processors that have already been
+// constructed, with a number of parallel threads.
+func NewFromProcessors(threads int, log log.Modular, processors ...iprocessor.V1) (Type, error) {
+	if threads == 1 {
 		return NewProcessor(processors...), nil
 	}
-	return newPoolV2(conf.Threads, mgr.Logger(), processors...)
+	return newPoolV2(threads, log, processors...)
 }
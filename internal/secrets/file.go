@@ -0,0 +1,22 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// fileProvider obtains secrets from files on disk, where the key is the path of
+// the file. This is suitable for secrets mounted as files such as those of
+// Kubernetes and Docker swarm.
+type fileProvider struct{}
+
+func (fileProvider) Lookup(ctx context.Context, key string) (string, error) {
+	b, err := os.ReadFile(key)
+	if err != nil {
+		return "", err
+	}
+	// Secret files often have a trailing newline, which is never intended as
+	// part of the value.
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
@@ -0,0 +1,5 @@
+// Package secrets provides a pluggable mechanism for resolving secret
+// references within configs at the point at which components are constructed,
+// which prevents secret values from being visible in the configs that are
+// echoed back by the CLI and HTTP API.
+package secrets
@@ -0,0 +1,303 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Provider is a backend from which secret values are obtained.
+type Provider interface {
+	// Lookup returns the value of a secret identified by a key, the format of
+	// which is specific to the provider.
+	Lookup(ctx context.Context, key string) (string, error)
+}
+
+// ProviderFunc is a closure that implements Provider.
+type ProviderFunc func(ctx context.Context, key string) (string, error)
+
+// Lookup returns the value of a secret identified by a key.
+func (f ProviderFunc) Lookup(ctx context.Context, key string) (string, error) {
+	return f(ctx, key)
+}
+
+//------------------------------------------------------------------------------
+
+var refRegex = regexp.MustCompile(`\${secret:([0-9A-Za-z_]+):([^}]+)}`)
+
+// ContainsReference returns true if a string contains at least one secret
+// reference of the form `${secret:<provider>:<key>}`.
+func ContainsReference(s string) bool {
+	return strings.Contains(s, "${secret:") && refRegex.MatchString(s)
+}
+
+// Providers is a collection of secret providers identified by name.
+type Providers struct {
+	providers map[string]Provider
+	mut       sync.RWMutex
+}
+
+// NewProviders creates an empty collection of secret providers.
+func NewProviders() *Providers {
+	return &Providers{
+		providers: map[string]Provider{},
+	}
+}
+
+// GlobalProviders contains the secret providers that are available to all
+// components by default, which includes all providers that have been imported.
+var GlobalProviders = NewProviders()
+
+func init() {
+	_ = GlobalProviders.Add("file", fileProvider{})
+	_ = GlobalProviders.Add("vault", newVaultProvider())
+}
+
+// Add a provider to the collection under a unique name, which is the name used
+// within secret references.
+func (p *Providers) Add(name string, provider Provider) error {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	if _, exists := p.providers[name]; exists {
+		return fmt.Errorf("secret provider '%v' already exists", name)
+	}
+	p.providers[name] = provider
+	return nil
+}
+
+// Names returns the sorted names of all providers in the collection.
+func (p *Providers) Names() []string {
+	p.mut.RLock()
+	defer p.mut.RUnlock()
+
+	names := make([]string, 0, len(p.providers))
+	for k := range p.providers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveString replaces all secret references within a string with the values
+// obtained from their providers.
+func (p *Providers) ResolveString(ctx context.Context, s string) (string, error) {
+	if !ContainsReference(s) {
+		return s, nil
+	}
+
+	var err error
+	resolved := refRegex.ReplaceAllStringFunc(s, func(ref string) string {
+		if err != nil {
+			return ""
+		}
+		groups := refRegex.FindStringSubmatch(ref)
+		name, key := groups[1], groups[2]
+
+		p.mut.RLock()
+		provider, exists := p.providers[name]
+		p.mut.RUnlock()
+		if !exists {
+			err = fmt.Errorf("secret provider '%v' was not recognised", name)
+			return ""
+		}
+
+		var value string
+		if value, err = provider.Lookup(ctx, key); err != nil {
+			err = fmt.Errorf("failed to obtain secret '%v' from provider '%v': %w", key, name, err)
+		}
+		return value
+	})
+	if err != nil {
+		return "", err
+	}
+	return resolved, nil
+}
+
+// Resolve walks a config, provided as a pointer, and replaces any secret
+// references found within its string values with the values obtained from
+// their providers.
+//
+// The config is never modified in place. Instead, the pointer is updated to a
+// copy of the config, where only the parts containing secret references are
+// copied. This allows a config to be resolved for the construction of a
+// component without the secret values leaking into a config that is retained
+// elsewhere.
+func (p *Providers) Resolve(ctx context.Context, confPtr interface{}) error {
+	v := reflect.ValueOf(confPtr)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("expected a non-nil pointer, got %T", confPtr)
+	}
+
+	resolved, changed, err := p.resolveValue(ctx, v.Elem())
+	if err != nil || !changed {
+		return err
+	}
+	v.Elem().Set(resolved)
+	return nil
+}
+
+func (p *Providers) resolveValue(ctx context.Context, v reflect.Value) (reflect.Value, bool, error) {
+	switch v.Kind() {
+	case reflect.String:
+		s := v.String()
+		if !ContainsReference(s) {
+			return v, false, nil
+		}
+		resolved, err := p.ResolveString(ctx, s)
+		if err != nil {
+			return v, false, err
+		}
+		return reflect.ValueOf(resolved).Convert(v.Type()), true, nil
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v, false, nil
+		}
+		elem, changed, err := p.resolveValue(ctx, v.Elem())
+		if err != nil || !changed {
+			return v, false, err
+		}
+		newPtr := reflect.New(v.Type().Elem())
+		newPtr.Elem().Set(elem)
+		return newPtr, true, nil
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, false, nil
+		}
+		elem, changed, err := p.resolveValue(ctx, v.Elem())
+		if err != nil || !changed {
+			return v, false, err
+		}
+		newIface := reflect.New(v.Type()).Elem()
+		newIface.Set(elem)
+		return newIface, true, nil
+
+	case reflect.Struct:
+		var newStruct reflect.Value
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath != "" {
+				// Unexported fields are left untouched.
+				continue
+			}
+			field, changed, err := p.resolveValue(ctx, v.Field(i))
+			if err != nil {
+				return v, false, err
+			}
+			if !changed {
+				continue
+			}
+			if !newStruct.IsValid() {
+				newStruct = reflect.New(v.Type()).Elem()
+				newStruct.Set(v)
+			}
+			newStruct.Field(i).Set(field)
+		}
+		if !newStruct.IsValid() {
+			return v, false, nil
+		}
+		return newStruct, true, nil
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return v, false, nil
+		}
+		var newSeq reflect.Value
+		for i := 0; i < v.Len(); i++ {
+			elem, changed, err := p.resolveValue(ctx, v.Index(i))
+			if err != nil {
+				return v, false, err
+			}
+			if !changed {
+				continue
+			}
+			if !newSeq.IsValid() {
+				if v.Kind() == reflect.Slice {
+					newSeq = reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+					reflect.Copy(newSeq, v)
+				} else {
+					newSeq = reflect.New(v.Type()).Elem()
+					newSeq.Set(v)
+				}
+			}
+			newSeq.Index(i).Set(elem)
+		}
+		if !newSeq.IsValid() {
+			return v, false, nil
+		}
+		return newSeq, true, nil
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v, false, nil
+		}
+		var newMap reflect.Value
+		iter := v.MapRange()
+		for iter.Next() {
+			elem, changed, err := p.resolveValue(ctx, iter.Value())
+			if err != nil {
+				return v, false, err
+			}
+			if !changed {
+				continue
+			}
+			if !newMap.IsValid() {
+				newMap = reflect.MakeMapWithSize(v.Type(), v.Len())
+				copyIter := v.MapRange()
+				for copyIter.Next() {
+					newMap.SetMapIndex(copyIter.Key(), copyIter.Value())
+				}
+			}
+			newMap.SetMapIndex(iter.Key(), elem)
+		}
+		if !newMap.IsValid() {
+			return v, false, nil
+		}
+		return newMap, true, nil
+	}
+	return v, false, nil
+}
+
+//------------------------------------------------------------------------------
+
+// SplitKey splits a secret key of the form `<path>#<field>` into its path and
+// field, where the field is optional.
+func SplitKey(key string) (path, field string) {
+	if i := strings.LastIndex(key, "#"); i >= 0 {
+		return key[:i], key[i+1:]
+	}
+	return key, ""
+}
+
+// SelectField obtains a field from a structured secret as a string. If the
+// field is empty then the secret must contain exactly one field, which is
+// returned. Values that are not strings are returned as JSON.
+func SelectField(data map[string]interface{}, field string) (string, error) {
+	if field == "" {
+		if len(data) != 1 {
+			return "", fmt.Errorf("secret contains %v fields and therefore a field must be specified", len(data))
+		}
+		for k := range data {
+			field = k
+		}
+	}
+
+	v, exists := data[field]
+	if !exists {
+		return "", fmt.Errorf("field '%v' was not found within the secret", field)
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,170 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func testProviders(t *testing.T) *Providers {
+	t.Helper()
+
+	p := NewProviders()
+	require.NoError(t, p.Add("test", ProviderFunc(func(ctx context.Context, key string) (string, error) {
+		if key == "nope" {
+			return "", errors.New("does not exist")
+		}
+		return "value of " + key, nil
+	})))
+	return p
+}
+
+func TestResolveString(t *testing.T) {
+	p := testProviders(t)
+
+	tests := map[string]string{
+		"foo":                "foo",
+		"${secret:test:foo}": "value of foo",
+		"a ${secret:test:foo} b ${secret:test:bar}": "a value of foo b value of bar",
+		"${FOO:bar}":        "${FOO:bar}",
+		"${!meta(\"foo\")}": "${!meta(\"foo\")}",
+	}
+	for in, exp := range tests {
+		out, err := p.ResolveString(context.Background(), in)
+		require.NoError(t, err, in)
+		assert.Equal(t, exp, out, in)
+	}
+
+	_, err := p.ResolveString(context.Background(), "${secret:test:nope}")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not exist")
+
+	_, err = p.ResolveString(context.Background(), "${secret:meow:foo}")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not recognised")
+}
+
+func TestProvidersAdd(t *testing.T) {
+	p := testProviders(t)
+	require.Error(t, p.Add("test", fileProvider{}))
+	require.NoError(t, p.Add("file", fileProvider{}))
+	assert.Equal(t, []string{"file", "test"}, p.Names())
+}
+
+type testConfig struct {
+	Name     string
+	Ignored  int
+	Strings  []string
+	Map      map[string]string
+	Child    *testConfig
+	Children []testConfig
+	Plugin   interface{}
+	secret   string
+}
+
+func TestResolveConfig(t *testing.T) {
+	p := testProviders(t)
+
+	var pluginNode yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(`
+foo: ${secret:test:plugin}
+bar: [ baz, "${secret:test:plugin_seq}" ]
+`), &pluginNode))
+
+	orig := testConfig{
+		Name:    "${secret:test:name}",
+		Ignored: 10,
+		Strings: []string{"a", "${secret:test:b}"},
+		Map:     map[string]string{"c": "${secret:test:c}", "d": "d"},
+		Child: &testConfig{
+			Name: "${secret:test:child}",
+		},
+		Children: []testConfig{
+			{Name: "unchanged"},
+		},
+		Plugin: &pluginNode,
+		secret: "${secret:test:unexported}",
+	}
+	conf := orig
+
+	require.NoError(t, p.Resolve(context.Background(), &conf))
+
+	assert.Equal(t, "value of name", conf.Name)
+	assert.Equal(t, 10, conf.Ignored)
+	assert.Equal(t, []string{"a", "value of b"}, conf.Strings)
+	assert.Equal(t, map[string]string{"c": "value of c", "d": "d"}, conf.Map)
+	assert.Equal(t, "value of child", conf.Child.Name)
+	assert.Equal(t, "${secret:test:unexported}", conf.secret)
+
+	var pluginConf map[string]interface{}
+	require.NoError(t, conf.Plugin.(*yaml.Node).Decode(&pluginConf))
+	assert.Equal(t, map[string]interface{}{
+		"foo": "value of plugin",
+		"bar": []interface{}{"baz", "value of plugin_seq"},
+	}, pluginConf)
+
+	// The original config must not be modified.
+	assert.Equal(t, "${secret:test:name}", orig.Name)
+	assert.Equal(t, []string{"a", "${secret:test:b}"}, orig.Strings)
+	assert.Equal(t, map[string]string{"c": "${secret:test:c}", "d": "d"}, orig.Map)
+	assert.Equal(t, "${secret:test:child}", orig.Child.Name)
+
+	var origPluginConf map[string]interface{}
+	require.NoError(t, orig.Plugin.(*yaml.Node).Decode(&origPluginConf))
+	assert.Equal(t, "${secret:test:plugin}", origPluginConf["foo"])
+
+	// Parts without references are not copied.
+	assert.Same(t, &orig.Children[0], &conf.Children[0])
+
+	badConf := testConfig{Strings: []string{"${secret:test:nope}"}}
+	require.Error(t, p.Resolve(context.Background(), &badConf))
+	assert.Equal(t, []string{"${secret:test:nope}"}, badConf.Strings)
+
+	require.Error(t, p.Resolve(context.Background(), badConf))
+}
+
+func TestFileProvider(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "foo"), []byte("hunter2\n"), 0o600))
+
+	p := NewProviders()
+	require.NoError(t, p.Add("file", fileProvider{}))
+
+	out, err := p.ResolveString(context.Background(), "password: ${secret:file:"+filepath.Join(dir, "foo")+"}")
+	require.NoError(t, err)
+	assert.Equal(t, "password: hunter2", out)
+
+	_, err = p.ResolveString(context.Background(), "${secret:file:"+filepath.Join(dir, "bar")+"}")
+	require.Error(t, err)
+}
+
+func TestSelectField(t *testing.T) {
+	data := map[string]interface{}{
+		"foo": "bar",
+		"baz": map[string]interface{}{"buz": 10.0},
+	}
+
+	v, err := SelectField(data, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "bar", v)
+
+	v, err = SelectField(data, "baz")
+	require.NoError(t, err)
+	assert.Equal(t, `{"buz":10}`, v)
+
+	_, err = SelectField(data, "nope")
+	require.Error(t, err)
+
+	_, err = SelectField(data, "")
+	require.Error(t, err)
+
+	v, err = SelectField(map[string]interface{}{"foo": "bar"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "bar", v)
+}
@@ -0,0 +1,79 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// vaultProvider obtains secrets from the HashiCorp Vault HTTP API, where the
+// key is the API path of the secret followed by an optional field, e.g.
+// `secret/data/foo#password`. Both version 1 and 2 of the KV secrets engine are
+// supported.
+//
+// The address and token of the Vault server are read from the standard
+// environment variables VAULT_ADDR, VAULT_TOKEN and VAULT_NAMESPACE at the
+// time of the lookup.
+type vaultProvider struct {
+	getenv func(string) string
+	client *http.Client
+}
+
+func newVaultProvider() *vaultProvider {
+	return &vaultProvider{
+		getenv: os.Getenv,
+		client: &http.Client{Timeout: time.Second * 30},
+	}
+}
+
+func (v *vaultProvider) Lookup(ctx context.Context, key string) (string, error) {
+	addr := v.getenv("VAULT_ADDR")
+	if addr == "" {
+		return "", errors.New("the environment variable VAULT_ADDR must be set")
+	}
+
+	path, field := SplitKey(key)
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimSuffix(addr, "/")+"/v1/"+strings.TrimPrefix(path, "/"), http.NoBody)
+	if err != nil {
+		return "", err
+	}
+	if token := v.getenv("VAULT_TOKEN"); token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if ns := v.getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+
+	res, err := v.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return "", fmt.Errorf("unexpected status code %v: %s", res.StatusCode, body)
+	}
+
+	var resBody struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&resBody); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	data := resBody.Data
+	if _, hasMeta := data["metadata"]; hasMeta {
+		// Version 2 of the KV engine nests the secret within the response.
+		if inner, ok := data["data"].(map[string]interface{}); ok {
+			data = inner
+		}
+	}
+	return SelectField(data, field)
+}
@@ -0,0 +1,62 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "footoken" {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/foo":
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"hunter2","user":"admin"},"metadata":{"version":1}}}`))
+		case "/v1/kv/bar":
+			_, _ = w.Write([]byte(`{"data":{"token":"bartoken"}}`))
+		default:
+			http.Error(w, `{"errors":[]}`, http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	env := map[string]string{
+		"VAULT_ADDR":  server.URL,
+		"VAULT_TOKEN": "footoken",
+	}
+	v := newVaultProvider()
+	v.getenv = func(k string) string {
+		return env[k]
+	}
+
+	out, err := v.Lookup(context.Background(), "secret/data/foo#password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", out)
+
+	out, err = v.Lookup(context.Background(), "kv/bar")
+	require.NoError(t, err)
+	assert.Equal(t, "bartoken", out)
+
+	_, err = v.Lookup(context.Background(), "secret/data/foo")
+	require.Error(t, err)
+
+	_, err = v.Lookup(context.Background(), "secret/data/nope#password")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "404")
+
+	env["VAULT_TOKEN"] = "badtoken"
+	_, err = v.Lookup(context.Background(), "kv/bar")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "403")
+
+	delete(env, "VAULT_ADDR")
+	_, err = v.Lookup(context.Background(), "kv/bar")
+	require.Error(t, err)
+}
@@ -33,7 +33,7 @@ func NewConfig() Config {
 
 // Sanitised returns a sanitised copy of the Benthos configuration, meaning
 // fields of no consequence (unused inputs, outputs, processors etc) are
-// excluded.
+// excluded, and the values of fields containing secrets are redacted.
 func (c Config) Sanitised() (interface{}, error) {
 	var node yaml.Node
 	if err := node.Encode(c); err != nil {
@@ -42,6 +42,7 @@ func (c Config) Sanitised() (interface{}, error) {
 
 	if err := Spec().SanitiseYAML(&node, docs.SanitiseConfig{
 		RemoveTypeField: true,
+		RedactSecrets:   true,
 	}); err != nil {
 		return nil, err
 	}
@@ -53,4 +54,20 @@ func (c Config) Sanitised() (interface{}, error) {
 	return g, nil
 }
 
+// usesDeadLetter returns true if any output of the config is a dead_letter
+// output, which reports the paths of the processors that flagged errors.
+func (c Config) usesDeadLetter() bool {
+	var node yaml.Node
+	if err := node.Encode(c); err != nil {
+		return false
+	}
+	var found bool
+	Spec().WalkYAMLComponents(nil, &node, nil, func(cType docs.Type, name string, path []string, node *yaml.Node) {
+		if cType == docs.TypeOutput && name == "dead_letter" {
+			found = true
+		}
+	})
+	return found
+}
+
 //------------------------------------------------------------------------------
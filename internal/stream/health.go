@@ -0,0 +1,60 @@
+package stream
+
+import (
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	iinput "github.com/benthosdev/benthos/v4/internal/component/input"
+	ioutput "github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/internal/health"
+	"github.com/benthosdev/benthos/v4/internal/interop"
+	"github.com/benthosdev/benthos/v4/internal/old/input"
+	"github.com/benthosdev/benthos/v4/internal/old/output"
+)
+
+type healthTracker interface {
+	TrackHealth(kind, label string) (*health.Tracker, interop.Manager)
+}
+
+// trackHealth registers a component of the stream with the health registry of
+// the manager. When the manager does not support health tracking the component
+// is registered with a detached registry instead.
+func trackHealth(mgr bundle.NewManagement, kind, label string) (*health.Tracker, bundle.NewManagement) {
+	if ht, ok := mgr.(healthTracker); ok {
+		tracker, tMgr := ht.TrackHealth(kind, label)
+		if nm, ok := tMgr.(bundle.NewManagement); ok {
+			return tracker, nm
+		}
+		tracker.Close()
+	}
+	return health.NewRegistry().Register("", kind, "", label), mgr
+}
+
+// newInput creates the input of the stream and tracks its health. An input that
+// references a resource is not tracked, as the resource records its own health.
+func (t *Type) newInput(conf input.Config) (iinput.Streamed, error) {
+	if conf.Type == input.TypeResource {
+		return t.manager.IntoPath("input").(bundle.NewManagement).NewInput(conf)
+	}
+	tracker, iMgr := trackHealth(t.manager.IntoPath("input").(bundle.NewManagement), health.KindInput, conf.Label)
+	in, err := iMgr.NewInput(conf)
+	if err != nil {
+		tracker.Close()
+		return nil, err
+	}
+	return health.TrackInput(tracker, in), nil
+}
+
+// newOutput creates the output of the stream and tracks its health. An output
+// that references a resource is not tracked, as the resource records its own
+// health.
+func (t *Type) newOutput(conf output.Config) (ioutput.Streamed, error) {
+	if conf.Type == output.TypeResource {
+		return t.manager.IntoPath("output").(bundle.NewManagement).NewOutput(conf)
+	}
+	tracker, oMgr := trackHealth(t.manager.IntoPath("output").(bundle.NewManagement), health.KindOutput, conf.Label)
+	out, err := oMgr.NewOutput(conf)
+	if err != nil {
+		tracker.Close()
+		return nil, err
+	}
+	return health.TrackOutput(tracker, out), nil
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"regexp"
 	"sort"
 	"strings"
 	"sync"
@@ -15,6 +16,7 @@ import (
 	"github.com/gorilla/mux"
 	"gopkg.in/yaml.v3"
 
+	"github.com/benthosdev/benthos/v4/internal/bundle"
 	"github.com/benthosdev/benthos/v4/internal/component/buffer"
 	"github.com/benthosdev/benthos/v4/internal/component/cache"
 	"github.com/benthosdev/benthos/v4/internal/component/ratelimit"
@@ -38,6 +40,13 @@ func (m *Type) registerEndpoints(enableCrud bool) {
 	if !enableCrud {
 		return
 	}
+	m.manager.RegisterEndpoint(
+		"/events",
+		"GET: Stream lifecycle, connection and error events of all streams as"+
+			" server-sent events. Events can be filtered with the URL params"+
+			" `stream` and `type`.",
+		m.events.HandleSSE,
+	)
 	m.manager.RegisterEndpoint(
 		"/streams",
 		"GET: List all streams along with their status and uptimes."+
@@ -57,6 +66,43 @@ func (m *Type) registerEndpoints(enableCrud bool) {
 		"GET a structured JSON object containing metrics for the stream.",
 		m.HandleStreamStats,
 	)
+	m.manager.RegisterEndpoint(
+		"/streams/{id}/pause",
+		"POST: Stop a stream from consuming messages from its input without"+
+			" closing it. Messages already in flight continue through the stream.",
+		m.HandleStreamPause,
+	)
+	m.manager.RegisterEndpoint(
+		"/streams/{id}/resume",
+		"POST: Resume consuming messages for a stream that was paused or drained.",
+		m.HandleStreamResume,
+	)
+	m.manager.RegisterEndpoint(
+		"/streams/{id}/drain",
+		"POST: Close the input of a stream and wait for all in-flight messages"+
+			" to be resolved. The stream config is retained and can be resumed.",
+		m.HandleStreamDrain,
+	)
+	m.manager.RegisterEndpoint(
+		"/streams/{id}/validate",
+		"POST: Parse, lint and construct the components of a stream config"+
+			" without running it, returning any errors found.",
+		m.HandleStreamValidate,
+	)
+	m.manager.RegisterEndpoint(
+		"/streams/{id}/quotas",
+		"GET: Read the quotas of a stream. POST: Set the quotas of a stream,"+
+			" which restarts the stream in order to apply them.",
+		m.HandleStreamQuotas,
+	)
+	m.manager.RegisterEndpoint(
+		"/streams/{id}/resources/{type}/{name}",
+		"POST: Create or replace a given resource configuration of a specified"+
+			" type within the namespace of a stream, where it is only accessible"+
+			" to that stream. Types supported are `cache`, `input`, `output`,"+
+			" `processor` and `rate_limit`.",
+		m.HandleStreamResourceCRUD,
+	)
 	m.manager.RegisterEndpoint(
 		"/resources/{type}/{id}",
 		"POST: Create or replace a given resource configuration of a specified type. Types supported are `cache`, `input`, `output`, `processor` and `rate_limit`.",
@@ -85,9 +131,49 @@ func (c ConfigSet) UnmarshalYAML(value *yaml.Node) error {
 	return nil
 }
 
-func lintStreamConfigNode(node *yaml.Node) (lints []string) {
+var validIDRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// validateID returns an error if an id provided via the API contains characters
+// other than alphanumerics, underscores and hyphens. Since ids form the keys of
+// persisted definitions this prevents them from escaping a store.
+func validateID(id string) error {
+	if !validIDRegexp.MatchString(id) {
+		return fmt.Errorf("id '%v' must only contain alphanumeric characters, underscores and hyphens", id)
+	}
+	return nil
+}
+
+// writeWarnings responds with a list of warnings for a request that succeeded.
+func writeWarnings(w http.ResponseWriter, warnings []string) {
+	var nonEmpty []string
+	for _, warn := range warnings {
+		if warn != "" {
+			nonEmpty = append(nonEmpty, warn)
+		}
+	}
+	if len(nonEmpty) == 0 {
+		return
+	}
+	sort.Strings(nonEmpty)
+	resBytes, _ := json.Marshal(struct {
+		Warnings []string `json:"warnings"`
+	}{
+		Warnings: nonEmpty,
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resBytes)
+}
+
+// lintStreamConfigNode returns the lint errors of a stream config separately
+// from lint warnings, which should not cause a request to be rejected.
+func lintStreamConfigNode(node *yaml.Node) (lints, warnings []string) {
 	for _, dLint := range stream.Spec().LintYAML(docs.NewLintContext(), node) {
-		lints = append(lints, fmt.Sprintf("line %v: %v", dLint.Line, dLint.What))
+		msg := fmt.Sprintf("line %v: %v", dLint.Line, dLint.What)
+		if dLint.Level == docs.LintError {
+			lints = append(lints, msg)
+		} else {
+			warnings = append(warnings, msg)
+		}
 	}
 	return
 }
@@ -115,6 +201,7 @@ func (m *Type) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
 
 	type confInfo struct {
 		Active    bool    `json:"active"`
+		State     string  `json:"state"`
 		Uptime    float64 `json:"uptime"`
 		UptimeStr string  `json:"uptime_str"`
 	}
@@ -124,6 +211,7 @@ func (m *Type) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
 	for id, strInfo := range m.streams {
 		infos[id] = confInfo{
 			Active:    strInfo.IsRunning(),
+			State:     strInfo.State(),
 			Uptime:    strInfo.Uptime().Seconds(),
 			UptimeStr: strInfo.Uptime().String(),
 		}
@@ -156,11 +244,15 @@ func (m *Type) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
 		}
 		var lints []string
 		for k, n := range nodeSet {
-			for _, l := range lintStreamConfigNode(&n) {
+			sLints, sWarnings := lintStreamConfigNode(&n)
+			for _, l := range sLints {
 				keyLint := fmt.Sprintf("stream '%v': %v", k, l)
 				lints = append(lints, keyLint)
 				m.manager.Logger().Debugf("Streams request linting error: %v\n", keyLint)
 			}
+			for _, w := range sWarnings {
+				m.manager.Logger().Warnf("Stream '%v' config: %v\n", k, w)
+			}
 		}
 		if len(lints) > 0 {
 			sort.Strings(lints)
@@ -180,6 +272,15 @@ func (m *Type) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	rawSet := map[string]yaml.Node{}
+	if requestErr = yaml.Unmarshal(setBytes, &rawSet); requestErr != nil {
+		return
+	}
+	rawBytes := func(id string) ([]byte, error) {
+		rawNode := rawSet[id]
+		return yaml.Marshal(&rawNode)
+	}
+
 	toDelete := []string{}
 	toUpdate := map[string]stream.Config{}
 	toCreate := map[string]stream.Config{}
@@ -193,6 +294,9 @@ func (m *Type) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
 	}
 	for id, conf := range newSet {
 		if _, exists := infos[id]; !exists {
+			if requestErr = validateID(id); requestErr != nil {
+				return
+			}
 			toCreate[id] = conf
 		}
 	}
@@ -202,6 +306,9 @@ func (m *Type) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
 		deadline = time.Now().Add(m.apiTimeout)
 	}
 
+	ctx, done := context.WithDeadline(r.Context(), deadline)
+	defer done()
+
 	wg := sync.WaitGroup{}
 	wg.Add(len(toDelete))
 	wg.Add(len(toUpdate))
@@ -210,10 +317,13 @@ func (m *Type) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
 	errDelete := make([]error, len(toDelete))
 	errUpdate := make([]error, len(toUpdate))
 	errCreate := make([]error, len(toCreate))
+	warnUpdate := make([]string, len(toUpdate))
 
 	for i, id := range toDelete {
 		go func(sid string, j int) {
-			errDelete[j] = m.Delete(sid, time.Until(deadline))
+			if errDelete[j] = m.Delete(sid, time.Until(deadline)); errDelete[j] == nil {
+				errDelete[j] = m.unpersistStream(ctx, sid)
+			}
 			wg.Done()
 		}(id, i)
 	}
@@ -221,7 +331,12 @@ func (m *Type) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
 	for id, conf := range toUpdate {
 		newConf := conf
 		go func(sid string, sconf *stream.Config, j int) {
-			errUpdate[j] = m.Update(sid, *sconf, time.Until(deadline))
+			if errUpdate[j] = m.Update(sid, *sconf, time.Until(deadline)); errUpdate[j] == nil {
+				var raw []byte
+				if raw, errUpdate[j] = rawBytes(sid); errUpdate[j] == nil {
+					warnUpdate[j] = m.persistUpdated(ctx, sid, raw)
+				}
+			}
 			wg.Done()
 		}(id, &newConf, i)
 		i++
@@ -230,7 +345,14 @@ func (m *Type) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
 	for id, conf := range toCreate {
 		newConf := conf
 		go func(sid string, sconf *stream.Config, j int) {
-			errCreate[j] = m.Create(sid, *sconf)
+			var raw []byte
+			if raw, errCreate[j] = rawBytes(sid); errCreate[j] != nil {
+				wg.Done()
+				return
+			}
+			if errCreate[j] = m.Create(sid, *sconf); errCreate[j] == nil {
+				errCreate[j] = m.persistCreated(ctx, sid, raw, time.Until(deadline))
+			}
 			wg.Done()
 		}(id, &newConf, i)
 		i++
@@ -257,7 +379,10 @@ func (m *Type) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
 
 	if len(errs) > 0 {
 		requestErr = errors.New(strings.Join(errs, "\n"))
+		return
 	}
+
+	writeWarnings(w, warnUpdate)
 }
 
 // HandleStreamCRUD is an http.HandleFunc for performing CRUD operations on
@@ -286,30 +411,37 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	readConfig := func() (confOut stream.Config, lints []string, err error) {
-		var confBytes []byte
-		if confBytes, err = io.ReadAll(r.Body); err != nil {
+	if (r.Method == "POST" || r.Method == "PUT") && r.URL.Query().Get("dry_run") == "true" {
+		m.HandleStreamValidate(w, r)
+		return
+	}
+
+	readConfig := func() (confOut stream.Config, rawBytes []byte, lints []string, err error) {
+		if rawBytes, err = io.ReadAll(r.Body); err != nil {
 			return
 		}
-		confBytes = config.ReplaceEnvVariables(confBytes)
+		confBytes := config.ReplaceEnvVariables(rawBytes)
 
 		if r.URL.Query().Get("chilled") != "true" {
 			var node yaml.Node
 			if err = yaml.Unmarshal(confBytes, &node); err != nil {
 				return
 			}
-			lints = lintStreamConfigNode(&node)
+			var warnings []string
+			lints, warnings = lintStreamConfigNode(&node)
 			for _, l := range lints {
 				m.manager.Logger().Infof("Stream '%v' config: %v\n", id, l)
 			}
+			for _, w := range warnings {
+				m.manager.Logger().Warnf("Stream '%v' config: %v\n", id, w)
+			}
 		}
 
 		confOut = stream.NewConfig()
 		err = yaml.Unmarshal(confBytes, &confOut)
 		return
 	}
-	patchConfig := func(confIn stream.Config) (confOut stream.Config, err error) {
-		var patchBytes []byte
+	patchConfig := func(confIn stream.Config) (confOut stream.Config, patchBytes []byte, err error) {
 		if patchBytes, err = io.ReadAll(r.Body); err != nil {
 			return
 		}
@@ -347,11 +479,18 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 		deadline = time.Now().Add(m.apiTimeout)
 	}
 
+	ctx, done := context.WithDeadline(r.Context(), deadline)
+	defer done()
+
 	var conf stream.Config
+	var rawBytes []byte
 	var lints []string
 	switch r.Method {
 	case "POST":
-		if conf, lints, requestErr = readConfig(); requestErr != nil {
+		if requestErr = validateID(id); requestErr != nil {
+			return
+		}
+		if conf, rawBytes, lints, requestErr = readConfig(); requestErr != nil {
 			return
 		}
 		if len(lints) > 0 {
@@ -364,7 +503,9 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 			w.Write(errBytes)
 			return
 		}
-		serverErr = m.Create(id, conf)
+		if serverErr = m.Create(id, conf); serverErr == nil {
+			serverErr = m.persistCreated(ctx, id, rawBytes, time.Until(deadline))
+		}
 	case "GET":
 		var info *StreamStatus
 		if info, serverErr = m.Read(id); serverErr == nil {
@@ -373,11 +514,13 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 			var bodyBytes []byte
 			if bodyBytes, serverErr = json.Marshal(struct {
 				Active    bool        `json:"active"`
+				State     string      `json:"state"`
 				Uptime    float64     `json:"uptime"`
 				UptimeStr string      `json:"uptime_str"`
 				Config    interface{} `json:"config"`
 			}{
 				Active:    info.IsRunning(),
+				State:     info.State(),
 				Uptime:    info.Uptime().Seconds(),
 				UptimeStr: info.Uptime().String(),
 				Config:    sanit,
@@ -389,7 +532,7 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 			w.Write(bodyBytes)
 		}
 	case "PUT":
-		if conf, lints, requestErr = readConfig(); requestErr != nil {
+		if conf, rawBytes, lints, requestErr = readConfig(); requestErr != nil {
 			return
 		}
 		if len(lints) > 0 {
@@ -402,16 +545,28 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 			w.Write(errBytes)
 			return
 		}
-		serverErr = m.Update(id, conf, time.Until(deadline))
+		if serverErr = m.Update(id, conf, time.Until(deadline)); serverErr == nil {
+			writeWarnings(w, []string{m.persistUpdated(ctx, id, rawBytes)})
+		}
 	case "DELETE":
-		serverErr = m.Delete(id, time.Until(deadline))
+		if serverErr = m.Delete(id, time.Until(deadline)); serverErr == nil {
+			serverErr = m.unpersistStream(ctx, id)
+		}
 	case "PATCH":
 		var info *StreamStatus
 		if info, serverErr = m.Read(id); serverErr == nil {
-			if conf, requestErr = patchConfig(info.Config()); requestErr != nil {
+			var patchBytes []byte
+			if conf, patchBytes, requestErr = patchConfig(info.Config()); requestErr != nil {
 				return
 			}
-			serverErr = m.Update(id, conf, time.Until(deadline))
+			if m.store != nil {
+				if rawBytes, requestErr = m.patchRawConfig(id, patchBytes); requestErr != nil {
+					return
+				}
+			}
+			if serverErr = m.Update(id, conf, time.Until(deadline)); serverErr == nil {
+				writeWarnings(w, []string{m.persistUpdated(ctx, id, rawBytes)})
+			}
 		}
 	default:
 		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
@@ -432,6 +587,36 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 // HandleResourceCRUD is an http.HandleFunc for performing CRUD operations on
 // resource components.
 func (m *Type) HandleResourceCRUD(w http.ResponseWriter, r *http.Request) {
+	m.handleResourceCRUD(w, r, "id", func() (bundle.NewManagement, error) {
+		return m.manager, nil
+	}, resourceStoreKey)
+}
+
+// HandleStreamResourceCRUD is an http.HandleFunc for performing CRUD operations
+// on resource components within the namespace of a stream.
+func (m *Type) HandleStreamResourceCRUD(w http.ResponseWriter, r *http.Request) {
+	streamID := mux.Vars(r)["id"]
+	if streamID == "" {
+		http.Error(w, "Var `id` must be set", http.StatusBadRequest)
+		return
+	}
+	if err := validateID(streamID); err != nil {
+		http.Error(w, fmt.Sprintf("Error: %v", err), http.StatusBadRequest)
+		return
+	}
+	m.handleResourceCRUD(w, r, "name", func() (bundle.NewManagement, error) {
+		return m.streamResources(streamID)
+	}, func(typeStr, name string) string {
+		return streamResourceStoreKey(streamID, typeStr, name)
+	})
+}
+
+func (m *Type) handleResourceCRUD(
+	w http.ResponseWriter, r *http.Request,
+	nameVar string,
+	getMgr func() (bundle.NewManagement, error),
+	storeKey func(typeStr, name string) string,
+) {
 	var serverErr, requestErr error
 	defer func() {
 		if r.Body != nil {
@@ -454,72 +639,32 @@ func (m *Type) HandleResourceCRUD(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id := mux.Vars(r)["id"]
+	id := mux.Vars(r)[nameVar]
 	if id == "" {
-		http.Error(w, "Var `id` must be set", http.StatusBadRequest)
+		http.Error(w, fmt.Sprintf("Var `%v` must be set", nameVar), http.StatusBadRequest)
+		return
+	}
+	if requestErr = validateID(id); requestErr != nil {
 		return
 	}
 
 	ctx, done := context.WithDeadline(r.Context(), time.Now().Add(m.apiTimeout))
 	defer done()
 
-	var storeFn func(*yaml.Node)
-
 	docType := docs.Type(mux.Vars(r)["type"])
-	switch docType {
-	case docs.TypeCache:
-		storeFn = func(n *yaml.Node) {
-			cacheConf := cache.NewConfig()
-			if requestErr = n.Decode(&cacheConf); requestErr != nil {
-				return
-			}
-			serverErr = m.manager.StoreCache(ctx, id, cacheConf)
-		}
-	case docs.TypeInput:
-		storeFn = func(n *yaml.Node) {
-			inputConf := input.NewConfig()
-			if requestErr = n.Decode(&inputConf); requestErr != nil {
-				return
-			}
-			serverErr = m.manager.StoreInput(ctx, id, inputConf)
-		}
-	case docs.TypeOutput:
-		storeFn = func(n *yaml.Node) {
-			outputConf := output.NewConfig()
-			if requestErr = n.Decode(&outputConf); requestErr != nil {
-				return
-			}
-			serverErr = m.manager.StoreOutput(ctx, id, outputConf)
-		}
-	case docs.TypeProcessor:
-		storeFn = func(n *yaml.Node) {
-			procConf := processor.NewConfig()
-			if requestErr = n.Decode(&procConf); requestErr != nil {
-				return
-			}
-			serverErr = m.manager.StoreProcessor(ctx, id, procConf)
-		}
-	case docs.TypeRateLimit:
-		storeFn = func(n *yaml.Node) {
-			rlConf := ratelimit.NewConfig()
-			if requestErr = n.Decode(&rlConf); requestErr != nil {
-				return
-			}
-			serverErr = m.manager.StoreRateLimit(ctx, id, rlConf)
-		}
-	default:
+	if !isResourceType(docType) {
 		http.Error(w, "Var `type` must be set to one of `cache`, `input`, `output`, `processor` or `rate_limit`", http.StatusBadRequest)
 		return
 	}
 
+	var rawBytes []byte
 	var confNode *yaml.Node
 	var lints []string
 	{
-		var confBytes []byte
-		if confBytes, requestErr = io.ReadAll(r.Body); requestErr != nil {
+		if rawBytes, requestErr = io.ReadAll(r.Body); requestErr != nil {
 			return
 		}
-		confBytes = config.ReplaceEnvVariables(confBytes)
+		confBytes := config.ReplaceEnvVariables(rawBytes)
 
 		var node yaml.Node
 		if requestErr = yaml.Unmarshal(confBytes, &node); requestErr != nil {
@@ -529,6 +674,10 @@ func (m *Type) HandleResourceCRUD(w http.ResponseWriter, r *http.Request) {
 
 		if r.URL.Query().Get("chilled") != "true" {
 			for _, l := range docs.LintYAML(docs.NewLintContext(), docType, &node) {
+				if l.Level != docs.LintError {
+					m.manager.Logger().Warnf("Resource '%v' config: line %v: %v\n", id, l.Line, l.What)
+					continue
+				}
 				lints = append(lints, fmt.Sprintf("line %v: %v", l.Line, l.What))
 				m.manager.Logger().Infof("Resource '%v' config: %v\n", id, l)
 			}
@@ -545,7 +694,63 @@ func (m *Type) HandleResourceCRUD(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	storeFn(confNode)
+	var mgr bundle.NewManagement
+	if mgr, serverErr = getMgr(); serverErr != nil {
+		return
+	}
+	if requestErr, serverErr = storeResource(ctx, mgr, docType, id, confNode); requestErr != nil || serverErr != nil {
+		return
+	}
+	serverErr = m.persist(ctx, storeKey(string(docType), id), rawBytes)
+}
+
+func isResourceType(t docs.Type) bool {
+	switch t {
+	case docs.TypeCache, docs.TypeInput, docs.TypeOutput, docs.TypeProcessor, docs.TypeRateLimit:
+		return true
+	}
+	return false
+}
+
+// storeResource decodes a resource config of a given type and adds it to a
+// manager, returning a request error if the config could not be decoded or a
+// server error if the resource could not be created.
+func storeResource(ctx context.Context, mgr bundle.NewManagement, docType docs.Type, id string, n *yaml.Node) (requestErr, serverErr error) {
+	switch docType {
+	case docs.TypeCache:
+		cacheConf := cache.NewConfig()
+		if requestErr = n.Decode(&cacheConf); requestErr != nil {
+			return
+		}
+		serverErr = mgr.StoreCache(ctx, id, cacheConf)
+	case docs.TypeInput:
+		inputConf := input.NewConfig()
+		if requestErr = n.Decode(&inputConf); requestErr != nil {
+			return
+		}
+		serverErr = mgr.StoreInput(ctx, id, inputConf)
+	case docs.TypeOutput:
+		outputConf := output.NewConfig()
+		if requestErr = n.Decode(&outputConf); requestErr != nil {
+			return
+		}
+		serverErr = mgr.StoreOutput(ctx, id, outputConf)
+	case docs.TypeProcessor:
+		procConf := processor.NewConfig()
+		if requestErr = n.Decode(&procConf); requestErr != nil {
+			return
+		}
+		serverErr = mgr.StoreProcessor(ctx, id, procConf)
+	case docs.TypeRateLimit:
+		rlConf := ratelimit.NewConfig()
+		if requestErr = n.Decode(&rlConf); requestErr != nil {
+			return
+		}
+		serverErr = mgr.StoreRateLimit(ctx, id, rlConf)
+	default:
+		requestErr = fmt.Errorf("resource type not supported: %v", docType)
+	}
+	return
 }
 
 // HandleStreamStats is an http.HandleFunc for obtaining metrics for a stream.
@@ -595,6 +800,15 @@ func (m *Type) HandleStreamStats(w http.ResponseWriter, r *http.Request) {
 			}
 			values["uptime_ns"] = info.Uptime().Nanoseconds()
 
+			quotas := info.Quotas()
+			values["quotas"] = struct {
+				Quotas
+				InFlight int `json:"in_flight"`
+			}{
+				Quotas:   quotas,
+				InFlight: info.InFlight(),
+			}
+
 			jBytes, err := json.Marshal(values)
 			if err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -614,6 +828,213 @@ func (m *Type) HandleStreamStats(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// HandleStreamValidate is an http.HandleFunc for validating a stream config
+// without creating or modifying the stream.
+func (m *Type) HandleStreamValidate(w http.ResponseWriter, r *http.Request) {
+	var serverErr, requestErr error
+	defer func() {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+		if serverErr != nil {
+			m.manager.Logger().Errorf("Stream validate Error: %v\n", serverErr)
+			http.Error(w, fmt.Sprintf("Error: %v", serverErr), http.StatusBadGateway)
+			return
+		}
+		if requestErr != nil {
+			m.manager.Logger().Debugf("Stream request validate Error: %v\n", requestErr)
+			http.Error(w, fmt.Sprintf("Error: %v", requestErr), http.StatusBadRequest)
+			return
+		}
+	}()
+
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		http.Error(w, "Var `id` must be set", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method != "POST" && r.Method != "PUT" {
+		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
+		return
+	}
+
+	var confBytes []byte
+	if confBytes, requestErr = io.ReadAll(r.Body); requestErr != nil {
+		return
+	}
+
+	vErrs := m.Validate(id, confBytes, r.URL.Query().Get("deprecated") == "true")
+	if vErrs == nil {
+		vErrs = []ValidationError{}
+	}
+
+	valid := true
+	for _, vErr := range vErrs {
+		if vErr.Kind != ValidationKindLintWarning {
+			valid = false
+			break
+		}
+	}
+
+	var resBytes []byte
+	if resBytes, serverErr = json.Marshal(struct {
+		Valid         bool              `json:"valid"`
+		Errors        []ValidationError `json:"errors"`
+		Unconstructed []string          `json:"unconstructed"`
+	}{
+		Valid:         valid,
+		Errors:        vErrs,
+		Unconstructed: validationUnconstructed,
+	}); serverErr != nil {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !valid {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	w.Write(resBytes)
+}
+
+// HandleStreamQuotas is an http.HandleFunc for reading and setting the quotas of
+// a stream.
+func (m *Type) HandleStreamQuotas(w http.ResponseWriter, r *http.Request) {
+	var serverErr, requestErr error
+	defer func() {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+		if serverErr != nil {
+			m.manager.Logger().Errorf("Stream quotas Error: %v\n", serverErr)
+			http.Error(w, fmt.Sprintf("Error: %v", serverErr), http.StatusBadGateway)
+			return
+		}
+		if requestErr != nil {
+			m.manager.Logger().Debugf("Stream request quotas Error: %v\n", requestErr)
+			http.Error(w, fmt.Sprintf("Error: %v", requestErr), http.StatusBadRequest)
+			return
+		}
+	}()
+
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		http.Error(w, "Var `id` must be set", http.StatusBadRequest)
+		return
+	}
+
+	deadline, hasDeadline := r.Context().Deadline()
+	if !hasDeadline {
+		deadline = time.Now().Add(m.apiTimeout)
+	}
+
+	switch r.Method {
+	case "GET":
+		var quotas Quotas
+		if quotas, serverErr = m.Quotas(id); serverErr == nil {
+			var resBytes []byte
+			if resBytes, serverErr = json.Marshal(quotas); serverErr == nil {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write(resBytes)
+			}
+		}
+	case "POST":
+		var quotaBytes []byte
+		if quotaBytes, requestErr = io.ReadAll(r.Body); requestErr != nil {
+			return
+		}
+		var quotas Quotas
+		if requestErr = yaml.Unmarshal(quotaBytes, &quotas); requestErr != nil {
+			return
+		}
+		if quotas.MaxThreads < 0 || quotas.MaxInFlight < 0 || quotas.MaxRate < 0 {
+			requestErr = errors.New("quotas must not be negative")
+			return
+		}
+		if serverErr = m.SetQuotas(id, quotas, time.Until(deadline)); serverErr == nil {
+			ctx, done := context.WithDeadline(r.Context(), deadline)
+			defer done()
+
+			var persistBytes []byte
+			if persistBytes, serverErr = json.Marshal(quotas); serverErr == nil {
+				serverErr = m.persist(ctx, quotasStoreKey(id), persistBytes)
+			}
+		}
+	default:
+		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
+	}
+	if serverErr == ErrStreamDoesNotExist {
+		serverErr = nil
+		http.Error(w, "Stream not found", http.StatusNotFound)
+		return
+	}
+}
+
+// HandleStreamPause is an http.HandleFunc for pausing a stream.
+func (m *Type) HandleStreamPause(w http.ResponseWriter, r *http.Request) {
+	m.handleStreamControl(w, r, "pause", func(id string, _ time.Duration) error {
+		return m.Pause(id)
+	})
+}
+
+// HandleStreamResume is an http.HandleFunc for resuming a paused or drained
+// stream.
+func (m *Type) HandleStreamResume(w http.ResponseWriter, r *http.Request) {
+	m.handleStreamControl(w, r, "resume", func(id string, _ time.Duration) error {
+		return m.Resume(id)
+	})
+}
+
+// HandleStreamDrain is an http.HandleFunc for draining a stream.
+func (m *Type) HandleStreamDrain(w http.ResponseWriter, r *http.Request) {
+	m.handleStreamControl(w, r, "drain", m.Drain)
+}
+
+func (m *Type) handleStreamControl(w http.ResponseWriter, r *http.Request, action string, fn func(id string, timeout time.Duration) error) {
+	var serverErr, requestErr error
+	defer func() {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+		if serverErr != nil {
+			m.manager.Logger().Errorf("Stream %v Error: %v\n", action, serverErr)
+			http.Error(w, fmt.Sprintf("Error: %v", serverErr), http.StatusBadGateway)
+			return
+		}
+		if requestErr != nil {
+			m.manager.Logger().Debugf("Stream request %v Error: %v\n", action, requestErr)
+			http.Error(w, fmt.Sprintf("Error: %v", requestErr), http.StatusBadRequest)
+			return
+		}
+	}()
+
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		http.Error(w, "Var `id` must be set", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method != "POST" {
+		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
+		return
+	}
+
+	deadline, hasDeadline := r.Context().Deadline()
+	if !hasDeadline {
+		deadline = time.Now().Add(m.apiTimeout)
+	}
+
+	serverErr = fn(id, time.Until(deadline))
+	if serverErr == ErrStreamDoesNotExist {
+		serverErr = nil
+		http.Error(w, "Stream not found", http.StatusNotFound)
+		return
+	}
+	if serverErr == ErrStreamDrained {
+		requestErr, serverErr = serverErr, nil
+	}
+}
+
 // HandleStreamReady is an http.HandleFunc for providing a ready check across
 // all streams.
 func (m *Type) HandleStreamReady(w http.ResponseWriter, r *http.Request) {
@@ -1,8 +1,11 @@
 package manager_test
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,6 +13,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -26,6 +30,7 @@ import (
 	"github.com/benthosdev/benthos/v4/internal/message"
 	"github.com/benthosdev/benthos/v4/internal/old/input"
 	"github.com/benthosdev/benthos/v4/internal/old/output"
+	"github.com/benthosdev/benthos/v4/internal/old/processor"
 	"github.com/benthosdev/benthos/v4/internal/stream"
 	"github.com/benthosdev/benthos/v4/internal/stream/manager"
 
@@ -37,7 +42,14 @@ func router(m *manager.Type) *mux.Router {
 	router.HandleFunc("/streams", m.HandleStreamsCRUD)
 	router.HandleFunc("/streams/{id}", m.HandleStreamCRUD)
 	router.HandleFunc("/streams/{id}/stats", m.HandleStreamStats)
+	router.HandleFunc("/streams/{id}/pause", m.HandleStreamPause)
+	router.HandleFunc("/streams/{id}/resume", m.HandleStreamResume)
+	router.HandleFunc("/streams/{id}/drain", m.HandleStreamDrain)
+	router.HandleFunc("/streams/{id}/quotas", m.HandleStreamQuotas)
+	router.HandleFunc("/streams/{id}/validate", m.HandleStreamValidate)
+	router.HandleFunc("/streams/{id}/resources/{type}/{name}", m.HandleStreamResourceCRUD)
 	router.HandleFunc("/resources/{type}/{id}", m.HandleResourceCRUD)
+	router.HandleFunc("/events", m.Events().HandleSSE)
 	return router
 }
 
@@ -101,6 +113,7 @@ func parseListBody(data *bytes.Buffer) listBody {
 
 type getBody struct {
 	Active    bool        `json:"active"`
+	State     string      `json:"state"`
 	Uptime    float64     `json:"uptime"`
 	UptimeStr string      `json:"uptime_str"`
 	Config    interface{} `json:"config"`
@@ -688,6 +701,21 @@ func TestTypeAPILinting(t *testing.T) {
 	response = httptest.NewRecorder()
 	r.ServeHTTP(response, request)
 	assert.Equal(t, http.StatusOK, response.Code)
+
+	// Lint warnings alone should not prevent a stream from being created.
+	request = genYAMLRequest("POST", "/streams/bar", `
+input:
+  generate:
+    mapping: |
+      let unused = "hello"
+      root = "hello"
+output:
+  drop: {}
+`)
+
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code, response.Body.String())
 }
 
 func TestResourceAPILinting(t *testing.T) {
@@ -826,6 +854,613 @@ func TestTypeAPIGetStats(t *testing.T) {
 	assert.Greater(t, len(stats.ChildrenMap()), 0, response.Body.String())
 }
 
+func TestTypeAPIPauseResumeDrain(t *testing.T) {
+	mgr, err := bmanager.NewV2(bmanager.NewResourceConfig(), mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	smgr := manager.New(mgr,
+		manager.OptSetAPITimeout(time.Second*10),
+	)
+
+	r := router(smgr)
+
+	require.NoError(t, smgr.Create("foo", harmlessConf()))
+
+	getState := func() string {
+		t.Helper()
+		request := genRequest("GET", "/streams/foo", nil)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+		return parseGetBody(t, response.Body).State
+	}
+
+	control := func(verb, action string) int {
+		t.Helper()
+		request := genRequest(verb, "/streams/foo/"+action, nil)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		return response.Code
+	}
+
+	assert.Equal(t, "running", getState())
+
+	request := genRequest("POST", "/streams/not_exist/pause", nil)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	assert.Equal(t, http.StatusBadRequest, control("GET", "pause"))
+
+	assert.Equal(t, http.StatusOK, control("POST", "pause"))
+	assert.Equal(t, "paused", getState())
+
+	assert.Equal(t, http.StatusOK, control("POST", "resume"))
+	assert.Equal(t, "running", getState())
+
+	assert.Equal(t, http.StatusOK, control("POST", "drain"))
+	assert.Equal(t, "drained", getState())
+
+	assert.Equal(t, http.StatusBadRequest, control("POST", "pause"))
+
+	assert.Equal(t, http.StatusOK, control("POST", "resume"))
+	assert.Equal(t, "running", getState())
+
+	require.NoError(t, smgr.Stop(time.Second*5))
+}
+
+func TestTypeAPIPersistence(t *testing.T) {
+	storeDir := t.TempDir()
+
+	res, err := bmanager.NewV2(bmanager.NewResourceConfig(), mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	mgr := manager.New(res,
+		manager.OptSetAPITimeout(time.Second*10),
+		manager.OptSetStore(manager.NewDirectoryStore(storeDir)),
+	)
+
+	r := router(mgr)
+	conf, err := harmlessConf().Sanitised()
+	require.NoError(t, err)
+
+	for _, id := range []string{"foo", "bar"} {
+		request := genRequest("POST", "/streams/"+id, conf)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	}
+
+	request := genYAMLRequest("POST", "/resources/cache/baz", `memory: {}`)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genRequest("POST", "/streams/foo/quotas", manager.Quotas{MaxInFlight: 3})
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genYAMLRequest("POST", "/streams/bar/resources/cache/buz", `memory: {}`)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genRequest("DELETE", "/streams/bar", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	require.NoError(t, mgr.Stop(time.Second*5))
+
+	res, err = bmanager.NewV2(bmanager.NewResourceConfig(), mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	mgr = manager.New(res,
+		manager.OptSetAPITimeout(time.Second*10),
+		manager.OptSetStore(manager.NewDirectoryStore(storeDir)),
+	)
+	require.NoError(t, mgr.LoadFromStore(context.Background()))
+
+	_, err = mgr.Read("foo")
+	require.NoError(t, err)
+
+	quotas, err := mgr.Quotas("foo")
+	require.NoError(t, err)
+	assert.Equal(t, manager.Quotas{MaxInFlight: 3}, quotas)
+
+	_, err = mgr.Read("bar")
+	assert.Equal(t, manager.ErrStreamDoesNotExist, err)
+
+	assert.True(t, res.ProbeCache("baz"))
+
+	require.NoError(t, mgr.Stop(time.Second*5))
+}
+
+func TestTypeAPIInvalidIDs(t *testing.T) {
+	storeDir := t.TempDir()
+
+	res, err := bmanager.NewV2(bmanager.NewResourceConfig(), mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	mgr := manager.New(res,
+		manager.OptSetAPITimeout(time.Second*10),
+		manager.OptSetStore(manager.NewDirectoryStore(storeDir)),
+	)
+	t.Cleanup(func() {
+		require.NoError(t, mgr.Stop(time.Second*5))
+	})
+
+	r := router(mgr)
+	conf, err := harmlessConf().Sanitised()
+	require.NoError(t, err)
+
+	for _, id := range []string{"foo..", "foo.bar", "foo~bar", "foo%20bar"} {
+		request := genRequest("POST", "/streams/"+id, conf)
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		assert.Equal(t, http.StatusBadRequest, response.Code, id)
+
+		request = genYAMLRequest("POST", "/resources/cache/"+id, `memory: {}`)
+		response = httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		assert.Equal(t, http.StatusBadRequest, response.Code, id)
+	}
+
+	request := genRequest("POST", "/streams", map[string]interface{}{
+		"../../escaped": conf,
+	})
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	request = genYAMLRequest("POST", "/streams/.../resources/cache/foo", `memory: {}`)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	request = genRequest("GET", "/streams", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "{}", response.Body.String())
+
+	files, err := os.ReadDir(storeDir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestTypeAPIPersistRawConfig(t *testing.T) {
+	os.Setenv("BENTHOS_TEST_SECRET_PATH", "/secret")
+	t.Cleanup(func() {
+		os.Unsetenv("BENTHOS_TEST_SECRET_PATH")
+	})
+
+	storeDir := t.TempDir()
+
+	res, err := bmanager.NewV2(bmanager.NewResourceConfig(), mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	mgr := manager.New(res,
+		manager.OptSetAPITimeout(time.Second*10),
+		manager.OptSetStore(manager.NewDirectoryStore(storeDir)),
+	)
+	t.Cleanup(func() {
+		require.NoError(t, mgr.Stop(time.Second*5))
+	})
+
+	r := router(mgr)
+
+	request := genYAMLRequest("POST", "/streams/foo", `
+input:
+  http_server:
+    path: ${BENTHOS_TEST_SECRET_PATH}
+output:
+  drop: {}
+`)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "/secret", info.Config().Input.HTTPServer.Path)
+
+	request = genYAMLRequest("PATCH", "/streams/foo", `
+input:
+  http_server:
+    timeout: 10s
+`)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	info, err = mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "/secret", info.Config().Input.HTTPServer.Path)
+	assert.Equal(t, "10s", info.Config().Input.HTTPServer.Timeout)
+
+	// Environment variables are persisted unresolved, and nothing other than
+	// what was provided is written.
+	storedBytes, err := os.ReadFile(filepath.Join(storeDir, "streams", "foo.yaml"))
+	require.NoError(t, err)
+
+	var stored interface{}
+	require.NoError(t, yaml.Unmarshal(storedBytes, &stored))
+	assert.Equal(t, map[string]interface{}{
+		"input": map[string]interface{}{
+			"http_server": map[string]interface{}{
+				"path":    "${BENTHOS_TEST_SECRET_PATH}",
+				"timeout": "10s",
+			},
+		},
+		"output": map[string]interface{}{
+			"drop": map[string]interface{}{},
+		},
+	}, stored)
+}
+
+func TestTypeAPIPatchPersistSecrets(t *testing.T) {
+	storeDir := t.TempDir()
+
+	res, err := bmanager.NewV2(bmanager.NewResourceConfig(), mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	mgr := manager.New(res,
+		manager.OptSetAPITimeout(time.Second*10),
+		manager.OptSetStore(manager.NewDirectoryStore(storeDir)),
+	)
+
+	// Streams created outside of the API do not have a raw config, and
+	// therefore patches are applied to their current config.
+	conf := harmlessConf()
+	conf.Output.Type = "http_client"
+	conf.Output.HTTPClient.URL = "http://localhost:4195/nope"
+	conf.Output.HTTPClient.Headers = map[string]string{
+		"Authorization": "Bearer hunter2",
+	}
+	require.NoError(t, mgr.Create("foo", conf))
+
+	r := router(mgr)
+
+	request := genYAMLRequest("PATCH", "/streams/foo", `
+input:
+  http_server:
+    timeout: 10s
+`)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	storedBytes, err := os.ReadFile(filepath.Join(storeDir, "streams", "foo.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(storedBytes), "Bearer hunter2")
+	assert.NotContains(t, string(storedBytes), "SECRET_SCRUBBED")
+
+	require.NoError(t, mgr.Stop(time.Second*5))
+
+	res, err = bmanager.NewV2(bmanager.NewResourceConfig(), mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	mgr = manager.New(res,
+		manager.OptSetAPITimeout(time.Second*10),
+		manager.OptSetStore(manager.NewDirectoryStore(storeDir)),
+	)
+	require.NoError(t, mgr.LoadFromStore(context.Background()))
+	t.Cleanup(func() {
+		require.NoError(t, mgr.Stop(time.Second*5))
+	})
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "10s", info.Config().Input.HTTPServer.Timeout)
+	assert.Equal(t, "Bearer hunter2", info.Config().Output.HTTPClient.Headers["Authorization"])
+}
+
+type errStore struct{}
+
+func (e errStore) List(ctx context.Context) (map[string][]byte, error) {
+	return map[string][]byte{}, nil
+}
+
+func (e errStore) Set(ctx context.Context, key string, value []byte) error {
+	return errors.New("store is broken")
+}
+
+func (e errStore) Delete(ctx context.Context, key string) error {
+	return errors.New("store is broken")
+}
+
+func TestTypeAPIPersistFailure(t *testing.T) {
+	res, err := bmanager.NewV2(bmanager.NewResourceConfig(), mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	mgr := manager.New(res,
+		manager.OptSetAPITimeout(time.Second*10),
+		manager.OptSetStore(errStore{}),
+	)
+	t.Cleanup(func() {
+		require.NoError(t, mgr.Stop(time.Second*5))
+	})
+
+	r := router(mgr)
+	conf, err := harmlessConf().Sanitised()
+	require.NoError(t, err)
+
+	// A created stream that cannot be persisted is removed.
+	request := genRequest("POST", "/streams/foo", conf)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadGateway, response.Code, response.Body.String())
+
+	_, err = mgr.Read("foo")
+	assert.Equal(t, manager.ErrStreamDoesNotExist, err)
+
+	// An updated stream that cannot be persisted is kept, with a warning.
+	require.NoError(t, mgr.Create("foo", harmlessConf()))
+
+	request = genYAMLRequest("PUT", "/streams/foo", `
+input:
+  http_server:
+    path: /changed
+output:
+  drop: {}
+`)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Contains(t, response.Body.String(), "was updated but not persisted: failed to persist streams/foo: store is broken")
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "/changed", info.Config().Input.HTTPServer.Path)
+}
+
+func TestTypeAPIQuotas(t *testing.T) {
+	mgr, err := bmanager.NewV2(bmanager.NewResourceConfig(), mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	smgr := manager.New(mgr,
+		manager.OptSetAPITimeout(time.Second*10),
+		manager.OptSetDefaultQuotas(manager.Quotas{MaxInFlight: 10}),
+	)
+
+	r := router(smgr)
+
+	require.NoError(t, smgr.Create("foo", harmlessConf()))
+	before, err := smgr.Read("foo")
+	require.NoError(t, err)
+
+	request := genRequest("GET", "/streams/foo/quotas", nil)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.JSONEq(t, `{"max_threads":0,"max_in_flight":10,"max_rate":0}`, response.Body.String())
+
+	request = genRequest("POST", "/streams/not_exist/quotas", manager.Quotas{MaxThreads: 1})
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	request = genRequest("POST", "/streams/foo/quotas", manager.Quotas{MaxThreads: -1})
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	request = genRequest("POST", "/streams/foo/quotas", manager.Quotas{
+		MaxThreads:  2,
+		MaxInFlight: 5,
+		MaxRate:     100,
+	})
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genRequest("GET", "/streams/foo/stats", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	stats, err := gabs.ParseJSON(response.Body.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, float64(2), stats.Path("quotas.max_threads").Data())
+	assert.Equal(t, float64(5), stats.Path("quotas.max_in_flight").Data())
+	assert.Equal(t, float64(100), stats.Path("quotas.max_rate").Data())
+	assert.Equal(t, float64(0), stats.Path("quotas.in_flight").Data())
+
+	// The config of the stream reflects what was submitted rather than the
+	// quotas applied, and the quotas are applied to the running stream.
+	info, err := smgr.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, -1, info.Config().Pipeline.Threads)
+	assert.Same(t, before, info)
+	assert.True(t, info.IsRunning())
+
+	require.NoError(t, smgr.Stop(time.Second*5))
+}
+
+func TestTypeAPIStreamResources(t *testing.T) {
+	mgr, err := bmanager.NewV2(bmanager.NewResourceConfig(), mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	smgr := manager.New(mgr,
+		manager.OptSetAPITimeout(time.Second*10),
+	)
+
+	r := router(smgr)
+
+	request := genYAMLRequest("POST", "/streams/foo/resources/cache/bar", `memory: {}`)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genYAMLRequest("POST", "/streams/foo/resources/nope/bar", `memory: {}`)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+
+	assert.False(t, mgr.ProbeCache("bar"))
+
+	conf := harmlessConf()
+	procConf := processor.NewConfig()
+	procConf.Type = "cache"
+	procConf.Cache.Resource = "bar"
+	procConf.Cache.Operator = "set"
+	procConf.Cache.Key = "foo"
+	conf.Pipeline.Processors = append(conf.Pipeline.Processors, procConf)
+
+	// Only the stream with the namespaced resource is able to access it.
+	require.NoError(t, smgr.Create("foo", conf))
+	require.Error(t, smgr.Create("baz", conf))
+
+	// The namespace survives updates of the stream.
+	conf.Buffer.Type = "memory"
+	require.NoError(t, smgr.Update("foo", conf, time.Second*5))
+
+	require.NoError(t, smgr.Delete("foo", time.Second*5))
+	require.Error(t, smgr.Create("foo", conf))
+
+	require.NoError(t, smgr.Stop(time.Second*5))
+}
+
+func TestTypeAPIValidate(t *testing.T) {
+	mgr, err := bmanager.NewV2(bmanager.NewResourceConfig(), mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	smgr := manager.New(mgr,
+		manager.OptSetAPITimeout(time.Second*10),
+	)
+
+	r := router(smgr)
+
+	type validateBody struct {
+		Valid         bool                      `json:"valid"`
+		Errors        []manager.ValidationError `json:"errors"`
+		Unconstructed []string                  `json:"unconstructed"`
+	}
+
+	tests := []struct {
+		name     string
+		url      string
+		config   string
+		code     int
+		expected validateBody
+	}{
+		{
+			name: "valid config",
+			url:  "/streams/foo/validate",
+			config: `
+input:
+  generate:
+    mapping: 'root = "hello"'
+output:
+  drop: {}
+`,
+			code:     http.StatusOK,
+			expected: validateBody{Valid: true, Errors: []manager.ValidationError{}},
+		},
+		{
+			name: "parse error",
+			url:  "/streams/foo/validate",
+			config: `
+input:
+  generate: [
+`,
+			code: http.StatusBadRequest,
+			expected: validateBody{Errors: []manager.ValidationError{
+				{Kind: "parse", Line: 3, Message: "yaml: line 3: did not find expected node content"},
+			}},
+		},
+		{
+			name: "lint and construction errors",
+			url:  "/streams/foo/validate",
+			config: `
+input:
+  generate:
+    mapping: 'root = "hello"'
+pipeline:
+  processors:
+    - bloblang: 'root = this'
+    - cache:
+        resource: nope
+        operator: get
+        key: foo
+output:
+  drop: {}
+  nope: true
+`,
+			code: http.StatusBadRequest,
+			expected: validateBody{Errors: []manager.ValidationError{
+				{Kind: "lint", Line: 14, Message: "field nope is invalid when the component type is drop (output)"},
+				{Kind: "construction", Line: 8, Path: "pipeline.processors.1", Message: "cache resource 'nope' was not found"},
+			}},
+		},
+		{
+			name: "lint warnings only",
+			url:  "/streams/foo/validate",
+			config: `
+input:
+  generate:
+    mapping: |
+      let unused = "hello"
+      root = "hello"
+output:
+  drop: {}
+`,
+			code: http.StatusOK,
+			expected: validateBody{Valid: true, Errors: []manager.ValidationError{
+				{Kind: "lint_warning", Line: 5, Column: 15, Message: "variable unused is declared but never used"},
+			}},
+		},
+		{
+			name: "missing resources",
+			url:  "/streams/foo/validate",
+			config: `
+input:
+  resource: nope_in
+output:
+  resource: nope_out
+`,
+			code: http.StatusBadRequest,
+			expected: validateBody{Errors: []manager.ValidationError{
+				{Kind: "construction", Line: 3, Path: "input", Message: "input resource 'nope_in' was not found"},
+				{Kind: "construction", Line: 5, Path: "output", Message: "output resource 'nope_out' was not found"},
+			}},
+		},
+		{
+			name: "dry run",
+			url:  "/streams/foo?dry_run=true",
+			config: `
+input:
+  generate:
+    mapping: 'root = "hello"'
+output:
+  drop: {}
+`,
+			code:     http.StatusOK,
+			expected: validateBody{Valid: true, Errors: []manager.ValidationError{}},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			request := genYAMLRequest("POST", test.url, test.config)
+			response := httptest.NewRecorder()
+			r.ServeHTTP(response, request)
+			require.Equal(t, test.code, response.Code, response.Body.String())
+
+			var actual validateBody
+			require.NoError(t, json.Unmarshal(response.Body.Bytes(), &actual))
+			test.expected.Unconstructed = []string{"input", "buffer", "output"}
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+
+	_, err = smgr.Read("foo")
+	assert.Equal(t, manager.ErrStreamDoesNotExist, err)
+}
+
 func TestTypeAPISetResources(t *testing.T) {
 	bmgr, err := bmanager.NewV2(bmanager.NewResourceConfig(), mock.NewManager(), log.Noop(), metrics.Noop())
 	require.NoError(t, err)
@@ -914,3 +1549,100 @@ file:
 	require.NoError(t, err)
 	assert.Equal(t, `{"id":"second","content":"hello world 2"}`, string(file2Bytes))
 }
+
+func TestTypeAPIEvents(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	mgr, err := bmanager.NewV2(bmanager.NewResourceConfig(), mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	smgr := manager.New(mgr,
+		manager.OptSetAPITimeout(time.Second*10),
+	)
+
+	server := httptest.NewServer(router(smgr))
+	defer server.Close()
+
+	req, err := http.NewRequestWithContext(tCtx, "GET", server.URL+"/events?stream=foo", http.NoBody)
+	require.NoError(t, err)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	seen := map[string][]string{}
+	scanner := bufio.NewScanner(res.Body)
+	waitForEvent := func(eventType string) {
+		t.Helper()
+		for len(seen[eventType]) == 0 {
+			var lastType string
+			if !scanner.Scan() {
+				t.Fatalf("event stream ended before %v: %v", eventType, scanner.Err())
+			}
+			line := scanner.Text()
+			if strings.HasPrefix(line, "event: ") {
+				lastType = strings.TrimPrefix(line, "event: ")
+				require.True(t, scanner.Scan())
+				seen[lastType] = append(seen[lastType], strings.TrimPrefix(scanner.Text(), "data: "))
+			}
+		}
+	}
+
+	createReq := genYAMLRequest("POST", server.URL+"/streams/foo", `
+input:
+  generate:
+    interval: 50ms
+    mapping: 'root = "hello world"'
+pipeline:
+  processors:
+    - log:
+        level: ERROR
+        message: 'failed to do a thing'
+    - log:
+        level: WARN
+        message: 'nearly failed to do a thing'
+output:
+  drop: {}
+`)
+	createRes, err := http.DefaultClient.Do(createReq)
+	require.NoError(t, err)
+	createRes.Body.Close()
+	require.Equal(t, http.StatusOK, createRes.StatusCode)
+
+	waitForEvent("stream_created")
+	waitForEvent("input_connected")
+	waitForEvent("output_connected")
+	waitForEvent("error")
+
+	var errEvent struct {
+		Stream  string            `json:"stream"`
+		Message string            `json:"message"`
+		Fields  map[string]string `json:"fields"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(seen["error"][0]), &errEvent))
+	assert.Equal(t, "foo", errEvent.Stream)
+	assert.Equal(t, "failed to do a thing", errEvent.Message)
+	assert.Equal(t, "root.pipeline.processors.0", errEvent.Fields["path"])
+
+	waitForEvent("warning")
+	require.NoError(t, json.Unmarshal([]byte(seen["warning"][0]), &errEvent))
+	assert.Equal(t, "nearly failed to do a thing", errEvent.Message)
+	assert.Equal(t, "root.pipeline.processors.1", errEvent.Fields["path"])
+
+	deleteRes, err := http.DefaultClient.Do(genRequest("DELETE", server.URL+"/streams/foo", nil))
+	require.NoError(t, err)
+	deleteRes.Body.Close()
+	require.Equal(t, http.StatusOK, deleteRes.StatusCode)
+
+	waitForEvent("stream_deleted")
+	assert.Empty(t, seen["input_disconnected"])
+	assert.Empty(t, seen["output_disconnected"])
+
+	require.NoError(t, smgr.Stop(time.Second*5))
+
+	// The event stream ends once the manager is stopped.
+	_, err = io.Copy(io.Discard, res.Body)
+	require.NoError(t, err)
+}
@@ -0,0 +1,182 @@
+package manager
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/api"
+	"github.com/benthosdev/benthos/v4/internal/interop"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/stream"
+)
+
+// The types of event published by a stream manager.
+const (
+	EventStreamCreated      = "stream_created"
+	EventStreamUpdated      = "stream_updated"
+	EventStreamDeleted      = "stream_deleted"
+	EventStreamPaused       = "stream_paused"
+	EventStreamResumed      = "stream_resumed"
+	EventStreamDrained      = "stream_drained"
+	EventInputConnected     = "input_connected"
+	EventInputDisconnected  = "input_disconnected"
+	EventOutputConnected    = "output_connected"
+	EventOutputDisconnected = "output_disconnected"
+	EventError              = "error"
+	EventWarning            = "warning"
+)
+
+// The interval at which the connection state of streams is checked for
+// changes.
+var connectionPollInterval = time.Millisecond * 500
+
+// Events returns the broadcaster of events published by the stream manager,
+// which includes changes to the lifecycle of streams, changes to the connection
+// state of their inputs and outputs, and error and warning logs.
+func (m *Type) Events() *api.Events {
+	return m.events
+}
+
+func (m *Type) publish(eventType, id string) {
+	m.events.Publish(api.Event{
+		Type:   eventType,
+		Stream: id,
+	})
+}
+
+//------------------------------------------------------------------------------
+
+// WatchConnections publishes events to a broadcaster whenever the connection
+// state of the input or output of a stream changes, until stop is closed.
+func WatchConnections(events *api.Events, id string, strm *stream.Type, stop <-chan struct{}) {
+	ticker := time.NewTicker(connectionPollInterval)
+	defer ticker.Stop()
+
+	publish := func(eventType string) {
+		events.Publish(api.Event{
+			Type:   eventType,
+			Stream: id,
+		})
+	}
+
+	var inConnected, outConnected bool
+	for {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+		if c := strm.InputConnected(); c != inConnected {
+			if inConnected = c; c {
+				publish(EventInputConnected)
+			} else {
+				publish(EventInputDisconnected)
+			}
+		}
+		if c := strm.OutputConnected(); c != outConnected {
+			if outConnected = c; c {
+				publish(EventOutputConnected)
+			} else {
+				publish(EventOutputDisconnected)
+			}
+		}
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type loggerReplacer interface {
+	WithLogger(l log.Modular) interop.Manager
+}
+
+// eventLogger wraps the logger of a stream and publishes an event for each
+// fatal, error and warning log, tagged with the fields of the logger such as
+// the component path.
+type eventLogger struct {
+	log.Modular
+
+	events *api.Events
+	stream string
+	fields map[string]string
+}
+
+// NewEventLogger wraps a logger so that fatal and error logs are published to
+// a broadcaster as error events, and warning logs as warning events, each
+// attributed to the stream id provided.
+func NewEventLogger(l log.Modular, events *api.Events, stream string) log.Modular {
+	return &eventLogger{
+		Modular: l,
+		events:  events,
+		stream:  stream,
+	}
+}
+
+func (l *eventLogger) withFields(fields map[string]string, child log.Modular) log.Modular {
+	newFields := make(map[string]string, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		newFields[k] = v
+	}
+	for k, v := range fields {
+		newFields[k] = v
+	}
+	// The stream is already identified by the event.
+	delete(newFields, "stream")
+	return &eventLogger{
+		Modular: child,
+		events:  l.events,
+		stream:  l.stream,
+		fields:  newFields,
+	}
+}
+
+func (l *eventLogger) WithFields(fields map[string]string) log.Modular {
+	return l.withFields(fields, l.Modular.WithFields(fields))
+}
+
+func (l *eventLogger) With(keyValues ...interface{}) log.Modular {
+	fields := map[string]string{}
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		fields[fmt.Sprint(keyValues[i])] = fmt.Sprint(keyValues[i+1])
+	}
+	return l.withFields(fields, l.Modular.With(keyValues...))
+}
+
+func (l *eventLogger) publish(eventType, message string) {
+	l.events.Publish(api.Event{
+		Type:    eventType,
+		Stream:  l.stream,
+		Message: strings.TrimSpace(message),
+		Fields:  l.fields,
+	})
+}
+
+func (l *eventLogger) Fatalf(format string, v ...interface{}) {
+	l.publish(EventError, fmt.Sprintf(format, v...))
+	l.Modular.Fatalf(format, v...)
+}
+
+func (l *eventLogger) Errorf(format string, v ...interface{}) {
+	l.Modular.Errorf(format, v...)
+	l.publish(EventError, fmt.Sprintf(format, v...))
+}
+
+func (l *eventLogger) Warnf(format string, v ...interface{}) {
+	l.Modular.Warnf(format, v...)
+	l.publish(EventWarning, fmt.Sprintf(format, v...))
+}
+
+func (l *eventLogger) Fatalln(message string) {
+	l.publish(EventError, message)
+	l.Modular.Fatalln(message)
+}
+
+func (l *eventLogger) Errorln(message string) {
+	l.Modular.Errorln(message)
+	l.publish(EventError, message)
+}
+
+func (l *eventLogger) Warnln(message string) {
+	l.Modular.Warnln(message)
+	l.publish(EventWarning, message)
+}
@@ -0,0 +1,93 @@
+package manager
+
+import (
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/stream"
+)
+
+// Quotas describes limits applied to an individual stream. A zero value for
+// any field means that it is unlimited.
+type Quotas struct {
+	// The maximum number of processing threads of the stream pipeline.
+	MaxThreads int `json:"max_threads" yaml:"max_threads"`
+
+	// The maximum number of messages read from the input of the stream that
+	// have not yet been acknowledged.
+	MaxInFlight int `json:"max_in_flight" yaml:"max_in_flight"`
+
+	// The maximum number of messages per second read from the input of the
+	// stream.
+	MaxRate float64 `json:"max_rate" yaml:"max_rate"`
+}
+
+// apply modifies a stream config so that it satisfies the quotas, returning the
+// modified config along with the input limits to apply to the stream.
+func (q Quotas) apply(conf stream.Config) (stream.Config, stream.InputLimits) {
+	if q.MaxThreads > 0 && (conf.Pipeline.Threads <= 0 || conf.Pipeline.Threads > q.MaxThreads) {
+		conf.Pipeline.Threads = q.MaxThreads
+	}
+	return conf, stream.InputLimits{
+		MaxInFlight: q.MaxInFlight,
+		MaxRate:     q.MaxRate,
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// OptSetDefaultQuotas sets the quotas applied to streams that have not had
+// quotas set explicitly.
+func OptSetDefaultQuotas(q Quotas) func(*Type) {
+	return func(t *Type) {
+		t.defaultQuotas = q
+	}
+}
+
+// Quotas returns the quotas applied to a stream. Returns an error if the stream
+// does not exist.
+func (m *Type) Quotas(id string) (Quotas, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, exists := m.streams[id]; !exists {
+		return Quotas{}, ErrStreamDoesNotExist
+	}
+	return m.quotasLocked(id), nil
+}
+
+func (m *Type) quotasLocked(id string) Quotas {
+	if q, exists := m.quotas[id]; exists {
+		return q
+	}
+	return m.defaultQuotas
+}
+
+// SetQuotas sets the quotas of a stream, replacing the defaults. The limits on
+// the input of the stream are applied to the running stream, and when the max
+// threads of the pipeline change the pipeline is reloaded in place. If the
+// pipeline fails to reload the stream is left unchanged. Returns an error if
+// the stream does not exist.
+func (m *Type) SetQuotas(id string, q Quotas, timeout time.Duration) error {
+	unlock := m.lockStream(id)
+	defer unlock()
+
+	wrapper, err := m.Read(id)
+	if err != nil {
+		return err
+	}
+
+	runConf, inputLimits := q.apply(wrapper.config)
+	if _, err := wrapper.strm.Reload(runConf, timeout); err != nil {
+		return err
+	}
+	wrapper.strm.SetInputLimits(inputLimits)
+
+	wrapper.setQuotas(q)
+
+	m.lock.Lock()
+	m.quotas[id] = q
+	m.lock.Unlock()
+
+	m.publish(EventStreamUpdated, id)
+	return nil
+}
@@ -0,0 +1,533 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/cache"
+	"github.com/benthosdev/benthos/v4/internal/config"
+	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/interop"
+	"github.com/benthosdev/benthos/v4/internal/stream"
+)
+
+// Store is a persistence backend for the definitions of streams and resources
+// that are modified via the streams mode HTTP API. Definitions are stored as
+// raw YAML documents identified by a key of the form `streams/<id>` or
+// `resources/<type>/<id>`.
+type Store interface {
+	// List returns all stored definitions mapped by their keys.
+	List(ctx context.Context) (map[string][]byte, error)
+
+	// Set writes a definition under a key, replacing any existing value.
+	Set(ctx context.Context, key string, value []byte) error
+
+	// Delete removes a definition by its key. Deleting a key that does not
+	// exist is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+//------------------------------------------------------------------------------
+
+const (
+	storeStreamsPrefix         = "streams/"
+	storeResourcesPrefix       = "resources/"
+	storeStreamResourcesPrefix = "stream_resources/"
+	storeQuotasPrefix          = "quotas/"
+)
+
+func streamStoreKey(id string) string {
+	return storeStreamsPrefix + id
+}
+
+func resourceStoreKey(typeStr, id string) string {
+	return storeResourcesPrefix + typeStr + "/" + id
+}
+
+func streamResourceStoreKey(streamID, typeStr, id string) string {
+	return storeStreamResourcesPrefix + streamID + "/" + typeStr + "/" + id
+}
+
+func quotasStoreKey(id string) string {
+	return storeQuotasPrefix + id
+}
+
+func (m *Type) persist(ctx context.Context, key string, value []byte) error {
+	if m.store == nil {
+		return nil
+	}
+	if err := m.store.Set(ctx, key, value); err != nil {
+		return fmt.Errorf("failed to persist %v: %w", key, err)
+	}
+	return nil
+}
+
+// persistStream writes the raw config of a stream, as it was provided to the
+// API and therefore prior to the replacement of environment variables, to the
+// store.
+func (m *Type) persistStream(ctx context.Context, id string, rawConf []byte) error {
+	m.setRawConfig(id, rawConf)
+	return m.persist(ctx, streamStoreKey(id), rawConf)
+}
+
+// persistCreated writes the raw config of a newly created stream to the store,
+// and if this fails the stream is deleted so that it does not run without a
+// persisted definition.
+func (m *Type) persistCreated(ctx context.Context, id string, rawConf []byte, timeout time.Duration) error {
+	err := m.persistStream(ctx, id, rawConf)
+	if err == nil {
+		return nil
+	}
+	if derr := m.Delete(id, timeout); derr != nil {
+		m.manager.Logger().Errorf("Failed to remove stream '%v' after it could not be persisted: %v\n", id, derr)
+	}
+	return err
+}
+
+// persistUpdated writes the raw config of an updated stream to the store. The
+// update itself has already succeeded, and therefore a failure is returned as a
+// warning rather than an error.
+func (m *Type) persistUpdated(ctx context.Context, id string, rawConf []byte) (warning string) {
+	if err := m.persistStream(ctx, id, rawConf); err != nil {
+		warning = fmt.Sprintf("stream '%v' was updated but not persisted: %v", id, err)
+		m.manager.Logger().Warnf("Stream %v\n", warning)
+	}
+	return
+}
+
+// patchRawConfig applies a patch to the raw config of a stream. Streams that
+// were not created via the API do not have a raw config, in which case the
+// patch is applied to the current config of the stream. Secrets are not
+// redacted from the current config as the result is persisted and must
+// therefore be runnable.
+func (m *Type) patchRawConfig(id string, patchBytes []byte) ([]byte, error) {
+	var base yaml.Node
+	if rawConf := m.rawConfig(id); rawConf != nil {
+		if err := yaml.Unmarshal(rawConf, &base); err != nil {
+			return nil, err
+		}
+	} else {
+		wrapper, err := m.Read(id)
+		if err != nil {
+			return nil, err
+		}
+		if err := base.Encode(wrapper.Config()); err != nil {
+			return nil, err
+		}
+		if err := stream.Spec().SanitiseYAML(&base, docs.SanitiseConfig{
+			RemoveTypeField: true,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	var patch yaml.Node
+	if err := yaml.Unmarshal(patchBytes, &patch); err != nil {
+		return nil, err
+	}
+	mergeYAMLNodes(documentContent(&base), documentContent(&patch))
+	return yaml.Marshal(&base)
+}
+
+func documentContent(n *yaml.Node) *yaml.Node {
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		return n.Content[0]
+	}
+	return n
+}
+
+// mergeYAMLNodes merges a patch into a node, where mappings are merged
+// recursively and any other value of the patch replaces that of the node.
+func mergeYAMLNodes(node, patch *yaml.Node) {
+	if node.Kind != yaml.MappingNode || patch.Kind != yaml.MappingNode {
+		*node = *patch
+		return
+	}
+	for i := 0; i+1 < len(patch.Content); i += 2 {
+		key, value := patch.Content[i], patch.Content[i+1]
+		found := false
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if node.Content[j].Value == key.Value {
+				mergeYAMLNodes(node.Content[j+1], value)
+				found = true
+				break
+			}
+		}
+		if !found {
+			node.Content = append(node.Content, key, value)
+		}
+	}
+}
+
+func (m *Type) unpersist(ctx context.Context, key string) error {
+	if m.store == nil {
+		return nil
+	}
+	if err := m.store.Delete(ctx, key); err != nil {
+		return fmt.Errorf("failed to remove persisted %v: %w", key, err)
+	}
+	return nil
+}
+
+// unpersistStream removes a stream definition from the store along with its
+// quotas and namespaced resources.
+func (m *Type) unpersistStream(ctx context.Context, id string) error {
+	if m.store == nil {
+		return nil
+	}
+	defs, err := m.store.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list persisted definitions: %w", err)
+	}
+	resPrefix := storeStreamResourcesPrefix + id + "/"
+	for k := range defs {
+		if k == streamStoreKey(id) || k == quotasStoreKey(id) || strings.HasPrefix(k, resPrefix) {
+			if err := m.unpersist(ctx, k); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// LoadFromStore reads all definitions from the configured store, adding the
+// resources to the manager and setting quotas, and then creating each stream.
+// Streams that already
+// exist, for example those loaded from config files, are replaced with the
+// stored definition. This is a no-op when no store has been configured.
+func (m *Type) LoadFromStore(ctx context.Context) error {
+	if m.store == nil {
+		return nil
+	}
+
+	defs, err := m.store.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list persisted definitions: %w", err)
+	}
+
+	var resourceKeys, streamResourceKeys, quotaKeys, streamKeys []string
+	for k := range defs {
+		switch {
+		case strings.HasPrefix(k, storeResourcesPrefix):
+			resourceKeys = append(resourceKeys, k)
+		case strings.HasPrefix(k, storeStreamResourcesPrefix):
+			streamResourceKeys = append(streamResourceKeys, k)
+		case strings.HasPrefix(k, storeQuotasPrefix):
+			quotaKeys = append(quotaKeys, k)
+		case strings.HasPrefix(k, storeStreamsPrefix):
+			streamKeys = append(streamKeys, k)
+		default:
+			m.manager.Logger().Warnf("Ignoring unrecognised persisted definition: %v\n", k)
+		}
+	}
+	sort.Strings(resourceKeys)
+	sort.Strings(streamResourceKeys)
+	sort.Strings(quotaKeys)
+	sort.Strings(streamKeys)
+
+	loadResource := func(k string, mgr bundle.NewManagement, typeStr, id string) error {
+		var node yaml.Node
+		if err := yaml.Unmarshal(config.ReplaceEnvVariables(defs[k]), &node); err != nil {
+			return fmt.Errorf("failed to parse persisted %v: %w", k, err)
+		}
+		requestErr, serverErr := storeResource(ctx, mgr, docs.Type(typeStr), id, &node)
+		if requestErr != nil {
+			return fmt.Errorf("failed to parse persisted %v: %w", k, requestErr)
+		}
+		if serverErr != nil {
+			return fmt.Errorf("failed to create persisted %v: %w", k, serverErr)
+		}
+		return nil
+	}
+
+	for _, k := range resourceKeys {
+		typeAndID := strings.SplitN(strings.TrimPrefix(k, storeResourcesPrefix), "/", 2)
+		if len(typeAndID) != 2 || !isResourceType(docs.Type(typeAndID[0])) {
+			m.manager.Logger().Warnf("Ignoring unrecognised persisted definition: %v\n", k)
+			continue
+		}
+		if err := loadResource(k, m.manager, typeAndID[0], typeAndID[1]); err != nil {
+			return err
+		}
+	}
+
+	for _, k := range streamResourceKeys {
+		parts := strings.SplitN(strings.TrimPrefix(k, storeStreamResourcesPrefix), "/", 3)
+		if len(parts) != 3 || !isResourceType(docs.Type(parts[1])) {
+			m.manager.Logger().Warnf("Ignoring unrecognised persisted definition: %v\n", k)
+			continue
+		}
+		mgr, err := m.streamResources(parts[0])
+		if err != nil {
+			return fmt.Errorf("failed to create persisted %v: %w", k, err)
+		}
+		if err := loadResource(k, mgr, parts[1], parts[2]); err != nil {
+			return err
+		}
+	}
+
+	for _, k := range quotaKeys {
+		var q Quotas
+		if err := json.Unmarshal(defs[k], &q); err != nil {
+			return fmt.Errorf("failed to parse persisted %v: %w", k, err)
+		}
+		m.lock.Lock()
+		m.quotas[strings.TrimPrefix(k, storeQuotasPrefix)] = q
+		m.lock.Unlock()
+	}
+
+	timeout := m.apiTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+
+	for _, k := range streamKeys {
+		id := strings.TrimPrefix(k, storeStreamsPrefix)
+
+		conf := stream.NewConfig()
+		if err := yaml.Unmarshal(config.ReplaceEnvVariables(defs[k]), &conf); err != nil {
+			return fmt.Errorf("failed to parse persisted %v: %w", k, err)
+		}
+
+		err := m.Create(id, conf)
+		if errors.Is(err, ErrStreamExists) {
+			err = m.Update(id, conf, timeout)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create persisted %v: %w", k, err)
+		}
+		m.setRawConfig(id, defs[k])
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+type dirStore struct {
+	dir string
+}
+
+// NewDirectoryStore returns a Store that writes each definition as a YAML file
+// within a directory, where the key of the definition is the relative path of
+// the file minus the `.yaml` extension.
+func NewDirectoryStore(dir string) Store {
+	return &dirStore{dir: filepath.Clean(dir)}
+}
+
+func (d *dirStore) keyPath(key string) (string, error) {
+	path := filepath.Join(d.dir, filepath.FromSlash(key)+".yaml")
+	if !strings.HasPrefix(path, d.dir+string(filepath.Separator)) {
+		return "", fmt.Errorf("key %v resolves to a path outside of the store directory", key)
+	}
+	return path, nil
+}
+
+func (d *dirStore) List(ctx context.Context) (map[string][]byte, error) {
+	defs := map[string][]byte{}
+	if _, err := os.Stat(d.dir); err != nil {
+		if os.IsNotExist(err) {
+			return defs, nil
+		}
+		return nil, err
+	}
+
+	err := filepath.Walk(d.dir, func(path string, info os.FileInfo, werr error) error {
+		if werr != nil {
+			return werr
+		}
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".yaml") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(d.dir, path)
+		if err != nil {
+			return err
+		}
+
+		var value []byte
+		if value, err = os.ReadFile(path); err != nil {
+			return err
+		}
+		defs[strings.TrimSuffix(filepath.ToSlash(rel), ".yaml")] = value
+		return nil
+	})
+	return defs, err
+}
+
+func (d *dirStore) Set(ctx context.Context, key string, value []byte) error {
+	path, err := d.keyPath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that a crash mid-write never leaves a
+	// truncated definition behind.
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), ".tmp_*")
+	if err != nil {
+		return err
+	}
+	if _, err = tmpFile.Write(value); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
+		return err
+	}
+	if err = tmpFile.Close(); err != nil {
+		os.Remove(tmpFile.Name())
+		return err
+	}
+	return os.Rename(tmpFile.Name(), path)
+}
+
+func (d *dirStore) Delete(ctx context.Context, key string) error {
+	path, err := d.keyPath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+const (
+	cacheStoreKeyPrefix = "benthos_streams/"
+	cacheStoreIndexKey  = "benthos_streams_index"
+)
+
+type cacheStore struct {
+	mgr   interop.Manager
+	cache string
+
+	// Caches do not support listing keys, and therefore we maintain an index
+	// of the keys within the cache itself.
+	indexMut sync.Mutex
+}
+
+// NewCacheStore returns a Store that writes definitions to a cache resource.
+// Since caches cannot list their keys an index of the stored keys is also
+// maintained within the cache.
+func NewCacheStore(mgr interop.Manager, cacheName string) Store {
+	return &cacheStore{mgr: mgr, cache: cacheName}
+}
+
+func (c *cacheStore) readIndex(ctx context.Context, cache cache.V1) ([]string, error) {
+	indexBytes, err := cache.Get(ctx, cacheStoreIndexKey)
+	if err != nil {
+		if errors.Is(err, component.ErrKeyNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var index []string
+	if err := json.Unmarshal(indexBytes, &index); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+func (c *cacheStore) writeIndex(ctx context.Context, cache cache.V1, index []string) error {
+	indexBytes, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	return cache.Set(ctx, cacheStoreIndexKey, indexBytes, nil)
+}
+
+func (c *cacheStore) access(ctx context.Context, fn func(cache.V1) error) (err error) {
+	if aerr := c.mgr.AccessCache(ctx, c.cache, func(cache cache.V1) {
+		err = fn(cache)
+	}); aerr != nil {
+		return aerr
+	}
+	return
+}
+
+func (c *cacheStore) List(ctx context.Context) (defs map[string][]byte, err error) {
+	c.indexMut.Lock()
+	defer c.indexMut.Unlock()
+
+	err = c.access(ctx, func(cache cache.V1) error {
+		index, err := c.readIndex(ctx, cache)
+		if err != nil {
+			return err
+		}
+		defs = make(map[string][]byte, len(index))
+		for _, key := range index {
+			value, err := cache.Get(ctx, cacheStoreKeyPrefix+key)
+			if err != nil {
+				if errors.Is(err, component.ErrKeyNotFound) {
+					continue
+				}
+				return err
+			}
+			defs[key] = value
+		}
+		return nil
+	})
+	return
+}
+
+func (c *cacheStore) Set(ctx context.Context, key string, value []byte) error {
+	c.indexMut.Lock()
+	defer c.indexMut.Unlock()
+
+	return c.access(ctx, func(cache cache.V1) error {
+		if err := cache.Set(ctx, cacheStoreKeyPrefix+key, value, nil); err != nil {
+			return err
+		}
+		index, err := c.readIndex(ctx, cache)
+		if err != nil {
+			return err
+		}
+		for _, k := range index {
+			if k == key {
+				return nil
+			}
+		}
+		return c.writeIndex(ctx, cache, append(index, key))
+	})
+}
+
+func (c *cacheStore) Delete(ctx context.Context, key string) error {
+	c.indexMut.Lock()
+	defer c.indexMut.Unlock()
+
+	return c.access(ctx, func(cache cache.V1) error {
+		index, err := c.readIndex(ctx, cache)
+		if err != nil {
+			return err
+		}
+		newIndex := make([]string, 0, len(index))
+		for _, k := range index {
+			if k != key {
+				newIndex = append(newIndex, k)
+			}
+		}
+		if len(newIndex) != len(index) {
+			if err := c.writeIndex(ctx, cache, newIndex); err != nil {
+				return err
+			}
+		}
+		if err := cache.Delete(ctx, cacheStoreKeyPrefix+key); err != nil && !errors.Is(err, component.ErrKeyNotFound) {
+			return err
+		}
+		return nil
+	})
+}
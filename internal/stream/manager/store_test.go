@@ -0,0 +1,72 @@
+package manager
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/manager/mock"
+)
+
+func testStore(t *testing.T, store Store) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	defs, err := store.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, defs)
+
+	require.NoError(t, store.Set(ctx, "streams/foo", []byte("foo v1")))
+	require.NoError(t, store.Set(ctx, "streams/bar", []byte("bar v1")))
+	require.NoError(t, store.Set(ctx, "resources/cache/baz", []byte("baz v1")))
+	require.NoError(t, store.Set(ctx, "streams/foo", []byte("foo v2")))
+
+	defs, err = store.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{
+		"streams/foo":         []byte("foo v2"),
+		"streams/bar":         []byte("bar v1"),
+		"resources/cache/baz": []byte("baz v1"),
+	}, defs)
+
+	require.NoError(t, store.Delete(ctx, "streams/bar"))
+	require.NoError(t, store.Delete(ctx, "streams/does_not_exist"))
+
+	defs, err = store.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{
+		"streams/foo":         []byte("foo v2"),
+		"resources/cache/baz": []byte("baz v1"),
+	}, defs)
+}
+
+func TestDirectoryStore(t *testing.T) {
+	testStore(t, NewDirectoryStore(t.TempDir()))
+}
+
+func TestDirectoryStoreKeyEscape(t *testing.T) {
+	dir := t.TempDir()
+	store := NewDirectoryStore(filepath.Join(dir, "store"))
+
+	ctx := context.Background()
+	require.Error(t, store.Set(ctx, "streams/../../escaped", []byte("nope")))
+	require.Error(t, store.Delete(ctx, "../escaped"))
+
+	_, err := os.Stat(filepath.Join(dir, "escaped.yaml"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCacheStore(t *testing.T) {
+	mgr := mock.NewManager()
+	mgr.Caches["foocache"] = map[string]mock.CacheItem{}
+
+	testStore(t, NewCacheStore(mgr, "foocache"))
+
+	_, err := NewCacheStore(mgr, "nope").List(context.Background())
+	require.Error(t, err)
+}
@@ -8,10 +8,12 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/benthosdev/benthos/v4/internal/api"
 	"github.com/benthosdev/benthos/v4/internal/bundle"
 	"github.com/benthosdev/benthos/v4/internal/component"
 	"github.com/benthosdev/benthos/v4/internal/component/metrics"
 	"github.com/benthosdev/benthos/v4/internal/component/processor"
+	"github.com/benthosdev/benthos/v4/internal/interop"
 	"github.com/benthosdev/benthos/v4/internal/log"
 	"github.com/benthosdev/benthos/v4/internal/stream"
 )
@@ -19,11 +21,18 @@ import (
 // StreamStatus tracks a stream along with information regarding its internals.
 type StreamStatus struct {
 	stoppedAfter int64
+	drained      int32
 	config       stream.Config
+	rawConfig    []byte
+	quotasMut    sync.Mutex
+	quotas       Quotas
 	strm         *stream.Type
 	logger       log.Modular
 	metrics      *metrics.Local
 	createdAt    time.Time
+
+	stopWatch     chan struct{}
+	stopWatchOnce sync.Once
 }
 
 // NewStreamStatus creates a new StreamStatus.
@@ -39,6 +48,7 @@ func NewStreamStatus(
 		logger:    logger,
 		metrics:   stats,
 		createdAt: time.Now(),
+		stopWatch: make(chan struct{}),
 	}
 }
 
@@ -54,6 +64,32 @@ func (s *StreamStatus) IsReady() bool {
 	return s.strm.IsReady()
 }
 
+// IsPaused returns a boolean indicating whether the stream is paused.
+func (s *StreamStatus) IsPaused() bool {
+	return s.strm.IsPaused()
+}
+
+// IsDrained returns a boolean indicating whether the stream has been drained,
+// in which case its input has been closed and all in-flight messages have been
+// resolved.
+func (s *StreamStatus) IsDrained() bool {
+	return atomic.LoadInt32(&s.drained) == 1
+}
+
+// State returns a string describing the current state of the stream, which is
+// one of `running`, `paused`, `drained` or `stopped`.
+func (s *StreamStatus) State() string {
+	switch {
+	case s.IsDrained():
+		return "drained"
+	case !s.IsRunning():
+		return "stopped"
+	case s.IsPaused():
+		return "paused"
+	}
+	return "running"
+}
+
 // Uptime returns a time.Duration indicating the current uptime of the stream.
 func (s *StreamStatus) Uptime() time.Duration {
 	if stoppedAfter := atomic.LoadInt64(&s.stoppedAfter); stoppedAfter > 0 {
@@ -67,6 +103,26 @@ func (s *StreamStatus) Config() stream.Config {
 	return s.config
 }
 
+// Quotas returns the quotas currently applied to the stream.
+func (s *StreamStatus) Quotas() Quotas {
+	s.quotasMut.Lock()
+	defer s.quotasMut.Unlock()
+	return s.quotas
+}
+
+func (s *StreamStatus) setQuotas(q Quotas) {
+	s.quotasMut.Lock()
+	s.quotas = q
+	s.quotasMut.Unlock()
+}
+
+// InFlight returns the number of messages read from the input of the stream
+// that have not yet been acknowledged. This is only tracked when the stream
+// has a max in flight quota, otherwise zero is returned.
+func (s *StreamStatus) InFlight() int {
+	return s.strm.InFlight()
+}
+
 // Metrics returns a metrics aggregator of the stream.
 func (s *StreamStatus) Metrics() *metrics.Local {
 	return s.metrics
@@ -75,6 +131,16 @@ func (s *StreamStatus) Metrics() *metrics.Local {
 // setClosed sets the flag indicating that the stream is closed.
 func (s *StreamStatus) setClosed() {
 	atomic.SwapInt64(&s.stoppedAfter, int64(time.Since(s.createdAt)))
+	s.stopWatching()
+}
+
+// stopWatching ends the monitoring of connection state changes of the stream,
+// which is done before stopping it in order to avoid publishing events for
+// disconnects that were requested.
+func (s *StreamStatus) stopWatching() {
+	s.stopWatchOnce.Do(func() {
+		close(s.stopWatch)
+	})
 }
 
 //------------------------------------------------------------------------------
@@ -91,20 +157,42 @@ type Type struct {
 	closed  bool
 	streams map[string]*StreamStatus
 
+	// Resources and quotas are scoped to a stream id rather than an individual
+	// stream, and therefore persist across updates of the stream.
+	namespaces    map[string]bundle.NewManagement
+	quotas        map[string]Quotas
+	defaultQuotas Quotas
+
 	manager    bundle.NewManagement
 	apiTimeout time.Duration
 	apiEnabled bool
+	store      Store
+	events     *api.Events
+
+	// Operations that modify an individual stream are serialised by a lock
+	// scoped to the stream id.
+	streamLocks map[string]*streamLock
 
 	lock sync.Mutex
 }
 
+type streamLock struct {
+	mut  sync.Mutex
+	refs int
+}
+
 // New creates a new stream manager.Type.
 func New(mgr bundle.NewManagement, opts ...func(*Type)) *Type {
 	t := &Type{
 		streams:    map[string]*StreamStatus{},
+		namespaces: map[string]bundle.NewManagement{},
+		quotas:     map[string]Quotas{},
 		apiTimeout: time.Second * 5,
 		apiEnabled: true,
 		manager:    mgr,
+		events:     api.NewEvents(100),
+
+		streamLocks: map[string]*streamLock{},
 	}
 	for _, opt := range opts {
 		opt(t)
@@ -130,19 +218,65 @@ func OptSetAPITimeout(tout time.Duration) func(*Type) {
 	}
 }
 
+// OptSetStore sets a persistence backend for the definitions of streams and
+// resources that are modified via the HTTP API. Changes are written through to
+// the store and can be reloaded with LoadFromStore.
+func OptSetStore(store Store) func(*Type) {
+	return func(t *Type) {
+		t.store = store
+	}
+}
+
 //------------------------------------------------------------------------------
 
 // Errors specifically returned by a stream manager.
 var (
 	ErrStreamExists       = errors.New("stream already exists")
 	ErrStreamDoesNotExist = errors.New("stream does not exist")
+	ErrStreamDrained      = errors.New("stream has been drained")
+	ErrNoNamespaces       = errors.New("resource namespaces are not supported by this manager")
 )
 
 //------------------------------------------------------------------------------
 
+// lockStream blocks until no other operation is modifying the stream of an id,
+// and returns a func that releases the lock.
+func (m *Type) lockStream(id string) func() {
+	m.lock.Lock()
+	l, exists := m.streamLocks[id]
+	if !exists {
+		l = &streamLock{}
+		m.streamLocks[id] = l
+	}
+	l.refs++
+	m.lock.Unlock()
+
+	l.mut.Lock()
+	return func() {
+		l.mut.Unlock()
+
+		m.lock.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(m.streamLocks, id)
+		}
+		m.lock.Unlock()
+	}
+}
+
 // Create attempts to construct and run a new stream under a unique ID. If the
 // ID already exists an error is returned.
 func (m *Type) Create(id string, conf stream.Config) error {
+	unlock := m.lockStream(id)
+	defer unlock()
+
+	if err := m.create(id, conf); err != nil {
+		return err
+	}
+	m.publish(EventStreamCreated, id)
+	return nil
+}
+
+func (m *Type) create(id string, conf stream.Config) error {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
@@ -155,18 +289,105 @@ func (m *Type) Create(id string, conf stream.Config) error {
 	}
 
 	strmFlatMetrics := metrics.NewLocal()
-	sMgr := m.manager.ForStream(id).WithAddedMetrics(strmFlatMetrics).(bundle.NewManagement)
+	sMgr := m.streamManagerLocked(id).WithAddedMetrics(strmFlatMetrics).(bundle.NewManagement)
+	if lr, ok := sMgr.(loggerReplacer); ok {
+		sMgr = lr.WithLogger(NewEventLogger(sMgr.Logger(), m.events, id)).(bundle.NewManagement)
+	}
+
+	quotas := m.quotasLocked(id)
+	runConf, inputLimits := quotas.apply(conf)
 
 	var wrapper *StreamStatus
-	strm, err := stream.New(conf, sMgr, stream.OptOnClose(func() {
+	strm, err := stream.New(runConf, sMgr, stream.OptOnClose(func() {
 		wrapper.setClosed()
-	}))
+	}), stream.OptSetInputLimits(inputLimits))
 	if err != nil {
 		return err
 	}
 
 	wrapper = NewStreamStatus(conf, strm, sMgr.Logger(), strmFlatMetrics)
+	wrapper.setQuotas(quotas)
 	m.streams[id] = wrapper
+	go WatchConnections(m.events, id, wrapper.strm, wrapper.stopWatch)
+	return nil
+}
+
+type resourceIsolator interface {
+	WithIsolatedResources() interop.Manager
+}
+
+type resourceCloser interface {
+	CloseAsync()
+	WaitForClose(timeout time.Duration) error
+}
+
+// streamManagerLocked returns the manager to be used by a stream, which has its
+// own namespace of resources when supported by the underlying manager.
+func (m *Type) streamManagerLocked(id string) bundle.NewManagement {
+	if ns, exists := m.namespaces[id]; exists {
+		return ns
+	}
+	sMgr := m.manager.ForStream(id)
+	iso, ok := sMgr.(resourceIsolator)
+	if !ok {
+		return sMgr.(bundle.NewManagement)
+	}
+	ns := iso.WithIsolatedResources().(bundle.NewManagement)
+	m.namespaces[id] = ns
+	return ns
+}
+
+// streamResources returns the manager holding the resource namespace of a
+// stream id, which may be used before the stream itself is created.
+func (m *Type) streamResources(id string) (bundle.NewManagement, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.closed {
+		return nil, component.ErrTypeClosed
+	}
+	ns := m.streamManagerLocked(id)
+	if _, exists := m.namespaces[id]; !exists {
+		return nil, ErrNoNamespaces
+	}
+	return ns, nil
+}
+
+// removeScope closes the resource namespace of a stream id and removes any
+// quotas set for it.
+func (m *Type) removeScope(id string, timeout time.Duration) error {
+	m.lock.Lock()
+	ns, exists := m.namespaces[id]
+	delete(m.namespaces, id)
+	delete(m.quotas, id)
+	m.lock.Unlock()
+
+	if !exists {
+		return nil
+	}
+	if c, ok := ns.(resourceCloser); ok {
+		c.CloseAsync()
+		return c.WaitForClose(timeout)
+	}
+	return nil
+}
+
+// setRawConfig records the raw config that a stream was created from, which is
+// used when patching the persisted definition of the stream.
+func (m *Type) setRawConfig(id string, rawConf []byte) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if wrapper, exists := m.streams[id]; exists {
+		wrapper.rawConfig = rawConf
+	}
+}
+
+func (m *Type) rawConfig(id string) []byte {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if wrapper, exists := m.streams[id]; exists {
+		return wrapper.rawConfig
+	}
 	return nil
 }
 
@@ -191,6 +412,9 @@ func (m *Type) Read(id string) (*StreamStatus, error) {
 // Update attempts to stop an existing stream and replace it with a new version
 // of the same stream.
 func (m *Type) Update(id string, conf stream.Config, timeout time.Duration) error {
+	unlock := m.lockStream(id)
+	defer unlock()
+
 	m.lock.Lock()
 	wrapper, exists := m.streams[id]
 	closed := m.closed
@@ -207,16 +431,34 @@ func (m *Type) Update(id string, conf stream.Config, timeout time.Duration) erro
 		return nil
 	}
 
-	if err := m.Delete(id, timeout); err != nil {
+	if err := m.stop(id, timeout); err != nil {
+		return err
+	}
+	if err := m.create(id, conf); err != nil {
 		return err
 	}
-	return m.Create(id, conf)
+	m.publish(EventStreamUpdated, id)
+	return nil
 }
 
-// Delete attempts to stop and remove a stream by its ID. Returns an error if
-// the stream was not found, or if clean shutdown fails in the specified period
-// of time.
+// Delete attempts to stop and remove a stream by its ID, along with any
+// resources and quotas scoped to it. Returns an error if the stream was not
+// found, or if clean shutdown fails in the specified period of time.
 func (m *Type) Delete(id string, timeout time.Duration) error {
+	unlock := m.lockStream(id)
+	defer unlock()
+
+	started := time.Now()
+	if err := m.stop(id, timeout); err != nil {
+		return err
+	}
+	m.publish(EventStreamDeleted, id)
+	return m.removeScope(id, timeout-time.Since(started))
+}
+
+// stop attempts to stop and remove a stream by its ID whilst retaining the
+// resources and quotas scoped to it.
+func (m *Type) stop(id string, timeout time.Duration) error {
 	m.lock.Lock()
 	if m.closed {
 		m.lock.Unlock()
@@ -229,6 +471,7 @@ func (m *Type) Delete(id string, timeout time.Duration) error {
 		return ErrStreamDoesNotExist
 	}
 
+	wrapper.stopWatching()
 	if err := wrapper.strm.Stop(timeout); err != nil {
 		return err
 	}
@@ -240,6 +483,74 @@ func (m *Type) Delete(id string, timeout time.Duration) error {
 	return nil
 }
 
+// Pause stops a stream from consuming messages from its input without closing
+// the input or its connections. Messages already in flight continue through the
+// stream. Returns an error if the stream was not found or has been drained.
+func (m *Type) Pause(id string) error {
+	unlock := m.lockStream(id)
+	defer unlock()
+
+	wrapper, err := m.Read(id)
+	if err != nil {
+		return err
+	}
+	if wrapper.IsDrained() {
+		return ErrStreamDrained
+	}
+	if wrapper.strm.Pause() {
+		m.publish(EventStreamPaused, id)
+	}
+	return nil
+}
+
+// Resume continues consuming messages for a stream that was either paused or
+// drained. Since a drained stream has closed its input a new input is created
+// from its existing config, and if this fails the stream remains drained.
+func (m *Type) Resume(id string) error {
+	unlock := m.lockStream(id)
+	defer unlock()
+
+	wrapper, err := m.Read(id)
+	if err != nil {
+		return err
+	}
+	if !wrapper.IsDrained() {
+		if wrapper.strm.Resume() {
+			m.publish(EventStreamResumed, id)
+		}
+		return nil
+	}
+
+	if err := wrapper.strm.RestartInput(); err != nil {
+		return err
+	}
+	atomic.StoreInt32(&wrapper.drained, 0)
+	m.publish(EventStreamResumed, id)
+	return nil
+}
+
+// Drain closes the input of a stream and blocks until all in-flight messages
+// have been resolved, or the timeout is reached. The rest of the stream along
+// with its config are retained and consumption can be restarted with Resume.
+func (m *Type) Drain(id string, timeout time.Duration) error {
+	unlock := m.lockStream(id)
+	defer unlock()
+
+	wrapper, err := m.Read(id)
+	if err != nil {
+		return err
+	}
+	if wrapper.IsDrained() {
+		return nil
+	}
+	if err := wrapper.strm.DrainInput(timeout); err != nil {
+		return err
+	}
+	atomic.StoreInt32(&wrapper.drained, 1)
+	m.publish(EventStreamDrained, id)
+	return nil
+}
+
 //------------------------------------------------------------------------------
 
 // Stop attempts to gracefully shut down all active streams and close the
@@ -252,6 +563,7 @@ func (m *Type) Stop(timeout time.Duration) error {
 
 	for k, v := range m.streams {
 		go func(id string, strm *StreamStatus) {
+			strm.stopWatching()
 			if err := strm.strm.Stop(timeout); err != nil {
 				resultChan <- id
 			} else {
@@ -267,8 +579,19 @@ func (m *Type) Stop(timeout time.Duration) error {
 		}
 	}
 
+	for id, ns := range m.namespaces {
+		if c, ok := ns.(resourceCloser); ok {
+			c.CloseAsync()
+			if err := c.WaitForClose(timeout); err != nil {
+				failedStreams = append(failedStreams, id)
+			}
+		}
+	}
+
 	m.streams = map[string]*StreamStatus{}
+	m.namespaces = map[string]bundle.NewManagement{}
 	m.closed = true
+	m.events.Close()
 
 	if len(failedStreams) > 0 {
 		return fmt.Errorf("failed to gracefully stop the following streams: %v", failedStreams)
@@ -0,0 +1,152 @@
+package manager
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/config"
+	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/interop"
+	"github.com/benthosdev/benthos/v4/internal/stream"
+)
+
+// The kinds of problem that can be found when validating a stream config.
+const (
+	ValidationKindParse        = "parse"
+	ValidationKindLint         = "lint"
+	ValidationKindLintWarning  = "lint_warning"
+	ValidationKindConstruction = "construction"
+)
+
+// ValidationError describes a problem found when validating a stream config.
+type ValidationError struct {
+	Kind    string `json:"kind"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+// validationUnconstructed lists the components of a stream config that are
+// linted but never constructed during validation, as many of them begin
+// consuming or bind addresses as soon as they exist. These are reported with
+// each validation result so that a valid result is not mistaken for a guarantee
+// that these components can be created.
+var validationUnconstructed = []string{"input", "buffer", "output"}
+
+var yamlErrLineRegexp = regexp.MustCompile(`line ([0-9]+):`)
+
+func parseValidationError(err error) ValidationError {
+	vErr := ValidationError{
+		Kind:    ValidationKindParse,
+		Message: err.Error(),
+	}
+	if matches := yamlErrLineRegexp.FindStringSubmatch(vErr.Message); len(matches) > 1 {
+		vErr.Line, _ = strconv.Atoi(matches[1])
+	}
+	return vErr
+}
+
+type validationManager interface {
+	ForValidation() interop.Manager
+}
+
+type closableComponent interface {
+	CloseAsync()
+	WaitForClose(timeout time.Duration) error
+}
+
+// Validate parses and lints a stream config, using the same rules as the
+// `benthos lint` subcommand, and then constructs the processors of the stream
+// without running them, returning all problems found. Lint warnings are
+// reported with the kind ValidationKindLintWarning and do not make a config
+// invalid. Processors are constructed with access to the resources that would
+// be available to the stream id.
+//
+// Inputs, buffers and outputs are never constructed, see
+// validationUnconstructed, instead they are only linted and references to
+// input and output resources are checked.
+func (m *Type) Validate(id string, confBytes []byte, rejectDeprecated bool) (vErrs []ValidationError) {
+	confBytes = config.ReplaceEnvVariables(confBytes)
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(confBytes, &node); err != nil {
+		return []ValidationError{parseValidationError(err)}
+	}
+
+	lintCtx := docs.NewLintContext()
+	lintCtx.RejectDeprecated = rejectDeprecated
+	for _, l := range stream.Spec().LintYAML(lintCtx, &node) {
+		kind := ValidationKindLint
+		if l.Level != docs.LintError {
+			kind = ValidationKindLintWarning
+		}
+		vErrs = append(vErrs, ValidationError{
+			Kind:    kind,
+			Line:    l.Line,
+			Column:  l.Column,
+			Message: l.What,
+		})
+	}
+
+	conf := stream.NewConfig()
+	if err := node.Decode(&conf); err != nil {
+		return append(vErrs, parseValidationError(err))
+	}
+
+	m.lock.Lock()
+	sMgr, exists := m.namespaces[id]
+	m.lock.Unlock()
+	if !exists {
+		sMgr = m.manager.ForStream(id).(bundle.NewManagement)
+	}
+	if vMgr, ok := sMgr.(validationManager); ok {
+		sMgr = vMgr.ForValidation().(bundle.NewManagement)
+	}
+
+	var toWait []closableComponent
+	defer func() {
+		for _, c := range toWait {
+			c.CloseAsync()
+		}
+		for _, c := range toWait {
+			_ = c.WaitForClose(time.Second)
+		}
+	}()
+
+	constructErr := func(err error, path ...string) {
+		vErr := ValidationError{
+			Kind:    ValidationKindConstruction,
+			Path:    query.SliceToDotPath(path...),
+			Message: err.Error(),
+		}
+		if n, _ := docs.GetYAMLPath(&node, path...); n != nil {
+			vErr.Line = n.Line
+		}
+		vErrs = append(vErrs, vErr)
+	}
+
+	if conf.Input.Type == "resource" && !sMgr.ProbeInput(conf.Input.Resource) {
+		constructErr(fmt.Errorf("input resource '%v' was not found", conf.Input.Resource), "input")
+	}
+
+	for i, pConf := range conf.Pipeline.Processors {
+		path := []string{"pipeline", "processors", strconv.Itoa(i)}
+		if proc, err := sMgr.IntoPath(path...).(bundle.NewManagement).NewProcessor(pConf); err != nil {
+			constructErr(err, path...)
+		} else {
+			toWait = append(toWait, proc)
+		}
+	}
+
+	if conf.Output.Type == "resource" && !sMgr.ProbeOutput(conf.Output.Resource) {
+		constructErr(fmt.Errorf("output resource '%v' was not found", conf.Output.Resource), "output")
+	}
+	return
+}
@@ -0,0 +1,381 @@
+package stream
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/component"
+	ioutput "github.com/benthosdev/benthos/v4/internal/component/output"
+	iprocessor "github.com/benthosdev/benthos/v4/internal/component/processor"
+	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/old/processor"
+	"github.com/benthosdev/benthos/v4/internal/pipeline"
+	"github.com/benthosdev/benthos/v4/internal/shutdown"
+)
+
+// ErrRestartRequired is returned when a stream config cannot be reloaded in
+// place as the input or buffer of the stream has changed, and therefore the
+// stream must be restarted in order to apply it.
+var ErrRestartRequired = errors.New("stream must be restarted in order to apply the config")
+
+// Reload attempts to apply a new config to the stream without restarting it.
+// The pipeline and output are only replaced when their configs have changed,
+// and processors of the pipeline that are unchanged are carried over to the new
+// pipeline. Transactions that are in flight when a layer is replaced are
+// finished by the previous layer before it closes.
+//
+// The paths of the components that were replaced are returned. If the input or
+// buffer of the stream have changed then ErrRestartRequired is returned along
+// with their paths, and the stream is left unchanged.
+func (t *Type) Reload(conf Config, timeout time.Duration) (replaced []string, err error) {
+	t.reloadMut.Lock()
+	defer t.reloadMut.Unlock()
+
+	if t.stopping {
+		return nil, component.ErrTypeClosed
+	}
+
+	if !docs.ConfigsEqual(t.conf.Input, conf.Input) {
+		replaced = append(replaced, "input")
+	}
+	if !docs.ConfigsEqual(t.conf.Buffer, conf.Buffer) {
+		replaced = append(replaced, "buffer")
+	}
+	if len(replaced) > 0 {
+		return replaced, ErrRestartRequired
+	}
+
+	pipelineChanged := !docs.ConfigsEqual(t.conf.Pipeline, conf.Pipeline)
+	outputChanged := !docs.ConfigsEqual(t.conf.Output, conf.Output)
+	if outputChanged {
+		t.trackFailSources(conf)
+	}
+
+	// Construct all new components before modifying the stream so that a
+	// failure leaves the stream untouched.
+	var newProcs, createdProcs []*sharedProcessor
+	closeCreated := func() {
+		for _, p := range createdProcs {
+			p.CloseAsync()
+		}
+	}
+	if pipelineChanged {
+		newProcs = make([]*sharedProcessor, len(conf.Pipeline.Processors))
+		for i, pConf := range conf.Pipeline.Processors {
+			if i < len(t.processors) && docs.ConfigsEqual(t.conf.Pipeline.Processors[i], pConf) {
+				newProcs[i] = t.processors[i]
+				continue
+			}
+			if newProcs[i], err = t.newProcessor(i, pConf); err != nil {
+				closeCreated()
+				return nil, err
+			}
+			createdProcs = append(createdProcs, newProcs[i])
+			replaced = append(replaced, "pipeline.processors."+strconv.Itoa(i))
+		}
+		for i := len(conf.Pipeline.Processors); i < len(t.processors); i++ {
+			replaced = append(replaced, "pipeline.processors."+strconv.Itoa(i))
+		}
+		if len(replaced) == 0 {
+			replaced = append(replaced, "pipeline")
+		}
+	}
+
+	newOutput := t.output()
+	if outputChanged {
+		if newOutput, err = t.newOutput(conf.Output); err != nil {
+			closeCreated()
+			return nil, err
+		}
+		replaced = append(replaced, "output")
+	}
+
+	if pipelineChanged {
+		var newPipe pipeline.Type
+		if newPipe, err = t.newPipeline(conf.Pipeline.Threads, newProcs); err != nil {
+			closeCreated()
+			if outputChanged {
+				newOutput.CloseAsync()
+			}
+			return nil, err
+		}
+		if err = t.swapPipeline(newPipe, newProcs, timeout); err != nil {
+			if outputChanged {
+				newOutput.CloseAsync()
+			}
+			return nil, err
+		}
+	}
+
+	if outputChanged {
+		if err = t.swapOutput(newOutput, timeout); err != nil {
+			return nil, err
+		}
+	}
+
+	t.conf = conf
+	return replaced, nil
+}
+
+func (t *Type) swapPipeline(newPipe pipeline.Type, newProcs []*sharedProcessor, timeout time.Duration) error {
+	t.pipelineMerger.reserve()
+	feed, ok := t.pipelineSwitch.swap()
+	if !ok {
+		t.pipelineMerger.release()
+		if newPipe != nil {
+			newPipe.CloseAsync()
+		}
+		return component.ErrTypeClosed
+	}
+
+	pipeOut := feed
+	if newPipe != nil {
+		if err := newPipe.Consume(feed); err != nil {
+			t.pipelineMerger.release()
+			return err
+		}
+		pipeOut = newPipe.TransactionChan()
+	}
+	t.pipelineMerger.add(pipeOut)
+
+	t.layersMut.Lock()
+	oldPipe := t.pipelineLayer
+	t.pipelineLayer = newPipe
+	t.processors = newProcs
+	t.layersMut.Unlock()
+
+	// The previous pipeline closes once it has finished processing the
+	// transactions remaining in its feed.
+	if oldPipe != nil {
+		if err := oldPipe.WaitForClose(timeout); err != nil {
+			oldPipe.CloseAsync()
+			return fmt.Errorf("failed to close previous pipeline: %w", err)
+		}
+	}
+	return nil
+}
+
+func (t *Type) swapOutput(newOutput ioutput.Streamed, timeout time.Duration) error {
+	// The new output is set before the feed of the previous one is closed in
+	// order to prevent its closure from being mistaken for the stream closing.
+	t.layersMut.Lock()
+	oldOutput := t.outputLayer
+	t.outputLayer = newOutput
+	t.layersMut.Unlock()
+
+	feed, ok := t.outputSwitch.swap()
+	if !ok {
+		t.layersMut.Lock()
+		t.outputLayer = oldOutput
+		t.layersMut.Unlock()
+		newOutput.CloseAsync()
+		return component.ErrTypeClosed
+	}
+	if err := newOutput.Consume(feed); err != nil {
+		return err
+	}
+
+	// The previous output closes once it has finished writing the transactions
+	// remaining in its feed.
+	if err := oldOutput.WaitForClose(timeout); err != nil {
+		oldOutput.CloseAsync()
+		return fmt.Errorf("failed to close previous output: %w", err)
+	}
+	return nil
+}
+
+func (t *Type) newProcessor(index int, conf processor.Config) (*sharedProcessor, error) {
+	pMgr := t.manager.IntoPath("pipeline", "processors", strconv.Itoa(index))
+	proc, err := processor.New(conf, pMgr, pMgr.Logger(), pMgr.Metrics())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create processor '%v': %v", conf.Type, err)
+	}
+	return &sharedProcessor{V1: proc}, nil
+}
+
+// newPipeline creates a pipeline from processors, which returns nil when there
+// are no processors.
+func (t *Type) newPipeline(threads int, procs []*sharedProcessor) (pipeline.Type, error) {
+	if len(procs) == 0 {
+		return nil, nil
+	}
+	refs := make([]iprocessor.V1, len(procs))
+	for i, p := range procs {
+		refs[i] = p.ref()
+	}
+	pipe, err := pipeline.NewFromProcessors(threads, t.manager.IntoPath("pipeline").Logger(), refs...)
+	if err != nil {
+		for _, r := range refs {
+			r.CloseAsync()
+		}
+		return nil, err
+	}
+	return pipe, nil
+}
+
+//------------------------------------------------------------------------------
+
+// sharedProcessor is a processor that can be used by the pipelines either side
+// of a reload, and is only closed once every pipeline referencing it has
+// closed its reference.
+type sharedProcessor struct {
+	iprocessor.V1
+	refs int32
+}
+
+func (s *sharedProcessor) ref() *processorRef {
+	atomic.AddInt32(&s.refs, 1)
+	return &processorRef{sharedProcessor: s}
+}
+
+type processorRef struct {
+	*sharedProcessor
+	closed int32
+}
+
+func (r *processorRef) CloseAsync() {
+	if atomic.CompareAndSwapInt32(&r.closed, 0, 1) && atomic.AddInt32(&r.refs, -1) == 0 {
+		r.V1.CloseAsync()
+	}
+}
+
+func (r *processorRef) WaitForClose(timeout time.Duration) error {
+	if atomic.LoadInt32(&r.refs) > 0 {
+		return nil
+	}
+	return r.V1.WaitForClose(timeout)
+}
+
+//------------------------------------------------------------------------------
+
+// feedSwitch forwards transactions from an upstream channel to a feed channel
+// consumed by a layer of the stream, where the feed can be swapped for a new
+// one without closing the upstream. Swapping closes the previous feed, allowing
+// the layer consuming it to finish its in-flight transactions and close.
+type feedSwitch struct {
+	in      <-chan message.Transaction
+	swaps   chan chan message.Transaction
+	done    chan struct{}
+	shutSig *shutdown.Signaller
+}
+
+func newFeedSwitch(in <-chan message.Transaction, shutSig *shutdown.Signaller) (*feedSwitch, <-chan message.Transaction) {
+	f := &feedSwitch{
+		in:      in,
+		swaps:   make(chan chan message.Transaction),
+		done:    make(chan struct{}),
+		shutSig: shutSig,
+	}
+	feed := make(chan message.Transaction)
+	go f.loop(feed)
+	return f, feed
+}
+
+func (f *feedSwitch) loop(feed chan message.Transaction) {
+	defer func() {
+		close(feed)
+		close(f.done)
+	}()
+
+	for {
+		var tran message.Transaction
+		select {
+		case t, open := <-f.in:
+			if !open {
+				return
+			}
+			tran = t
+		case newFeed := <-f.swaps:
+			close(feed)
+			feed = newFeed
+			continue
+		case <-f.shutSig.CloseNowChan():
+			return
+		}
+
+		for sent := false; !sent; {
+			select {
+			case feed <- tran:
+				sent = true
+			case newFeed := <-f.swaps:
+				close(feed)
+				feed = newFeed
+			case <-f.shutSig.CloseNowChan():
+				return
+			}
+		}
+	}
+}
+
+// swap closes the current feed and returns a new one, or returns false if the
+// upstream has closed.
+func (f *feedSwitch) swap() (<-chan message.Transaction, bool) {
+	newFeed := make(chan message.Transaction)
+	select {
+	case f.swaps <- newFeed:
+		return newFeed, true
+	case <-f.done:
+		return nil, false
+	}
+}
+
+// feedMerger forwards transactions from the output channels of each pipeline
+// fed by a feedSwitch into a single channel, which is closed once the switch
+// has closed and all pipelines have closed their output channels.
+type feedMerger struct {
+	out     chan message.Transaction
+	wg      sync.WaitGroup
+	shutSig *shutdown.Signaller
+}
+
+func newFeedMerger(sw *feedSwitch, initial <-chan message.Transaction, shutSig *shutdown.Signaller) *feedMerger {
+	m := &feedMerger{
+		out:     make(chan message.Transaction),
+		shutSig: shutSig,
+	}
+	m.reserve()
+	m.add(initial)
+	go func() {
+		<-sw.done
+		m.wg.Wait()
+		close(m.out)
+	}()
+	return m
+}
+
+// reserve must be called before swapping the feed of the switch, and followed
+// by either add or release, which prevents the merger from closing before the
+// new pipeline is added.
+func (m *feedMerger) reserve() {
+	m.wg.Add(1)
+}
+
+func (m *feedMerger) release() {
+	m.wg.Done()
+}
+
+func (m *feedMerger) add(c <-chan message.Transaction) {
+	go func() {
+		defer m.wg.Done()
+		for {
+			select {
+			case tran, open := <-c:
+				if !open {
+					return
+				}
+				select {
+				case m.out <- tran:
+				case <-m.shutSig.CloseNowChan():
+					return
+				}
+			case <-m.shutSig.CloseNowChan():
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,131 @@
+package stream_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/manager"
+	"github.com/benthosdev/benthos/v4/internal/manager/mock"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/old/input"
+	"github.com/benthosdev/benthos/v4/internal/old/output"
+	"github.com/benthosdev/benthos/v4/internal/old/processor"
+	"github.com/benthosdev/benthos/v4/internal/stream"
+)
+
+func TestTypeReload(t *testing.T) {
+	newMgr, err := manager.NewV2(manager.NewResourceConfig(), mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	inChan := make(chan message.Transaction)
+	newMgr.SetPipe("foo_in", inChan)
+
+	bloblProc := func(mapping string) processor.Config {
+		pConf := processor.NewConfig()
+		pConf.Type = processor.TypeBloblang
+		pConf.Bloblang = processor.BloblangConfig(mapping)
+		return pConf
+	}
+
+	conf := stream.NewConfig()
+	conf.Input.Type = input.TypeInproc
+	conf.Input.Inproc = "foo_in"
+	conf.Pipeline.Processors = []processor.Config{
+		bloblProc(`root = content().uppercase()`),
+		bloblProc(`root = content() + " a"`),
+	}
+	conf.Output.Type = output.TypeInproc
+	conf.Output.Inproc = "foo_out"
+
+	closedChan := make(chan struct{})
+	strm, err := stream.New(conf, newMgr, stream.OptOnClose(func() {
+		close(closedChan)
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, strm.Stop(time.Minute))
+	})
+
+	sendAndReceive := func(pipe, content, expected string) {
+		t.Helper()
+
+		var outChan <-chan message.Transaction
+		require.Eventually(t, func() bool {
+			outChan, err = newMgr.GetPipe(pipe)
+			return err == nil
+		}, time.Second, time.Millisecond*10)
+
+		resChan := make(chan error, 1)
+		select {
+		case inChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte(content)}), resChan):
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+		select {
+		case tran := <-outChan:
+			assert.Equal(t, expected, string(tran.Payload.Get(0).Get()))
+			require.NoError(t, tran.Ack(context.Background(), nil))
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+		select {
+		case err := <-resChan:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+	}
+
+	sendAndReceive("foo_out", "hello", "HELLO a")
+
+	replaced, err := strm.Reload(conf, time.Second*5)
+	require.NoError(t, err)
+	assert.Empty(t, replaced)
+
+	conf.Pipeline.Processors = []processor.Config{
+		bloblProc(`root = content().uppercase()`),
+		bloblProc(`root = content() + " b"`),
+	}
+	replaced, err = strm.Reload(conf, time.Second*5)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pipeline.processors.1"}, replaced)
+
+	sendAndReceive("foo_out", "hello", "HELLO b")
+
+	conf.Pipeline.Processors = conf.Pipeline.Processors[:1]
+	conf.Output.Inproc = "bar_out"
+	replaced, err = strm.Reload(conf, time.Second*5)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pipeline.processors.1", "output"}, replaced)
+
+	sendAndReceive("bar_out", "hello", "HELLO")
+
+	// Replacing the output must not be mistaken for the stream closing.
+	select {
+	case <-closedChan:
+		t.Fatal("stream closed after reload")
+	default:
+	}
+
+	badConf := conf
+	badConf.Pipeline.Processors = []processor.Config{bloblProc(`root = nope(`)}
+	_, err = strm.Reload(badConf, time.Second*5)
+	require.Error(t, err)
+
+	sendAndReceive("bar_out", "hello", "HELLO")
+
+	inConf := conf
+	inConf.Input.Inproc = "bar_in"
+	replaced, err = strm.Reload(inConf, time.Second*5)
+	require.True(t, errors.Is(err, stream.ErrRestartRequired))
+	assert.Equal(t, []string{"input"}, replaced)
+
+	sendAndReceive("bar_out", "hello", "HELLO")
+}
@@ -2,8 +2,10 @@ package stream
 
 import (
 	"bytes"
+	"errors"
 	"net/http"
 	"runtime/pprof"
+	"sync"
 	"time"
 
 	"github.com/benthosdev/benthos/v4/internal/bundle"
@@ -11,8 +13,10 @@ import (
 	ibuffer "github.com/benthosdev/benthos/v4/internal/component/buffer"
 	iinput "github.com/benthosdev/benthos/v4/internal/component/input"
 	ioutput "github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/internal/interop"
 	"github.com/benthosdev/benthos/v4/internal/message"
 	"github.com/benthosdev/benthos/v4/internal/pipeline"
+	"github.com/benthosdev/benthos/v4/internal/shutdown"
 )
 
 //------------------------------------------------------------------------------
@@ -22,11 +26,25 @@ type Type struct {
 	conf Config
 
 	inputLayer    iinput.Streamed
+	inputValve    *inputValve
 	bufferLayer   ibuffer.Streamed
 	pipelineLayer pipeline.Type
 	outputLayer   ioutput.Streamed
 
-	manager bundle.NewManagement
+	// The pipeline and output layers are fed through switches that allow them
+	// to be replaced when the stream is reloaded.
+	pipelineSwitch *feedSwitch
+	pipelineMerger *feedMerger
+	outputSwitch   *feedSwitch
+	processors     []*sharedProcessor
+	layersMut      sync.RWMutex
+
+	reloadMut sync.Mutex
+	stopping  bool
+	shutSig   *shutdown.Signaller
+
+	manager     bundle.NewManagement
+	inputLimits InputLimits
 
 	onClose func()
 }
@@ -37,22 +55,24 @@ func New(conf Config, mgr bundle.NewManagement, opts ...func(*Type)) (*Type, err
 		conf:    conf,
 		manager: mgr,
 		onClose: func() {},
+		shutSig: shutdown.NewSignaller(),
 	}
 	for _, opt := range opts {
 		opt(t)
 	}
+	t.trackFailSources(conf)
 	if err := t.start(); err != nil {
 		return nil, err
 	}
 
 	healthCheck := func(w http.ResponseWriter, r *http.Request) {
 		connected := true
-		if !t.inputLayer.Connected() {
+		if !t.input().Connected() {
 			connected = false
 			w.WriteHeader(http.StatusServiceUnavailable)
 			w.Write([]byte("input not connected\n"))
 		}
-		if !t.outputLayer.Connected() {
+		if !t.output().Connected() {
 			connected = false
 			w.WriteHeader(http.StatusServiceUnavailable)
 			w.Write([]byte("output not connected\n"))
@@ -69,6 +89,19 @@ func New(conf Config, mgr bundle.NewManagement, opts ...func(*Type)) (*Type, err
 	return t, nil
 }
 
+type failSourceManager interface {
+	WithFailSources() interop.Manager
+}
+
+// trackFailSources switches the manager of the stream to one where processors
+// annotate the messages they flag with errors with their path when the config
+// contains a dead_letter output, which reports them.
+func (t *Type) trackFailSources(conf Config) {
+	if fs, ok := t.manager.(failSourceManager); ok && conf.usesDeadLetter() {
+		t.manager = fs.WithFailSources().(bundle.NewManagement)
+	}
+}
+
 //------------------------------------------------------------------------------
 
 // OptOnClose sets a closure to be called when the stream closes.
@@ -78,18 +111,67 @@ func OptOnClose(onClose func()) func(*Type) {
 	}
 }
 
+// OptSetInputLimits sets limits on the rate at which the stream consumes
+// messages from its input.
+func OptSetInputLimits(limits InputLimits) func(*Type) {
+	return func(t *Type) {
+		t.inputLimits = limits
+	}
+}
+
 //------------------------------------------------------------------------------
 
 // IsReady returns a boolean indicating whether both the input and output layers
 // of the stream are connected.
 func (t *Type) IsReady() bool {
-	return t.inputLayer.Connected() && t.outputLayer.Connected()
+	return t.input().Connected() && t.output().Connected()
+}
+
+// InputConnected returns a boolean indicating whether the input layer of the
+// stream is connected.
+func (t *Type) InputConnected() bool {
+	return t.input().Connected()
+}
+
+// OutputConnected returns a boolean indicating whether the output layer of the
+// stream is connected.
+func (t *Type) OutputConnected() bool {
+	return t.output().Connected()
+}
+
+// Pause stops the stream from consuming messages from its input layer without
+// closing it, allowing in-flight messages to continue through the stream.
+// Returns false if the stream was already paused.
+func (t *Type) Pause() bool {
+	return t.inputValve.pause()
+}
+
+// Resume continues consuming messages from the input layer of a paused stream.
+// Returns false if the stream was not paused.
+func (t *Type) Resume() bool {
+	return t.inputValve.resume()
+}
+
+// SetInputLimits replaces the limits on the rate at which the stream consumes
+// messages from its input, which take effect from the next message read.
+func (t *Type) SetInputLimits(limits InputLimits) {
+	t.inputValve.setLimits(limits)
+}
+
+// IsPaused returns a boolean indicating whether the stream is paused.
+func (t *Type) IsPaused() bool {
+	return t.inputValve.paused()
+}
+
+// InFlight returns the number of messages read from the input that have not
+// yet been acknowledged.
+func (t *Type) InFlight() int {
+	return t.inputValve.inFlightCount()
 }
 
 func (t *Type) start() (err error) {
 	// Constructors
-	iMgr := t.manager.IntoPath("input").(bundle.NewManagement)
-	if t.inputLayer, err = iMgr.NewInput(t.conf.Input); err != nil {
+	if t.inputLayer, err = t.newInput(t.conf.Input); err != nil {
 		return
 	}
 	if t.conf.Buffer.Type != "none" {
@@ -98,60 +180,108 @@ func (t *Type) start() (err error) {
 			return
 		}
 	}
-	if tLen := len(t.conf.Pipeline.Processors); tLen > 0 {
-		pMgr := t.manager.IntoPath("pipeline")
-		if t.pipelineLayer, err = pipeline.New(t.conf.Pipeline, pMgr); err != nil {
+	t.processors = make([]*sharedProcessor, len(t.conf.Pipeline.Processors))
+	for i, pConf := range t.conf.Pipeline.Processors {
+		if t.processors[i], err = t.newProcessor(i, pConf); err != nil {
 			return
 		}
 	}
-	oMgr := t.manager.IntoPath("output").(bundle.NewManagement)
-	if t.outputLayer, err = oMgr.NewOutput(t.conf.Output); err != nil {
+	if t.pipelineLayer, err = t.newPipeline(t.conf.Pipeline.Threads, t.processors); err != nil {
+		return
+	}
+	if t.outputLayer, err = t.newOutput(t.conf.Output); err != nil {
 		return
 	}
 
 	// Start chaining components
 	var nextTranChan <-chan message.Transaction
 
-	nextTranChan = t.inputLayer.TransactionChan()
+	t.inputValve = newInputValve(t.inputLayer.TransactionChan(), t.inputLimits, t.manager.Metrics())
+	nextTranChan = t.inputValve.out
 	if t.bufferLayer != nil {
 		if err = t.bufferLayer.Consume(nextTranChan); err != nil {
 			return
 		}
 		nextTranChan = t.bufferLayer.TransactionChan()
 	}
+
+	var pipelineFeed <-chan message.Transaction
+	t.pipelineSwitch, pipelineFeed = newFeedSwitch(nextTranChan, t.shutSig)
+	nextTranChan = pipelineFeed
 	if t.pipelineLayer != nil {
 		if err = t.pipelineLayer.Consume(nextTranChan); err != nil {
 			return
 		}
 		nextTranChan = t.pipelineLayer.TransactionChan()
 	}
-	if err = t.outputLayer.Consume(nextTranChan); err != nil {
+	t.pipelineMerger = newFeedMerger(t.pipelineSwitch, nextTranChan, t.shutSig)
+
+	var outputFeed <-chan message.Transaction
+	t.outputSwitch, outputFeed = newFeedSwitch(t.pipelineMerger.out, t.shutSig)
+	if err = t.outputLayer.Consume(outputFeed); err != nil {
 		return
 	}
 
-	go func(out ioutput.Streamed) {
+	go func() {
 		for {
-			if err := out.WaitForClose(time.Second); err == nil {
+			// The output layer may be replaced by a reload, in which case the
+			// previous output closing does not mean the stream has closed.
+			out := t.output()
+			if err := out.WaitForClose(time.Second); err == nil && out == t.output() {
 				t.onClose()
 				return
 			}
 		}
-	}(t.outputLayer)
+	}()
 
 	return nil
 }
 
+func (t *Type) input() iinput.Streamed {
+	t.layersMut.RLock()
+	defer t.layersMut.RUnlock()
+	return t.inputLayer
+}
+
+func (t *Type) pipeline() pipeline.Type {
+	t.layersMut.RLock()
+	defer t.layersMut.RUnlock()
+	return t.pipelineLayer
+}
+
+func (t *Type) output() ioutput.Streamed {
+	t.layersMut.RLock()
+	defer t.layersMut.RUnlock()
+	return t.outputLayer
+}
+
+// setStopping prevents the stream from being reloaded once it begins to stop,
+// waiting for any reload in progress to finish.
+func (t *Type) setStopping() {
+	t.reloadMut.Lock()
+	t.stopping = true
+	t.reloadMut.Unlock()
+}
+
 // StopGracefully attempts to close the stream in the most graceful way by only
 // closing the input layer and waiting for all other layers to terminate by
 // proxy. This should guarantee that all in-flight and buffered data is resolved
 // before shutting down.
 func (t *Type) StopGracefully(timeout time.Duration) (err error) {
-	t.inputLayer.CloseAsync()
+	t.setStopping()
+	inputLayer, pipelineLayer, outputLayer := t.input(), t.pipeline(), t.output()
+
+	inputLayer.CloseAsync()
+	t.inputValve.resume()
 	started := time.Now()
-	if err = t.inputLayer.WaitForClose(timeout); err != nil {
+	if err = inputLayer.WaitForClose(timeout); err != nil {
 		return
 	}
 
+	// The valve is only closed once the input has, as closing it rejects any
+	// transactions that the input is still passing on.
+	t.inputValve.close()
+
 	var remaining time.Duration
 
 	// If we have a buffer then wait right here. We want to try and allow the
@@ -168,39 +298,104 @@ func (t *Type) StopGracefully(timeout time.Duration) (err error) {
 	}
 
 	// After this point we can start closing the remaining components.
-	if t.pipelineLayer != nil {
-		t.pipelineLayer.CloseAsync()
+	if pipelineLayer != nil {
+		pipelineLayer.CloseAsync()
 		remaining = timeout - time.Since(started)
 		if remaining < 0 {
 			return component.ErrTimeout
 		}
-		if err = t.pipelineLayer.WaitForClose(remaining); err != nil {
+		if err = pipelineLayer.WaitForClose(remaining); err != nil {
 			return
 		}
 	}
 
-	t.outputLayer.CloseAsync()
+	outputLayer.CloseAsync()
 	remaining = timeout - time.Since(started)
 	if remaining < 0 {
 		return component.ErrTimeout
 	}
-	if err = t.outputLayer.WaitForClose(remaining); err != nil {
+	if err = outputLayer.WaitForClose(remaining); err != nil {
 		return
 	}
 
 	return nil
 }
 
+// DrainInput closes the input layer of the stream and blocks until all messages
+// read from it have been acknowledged, or the timeout is reached. For streams
+// with a buffer a message is acknowledged once it is written to the buffer.
+// The remaining layers of the stream continue to run, and a new input layer can
+// be started with RestartInput.
+func (t *Type) DrainInput(timeout time.Duration) error {
+	t.reloadMut.Lock()
+	stopping := t.stopping
+	t.reloadMut.Unlock()
+	if stopping {
+		return component.ErrTypeClosed
+	}
+
+	inputLayer := t.input()
+	detached := t.inputValve.holdOpen()
+
+	inputLayer.CloseAsync()
+	t.inputValve.resume()
+	started := time.Now()
+	if err := inputLayer.WaitForClose(timeout); err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(timeout - time.Since(started))
+	defer timer.Stop()
+	for _, c := range []<-chan struct{}{detached, t.inputValve.idle()} {
+		select {
+		case <-c:
+		case <-timer.C:
+			return component.ErrTimeout
+		}
+	}
+	return nil
+}
+
+// RestartInput constructs a new input layer from the config of the stream in
+// order to replace an input layer that was closed with DrainInput. If the input
+// fails to construct the stream remains drained.
+func (t *Type) RestartInput() error {
+	t.reloadMut.Lock()
+	defer t.reloadMut.Unlock()
+	if t.stopping {
+		return component.ErrTypeClosed
+	}
+
+	in, err := t.newInput(t.conf.Input)
+	if err != nil {
+		return err
+	}
+	if !t.inputValve.attach(in.TransactionChan()) {
+		in.CloseAsync()
+		return errors.New("input has not been drained")
+	}
+
+	t.layersMut.Lock()
+	t.inputLayer = in
+	t.layersMut.Unlock()
+	return nil
+}
+
 // StopOrdered attempts to close all components of the stream in the order of
 // positions within the stream, this allows data to flush all the way through
 // the pipeline under certain circumstances but is less graceful than
 // stopGracefully, which should be attempted first.
 func (t *Type) StopOrdered(timeout time.Duration) (err error) {
-	t.inputLayer.CloseAsync()
+	t.setStopping()
+	inputLayer, pipelineLayer, outputLayer := t.input(), t.pipeline(), t.output()
+
+	inputLayer.CloseAsync()
+	t.inputValve.resume()
 	started := time.Now()
-	if err = t.inputLayer.WaitForClose(timeout); err != nil {
+	if err = inputLayer.WaitForClose(timeout); err != nil {
 		return
 	}
+	t.inputValve.close()
 
 	var remaining time.Duration
 
@@ -215,23 +410,23 @@ func (t *Type) StopOrdered(timeout time.Duration) (err error) {
 		}
 	}
 
-	if t.pipelineLayer != nil {
-		t.pipelineLayer.CloseAsync()
+	if pipelineLayer != nil {
+		pipelineLayer.CloseAsync()
 		remaining = timeout - time.Since(started)
 		if remaining < 0 {
 			return component.ErrTimeout
 		}
-		if err = t.pipelineLayer.WaitForClose(remaining); err != nil {
+		if err = pipelineLayer.WaitForClose(remaining); err != nil {
 			return
 		}
 	}
 
-	t.outputLayer.CloseAsync()
+	outputLayer.CloseAsync()
 	remaining = timeout - time.Since(started)
 	if remaining < 0 {
 		return component.ErrTimeout
 	}
-	if err = t.outputLayer.WaitForClose(remaining); err != nil {
+	if err = outputLayer.WaitForClose(remaining); err != nil {
 		return
 	}
 
@@ -242,17 +437,24 @@ func (t *Type) StopOrdered(timeout time.Duration) (err error) {
 // the stream to gracefully wind down in the order of component layers. This
 // should only be attempted if both stopGracefully and stopOrdered failed.
 func (t *Type) StopUnordered(timeout time.Duration) (err error) {
-	t.inputLayer.CloseAsync()
+	t.setStopping()
+	t.shutSig.CloseNow()
+
+	inputLayer, pipelineLayer, outputLayer := t.input(), t.pipeline(), t.output()
+
+	inputLayer.CloseAsync()
+	t.inputValve.resume()
+	t.inputValve.close()
 	if t.bufferLayer != nil {
 		t.bufferLayer.CloseAsync()
 	}
-	if t.pipelineLayer != nil {
-		t.pipelineLayer.CloseAsync()
+	if pipelineLayer != nil {
+		pipelineLayer.CloseAsync()
 	}
-	t.outputLayer.CloseAsync()
+	outputLayer.CloseAsync()
 
 	started := time.Now()
-	if err = t.inputLayer.WaitForClose(timeout); err != nil {
+	if err = inputLayer.WaitForClose(timeout); err != nil {
 		return
 	}
 
@@ -268,12 +470,12 @@ func (t *Type) StopUnordered(timeout time.Duration) (err error) {
 		}
 	}
 
-	if t.pipelineLayer != nil {
+	if pipelineLayer != nil {
 		remaining = timeout - time.Since(started)
 		if remaining < 0 {
 			return component.ErrTimeout
 		}
-		if err = t.pipelineLayer.WaitForClose(remaining); err != nil {
+		if err = pipelineLayer.WaitForClose(remaining); err != nil {
 			return
 		}
 	}
@@ -282,7 +484,7 @@ func (t *Type) StopUnordered(timeout time.Duration) (err error) {
 	if remaining < 0 {
 		return component.ErrTimeout
 	}
-	if err = t.outputLayer.WaitForClose(remaining); err != nil {
+	if err = outputLayer.WaitForClose(remaining); err != nil {
 		return
 	}
 
@@ -1,6 +1,10 @@
 package stream_test
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -8,9 +12,11 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/health"
 	"github.com/benthosdev/benthos/v4/internal/log"
 	"github.com/benthosdev/benthos/v4/internal/manager"
 	"github.com/benthosdev/benthos/v4/internal/manager/mock"
+	"github.com/benthosdev/benthos/v4/internal/message"
 	"github.com/benthosdev/benthos/v4/internal/old/input"
 	"github.com/benthosdev/benthos/v4/internal/old/output"
 	"github.com/benthosdev/benthos/v4/internal/old/processor"
@@ -122,3 +128,199 @@ func TestTypeCloseUnordered(t *testing.T) {
 	require.NoError(t, err)
 	assert.NoError(t, strm.StopUnordered(time.Minute))
 }
+
+func TestTypePauseResume(t *testing.T) {
+	newMgr, err := manager.NewV2(manager.NewResourceConfig(), mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	inChan := make(chan message.Transaction)
+	newMgr.SetPipe("foo_in", inChan)
+
+	conf := stream.NewConfig()
+	conf.Input.Type = input.TypeInproc
+	conf.Input.Inproc = "foo_in"
+	conf.Output.Type = output.TypeInproc
+	conf.Output.Inproc = "foo_out"
+
+	strm, err := stream.New(conf, newMgr)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, strm.Stop(time.Minute))
+	})
+
+	var outChan <-chan message.Transaction
+	require.Eventually(t, func() bool {
+		outChan, err = newMgr.GetPipe("foo_out")
+		return err == nil
+	}, time.Second, time.Millisecond*10)
+
+	send := func(content string) {
+		t.Helper()
+		resChan := make(chan error, 1)
+		select {
+		case inChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte(content)}), resChan):
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+	}
+
+	send("first")
+	select {
+	case tran := <-outChan:
+		assert.Equal(t, "first", string(tran.Payload.Get(0).Get()))
+		require.NoError(t, tran.Ack(context.Background(), nil))
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+
+	assert.False(t, strm.IsPaused())
+	assert.True(t, strm.Pause())
+	assert.False(t, strm.Pause())
+	assert.True(t, strm.IsPaused())
+
+	send("second")
+	select {
+	case <-outChan:
+		t.Fatal("unexpected message whilst paused")
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	assert.True(t, strm.Resume())
+	assert.False(t, strm.Resume())
+	assert.False(t, strm.IsPaused())
+
+	select {
+	case tran := <-outChan:
+		assert.Equal(t, "second", string(tran.Payload.Get(0).Get()))
+		require.NoError(t, tran.Ack(context.Background(), nil))
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+}
+
+func TestTypeDrainInput(t *testing.T) {
+	newMgr, err := manager.NewV2(manager.NewResourceConfig(), mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	inChan := make(chan message.Transaction)
+	newMgr.SetPipe("foo_in", inChan)
+
+	conf := stream.NewConfig()
+	conf.Input.Type = input.TypeInproc
+	conf.Input.Inproc = "foo_in"
+	conf.Output.Type = output.TypeInproc
+	conf.Output.Inproc = "foo_out"
+
+	var closed int32
+	strm, err := stream.New(conf, newMgr, stream.OptOnClose(func() {
+		atomic.StoreInt32(&closed, 1)
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, strm.Stop(time.Minute))
+	})
+
+	var outChan <-chan message.Transaction
+	require.Eventually(t, func() bool {
+		outChan, err = newMgr.GetPipe("foo_out")
+		return err == nil
+	}, time.Second, time.Millisecond*10)
+
+	send := func(content string) {
+		t.Helper()
+		resChan := make(chan error, 1)
+		select {
+		case inChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte(content)}), resChan):
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+	}
+	receive := func(content string) message.Transaction {
+		t.Helper()
+		select {
+		case tran := <-outChan:
+			assert.Equal(t, content, string(tran.Payload.Get(0).Get()))
+			return tran
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+		return message.Transaction{}
+	}
+
+	require.Error(t, strm.RestartInput())
+
+	send("first")
+	tran := receive("first")
+	assert.Equal(t, 1, strm.InFlight())
+
+	drainErr := make(chan error, 1)
+	go func() {
+		drainErr <- strm.DrainInput(time.Second * 5)
+	}()
+
+	select {
+	case err := <-drainErr:
+		t.Fatalf("drain finished with a message in flight: %v", err)
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	require.NoError(t, tran.Ack(context.Background(), nil))
+	select {
+	case err := <-drainErr:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+
+	// The remaining layers of the stream are left running.
+	assert.Equal(t, int32(0), atomic.LoadInt32(&closed))
+	assert.Equal(t, 0, strm.InFlight())
+
+	require.NoError(t, strm.RestartInput())
+	send("second")
+	tran = receive("second")
+	require.NoError(t, tran.Ack(context.Background(), nil))
+}
+
+func TestTypeHealth(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	reg := health.NewRegistry()
+	newMgr, err := manager.NewV2(manager.NewResourceConfig(), mock.NewManager(), log.Noop(), metrics.Noop(), manager.OptSetHealthRegistry(reg))
+	require.NoError(t, err)
+
+	conf := stream.NewConfig()
+	conf.Input.Type = input.TypeGenerate
+	conf.Input.Generate.Mapping = `root = "hello"`
+	conf.Input.Generate.Interval = "10ms"
+	conf.Output.Label = "foo"
+	conf.Output.Type = output.TypeHTTPClient
+	conf.Output.HTTPClient.URL = ts.URL
+	conf.Output.HTTPClient.NumRetries = 0
+
+	strm, err := stream.New(conf, newMgr)
+	require.NoError(t, err)
+
+	statuses := reg.Statuses(health.Thresholds{})
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "root.input", statuses[0].Path)
+	assert.Equal(t, health.KindInput, statuses[0].Kind)
+	assert.Equal(t, "root.output", statuses[1].Path)
+	assert.Equal(t, health.KindOutput, statuses[1].Kind)
+	assert.Equal(t, "foo", statuses[1].Label)
+
+	assert.Eventually(t, func() bool {
+		statuses = reg.Statuses(health.Thresholds{})
+		return statuses[0].LastSuccess != nil && statuses[1].LastError != ""
+	}, time.Second*5, time.Millisecond*10)
+	assert.Equal(t, health.StateHealthy, statuses[0].State)
+	assert.Nil(t, statuses[1].LastSuccess)
+	assert.Contains(t, statuses[1].LastError, "502")
+	assert.Equal(t, health.StateDegraded, statuses[1].State)
+
+	require.NoError(t, strm.Stop(time.Minute))
+	assert.Empty(t, reg.Statuses(health.Thresholds{}))
+}
@@ -0,0 +1,357 @@
+package stream
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+// InputLimits describes limits on the rate at which a stream consumes messages
+// from its input. A zero value for any field means that it is unlimited.
+type InputLimits struct {
+	// The maximum number of messages that may be in flight at any given time,
+	// a message is in flight from when it is read from the input until it is
+	// acknowledged.
+	MaxInFlight int
+
+	// The maximum number of messages to read from the input per second.
+	MaxRate float64
+}
+
+// inputValve sits between the input layer and the rest of a stream and, whilst
+// closed, stops reading transactions from the input. Since inputs block until
+// their transactions are consumed this halts ingestion without tearing down
+// the input and its connections.
+//
+// The valve can also be held open whilst its input is closed, in which case the
+// rest of the stream continues to run and a new input can be attached later.
+type inputValve struct {
+	in  <-chan message.Transaction
+	out chan message.Transaction
+
+	mut        sync.Mutex
+	pauseChan  chan struct{}
+	resumeChan chan struct{}
+	detached   chan struct{}
+	attachChan chan (<-chan message.Transaction)
+	closeChan  chan struct{}
+	closeOnce  sync.Once
+
+	inFlightMut  sync.Mutex
+	limits       InputLimits
+	inFlight     int
+	idleChan     chan struct{}
+	releasedChan chan struct{}
+	nextRead     time.Time
+
+	mInFlight  metrics.StatGauge
+	mThrottled metrics.StatCounter
+}
+
+func newInputValve(in <-chan message.Transaction, limits InputLimits, stats metrics.Type) *inputValve {
+	v := &inputValve{
+		in:           in,
+		out:          make(chan message.Transaction),
+		pauseChan:    make(chan struct{}),
+		attachChan:   make(chan (<-chan message.Transaction), 1),
+		closeChan:    make(chan struct{}),
+		limits:       limits,
+		idleChan:     make(chan struct{}),
+		releasedChan: make(chan struct{}),
+		mInFlight:    stats.GetGauge("quota_in_flight"),
+		mThrottled:   stats.GetCounter("quota_throttled"),
+	}
+	close(v.idleChan)
+	go v.loop()
+	return v
+}
+
+// setLimits replaces the limits of the valve, which apply to the next message
+// read from the input.
+func (v *inputValve) setLimits(limits InputLimits) {
+	v.inFlightMut.Lock()
+	v.limits = limits
+	v.inFlightMut.Unlock()
+
+	// Wake up a read waiting on the previous max in flight.
+	v.notifyReleased()
+}
+
+func (v *inputValve) getLimits() InputLimits {
+	v.inFlightMut.Lock()
+	defer v.inFlightMut.Unlock()
+	return v.limits
+}
+
+func (v *inputValve) notifyReleased() {
+	v.inFlightMut.Lock()
+	close(v.releasedChan)
+	v.releasedChan = make(chan struct{})
+	v.inFlightMut.Unlock()
+}
+
+// acquire blocks until n messages are permitted to be in flight, returning
+// false if the valve was closed in the meantime. A batch larger than the limit
+// is permitted once nothing else is in flight.
+func (v *inputValve) acquire(n int) bool {
+	throttled := false
+	for {
+		v.inFlightMut.Lock()
+		if maxInFlight := v.limits.MaxInFlight; maxInFlight <= 0 || v.inFlight == 0 || v.inFlight+n <= maxInFlight {
+			if v.inFlight == 0 {
+				v.idleChan = make(chan struct{})
+			}
+			v.inFlight += n
+			v.mInFlight.Set(int64(v.inFlight))
+			v.inFlightMut.Unlock()
+			return true
+		}
+		releasedChan := v.releasedChan
+		v.inFlightMut.Unlock()
+
+		if !throttled {
+			v.mThrottled.Incr(1)
+			throttled = true
+		}
+		select {
+		case <-releasedChan:
+		case <-v.closeChan:
+			return false
+		}
+	}
+}
+
+func (v *inputValve) release(n int) {
+	v.inFlightMut.Lock()
+	v.inFlight -= n
+	if v.inFlight == 0 {
+		close(v.idleChan)
+	}
+	v.mInFlight.Set(int64(v.inFlight))
+	v.inFlightMut.Unlock()
+	v.notifyReleased()
+}
+
+// inFlightCount returns the number of messages currently in flight.
+func (v *inputValve) inFlightCount() int {
+	v.inFlightMut.Lock()
+	defer v.inFlightMut.Unlock()
+	return v.inFlight
+}
+
+// idle returns a channel that is closed once no messages are in flight.
+func (v *inputValve) idle() <-chan struct{} {
+	v.inFlightMut.Lock()
+	defer v.inFlightMut.Unlock()
+	return v.idleChan
+}
+
+// throttle blocks until n messages are permitted to be read according to the
+// max rate, spreading reads evenly over time. Returns false if the valve was
+// closed in the meantime.
+func (v *inputValve) throttle(n int, maxRate float64) bool {
+	now := time.Now()
+	if v.nextRead.After(now) {
+		v.mThrottled.Incr(1)
+		timer := time.NewTimer(v.nextRead.Sub(now))
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-v.closeChan:
+			return false
+		}
+	} else {
+		v.nextRead = now
+	}
+	v.nextRead = v.nextRead.Add(time.Duration(float64(n) / maxRate * float64(time.Second)))
+	return true
+}
+
+// limit blocks until a transaction is permitted by the limits of the valve,
+// and returns it wrapped so that it is tracked whilst in flight. Returns false
+// if the valve was closed in the meantime.
+func (v *inputValve) limit(tran message.Transaction) (message.Transaction, bool) {
+	n := tran.Payload.Len()
+	if maxRate := v.getLimits().MaxRate; maxRate > 0 {
+		if !v.throttle(n, maxRate) {
+			return tran, false
+		}
+	}
+
+	if !v.acquire(n) {
+		return tran, false
+	}
+	var releaseOnce sync.Once
+	return message.NewTransactionFunc(tran.Payload, func(ctx context.Context, err error) error {
+		releaseOnce.Do(func() {
+			v.release(n)
+		})
+		return tran.Ack(ctx, err)
+	}), true
+}
+
+// send passes a transaction on, waiting whilst the valve is paused. Returns
+// false if the valve was closed in the meantime.
+func (v *inputValve) send(tran message.Transaction) bool {
+	for {
+		v.mut.Lock()
+		resumeChan := v.resumeChan
+		v.mut.Unlock()
+		if resumeChan == nil {
+			break
+		}
+		select {
+		case <-resumeChan:
+		case <-v.closeChan:
+			return false
+		}
+	}
+
+	// Prefer passing the transaction on when the stream is able to take it.
+	select {
+	case v.out <- tran:
+		return true
+	default:
+	}
+	select {
+	case v.out <- tran:
+		return true
+	case <-v.closeChan:
+		return false
+	}
+}
+
+func (v *inputValve) loop() {
+	defer close(v.out)
+	for {
+		v.mut.Lock()
+		pauseChan, resumeChan := v.pauseChan, v.resumeChan
+		v.mut.Unlock()
+
+		if resumeChan != nil {
+			select {
+			case <-resumeChan:
+			case <-v.closeChan:
+			}
+			continue
+		}
+
+		select {
+		case tran, open := <-v.in:
+			if !open {
+				if v.in = v.awaitInput(); v.in == nil {
+					return
+				}
+				continue
+			}
+			var ok bool
+			if tran, ok = v.limit(tran); ok {
+				ok = v.send(tran)
+			}
+			if !ok {
+				// The stream is stopping and so the transaction is rejected,
+				// allowing the input to close without waiting on it.
+				_ = tran.Ack(context.Background(), component.ErrTypeClosed)
+				return
+			}
+		case <-pauseChan:
+		}
+	}
+}
+
+// awaitInput is called once the current input has closed, and if the valve is
+// held open blocks until a new input is attached. Returns nil if the valve
+// should close.
+func (v *inputValve) awaitInput() <-chan message.Transaction {
+	v.mut.Lock()
+	detached := v.detached
+	v.mut.Unlock()
+	if detached == nil {
+		return nil
+	}
+
+	close(detached)
+	select {
+	case in := <-v.attachChan:
+		return in
+	case <-v.closeChan:
+		return nil
+	}
+}
+
+// holdOpen prevents the closure of the current input from closing the valve.
+// Returns a channel that is closed once the input has closed and all of its
+// transactions have been passed on.
+func (v *inputValve) holdOpen() <-chan struct{} {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	if v.detached == nil {
+		v.detached = make(chan struct{})
+	}
+	return v.detached
+}
+
+// attach feeds the valve from a new input, returns false if the valve is not
+// being held open with a closed input.
+func (v *inputValve) attach(in <-chan message.Transaction) bool {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	if v.detached == nil {
+		return false
+	}
+	select {
+	case <-v.detached:
+	default:
+		return false
+	}
+	v.detached = nil
+	v.attachChan <- in
+	return true
+}
+
+// close signals that the stream is stopping, which stops a valve that is held
+// open from waiting for a new input, and aborts any wait on the limits of the
+// valve.
+func (v *inputValve) close() {
+	v.closeOnce.Do(func() {
+		close(v.closeChan)
+	})
+}
+
+// pause stops transactions from being read from the input, returns false if
+// the valve was already paused.
+func (v *inputValve) pause() bool {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	if v.resumeChan != nil {
+		return false
+	}
+	v.resumeChan = make(chan struct{})
+	close(v.pauseChan)
+	return true
+}
+
+// resume allows transactions to be read from the input once more, returns
+// false if the valve was not paused.
+func (v *inputValve) resume() bool {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	if v.resumeChan == nil {
+		return false
+	}
+	close(v.resumeChan)
+	v.resumeChan = nil
+	v.pauseChan = make(chan struct{})
+	return true
+}
+
+// paused returns true if the valve is currently paused.
+func (v *inputValve) paused() bool {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	return v.resumeChan != nil
+}
@@ -0,0 +1,172 @@
+package stream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+func TestInputValveMaxInFlight(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	in := make(chan message.Transaction)
+	v := newInputValve(in, InputLimits{MaxInFlight: 2}, metrics.Noop())
+
+	go func() {
+		for _, p := range []string{"foo", "bar", "baz"} {
+			in <- message.NewTransaction(message.QuickBatch([][]byte{[]byte(p)}), make(chan error, 1))
+		}
+		close(in)
+	}()
+
+	var trans []message.Transaction
+	for i := 0; i < 2; i++ {
+		select {
+		case tran := <-v.out:
+			trans = append(trans, tran)
+		case <-tCtx.Done():
+			t.Fatal("timed out")
+		}
+	}
+	assert.Equal(t, 2, v.inFlightCount())
+
+	select {
+	case <-v.out:
+		t.Fatal("received message beyond max in flight")
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	require.NoError(t, trans[0].Ack(tCtx, nil))
+
+	select {
+	case tran := <-v.out:
+		assert.Equal(t, "baz", string(tran.Payload.Get(0).Get()))
+		require.NoError(t, tran.Ack(tCtx, nil))
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+	require.NoError(t, trans[1].Ack(tCtx, nil))
+	assert.Equal(t, 0, v.inFlightCount())
+
+	select {
+	case _, open := <-v.out:
+		assert.False(t, open)
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+}
+
+func TestInputValveMaxRate(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	in := make(chan message.Transaction)
+	v := newInputValve(in, InputLimits{MaxRate: 50}, metrics.Noop())
+
+	go func() {
+		for i := 0; i < 6; i++ {
+			in <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("foo")}), make(chan error, 1))
+		}
+		close(in)
+	}()
+
+	started := time.Now()
+	for i := 0; i < 6; i++ {
+		select {
+		case <-v.out:
+		case <-tCtx.Done():
+			t.Fatal("timed out")
+		}
+	}
+
+	// The first message is immediate and each subsequent message is spaced by
+	// 20ms.
+	assert.GreaterOrEqual(t, time.Since(started), time.Millisecond*100)
+}
+
+func TestInputValveCloseWhilstLimited(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	for name, limits := range map[string]InputLimits{
+		"max in flight": {MaxInFlight: 1},
+		"max rate":      {MaxRate: 0.001},
+	} {
+		limits := limits
+		t.Run(name, func(t *testing.T) {
+			in := make(chan message.Transaction)
+			v := newInputValve(in, limits, metrics.Noop())
+
+			first := make(chan error, 1)
+			in <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("first")}), first)
+			select {
+			case <-v.out:
+			case <-tCtx.Done():
+				t.Fatal("timed out")
+			}
+
+			// The second message waits on the limits until the valve closes,
+			// at which point it is rejected.
+			second := make(chan error, 1)
+			in <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("second")}), second)
+			v.close()
+
+			select {
+			case err := <-second:
+				assert.Equal(t, component.ErrTypeClosed, err)
+			case <-tCtx.Done():
+				t.Fatal("timed out")
+			}
+			select {
+			case _, open := <-v.out:
+				assert.False(t, open)
+			case <-tCtx.Done():
+				t.Fatal("timed out")
+			}
+		})
+	}
+}
+
+func TestInputValveSetLimits(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	in := make(chan message.Transaction)
+	v := newInputValve(in, InputLimits{MaxInFlight: 1}, metrics.Noop())
+
+	go func() {
+		for _, p := range []string{"foo", "bar"} {
+			in <- message.NewTransaction(message.QuickBatch([][]byte{[]byte(p)}), make(chan error, 1))
+		}
+	}()
+
+	select {
+	case <-v.out:
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+	select {
+	case <-v.out:
+		t.Fatal("received message beyond max in flight")
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	// Raising the limit releases the waiting message without anything being
+	// acknowledged.
+	v.setLimits(InputLimits{MaxInFlight: 2})
+	select {
+	case tran := <-v.out:
+		assert.Equal(t, "bar", string(tran.Payload.Get(0).Get()))
+	case <-tCtx.Done():
+		t.Fatal("timed out")
+	}
+	assert.Equal(t, 2, v.inFlightCount())
+}
@@ -62,6 +62,40 @@ func (e *Environment) Parse(blobl string) (*Executor, error) {
 	return newExecutor(exec), nil
 }
 
+// LintWarning describes a potential problem within a Bloblang mapping that was
+// found by a static analysis, but doesn't prevent the mapping from being
+// executed.
+type LintWarning struct {
+	Line   int
+	Column int
+	What   string
+}
+
+// ParseWithWarnings parses a Bloblang mapping in the same way as Parse, and
+// additionally performs a static analysis of the mapping in order to return
+// warnings of potential problems. This includes queries that will definitely
+// fail due to the types of values they are applied to, unreachable match cases
+// and if branches, unused or undeclared variables, and the use of deprecated
+// functions and methods.
+func (e *Environment) ParseWithWarnings(blobl string) (*Executor, []LintWarning, error) {
+	exec, err := e.env.NewMapping(blobl)
+	if err != nil {
+		if pErr, ok := err.(*parser.Error); ok {
+			return nil, nil, internalToPublicParserError([]rune(blobl), pErr)
+		}
+		return nil, nil, err
+	}
+	var warnings []LintWarning
+	for _, l := range exec.Lint(nil) {
+		warnings = append(warnings, LintWarning{
+			Line:   l.Line,
+			Column: l.Column,
+			What:   l.What,
+		})
+	}
+	return newExecutor(exec), warnings, nil
+}
+
 // RegisterMethod adds a new Bloblang method to the environment. All method
 // names must match the regular expression /^[a-z0-9]+(_[a-z0-9]+)*$/ (snake
 // case).
@@ -103,6 +137,9 @@ func (e *Environment) RegisterMethodV2(name string, spec *PluginSpec, ctor Metho
 	}
 	iSpec := query.NewMethodSpec(name, spec.description).InCategory(category, "", examples...)
 	iSpec.Params = spec.params
+	if spec.deprecated {
+		iSpec.Status = query.StatusDeprecated
+	}
 	return e.env.RegisterMethod(iSpec, func(target query.Function, args *query.ParsedParams) (query.Function, error) {
 		fn, err := ctor(&ParsedParams{par: args})
 		if err != nil {
@@ -155,6 +192,9 @@ func (e *Environment) RegisterFunctionV2(name string, spec *PluginSpec, ctor Fun
 	}
 	iSpec := query.NewFunctionSpec(category, name, spec.description, examples...)
 	iSpec.Params = spec.params
+	if spec.deprecated {
+		iSpec.Status = query.StatusDeprecated
+	}
 	return e.env.RegisterFunction(iSpec, func(args *query.ParsedParams) (query.Function, error) {
 		fn, err := ctor(&ParsedParams{par: args})
 		if err != nil {
@@ -1,6 +1,7 @@
 package bloblang
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -79,6 +80,28 @@ func TestEnvironmentV2(t *testing.T) {
 	assert.Equal(t, "bar", v)
 }
 
+func TestEnvironmentPluginMethodsNotFolded(t *testing.T) {
+	env := NewEnvironment()
+
+	var calls int
+	require.NoError(t, env.RegisterMethodV2("counted", NewPluginSpec(), func(_ *ParsedParams) (Method, error) {
+		return StringMethod(func(s string) (interface{}, error) {
+			calls++
+			return fmt.Sprintf("%v:%v", s, calls), nil
+		}), nil
+	}))
+
+	exe, err := env.Parse(`root = "foo".counted()`)
+	require.NoError(t, err)
+	assert.Equal(t, 0, calls)
+
+	for _, exp := range []string{"foo:1", "foo:2"} {
+		v, err := exe.Query(nil)
+		require.NoError(t, err)
+		assert.Equal(t, exp, v)
+	}
+}
+
 func TestEmptyEnvironment(t *testing.T) {
 	env := NewEmptyEnvironment()
 
@@ -106,3 +129,36 @@ func TestEnvironmentDisabledImports(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "imports are disabled in this context")
 }
+
+func TestEnvironmentParseWithWarnings(t *testing.T) {
+	env := NewEnvironment()
+
+	require.NoError(t, env.RegisterFunctionV2("old_bar", NewPluginSpec().Deprecated(), func(_ *ParsedParams) (Function, error) {
+		return func() (interface{}, error) {
+			return "bar", nil
+		}, nil
+	}))
+
+	exe, warnings, err := env.ParseWithWarnings(`let unused = "nope"
+root.foo = this.foo.number().uppercase()
+root.bar = old_bar()`)
+	require.NoError(t, err)
+	assert.Equal(t, []LintWarning{
+		{Line: 1, Column: 1, What: "variable unused is declared but never used"},
+		{Line: 2, Column: 1, What: "method uppercase: expected string or bytes value, got number from method number"},
+		{Line: 3, Column: 1, What: "function old_bar is deprecated"},
+	}, warnings)
+
+	v, err := exe.Query(map[string]interface{}{"foo": "nah"})
+	require.Error(t, err)
+	assert.Nil(t, v)
+
+	_, warnings, err = env.ParseWithWarnings(`root = this.foo.uppercase()`)
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	_, _, err = env.ParseWithWarnings(`root = this.foo.`)
+	require.Error(t, err)
+	_, isParseErr := err.(*ParseError)
+	assert.True(t, isParseErr)
+}
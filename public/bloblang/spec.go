@@ -84,6 +84,7 @@ func (d ParamDefinition) Default(v interface{}) ParamDefinition {
 type PluginSpec struct {
 	category    string
 	description string
+	deprecated  bool
 	params      query.Params
 	examples    []pluginExample
 }
@@ -116,6 +117,13 @@ func (p *PluginSpec) Description(str string) *PluginSpec {
 	return p
 }
 
+// Deprecated marks the plugin as deprecated, mappings that use the plugin will
+// be given a lint warning suggesting that it should no longer be used.
+func (p *PluginSpec) Deprecated() *PluginSpec {
+	p.deprecated = true
+	return p
+}
+
 // Example adds an optional example to the plugin spec, this is used when
 // generating documentation for the plugin. An example consists of a short
 // summary, a mapping demonstrating the plugin, and one or more input/output
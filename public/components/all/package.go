@@ -15,6 +15,7 @@ import (
 	_ "github.com/benthosdev/benthos/v4/internal/impl/generic"
 	_ "github.com/benthosdev/benthos/v4/internal/impl/influxdb"
 	_ "github.com/benthosdev/benthos/v4/internal/impl/jaeger"
+	_ "github.com/benthosdev/benthos/v4/internal/impl/jwt"
 	_ "github.com/benthosdev/benthos/v4/internal/impl/kafka"
 	_ "github.com/benthosdev/benthos/v4/internal/impl/maxmind"
 	_ "github.com/benthosdev/benthos/v4/internal/impl/memcached"
@@ -187,6 +187,13 @@ func (c *ConfigField) Optional() *ConfigField {
 	return c
 }
 
+// Secret marks a config field as containing sensitive information, such as a
+// password, and therefore its value will be redacted when a config is printed.
+func (c *ConfigField) Secret() *ConfigField {
+	c.field = c.field.Secret()
+	return c
+}
+
 // Example adds an example value to the field which will be shown when printing
 // documentation for the component config spec.
 func (c *ConfigField) Example(e interface{}) *ConfigField {
@@ -502,6 +502,8 @@ func (s *StreamBuilder) SetFields(pathValues ...interface{}) error {
 		return err
 	}
 
+	// Secrets are not redacted here as the node is decoded back into the
+	// config of the builder.
 	if err := config.Spec().SanitiseYAML(&rootNode, docs.SanitiseConfig{
 		RemoveTypeField:  true,
 		RemoveDeprecated: false,
@@ -616,7 +618,8 @@ func (s *StreamBuilder) SetLoggerYAML(conf string) error {
 //------------------------------------------------------------------------------
 
 // AsYAML prints a YAML representation of the stream config as it has been
-// currently built.
+// currently built. The values of fields containing sensitive information, and
+// credentials embedded within URLs, are redacted.
 func (s *StreamBuilder) AsYAML() (string, error) {
 	conf := s.buildConfig()
 
@@ -628,6 +631,7 @@ func (s *StreamBuilder) AsYAML() (string, error) {
 	if err := config.Spec().SanitiseYAML(&node, docs.SanitiseConfig{
 		RemoveTypeField:  true,
 		RemoveDeprecated: false,
+		RedactSecrets:    true,
 		DocsProvider:     s.env.internal,
 	}); err != nil {
 		return "", err
@@ -712,6 +716,7 @@ func (s *StreamBuilder) buildWithEnv(env *bundle.Environment) (*Stream, error) {
 		if err == nil {
 			_ = config.Spec().SanitiseYAML(&sanitNode, docs.SanitiseConfig{
 				RemoveTypeField: true,
+				RedactSecrets:   true,
 				DocsProvider:    env,
 			})
 		}
@@ -732,6 +737,16 @@ func (s *StreamBuilder) buildWithEnv(env *bundle.Environment) (*Stream, error) {
 		return nil, err
 	}
 
+	healthThresholds, err := s.http.Health.Thresholds()
+	if err != nil {
+		return nil, err
+	}
+	mgr.RegisterEndpoint(
+		"/health",
+		"Returns the health of each input, output and resource as a JSON object. A 503 is returned if any component is unhealthy.",
+		mgr.Health().Handler(healthThresholds),
+	)
+
 	if s.producerChan != nil {
 		mgr.SetPipe(s.producerID, s.producerChan)
 	}
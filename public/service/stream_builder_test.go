@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -202,6 +203,62 @@ logger:
 	}
 }
 
+func TestStreamBuilderSecrets(t *testing.T) {
+	authChan := make(chan string, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case authChan <- r.Header.Get("Authorization"):
+		default:
+		}
+	}))
+	defer ts.Close()
+
+	b := service.NewStreamBuilder()
+	require.NoError(t, b.SetYAML(fmt.Sprintf(`
+input:
+  generate:
+    count: 1
+    interval: ""
+    mapping: 'root = "hello world"'
+output:
+  http_client:
+    url: %v
+    verb: POST
+    headers:
+      Authorization: Bearer foo
+logger:
+  level: NONE
+`, ts.URL)))
+
+	// Setting fields must not lose the values of secrets.
+	require.NoError(t, b.SetFields("output.http_client.verb", "PUT"))
+
+	act, err := b.AsYAML()
+	require.NoError(t, err)
+	assert.Contains(t, act, "Authorization: '!!!SECRET_SCRUBBED!!!'")
+	assert.NotContains(t, act, "Bearer foo")
+
+	strm, err := b.Build()
+	require.NoError(t, err)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	go func() {
+		_ = strm.Run(ctx)
+	}()
+	defer func() {
+		require.NoError(t, strm.StopWithin(time.Second*5))
+	}()
+
+	select {
+	case auth := <-authChan:
+		assert.Equal(t, "Bearer foo", auth)
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+}
+
 func TestStreamBuilderConsumerFunc(t *testing.T) {
 	tmpDir := t.TempDir()
 